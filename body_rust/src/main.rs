@@ -3,10 +3,16 @@ mod accessibility;
 mod effector;
 
 use accessibility::UIElement;
-use anyhow::Result;
+use anyhow::{Context, Result};
+use std::collections::HashSet;
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::{Arc, Mutex};
 use std::time::Duration;
 use tokio::sync::mpsc;
 use tokio::time;
+use tonic::metadata::{Ascii, MetadataValue};
+use tonic::transport::Channel;
+use tonic::{Request, Status};
 use windows::Win32::System::Com::{
     CoCreateInstance, CoInitializeEx, CLSCTX_INPROC_SERVER, COINIT_MULTITHREADED,
 };
@@ -19,13 +25,29 @@ pub mod ghost_proto {
 use ghost_proto::nervous_system_client::NervousSystemClient;
 use ghost_proto::FocusState;
 
+/// Reads the Body's kernel credential from GHOST_BODY_TOKEN or the kernel's generated token file
+fn body_token() -> Result<MetadataValue<Ascii>> {
+    let token = match std::env::var("GHOST_BODY_TOKEN") {
+        Ok(token) if !token.trim().is_empty() => token,
+        _ => {
+            let path = std::env::var("GHOST_BODY_TOKEN_FILE")
+                .unwrap_or_else(|_| "../conscience_go/data/tokens/body.token".to_string());
+            std::fs::read_to_string(&path)
+                .with_context(|| format!("Failed to read body token from {}", path))?
+        }
+    };
+    format!("Bearer {}", token.trim())
+        .parse()
+        .context("Body token is not valid header text")
+}
+
 #[tokio::main]
 async fn main() -> Result<()> {
     println!("[SENTINEL] Body Online. Connecting to Conscience...");
 
-    // 1. Connect to Kernel (gRPC)
-    let client = loop {
-        match NervousSystemClient::connect("http://127.0.0.1:50051").await {
+    // 1. Connect to Kernel (gRPC); every call carries the Body's role token
+    let channel = loop {
+        match Channel::from_static("http://127.0.0.1:50051").connect().await {
             Ok(c) => {
                 println!("[SENTINEL] Connected to Nervous System.");
                 break c;
@@ -37,8 +59,16 @@ async fn main() -> Result<()> {
         }
     };
 
-    // Clone for the two concurrent tasks
+    // The kernel writes a generated token on first start, so read it once it is up
+    let token = body_token()?;
+    let client = NervousSystemClient::with_interceptor(channel, move |mut req: Request<()>| {
+        req.metadata_mut().insert("authorization", token.clone());
+        Ok::<_, Status>(req)
+    });
+
+    // Clone for the concurrent tasks
     let mut focus_client = client.clone();
+    let mut outcome_client = client.clone();
     let mut action_client = client;
 
     println!("[SENTINEL] Vision Active. Streaming Focus...");
@@ -99,8 +129,27 @@ async fn main() -> Result<()> {
                 let mut stream = response.into_inner();
 
                 // Spawn a dedicated thread for the Effector (needs its own thread for input sim)
+                // Each command carries the stop generation it arrived in; an emergency stop bumps
+                // the generation so everything still buffered is dropped instead of executed.
                 let (action_tx, action_rx) =
-                    std::sync::mpsc::channel::<ghost_proto::ActionCommand>();
+                    std::sync::mpsc::channel::<(u64, ghost_proto::ActionCommand)>();
+                let stop_generation = Arc::new(AtomicU64::new(0));
+                let effector_generation = Arc::clone(&stop_generation);
+                // Traces of cancelled goals; their buffered commands are dropped the same way
+                let aborted_traces: Arc<Mutex<HashSet<String>>> =
+                    Arc::new(Mutex::new(HashSet::new()));
+                let effector_aborted = Arc::clone(&aborted_traces);
+
+                // Outcomes go back to the kernel so it can close each command's trace
+                let (outcome_tx, mut outcome_rx) =
+                    mpsc::unbounded_channel::<ghost_proto::ActionOutcome>();
+                tokio::spawn(async move {
+                    while let Some(outcome) = outcome_rx.recv().await {
+                        if let Err(e) = outcome_client.report_outcome(outcome).await {
+                            eprintln!("[SENTINEL] Failed to report outcome: {}", e);
+                        }
+                    }
+                });
 
                 std::thread::spawn(move || {
                     let mut eff = match effector::Effector::new() {
@@ -114,12 +163,41 @@ async fn main() -> Result<()> {
                         }
                     };
 
-                    while let Ok(cmd) = action_rx.recv() {
+                    while let Ok((generation, cmd)) = action_rx.recv() {
+                        if generation != effector_generation.load(Ordering::SeqCst) {
+                            println!(
+                                "[EFFECTOR] Dropped after emergency stop: {}",
+                                cmd.command_id
+                            );
+                            let _ = outcome_tx.send(ghost_proto::ActionOutcome {
+                                command_id: cmd.command_id.clone(),
+                                trace_id: cmd.trace_id.clone(),
+                                success: false,
+                                error: "dropped after emergency stop".to_string(),
+                            });
+                            continue;
+                        }
+                        let aborted = effector_aborted
+                            .lock()
+                            .map_or(false, |traces| traces.contains(&cmd.trace_id));
+                        if aborted {
+                            println!(
+                                "[EFFECTOR] Dropped after goal cancellation: {}",
+                                cmd.command_id
+                            );
+                            let _ = outcome_tx.send(ghost_proto::ActionOutcome {
+                                command_id: cmd.command_id.clone(),
+                                trace_id: cmd.trace_id.clone(),
+                                success: false,
+                                error: "dropped after goal cancellation".to_string(),
+                            });
+                            continue;
+                        }
                         if let Some(action) = &cmd.action {
                             let action_type = action.r#type.to_uppercase();
                             println!(
-                                "[EFFECTOR] Executing: {} ({})",
-                                action_type, cmd.command_id
+                                "[EFFECTOR] Executing: {} ({}, trace {})",
+                                action_type, cmd.command_id, cmd.trace_id
                             );
 
                             // Convert proto payload map<string,string> to serde_json::Value
@@ -162,22 +240,80 @@ async fn main() -> Result<()> {
                                 }
                             };
 
-                            match result {
+                            let error = match &result {
                                 Ok(()) => {
-                                    println!("[EFFECTOR] Completed: {}", cmd.command_id)
+                                    println!("[EFFECTOR] Completed: {}", cmd.command_id);
+                                    String::new()
                                 }
                                 Err(e) => {
-                                    eprintln!("[EFFECTOR] Failed {}: {}", cmd.command_id, e)
+                                    eprintln!("[EFFECTOR] Failed {}: {}", cmd.command_id, e);
+                                    e.to_string()
                                 }
-                            }
+                            };
+                            let _ = outcome_tx.send(ghost_proto::ActionOutcome {
+                                command_id: cmd.command_id.clone(),
+                                trace_id: cmd.trace_id.clone(),
+                                success: result.is_ok(),
+                                error,
+                            });
                         }
                     }
                 });
 
                 // Read actions from gRPC stream and forward to effector thread
                 while let Ok(Some(cmd)) = stream.message().await {
-                    println!("[SENTINEL] Action received: {}", cmd.command_id);
-                    let _ = action_tx.send(cmd);
+                    println!(
+                        "[SENTINEL] Action received: {} (trace {})",
+                        cmd.command_id, cmd.trace_id
+                    );
+
+                    // The kernel's kill switch: abort everything queued for the Effector
+                    let is_stop = cmd
+                        .action
+                        .as_ref()
+                        .map_or(false, |a| a.r#type.eq_ignore_ascii_case("STOP"));
+                    if is_stop {
+                        let reason = cmd
+                            .action
+                            .as_ref()
+                            .and_then(|a| a.payload.get("reason"))
+                            .map(|s| s.as_str())
+                            .unwrap_or("");
+                        stop_generation.fetch_add(1, Ordering::SeqCst);
+                        println!("[SENTINEL] EMERGENCY STOP: {}", reason);
+                        continue;
+                    }
+
+                    // A cancelled goal: drop every command still buffered under its traces
+                    let is_abort = cmd
+                        .action
+                        .as_ref()
+                        .map_or(false, |a| a.r#type.eq_ignore_ascii_case("ABORT"));
+                    if is_abort {
+                        let payload = cmd.action.as_ref().map(|a| &a.payload);
+                        let field = |key: &str| {
+                            payload
+                                .and_then(|p| p.get(key))
+                                .map(|s| s.as_str())
+                                .unwrap_or("")
+                        };
+                        if let Ok(mut traces) = aborted_traces.lock() {
+                            traces.extend(
+                                field("trace_ids")
+                                    .split(',')
+                                    .filter(|t| !t.is_empty())
+                                    .map(String::from),
+                            );
+                        }
+                        println!(
+                            "[SENTINEL] GOAL ABORTED: {} ({})",
+                            field("goal_id"),
+                            field("reason")
+                        );
+                        continue;
+                    }
+
+                    let _ = action_tx.send((stop_generation.load(Ordering::SeqCst), cmd));
                 }
             }
             Err(e) => {
@@ -1,4 +1,7 @@
 # // Author: Enkae (enkae.dev@pm.me)
+import os
+import socket
+import threading
 import requests
 import time
 import ollama
@@ -8,6 +11,9 @@ from typing import Optional, Dict, Any
 API_URL = "http://localhost:3000/api/artifacts"
 ENRICH_URL_TEMPLATE = "http://localhost:3000/api/artifacts/{}/enrich"
 GOAL_URL = "http://localhost:3000/api/goal"
+GOAL_PLAN_URL_TEMPLATE = "http://localhost:3000/api/goal/{}/plan"
+GOAL_FAIL_URL_TEMPLATE = "http://localhost:3000/api/goal/{}/fail"
+GOAL_HEARTBEAT_URL_TEMPLATE = "http://localhost:3000/api/goal/{}/heartbeat"
 PROPOSE_URL = "http://localhost:3000/api/propose"
 VECTOR_SEARCH_URL = "http://localhost:3000/api/search/vector"
 ACTION_STATUS_URL_TEMPLATE = "http://localhost:3000/api/actions/{}"
@@ -16,6 +22,9 @@ SLEEP_INTERVAL = 5
 PLANNER_INTERVAL = 2  # Poll for goals every 2 seconds
 ACTION_POLL_INTERVAL = 0.5  # Poll action status every 500ms
 STATE_POLL_INTERVAL = 1  # Poll state every 1 second
+WORKER_ID = f"{socket.gethostname()}-{os.getpid()}"  # Names this planner on goal leases
+GOAL_LEASE = 60  # Seconds a claimed goal stays ours without a heartbeat
+GOAL_WAIT = 10  # Seconds the kernel holds a goal poll open waiting for work
 
 def load_model() -> SentenceTransformer:
     print("[PYTHON] Loading SentenceTransformer model...")
@@ -226,9 +235,10 @@ def search_memory(goal_text: str, model: Optional[SentenceTransformer] = None) -
         return "Memory search failed."
 
 def fetch_active_goal() -> Optional[Dict[str, Any]]:
-    """Poll for an active goal from the Go API"""
+    """Claim the oldest active goal under a lease, long-polling until one is available"""
     try:
-        response = requests.get(GOAL_URL, timeout=5)
+        params = {"worker": WORKER_ID, "lease": f"{GOAL_LEASE}s", "wait": f"{GOAL_WAIT}s"}
+        response = requests.get(GOAL_URL, params=params, timeout=GOAL_WAIT + 5)
         response.raise_for_status()
         goal_data = response.json()
 
@@ -321,9 +331,11 @@ Now generate the plan for: '{goal_text}'"""
         print(f"[PLANNER] Error generating plan: {e}")
         return []
 
-def propose_action(intent: str, payload: Dict[str, Any], risk_score: int, domain: str = "PLANNER") -> Optional[str]:
+def propose_action(intent: str, payload: Dict[str, Any], risk_score: int, domain: str = "PLANNER",
+                   goal_id: Optional[str] = None, goal_step: Optional[int] = None) -> Optional[str]:
     """
     Send an action proposal to the Permission Kernel.
+    When goal_id is given, the proposal is linked to step goal_step of that goal's plan.
     Returns the action ID if successful, None otherwise.
     """
     try:
@@ -334,6 +346,9 @@ def propose_action(intent: str, payload: Dict[str, Any], risk_score: int, domain
             "domain": domain,
             "interaction_type": "PERMISSION"
         }
+        if goal_id is not None:
+            action_payload["goal_id"] = goal_id
+            action_payload["goal_step"] = goal_step
 
         response = requests.post(PROPOSE_URL, json=action_payload, timeout=5)
         response.raise_for_status()
@@ -395,11 +410,117 @@ def poll_action_status(action_id: str, timeout_seconds: int = 300) -> str:
             time.sleep(ACTION_POLL_INTERVAL)
             continue
 
-def update_goal_status(goal_id: str, status: str) -> bool:
-    """Update goal status (mock implementation - would need new API endpoint)"""
-    # For now, just log. In production, you'd call PUT /api/goal/{id}
-    print(f"[PLANNER] Goal {goal_id[:8]} status: {status}")
-    return True
+class GoalLease:
+    """
+    Keeps a claimed goal's lease alive from a background thread.
+    lost is set when the kernel refuses a heartbeat, meaning another planner may own the goal.
+    """
+
+    def __init__(self, goal_id: str):
+        self.goal_id = goal_id
+        self.lost = threading.Event()
+        self._stop = threading.Event()
+        self._thread = threading.Thread(target=self._run, daemon=True)
+
+    def __enter__(self):
+        self._thread.start()
+        return self
+
+    def __exit__(self, *exc):
+        self._stop.set()
+        self._thread.join()
+
+    def _run(self):
+        url = GOAL_HEARTBEAT_URL_TEMPLATE.format(self.goal_id)
+        params = {"worker": WORKER_ID, "lease": f"{GOAL_LEASE}s"}
+        while not self._stop.wait(GOAL_LEASE / 3):
+            try:
+                response = requests.post(url, params=params, timeout=5)
+                if response.status_code == 409:
+                    print(f"[PLANNER] ✗ Lost the lease on goal {self.goal_id[:8]}")
+                    self.lost.set()
+                    return
+                response.raise_for_status()
+            except requests.exceptions.RequestException as e:
+                print(f"[PLANNER] Heartbeat failed: {e}")
+
+def submit_plan(goal_id: str, plan: list[Dict[str, Any]]) -> bool:
+    """
+    Store the plan's steps on the goal (PUT /api/goal/{id}/plan).
+    The kernel then derives the goal's status from the outcome of each step's proposal.
+    """
+    steps = [{"intent": step.get('intent', 'Unknown action')} for step in plan]
+    try:
+        response = requests.put(GOAL_PLAN_URL_TEMPLATE.format(goal_id), params={"worker": WORKER_ID},
+                                json={"steps": steps}, timeout=5)
+        response.raise_for_status()
+        print(f"[PLANNER] Plan of {len(steps)} steps stored for goal {goal_id[:8]}")
+        return True
+    except requests.exceptions.RequestException as e:
+        print(f"[PLANNER] ✗ Failed to store plan: {e}")
+        return False
+
+def fail_goal(goal_id: str) -> bool:
+    """Mark a goal FAILED when it cannot be planned or a step never finished (POST /api/goal/{id}/fail)"""
+    try:
+        response = requests.post(GOAL_FAIL_URL_TEMPLATE.format(goal_id), params={"worker": WORKER_ID}, timeout=5)
+        response.raise_for_status()
+        print(f"[PLANNER] Goal {goal_id[:8]} status: FAILED")
+        return True
+    except requests.exceptions.RequestException as e:
+        print(f"[PLANNER] ✗ Failed to mark goal as failed: {e}")
+        return False
+
+def run_goal(goal_id: str, goal_text: str, lease: GoalLease) -> None:
+    """Plan a claimed goal and execute its steps one at a time"""
+    # Generate plan
+    plan = generate_plan(goal_text)
+
+    if not plan:
+        print("[PLANNER] ✗ Failed to generate plan. Marking goal as FAILED.")
+        fail_goal(goal_id)
+        return
+
+    if not submit_plan(goal_id, plan):
+        return
+
+    # Submit each step to Permission Kernel SEQUENTIALLY
+    print(f"\n[PLANNER] Executing {len(plan)} steps SEQUENTIALLY...\n")
+
+    for idx, step in enumerate(plan):
+        if lease.lost.is_set():
+            print("[PLANNER] ✗ Lease lost, leaving the goal to its new planner")
+            return
+
+        intent = step.get('intent', 'Unknown action')
+        payload = step.get('payload', {})
+        risk_score = step.get('risk_score', 50)
+
+        print(f"\n[PLANNER] Step {idx + 1}/{len(plan)}: {intent}")
+
+        # Propose the action to the Permission Kernel
+        action_id = propose_action(intent, payload, risk_score, domain="PLANNER",
+                                   goal_id=goal_id, goal_step=idx)
+
+        if not action_id:
+            print(f"[PLANNER] ✗ Failed to propose step {idx + 1}, stopping plan execution")
+            fail_goal(goal_id)
+            return
+
+        # CRITICAL: Wait for this action to complete before moving to next step
+        final_status = poll_action_status(action_id, timeout_seconds=300)
+
+        if final_status != 'COMPLETED':
+            print(f"[PLANNER] ✗ Step {idx + 1} did not complete successfully (status: {final_status})")
+            print(f"[PLANNER] Stopping plan execution")
+            # Failed and rejected steps already failed the goal; a timeout has to say so
+            fail_goal(goal_id)
+            return
+
+        print(f"[PLANNER] ✓ Step {idx + 1}/{len(plan)} completed, proceeding to next step")
+
+    # The kernel marks the goal COMPLETED once its last step completes
+    print(f"\n[PLANNER] ✓ All {len(plan)} steps completed successfully")
 
 def planner_loop():
     """
@@ -432,54 +553,12 @@ def planner_loop():
         goal_id = goal.get('id', 'unknown')
         goal_text = goal.get('goal', '')
 
-        print(f"\n[PLANNER] 🎯 Active goal detected: {goal_text}")
-        print(f"[PLANNER]    Goal ID: {goal_id[:8]}")
-
-        # Update goal status to PLANNING
-        update_goal_status(goal_id, 'PLANNING')
-
-        # Generate plan
-        plan = generate_plan(goal_text)
+        print(f"\n[PLANNER] 🎯 Goal claimed: {goal_text}")
+        print(f"[PLANNER]    Goal ID: {goal_id[:8]} | Worker: {WORKER_ID}")
 
-        if not plan:
-            print("[PLANNER] ✗ Failed to generate plan. Marking goal as FAILED.")
-            update_goal_status(goal_id, 'FAILED')
-            time.sleep(PLANNER_INTERVAL)
-            continue
-
-        # Submit each step to Permission Kernel SEQUENTIALLY
-        print(f"\n[PLANNER] Executing {len(plan)} steps SEQUENTIALLY...\n")
-
-        for idx, step in enumerate(plan):
-            intent = step.get('intent', 'Unknown action')
-            payload = step.get('payload', {})
-            risk_score = step.get('risk_score', 50)
-
-            print(f"\n[PLANNER] Step {idx + 1}/{len(plan)}: {intent}")
-
-            # Propose the action to the Permission Kernel
-            action_id = propose_action(intent, payload, risk_score, domain="PLANNER")
-
-            if not action_id:
-                print(f"[PLANNER] ✗ Failed to propose step {idx + 1}, stopping plan execution")
-                update_goal_status(goal_id, 'FAILED')
-                break
-
-            # CRITICAL: Wait for this action to complete before moving to next step
-            final_status = poll_action_status(action_id, timeout_seconds=300)
-
-            if final_status != 'COMPLETED':
-                print(f"[PLANNER] ✗ Step {idx + 1} did not complete successfully (status: {final_status})")
-                print(f"[PLANNER] Stopping plan execution")
-                update_goal_status(goal_id, 'FAILED')
-                break
-
-            print(f"[PLANNER] ✓ Step {idx + 1}/{len(plan)} completed, proceeding to next step")
-
-        else:
-            # All steps completed successfully (for-else)
-            print(f"\n[PLANNER] ✓ All {len(plan)} steps completed successfully")
-            update_goal_status(goal_id, 'COMPLETED')
+        # The lease keeps other planners off this goal until we finish or stop heartbeating
+        with GoalLease(goal_id) as lease:
+            run_goal(goal_id, goal_text, lease)
 
         # Wait before checking for next goal
         time.sleep(PLANNER_INTERVAL)
@@ -26,7 +26,7 @@ from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 from google.protobuf import empty_pb2 as google_dot_protobuf_dot_empty__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0bghost.proto\x12\x05ghost\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\"R\n\nFocusState\x12\x14\n\x0cwindow_title\x18\x01 \x01(\t\x12\x14\n\x0cprocess_name\x18\x02 \x01(\t\x12\x18\n\x10ui_tree_snapshot\x18\x03 \x01(\t\"U\n\x11PermissionRequest\x12\x0e\n\x06intent\x18\x01 \x01(\t\x12\x1e\n\x07\x61\x63tions\x18\x02 \x03(\x0b\x32\r.ghost.Action\x12\x10\n\x08trace_id\x18\x03 \x01(\t\"K\n\x12PermissionResponse\x12\x10\n\x08\x61pproved\x18\x01 \x01(\x08\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x13\n\x0btrust_score\x18\x03 \x01(\x05\"s\n\x06\x41\x63tion\x12\x0c\n\x04type\x18\x01 \x01(\t\x12+\n\x07payload\x18\x02 \x03(\x0b\x32\x1a.ghost.Action.PayloadEntry\x1a.\n\x0cPayloadEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"B\n\rActionCommand\x12\x12\n\ncommand_id\x18\x01 \x01(\t\x12\x1d\n\x06\x61\x63tion\x18\x02 \x01(\x0b\x32\r.ghost.Action\"0\n\x0bPendingList\x12!\n\x05items\x18\x01 \x03(\x0b\x32\x12.ghost.PendingItem\"D\n\x0bPendingItem\x12\x11\n\taction_id\x18\x01 \x01(\t\x12\x0e\n\x06intent\x18\x02 \x01(\t\x12\x12\n\nrisk_score\x18\x03 \x01(\x05\"7\n\x10\x41pprovalDecision\x12\x11\n\taction_id\x18\x01 \x01(\t\x12\x10\n\x08\x61pproved\x18\x02 \x01(\x08\"+\n\x0bModeRequest\x12\x0e\n\x06\x64omain\x18\x01 \x01(\t\x12\x0c\n\x04mode\x18\x02 \x01(\t\"2\n\x0bSystemState\x12\r\n\x05state\x18\x01 \x01(\t\x12\x14\n\x0c\x61\x63tive_focus\x18\x02 \x01(\t\"\x16\n\x03\x41\x63k\x12\x0f\n\x07success\x18\x01 \x01(\x08\x32\xa9\x04\n\rNervousSystem\x12:\n\x0bReportFocus\x12\x11.ghost.FocusState\x1a\x16.google.protobuf.Empty(\x01\x12H\n\x11RequestPermission\x12\x18.ghost.PermissionRequest\x1a\x19.ghost.PermissionResponse\x12?\n\rStreamActions\x12\x16.google.protobuf.Empty\x1a\x14.ghost.ActionCommand0\x01\x12X\n\x13GetPendingApprovals\x12\x16.google.protobuf.Empty\x1a\x12.ghost.PendingList\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/approvals\x12U\n\rApproveAction\x12\x17.ghost.ApprovalDecision\x1a\n.ghost.Ack\"\x1f\x82\xd3\xe4\x93\x02\x19\"\x17/v1/approve/{action_id}\x12H\n\rSetSystemMode\x12\x12.ghost.ModeRequest\x1a\n.ghost.Ack\"\x17\x82\xd3\xe4\x93\x02\x11\"\x0f/v1/system/mode\x12V\n\x0eGetSystemState\x12\x16.google.protobuf.Empty\x1a\x12.ghost.SystemState\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/system/stateB Z\x1eghost/kernel/internal/protocolb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0bghost.proto\x12\x05ghost\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\"R\n\nFocusState\x12\x14\n\x0cwindow_title\x18\x01 \x01(\t\x12\x14\n\x0cprocess_name\x18\x02 \x01(\t\x12\x18\n\x10ui_tree_snapshot\x18\x03 \x01(\t\"f\n\x11PermissionRequest\x12\x0e\n\x06intent\x18\x01 \x01(\t\x12\x1e\n\x07\x61\x63tions\x18\x02 \x03(\x0b\x32\r.ghost.Action\x12\x10\n\x08trace_id\x18\x03 \x01(\t\x12\x0f\n\x07\x64ry_run\x18\x04 \x01(\x08\"p\n\x12PermissionResponse\x12\x10\n\x08\x61pproved\x18\x01 \x01(\x08\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x13\n\x0btrust_score\x18\x03 \x01(\x05\x12#\n\x05trace\x18\x04 \x01(\x0b\x32\x14.ghost.DecisionTrace\"\xe9\x01\n\rDecisionTrace\x12\x0c\n\x04path\x18\x01 \x01(\t\x12\x10\n\x08\x64\x65\x63ision\x18\x02 \x01(\t\x12\x0c\n\x04rule\x18\x03 \x01(\t\x12\x0e\n\x06reason\x18\x04 \x01(\t\x12 \n\x06\x63hecks\x18\x05 \x03(\x0b\x32\x10.ghost.RuleCheck\x12\"\n\x07\x61\x63tions\x18\x06 \x03(\x0b\x32\x11.ghost.ActionRisk\x12\x0c\n\x04mode\x18\x07 \x01(\t\x12\x13\n\x0btrust_score\x18\x08 \x01(\x05\x12 \n\x05\x66ocus\x18\t \x01(\x0b\x32\x11.ghost.FocusCheck\x12\x0f\n\x07\x64ry_run\x18\n \x01(\x08\"9\n\tRuleCheck\x12\x0c\n\x04rule\x18\x01 \x01(\t\x12\x0e\n\x06passed\x18\x02 \x01(\x08\x12\x0e\n\x06\x64\x65tail\x18\x03 \x01(\t\"7\n\nActionRisk\x12\r\n\x05index\x18\x01 \x01(\x05\x12\x0c\n\x04type\x18\x02 \x01(\t\x12\x0c\n\x04risk\x18\x03 \x01(\x05\"?\n\nFocusCheck\x12\x10\n\x08\x65xpected\x18\x01 \x01(\t\x12\x0e\n\x06\x61\x63tual\x18\x02 \x01(\t\x12\x0f\n\x07matched\x18\x03 \x01(\x08\"s\n\x06\x41\x63tion\x12\x0c\n\x04type\x18\x01 \x01(\t\x12+\n\x07payload\x18\x02 \x03(\x0b\x32\x1a.ghost.Action.PayloadEntry\x1a.\n\x0cPayloadEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"T\n\rActionCommand\x12\x12\n\ncommand_id\x18\x01 \x01(\t\x12\x1d\n\x06\x61\x63tion\x18\x02 \x01(\x0b\x32\r.ghost.Action\x12\x10\n\x08trace_id\x18\x03 \x01(\t\"U\n\rActionOutcome\x12\x12\n\ncommand_id\x18\x01 \x01(\t\x12\x10\n\x08trace_id\x18\x02 \x01(\t\x12\x0f\n\x07success\x18\x03 \x01(\x08\x12\r\n\x05\x65rror\x18\x04 \x01(\t\"0\n\x0bPendingList\x12!\n\x05items\x18\x01 \x03(\x0b\x32\x12.ghost.PendingItem\"D\n\x0bPendingItem\x12\x11\n\taction_id\x18\x01 \x01(\t\x12\x0e\n\x06intent\x18\x02 \x01(\t\x12\x12\n\nrisk_score\x18\x03 \x01(\x05\"E\n\x10\x41pprovalDecision\x12\x11\n\taction_id\x18\x01 \x01(\t\x12\x10\n\x08\x61pproved\x18\x02 \x01(\x08\x12\x0c\n\x04\x63ode\x18\x03 \x01(\t\"+\n\x0bModeRequest\x12\x0e\n\x06\x64omain\x18\x01 \x01(\t\x12\x0c\n\x04mode\x18\x02 \x01(\t\"_\n\x0bSystemState\x12\r\n\x05state\x18\x01 \x01(\t\x12\x14\n\x0c\x61\x63tive_focus\x18\x02 \x01(\t\x12\x16\n\x0e\x65mergency_stop\x18\x03 \x01(\x08\x12\x13\n\x0bstop_reason\x18\x04 \x01(\t\"\x1d\n\x0bStopRequest\x12\x0e\n\x06reason\x18\x01 \x01(\t\"\x1e\n\x0cRearmRequest\x12\x0e\n\x06reason\x18\x01 \x01(\t\"s\n\nStopResult\x12\x0f\n\x07\x65ngaged\x18\x01 \x01(\x08\x12\r\n\x05state\x18\x02 \x01(\t\x12\x0e\n\x06reason\x18\x03 \x01(\t\x12\x19\n\x11\x63\x61ncelled_actions\x18\x04 \x01(\x05\x12\x1a\n\x12\x63\x61ncelled_commands\x18\x05 \x01(\x05\"\x16\n\x03\x41\x63k\x12\x0f\n\x07success\x18\x01 \x01(\x08\"4\n\x11\x43\x61ncelGoalRequest\x12\x0f\n\x07goal_id\x18\x01 \x01(\t\x12\x0e\n\x06reason\x18\x02 \x01(\t\"\x95\x01\n\x10\x43\x61ncelGoalResult\x12\x0f\n\x07goal_id\x18\x01 \x01(\t\x12\x0e\n\x06status\x18\x02 \x01(\t\x12\x18\n\x10rejected_actions\x18\x03 \x01(\x05\x12\x19\n\x11\x63\x61ncelled_actions\x18\x04 \x01(\x05\x12\x1a\n\x12\x63\x61ncelled_commands\x18\x05 \x01(\x05\x12\x0f\n\x07\x61\x62orted\x18\x06 \x01(\x08\"\xa4\x01\n\x05Grant\x12\x10\n\x08grant_id\x18\x01 \x01(\t\x12\x13\n\x0b\x61\x63tion_type\x18\x02 \x01(\t\x12\x0f\n\x07process\x18\x03 \x01(\t\x12\x13\n\x0bpath_prefix\x18\x04 \x01(\t\x12\x12\n\nexpires_at\x18\x05 \x01(\x03\x12\x12\n\ncreated_by\x18\x06 \x01(\t\x12\x12\n\ncreated_at\x18\x07 \x01(\x03\x12\x12\n\nrevoked_at\x18\x08 \x01(\x03\"i\n\x12\x43reateGrantRequest\x12\x13\n\x0b\x61\x63tion_type\x18\x01 \x01(\t\x12\x0f\n\x07process\x18\x02 \x01(\t\x12\x13\n\x0bpath_prefix\x18\x03 \x01(\t\x12\x18\n\x10\x64uration_seconds\x18\x04 \x01(\x03\"-\n\x11ListGrantsRequest\x12\x18\n\x10include_inactive\x18\x01 \x01(\x08\")\n\tGrantList\x12\x1c\n\x06grants\x18\x01 \x03(\x0b\x32\x0c.ghost.Grant\"&\n\x12RevokeGrantRequest\x12\x10\n\x08grant_id\x18\x01 \x01(\t2\xd1\x08\n\rNervousSystem\x12:\n\x0bReportFocus\x12\x11.ghost.FocusState\x1a\x16.google.protobuf.Empty(\x01\x12H\n\x11RequestPermission\x12\x18.ghost.PermissionRequest\x1a\x19.ghost.PermissionResponse\x12?\n\rStreamActions\x12\x16.google.protobuf.Empty\x1a\x14.ghost.ActionCommand0\x01\x12\x31\n\rReportOutcome\x12\x14.ghost.ActionOutcome\x1a\n.ghost.Ack\x12X\n\x13GetPendingApprovals\x12\x16.google.protobuf.Empty\x1a\x12.ghost.PendingList\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/approvals\x12U\n\rApproveAction\x12\x17.ghost.ApprovalDecision\x1a\n.ghost.Ack\"\x1f\x82\xd3\xe4\x93\x02\x19\"\x17/v1/approve/{action_id}\x12H\n\rSetSystemMode\x12\x12.ghost.ModeRequest\x1a\n.ghost.Ack\"\x17\x82\xd3\xe4\x93\x02\x11\"\x0f/v1/system/mode\x12V\n\x0eGetSystemState\x12\x16.google.protobuf.Empty\x1a\x12.ghost.SystemState\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/system/state\x12S\n\rEmergencyStop\x12\x12.ghost.StopRequest\x1a\x11.ghost.StopResult\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/system/estop\x12L\n\x05Rearm\x12\x13.ghost.RearmRequest\x1a\x11.ghost.StopResult\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/system/rearm\x12\\\n\nCancelGoal\x12\x18.ghost.CancelGoalRequest\x1a\x17.ghost.CancelGoalResult\"\x1b\x82\xd3\xe4\x93\x02\x15*\x13/v1/goals/{goal_id}\x12M\n\x0b\x43reateGrant\x12\x19.ghost.CreateGrantRequest\x1a\x0c.ghost.Grant\"\x15\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n/v1/grants\x12L\n\nListGrants\x12\x18.ghost.ListGrantsRequest\x1a\x10.ghost.GrantList\"\x12\x82\xd3\xe4\x93\x02\x0c\x12\n/v1/grants\x12U\n\x0bRevokeGrant\x12\x19.ghost.RevokeGrantRequest\x1a\x0c.ghost.Grant\"\x1d\x82\xd3\xe4\x93\x02\x17*\x15/v1/grants/{grant_id}B Z\x1eghost/kernel/internal/protocolb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -44,30 +44,72 @@ if not _descriptor._USE_C_DESCRIPTORS:
   _globals['_NERVOUSSYSTEM'].methods_by_name['SetSystemMode']._serialized_options = b'\202\323\344\223\002\021\"\017/v1/system/mode'
   _globals['_NERVOUSSYSTEM'].methods_by_name['GetSystemState']._loaded_options = None
   _globals['_NERVOUSSYSTEM'].methods_by_name['GetSystemState']._serialized_options = b'\202\323\344\223\002\022\022\020/v1/system/state'
+  _globals['_NERVOUSSYSTEM'].methods_by_name['EmergencyStop']._loaded_options = None
+  _globals['_NERVOUSSYSTEM'].methods_by_name['EmergencyStop']._serialized_options = b'\202\323\344\223\002\025:\001*\"\020/v1/system/estop'
+  _globals['_NERVOUSSYSTEM'].methods_by_name['Rearm']._loaded_options = None
+  _globals['_NERVOUSSYSTEM'].methods_by_name['Rearm']._serialized_options = b'\202\323\344\223\002\025:\001*\"\020/v1/system/rearm'
+  _globals['_NERVOUSSYSTEM'].methods_by_name['CancelGoal']._loaded_options = None
+  _globals['_NERVOUSSYSTEM'].methods_by_name['CancelGoal']._serialized_options = b'\202\323\344\223\002\025*\023/v1/goals/{goal_id}'
+  _globals['_NERVOUSSYSTEM'].methods_by_name['CreateGrant']._loaded_options = None
+  _globals['_NERVOUSSYSTEM'].methods_by_name['CreateGrant']._serialized_options = b'\202\323\344\223\002\017:\001*\"\n/v1/grants'
+  _globals['_NERVOUSSYSTEM'].methods_by_name['ListGrants']._loaded_options = None
+  _globals['_NERVOUSSYSTEM'].methods_by_name['ListGrants']._serialized_options = b'\202\323\344\223\002\014\022\n/v1/grants'
+  _globals['_NERVOUSSYSTEM'].methods_by_name['RevokeGrant']._loaded_options = None
+  _globals['_NERVOUSSYSTEM'].methods_by_name['RevokeGrant']._serialized_options = b'\202\323\344\223\002\027*\025/v1/grants/{grant_id}'
   _globals['_FOCUSSTATE']._serialized_start=81
   _globals['_FOCUSSTATE']._serialized_end=163
   _globals['_PERMISSIONREQUEST']._serialized_start=165
-  _globals['_PERMISSIONREQUEST']._serialized_end=250
-  _globals['_PERMISSIONRESPONSE']._serialized_start=252
-  _globals['_PERMISSIONRESPONSE']._serialized_end=327
-  _globals['_ACTION']._serialized_start=329
-  _globals['_ACTION']._serialized_end=444
-  _globals['_ACTION_PAYLOADENTRY']._serialized_start=398
-  _globals['_ACTION_PAYLOADENTRY']._serialized_end=444
-  _globals['_ACTIONCOMMAND']._serialized_start=446
-  _globals['_ACTIONCOMMAND']._serialized_end=512
-  _globals['_PENDINGLIST']._serialized_start=514
-  _globals['_PENDINGLIST']._serialized_end=562
-  _globals['_PENDINGITEM']._serialized_start=564
-  _globals['_PENDINGITEM']._serialized_end=632
-  _globals['_APPROVALDECISION']._serialized_start=634
-  _globals['_APPROVALDECISION']._serialized_end=689
-  _globals['_MODEREQUEST']._serialized_start=691
-  _globals['_MODEREQUEST']._serialized_end=734
-  _globals['_SYSTEMSTATE']._serialized_start=736
-  _globals['_SYSTEMSTATE']._serialized_end=786
-  _globals['_ACK']._serialized_start=788
-  _globals['_ACK']._serialized_end=810
-  _globals['_NERVOUSSYSTEM']._serialized_start=813
-  _globals['_NERVOUSSYSTEM']._serialized_end=1366
+  _globals['_PERMISSIONREQUEST']._serialized_end=267
+  _globals['_PERMISSIONRESPONSE']._serialized_start=269
+  _globals['_PERMISSIONRESPONSE']._serialized_end=381
+  _globals['_DECISIONTRACE']._serialized_start=384
+  _globals['_DECISIONTRACE']._serialized_end=617
+  _globals['_RULECHECK']._serialized_start=619
+  _globals['_RULECHECK']._serialized_end=676
+  _globals['_ACTIONRISK']._serialized_start=678
+  _globals['_ACTIONRISK']._serialized_end=733
+  _globals['_FOCUSCHECK']._serialized_start=735
+  _globals['_FOCUSCHECK']._serialized_end=798
+  _globals['_ACTION']._serialized_start=800
+  _globals['_ACTION']._serialized_end=915
+  _globals['_ACTION_PAYLOADENTRY']._serialized_start=869
+  _globals['_ACTION_PAYLOADENTRY']._serialized_end=915
+  _globals['_ACTIONCOMMAND']._serialized_start=917
+  _globals['_ACTIONCOMMAND']._serialized_end=1001
+  _globals['_ACTIONOUTCOME']._serialized_start=1003
+  _globals['_ACTIONOUTCOME']._serialized_end=1088
+  _globals['_PENDINGLIST']._serialized_start=1090
+  _globals['_PENDINGLIST']._serialized_end=1138
+  _globals['_PENDINGITEM']._serialized_start=1140
+  _globals['_PENDINGITEM']._serialized_end=1208
+  _globals['_APPROVALDECISION']._serialized_start=1210
+  _globals['_APPROVALDECISION']._serialized_end=1279
+  _globals['_MODEREQUEST']._serialized_start=1281
+  _globals['_MODEREQUEST']._serialized_end=1324
+  _globals['_SYSTEMSTATE']._serialized_start=1326
+  _globals['_SYSTEMSTATE']._serialized_end=1421
+  _globals['_STOPREQUEST']._serialized_start=1423
+  _globals['_STOPREQUEST']._serialized_end=1452
+  _globals['_REARMREQUEST']._serialized_start=1454
+  _globals['_REARMREQUEST']._serialized_end=1484
+  _globals['_STOPRESULT']._serialized_start=1486
+  _globals['_STOPRESULT']._serialized_end=1601
+  _globals['_ACK']._serialized_start=1603
+  _globals['_ACK']._serialized_end=1625
+  _globals['_CANCELGOALREQUEST']._serialized_start=1627
+  _globals['_CANCELGOALREQUEST']._serialized_end=1679
+  _globals['_CANCELGOALRESULT']._serialized_start=1682
+  _globals['_CANCELGOALRESULT']._serialized_end=1831
+  _globals['_GRANT']._serialized_start=1834
+  _globals['_GRANT']._serialized_end=1998
+  _globals['_CREATEGRANTREQUEST']._serialized_start=2000
+  _globals['_CREATEGRANTREQUEST']._serialized_end=2105
+  _globals['_LISTGRANTSREQUEST']._serialized_start=2107
+  _globals['_LISTGRANTSREQUEST']._serialized_end=2152
+  _globals['_GRANTLIST']._serialized_start=2154
+  _globals['_GRANTLIST']._serialized_end=2195
+  _globals['_REVOKEGRANTREQUEST']._serialized_start=2197
+  _globals['_REVOKEGRANTREQUEST']._serialized_end=2235
+  _globals['_NERVOUSSYSTEM']._serialized_start=2238
+  _globals['_NERVOUSSYSTEM']._serialized_end=3343
 # @@protoc_insertion_point(module_scope)
@@ -53,6 +53,11 @@ class NervousSystemStub(object):
                 request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
                 response_deserializer=ghost__pb2.ActionCommand.FromString,
                 _registered_method=True)
+        self.ReportOutcome = channel.unary_unary(
+                '/ghost.NervousSystem/ReportOutcome',
+                request_serializer=ghost__pb2.ActionOutcome.SerializeToString,
+                response_deserializer=ghost__pb2.Ack.FromString,
+                _registered_method=True)
         self.GetPendingApprovals = channel.unary_unary(
                 '/ghost.NervousSystem/GetPendingApprovals',
                 request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
@@ -73,6 +78,36 @@ class NervousSystemStub(object):
                 request_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
                 response_deserializer=ghost__pb2.SystemState.FromString,
                 _registered_method=True)
+        self.EmergencyStop = channel.unary_unary(
+                '/ghost.NervousSystem/EmergencyStop',
+                request_serializer=ghost__pb2.StopRequest.SerializeToString,
+                response_deserializer=ghost__pb2.StopResult.FromString,
+                _registered_method=True)
+        self.Rearm = channel.unary_unary(
+                '/ghost.NervousSystem/Rearm',
+                request_serializer=ghost__pb2.RearmRequest.SerializeToString,
+                response_deserializer=ghost__pb2.StopResult.FromString,
+                _registered_method=True)
+        self.CancelGoal = channel.unary_unary(
+                '/ghost.NervousSystem/CancelGoal',
+                request_serializer=ghost__pb2.CancelGoalRequest.SerializeToString,
+                response_deserializer=ghost__pb2.CancelGoalResult.FromString,
+                _registered_method=True)
+        self.CreateGrant = channel.unary_unary(
+                '/ghost.NervousSystem/CreateGrant',
+                request_serializer=ghost__pb2.CreateGrantRequest.SerializeToString,
+                response_deserializer=ghost__pb2.Grant.FromString,
+                _registered_method=True)
+        self.ListGrants = channel.unary_unary(
+                '/ghost.NervousSystem/ListGrants',
+                request_serializer=ghost__pb2.ListGrantsRequest.SerializeToString,
+                response_deserializer=ghost__pb2.GrantList.FromString,
+                _registered_method=True)
+        self.RevokeGrant = channel.unary_unary(
+                '/ghost.NervousSystem/RevokeGrant',
+                request_serializer=ghost__pb2.RevokeGrantRequest.SerializeToString,
+                response_deserializer=ghost__pb2.Grant.FromString,
+                _registered_method=True)
 
 
 class NervousSystemServicer(object):
@@ -105,6 +140,13 @@ class NervousSystemServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def ReportOutcome(self, request, context):
+        """Sentinel reports how each command it received turned out.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
     def GetPendingApprovals(self, request, context):
         """--- HUMAN CONTROL PLANE (Gateway HTTP) ---
 
@@ -135,6 +177,55 @@ class NervousSystemServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def EmergencyStop(self, request, context):
+        """--- KILL SWITCH ---
+
+        Anyone says: "Stop everything now." Pauses, cancels queued work and tells the Body to abort.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def Rearm(self, request, context):
+        """User says: "Safe to continue." Clears the stop and resumes in SHADOW.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CancelGoal(self, request, context):
+        """--- GOALS ---
+
+        User or planner says: "Drop this goal." Rejects its waiting proposals, revokes its queued
+        commands and tells the Body to abort a step in progress.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateGrant(self, request, context):
+        """--- STANDING APPROVALS ---
+
+        User says: "Allow TYPE in code.exe for 30 minutes." Matching proposals run without asking.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListGrants(self, request, context):
+        """Grants in force, or every grant ever made
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RevokeGrant(self, request, context):
+        """User says: "Ask me again." Ends a grant at once.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_NervousSystemServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -153,6 +244,11 @@ def add_NervousSystemServicer_to_server(servicer, server):
                     request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
                     response_serializer=ghost__pb2.ActionCommand.SerializeToString,
             ),
+            'ReportOutcome': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReportOutcome,
+                    request_deserializer=ghost__pb2.ActionOutcome.FromString,
+                    response_serializer=ghost__pb2.Ack.SerializeToString,
+            ),
             'GetPendingApprovals': grpc.unary_unary_rpc_method_handler(
                     servicer.GetPendingApprovals,
                     request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
@@ -173,6 +269,36 @@ def add_NervousSystemServicer_to_server(servicer, server):
                     request_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
                     response_serializer=ghost__pb2.SystemState.SerializeToString,
             ),
+            'EmergencyStop': grpc.unary_unary_rpc_method_handler(
+                    servicer.EmergencyStop,
+                    request_deserializer=ghost__pb2.StopRequest.FromString,
+                    response_serializer=ghost__pb2.StopResult.SerializeToString,
+            ),
+            'Rearm': grpc.unary_unary_rpc_method_handler(
+                    servicer.Rearm,
+                    request_deserializer=ghost__pb2.RearmRequest.FromString,
+                    response_serializer=ghost__pb2.StopResult.SerializeToString,
+            ),
+            'CancelGoal': grpc.unary_unary_rpc_method_handler(
+                    servicer.CancelGoal,
+                    request_deserializer=ghost__pb2.CancelGoalRequest.FromString,
+                    response_serializer=ghost__pb2.CancelGoalResult.SerializeToString,
+            ),
+            'CreateGrant': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateGrant,
+                    request_deserializer=ghost__pb2.CreateGrantRequest.FromString,
+                    response_serializer=ghost__pb2.Grant.SerializeToString,
+            ),
+            'ListGrants': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListGrants,
+                    request_deserializer=ghost__pb2.ListGrantsRequest.FromString,
+                    response_serializer=ghost__pb2.GrantList.SerializeToString,
+            ),
+            'RevokeGrant': grpc.unary_unary_rpc_method_handler(
+                    servicer.RevokeGrant,
+                    request_deserializer=ghost__pb2.RevokeGrantRequest.FromString,
+                    response_serializer=ghost__pb2.Grant.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'ghost.NervousSystem', rpc_method_handlers)
@@ -268,6 +394,33 @@ class NervousSystem(object):
             metadata,
             _registered_method=True)
 
+    @staticmethod
+    def ReportOutcome(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/ghost.NervousSystem/ReportOutcome',
+            ghost__pb2.ActionOutcome.SerializeToString,
+            ghost__pb2.Ack.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
     @staticmethod
     def GetPendingApprovals(request,
             target,
@@ -375,3 +528,165 @@ class NervousSystem(object):
             timeout,
             metadata,
             _registered_method=True)
+
+    @staticmethod
+    def EmergencyStop(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/ghost.NervousSystem/EmergencyStop',
+            ghost__pb2.StopRequest.SerializeToString,
+            ghost__pb2.StopResult.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def Rearm(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/ghost.NervousSystem/Rearm',
+            ghost__pb2.RearmRequest.SerializeToString,
+            ghost__pb2.StopResult.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CancelGoal(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/ghost.NervousSystem/CancelGoal',
+            ghost__pb2.CancelGoalRequest.SerializeToString,
+            ghost__pb2.CancelGoalResult.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CreateGrant(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/ghost.NervousSystem/CreateGrant',
+            ghost__pb2.CreateGrantRequest.SerializeToString,
+            ghost__pb2.Grant.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListGrants(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/ghost.NervousSystem/ListGrants',
+            ghost__pb2.ListGrantsRequest.SerializeToString,
+            ghost__pb2.GrantList.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def RevokeGrant(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/ghost.NervousSystem/RevokeGrant',
+            ghost__pb2.RevokeGrantRequest.SerializeToString,
+            ghost__pb2.Grant.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
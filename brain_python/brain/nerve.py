@@ -6,6 +6,7 @@ Handles connection management and type conversion.
 
 import grpc
 import logging
+import os
 from . import ghost_pb2
 from . import ghost_pb2_grpc
 
@@ -15,18 +16,61 @@ class Nerve:
     The gRPC Client wrapper for the Nervous System (Kernel).
     Handles connection management and type conversion.
     """
-    def __init__(self, host: str = "localhost", port: int = 50051):
-        self.target = f"{host}:{port}"
+    def __init__(self, host: str = "localhost", port: int = 50051, token: str = None):
+        # GHOST_GRPC_TARGET overrides host/port, e.g. "unix:../conscience_go/data/kernel.sock"
+        self.target = os.environ.get("GHOST_GRPC_TARGET", "").strip() or f"{host}:{port}"
         self.channel = None
         self.stub = None
+        self.token = token
         self.logger = logging.getLogger("Nerve")
 
+    def _load_token(self) -> str:
+        """
+        Resolve the Brain's kernel credential: explicit argument, GHOST_BRAIN_TOKEN,
+        or the token file the kernel generates on first start.
+        """
+        if self.token:
+            return self.token
+        token = os.environ.get("GHOST_BRAIN_TOKEN", "").strip()
+        if token:
+            return token
+        path = os.environ.get("GHOST_BRAIN_TOKEN_FILE", "../conscience_go/data/tokens/brain.token")
+        with open(path, "r", encoding="utf-8") as f:
+            return f.read().strip()
+
+    def _tls_credentials(self):
+        """
+        Mutual TLS credentials from the kernel's generated certificate directory,
+        or None when GHOST_GRPC_TLS_DIR is unset and the channel stays plaintext.
+        """
+        tls_dir = os.environ.get("GHOST_GRPC_TLS_DIR", "").strip()
+        if not tls_dir:
+            return None
+
+        def read(name: str) -> bytes:
+            with open(os.path.join(tls_dir, name), "rb") as f:
+                return f.read()
+
+        return grpc.ssl_channel_credentials(
+            root_certificates=read("ca.pem"),
+            private_key=read("brain.key"),
+            certificate_chain=read("brain.pem"),
+        )
+
     def connect(self) -> None:
         """Establish the gRPC channel."""
-        # For localhost, insecure is standard. 
-        # In Phase 2 (Enclave), we would switch to secure_channel with SSL.
-        self.channel = grpc.insecure_channel(self.target)
+        credentials = self._tls_credentials()
+        if credentials:
+            # The kernel's certificate is issued to "localhost", whatever the target is
+            self.channel = grpc.secure_channel(
+                self.target, credentials,
+                options=(("grpc.ssl_target_name_override", "localhost"),),
+            )
+        else:
+            self.channel = grpc.insecure_channel(self.target)
         self.stub = ghost_pb2_grpc.NervousSystemStub(self.channel)
+        # Every call authenticates as the Brain role
+        self.metadata = (("authorization", f"Bearer {self._load_token()}"),)
         self.logger.info(f"Connected to Nervous System at {self.target}")
 
     def request_permission(self, intent: str, actions: list, trace_id: str) -> dict:
@@ -54,7 +98,7 @@ class Nerve:
         )
 
         try:
-            resp = self.stub.RequestPermission(req)
+            resp = self.stub.RequestPermission(req, metadata=self.metadata)
             return {
                 "approved": resp.approved,
                 "reason": resp.reason,
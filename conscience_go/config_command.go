@@ -0,0 +1,49 @@
+// Author: Enkae (enkae.dev@pm.me)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"ghost/kernel/internal/config"
+)
+
+// runConfigCommand implements `ghost config <command>` and returns the process exit code
+func runConfigCommand(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	loader := config.NewLoader(fs)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "Usage: ghost config [flags] <command>")
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "Commands:")
+		fmt.Fprintln(out, "  print      show the effective configuration (defaults < file < env < flags) with secrets masked")
+		fmt.Fprintln(out, "  validate   check the configuration and exit non-zero on errors")
+		fmt.Fprintln(out, "")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || (fs.Arg(0) != "print" && fs.Arg(0) != "validate") {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if fs.Arg(0) == "validate" {
+		fmt.Println("configuration is valid")
+		return 0
+	}
+	if err := config.Print(os.Stdout, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,184 @@
+// Author: Enkae (enkae.dev@pm.me)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/config"
+)
+
+// runDBCommand implements `ghost db <command>` and returns the process exit code
+func runDBCommand(args []string) int {
+	fs := flag.NewFlagSet("db", flag.ContinueOnError)
+	loader := config.NewLoader(fs)
+	redact := fs.Bool("redact", false, "export: replace captured content with placeholders")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "Usage: ghost db [flags] <command>")
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "Commands:")
+		fmt.Fprintln(out, "  migrate          apply pending schema migrations")
+		fmt.Fprintln(out, "  status           show applied and pending migrations")
+		fmt.Fprintln(out, "  backup <file>    write an online snapshot of the database")
+		fmt.Fprintln(out, "  export [file]    write goals, modes, history, memories and audit log as JSON lines (default stdout)")
+		fmt.Fprintln(out, "  import <file>    merge a previous export into this database ('-' reads stdin)")
+		fmt.Fprintln(out, "")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+
+	// status only reads, so it must not create the file or its bookkeeping tables
+	storeCfg := adapter.DefaultStoreConfig(cfg.Database.Path)
+	storeCfg.ReadOnly = fs.Arg(0) == "status"
+	db, err := adapter.OpenStore(storeCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	switch fs.Arg(0) {
+	case "migrate":
+		applied, err := adapter.Migrate(ctx, db)
+		for _, m := range applied {
+			fmt.Printf("applied %04d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return 0
+
+	case "status":
+		status, err := adapter.GetSchemaStatus(ctx, db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read schema status: %v\n", err)
+			return 1
+		}
+		if status.Unversioned {
+			fmt.Printf("schema unversioned (kernel supports %d); run `ghost db migrate`\n", status.Latest)
+		} else {
+			fmt.Printf("schema version %d (kernel supports %d)\n", status.Current, status.Latest)
+		}
+		for _, m := range status.Applied {
+			fmt.Printf("  [x] %04d %s (applied %s)\n", m.Version, m.Name, m.AppliedAt.Format("2006-01-02 15:04:05"))
+		}
+		for _, m := range status.Pending {
+			fmt.Printf("  [ ] %04d %s\n", m.Version, m.Name)
+		}
+		if status.Current > status.Latest {
+			fmt.Fprintln(os.Stderr, "database was migrated by a newer kernel; upgrade before starting")
+			return 1
+		}
+		return 0
+
+	case "backup", "export", "import":
+		return runPortabilityCommand(ctx, db, fs.Arg(0), fs.Args()[1:], cfg, *redact)
+
+	default:
+		fs.Usage()
+		return 2
+	}
+}
+
+// runPortabilityCommand implements backup, export and import against a migrated database
+func runPortabilityCommand(ctx context.Context, db *adapter.Store, command string, args []string, cfg *config.Config, redact bool) int {
+	if _, err := adapter.Migrate(ctx, db); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to migrate database: %v\n", err)
+		return 1
+	}
+
+	backupRepo := adapter.NewBackupRepository(db)
+	auditRepo, err := adapter.NewAuditRepository(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to init AuditRepository: %v\n", err)
+		return 1
+	}
+
+	if command == "backup" {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ghost db backup <file>")
+			return 2
+		}
+		if err := backupRepo.Backup(ctx, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Backup failed: %v\n", err)
+			return 1
+		}
+		auditRepo.Record(ctx, adapter.AuditEventBackup, "cli", map[string]string{"destination": args[0]})
+		fmt.Printf("backed up to %s\n", args[0])
+		return 0
+	}
+
+	// Export and import move plaintext, so they need the key of an encrypted database
+	cipher, err := adapter.OpenFieldCipher(ctx, db, adapter.KeySource{Passphrase: cfg.Database.Passphrase, KeyFile: cfg.Database.KeyFile})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open database key: %v\n", err)
+		return 1
+	}
+	backupRepo.SetCipher(cipher)
+	auditRepo.SetCipher(cipher)
+
+	var counts map[string]int
+	if command == "export" {
+		out := io.Writer(os.Stdout)
+		if len(args) > 0 && args[0] != "-" {
+			f, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to create export file: %v\n", err)
+				return 1
+			}
+			defer f.Close()
+			out = f
+		}
+		if counts, err = backupRepo.Export(ctx, out, adapter.ExportOptions{Redact: redact}); err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+			return 1
+		}
+		auditRepo.Record(ctx, adapter.AuditEventExport, "cli", map[string]interface{}{"counts": counts, "redacted": redact})
+	} else {
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: ghost db import <file>")
+			return 2
+		}
+		in := io.Reader(os.Stdin)
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to open import file: %v\n", err)
+				return 1
+			}
+			defer f.Close()
+			in = f
+		}
+		if counts, err = backupRepo.Import(ctx, in); err != nil {
+			fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+			return 1
+		}
+		auditRepo.Record(ctx, adapter.AuditEventImport, "cli", map[string]interface{}{"counts": counts})
+	}
+
+	fmt.Fprintf(os.Stderr, "%s complete: %v\n", command, counts)
+	return 0
+}
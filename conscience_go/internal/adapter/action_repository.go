@@ -5,86 +5,60 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"ghost/kernel/internal/domain"
 )
 
+// Action proposal errors
+var (
+	ErrActionNotFound = errors.New("action proposal not found")
+	ErrActionState    = errors.New("action proposal cannot do that in its current status")
+)
+
 // ActionRepository manages action proposal persistence and user mode settings
 type ActionRepository struct {
-	db *sql.DB
+	db     DB
+	cipher *FieldCipher
+
+	statusListeners []func(ctx context.Context, id string, status domain.ActionProposalStatus)
 }
 
 // NewActionRepository creates a new ActionRepository and initializes tables
-func NewActionRepository(db *sql.DB) (*ActionRepository, error) {
+func NewActionRepository(db DB) (*ActionRepository, error) {
 	repo := &ActionRepository{db: db}
 
-	// Create action_proposals table
-	createActionsTableSQL := `
-	CREATE TABLE IF NOT EXISTS action_proposals (
-		id TEXT PRIMARY KEY,
-		intent TEXT NOT NULL,
-		risk_score INTEGER NOT NULL,
-		status TEXT NOT NULL,
-		payload TEXT NOT NULL,
-		domain TEXT NOT NULL,
-		created_at DATETIME NOT NULL,
-		updated_at DATETIME NOT NULL,
-		approved_at DATETIME,
-		interaction_type TEXT NOT NULL DEFAULT 'PERMISSION',
-		agent_message TEXT,
-		user_response TEXT
-	);
-	`
-
-	if _, err := db.Exec(createActionsTableSQL); err != nil {
-		return nil, fmt.Errorf("failed to create action_proposals table: %w", err)
-	}
-
-	// Migrate existing tables to add new columns if they don't exist
-	migrateActionsSQL := []string{
-		"ALTER TABLE action_proposals ADD COLUMN interaction_type TEXT NOT NULL DEFAULT 'PERMISSION';",
-		"ALTER TABLE action_proposals ADD COLUMN agent_message TEXT;",
-		"ALTER TABLE action_proposals ADD COLUMN user_response TEXT;",
-	}
-
-	for _, stmt := range migrateActionsSQL {
-		// Ignore errors if columns already exist
-		_, _ = db.Exec(stmt)
+	if err := ensureSchema(db); err != nil {
+		return nil, err
 	}
 
-	// Create user_modes table
-	createModesTableSQL := `
-	CREATE TABLE IF NOT EXISTS user_modes (
-		domain TEXT PRIMARY KEY,
-		mode TEXT NOT NULL,
-		updated_at DATETIME NOT NULL
-	);
-	`
-
-	if _, err := db.Exec(createModesTableSQL); err != nil {
-		return nil, fmt.Errorf("failed to create user_modes table: %w", err)
-	}
-
-	// Insert default mode (AUTO for all domains)
-	defaultModeSQL := `
-	INSERT OR IGNORE INTO user_modes (domain, mode, updated_at)
-	VALUES ('*', 'AUTO', ?);
-	`
+	return repo, nil
+}
 
-	if _, err := db.Exec(defaultModeSQL, time.Now()); err != nil {
-		return nil, fmt.Errorf("failed to insert default mode: %w", err)
-	}
+// SetCipher enables at-rest encryption of action payloads and user responses
+func (r *ActionRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
 
-	return repo, nil
+// OnStatusChange registers fn to run after every committed status change, batch steps included
+// Register listeners before serving; they run synchronously on the caller's goroutine.
+func (r *ActionRepository) OnStatusChange(fn func(ctx context.Context, id string, status domain.ActionProposalStatus)) {
+	r.statusListeners = append(r.statusListeners, fn)
 }
 
 // SaveActionProposal persists an action proposal to the database
 func (r *ActionRepository) SaveActionProposal(ctx context.Context, action *domain.ActionProposal) error {
+	return r.insertAction(ctx, r.db, action)
+}
+
+// insertAction writes an action proposal with db, which may be a transaction
+func (r *ActionRepository) insertAction(ctx context.Context, db execer, action *domain.ActionProposal) error {
 	insertSQL := `
-	INSERT INTO action_proposals (id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at, interaction_type, agent_message, user_response)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO action_proposals (id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at, interaction_type, agent_message, user_response, trace_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	payloadJSON, err := json.Marshal(action.Payload)
@@ -92,21 +66,31 @@ func (r *ActionRepository) SaveActionProposal(ctx context.Context, action *domai
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	_, err = r.db.ExecContext(
+	payload, err := r.cipher.Seal("action_proposals", "payload", action.ID, string(payloadJSON))
+	if err != nil {
+		return err
+	}
+	userResponse, err := r.cipher.Seal("action_proposals", "user_response", action.ID, action.UserResponse)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(
 		ctx,
 		insertSQL,
 		action.ID,
 		action.Intent,
 		action.RiskScore,
 		string(action.Status),
-		string(payloadJSON),
+		payload,
 		action.Domain,
 		action.CreatedAt,
 		action.UpdatedAt,
 		action.ApprovedAt,
 		string(action.InteractionType),
 		action.AgentMessage,
-		action.UserResponse,
+		userResponse,
+		action.TraceID,
 	)
 
 	if err != nil {
@@ -145,14 +129,80 @@ func (r *ActionRepository) UpdateActionStatus(ctx context.Context, id string, st
 		return fmt.Errorf("action proposal not found: %s", id)
 	}
 
+	r.notifyStatus(ctx, id, status)
+
+	return nil
+}
+
+// UpdateActionStatusFrom moves a proposal to status only if it is currently in one of from
+// A proposal in any other status is left unchanged and fails with ErrActionState.
+func (r *ActionRepository) UpdateActionStatusFrom(ctx context.Context, id string, status domain.ActionProposalStatus, from ...domain.ActionProposalStatus) error {
+	now := time.Now()
+	var approvedAt *time.Time
+	if status == domain.ActionProposalStatusApproved {
+		approvedAt = &now
+	}
+
+	args := []interface{}{string(status), now, approvedAt, id}
+	placeholders := make([]string, len(from))
+	for i, current := range from {
+		placeholders[i] = "?"
+		args = append(args, string(current))
+	}
+	updateSQL := `
+	UPDATE action_proposals
+	SET status = ?, updated_at = ?, approved_at = COALESCE(?, approved_at)
+	WHERE id = ? AND status IN (` + strings.Join(placeholders, ", ") + `)
+	`
+
+	result, err := r.db.ExecContext(ctx, updateSQL, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update action status: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		var current string
+		err := r.db.QueryRowContext(ctx, "SELECT status FROM action_proposals WHERE id = ?", id).Scan(&current)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrActionNotFound, id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read action status: %w", err)
+		}
+		return fmt.Errorf("%w: it is %s", ErrActionState, current)
+	}
+
+	r.notifyStatus(ctx, id, status)
 	return nil
 }
 
+// ApproveAction approves a proposal still waiting on a decision
+// One a goal cancellation rejected, the emergency stop cancelled or the effector already took fails with ErrActionState.
+func (r *ActionRepository) ApproveAction(ctx context.Context, id string) error {
+	return r.UpdateActionStatusFrom(ctx, id, domain.ActionProposalStatusApproved, domain.ActionProposalStatusPending, domain.ActionProposalStatusWaitingForUser)
+}
+
+// notifyStatus runs the status listeners for a change that has been committed
+func (r *ActionRepository) notifyStatus(ctx context.Context, id string, status domain.ActionProposalStatus) {
+	for _, fn := range r.statusListeners {
+		fn(ctx, id, status)
+	}
+}
+
 // UpdateUserResponse updates the user's response for an action proposal
 // Used for clarifications where the agent needs context from the user
 func (r *ActionRepository) UpdateUserResponse(ctx context.Context, id string, userResponse string) error {
 	now := time.Now()
 
+	userResponse, err := r.cipher.Seal("action_proposals", "user_response", id, userResponse)
+	if err != nil {
+		return err
+	}
+
 	updateSQL := `
 	UPDATE action_proposals
 	SET user_response = ?, updated_at = ?
@@ -185,7 +235,7 @@ func (r *ActionRepository) GetActionProposal(ctx context.Context, id string) (*d
 // GetActionByID retrieves a single action proposal by ID with full fields
 func (r *ActionRepository) GetActionByID(ctx context.Context, id string) (*domain.ActionProposal, error) {
 	query := `
-	SELECT id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at, interaction_type, agent_message, user_response
+	SELECT id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at, interaction_type, agent_message, user_response, trace_id
 	FROM action_proposals
 	WHERE id = ?
 	`
@@ -197,6 +247,7 @@ func (r *ActionRepository) GetActionByID(ctx context.Context, id string) (*domai
 	var approvedAt sql.NullTime
 	var agentMessage sql.NullString
 	var userResponse sql.NullString
+	var traceID sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&action.ID,
@@ -211,10 +262,11 @@ func (r *ActionRepository) GetActionByID(ctx context.Context, id string) (*domai
 		&interactionType,
 		&agentMessage,
 		&userResponse,
+		&traceID,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("action proposal not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrActionNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query action proposal: %w", err)
@@ -233,6 +285,11 @@ func (r *ActionRepository) GetActionByID(ctx context.Context, id string) (*domai
 	if userResponse.Valid {
 		action.UserResponse = userResponse.String
 	}
+	action.TraceID = traceID.String
+
+	if err := r.openAction(&action); err != nil {
+		return nil, err
+	}
 
 	return &action, nil
 }
@@ -241,7 +298,7 @@ func (r *ActionRepository) GetActionByID(ctx context.Context, id string) (*domai
 // Includes both permission requests and clarification requests
 func (r *ActionRepository) GetPendingApprovals(ctx context.Context) ([]*domain.ActionProposal, error) {
 	query := `
-	SELECT id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at, interaction_type, agent_message, user_response
+	SELECT id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at, interaction_type, agent_message, user_response, trace_id
 	FROM action_proposals
 	WHERE status IN (?, ?)
 	ORDER BY created_at ASC
@@ -265,6 +322,7 @@ func (r *ActionRepository) GetPendingApprovals(ctx context.Context) ([]*domain.A
 		var approvedAt sql.NullTime
 		var agentMessage sql.NullString
 		var userResponse sql.NullString
+		var traceID sql.NullString
 
 		err := rows.Scan(
 			&action.ID,
@@ -279,6 +337,7 @@ func (r *ActionRepository) GetPendingApprovals(ctx context.Context) ([]*domain.A
 			&interactionType,
 			&agentMessage,
 			&userResponse,
+			&traceID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan action proposal: %w", err)
@@ -297,6 +356,11 @@ func (r *ActionRepository) GetPendingApprovals(ctx context.Context) ([]*domain.A
 		if userResponse.Valid {
 			action.UserResponse = userResponse.String
 		}
+		action.TraceID = traceID.String
+
+		if err := r.openAction(&action); err != nil {
+			return nil, err
+		}
 
 		actions = append(actions, &action)
 	}
@@ -366,7 +430,7 @@ func (r *ActionRepository) SetUserMode(ctx context.Context, domainName string, m
 // This is the Effector Queue - only APPROVED actions flow to the Sentinel
 func (r *ActionRepository) GetApprovedActions(ctx context.Context) ([]*domain.ActionProposal, error) {
 	query := `
-	SELECT id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at
+	SELECT id, intent, risk_score, status, payload, domain, created_at, updated_at, approved_at, trace_id
 	FROM action_proposals
 	WHERE status IN (?, ?)
 	ORDER BY approved_at ASC, created_at ASC
@@ -390,6 +454,7 @@ func (r *ActionRepository) GetApprovedActions(ctx context.Context) ([]*domain.Ac
 		var payloadJSON string
 		var status string
 		var approvedAt sql.NullTime
+		var traceID sql.NullString
 
 		err := rows.Scan(
 			&action.ID,
@@ -401,6 +466,7 @@ func (r *ActionRepository) GetApprovedActions(ctx context.Context) ([]*domain.Ac
 			&action.CreatedAt,
 			&action.UpdatedAt,
 			&approvedAt,
+			&traceID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan action proposal: %w", err)
@@ -408,11 +474,16 @@ func (r *ActionRepository) GetApprovedActions(ctx context.Context) ([]*domain.Ac
 
 		action.Status = domain.ActionProposalStatus(status)
 		action.Payload = json.RawMessage(payloadJSON)
+		action.TraceID = traceID.String
 
 		if approvedAt.Valid {
 			action.ApprovedAt = &approvedAt.Time
 		}
 
+		if err := r.openAction(&action); err != nil {
+			return nil, err
+		}
+
 		actions = append(actions, &action)
 	}
 
@@ -422,3 +493,163 @@ func (r *ActionRepository) GetApprovedActions(ctx context.Context) ([]*domain.Ac
 
 	return actions, nil
 }
+
+// GetActionsByTraceID retrieves the action proposals an intent produced, oldest first
+func (r *ActionRepository) GetActionsByTraceID(ctx context.Context, traceID string) ([]*domain.ActionProposal, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id FROM action_proposals WHERE trace_id = ? ORDER BY created_at ASC", traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query actions by trace: %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan action id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	actions := make([]*domain.ActionProposal, 0, len(ids))
+	for _, id := range ids {
+		action, err := r.GetActionByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// ApproveRevision approves a proposal with the payload an approver edited, keeping the original in proposal_revisions
+// Only proposals still waiting on a decision can be edited; others fail with ErrActionState.
+func (r *ActionRepository) ApproveRevision(ctx context.Context, rev *domain.ProposalRevision) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin revision transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM action_proposals WHERE id = ?", rev.ActionID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrActionNotFound, rev.ActionID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read action status: %w", err)
+	}
+	switch domain.ActionProposalStatus(status) {
+	case domain.ActionProposalStatusPending, domain.ActionProposalStatusWaitingForUser, domain.ActionProposalStatusWaitingForContext:
+	default:
+		return fmt.Errorf("%w: it is %s", ErrActionState, status)
+	}
+
+	changesJSON, err := json.Marshal(rev.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload changes: %w", err)
+	}
+	sealed := make([]string, 0, 3)
+	for _, field := range []struct{ column, value string }{
+		{"original", string(rev.Original)},
+		{"edited", string(rev.Edited)},
+		{"changes", string(changesJSON)},
+	} {
+		value, err := r.cipher.Seal("proposal_revisions", field.column, rev.ID, field.value)
+		if err != nil {
+			return err
+		}
+		sealed = append(sealed, value)
+	}
+
+	insertSQL := `
+	INSERT INTO proposal_revisions (id, action_id, original, edited, changes, actor, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, insertSQL, rev.ID, rev.ActionID, sealed[0], sealed[1], sealed[2], nullString(rev.Actor), rev.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert proposal revision: %w", err)
+	}
+
+	payload, err := r.cipher.Seal("action_proposals", "payload", rev.ActionID, string(rev.Edited))
+	if err != nil {
+		return err
+	}
+	updateSQL := `
+	UPDATE action_proposals
+	SET payload = ?, status = ?, updated_at = ?, approved_at = ?
+	WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, updateSQL, payload, string(domain.ActionProposalStatusApproved), rev.CreatedAt, rev.CreatedAt, rev.ActionID); err != nil {
+		return fmt.Errorf("failed to approve revised action: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit proposal revision: %w", err)
+	}
+
+	r.notifyStatus(ctx, rev.ActionID, domain.ActionProposalStatusApproved)
+	return nil
+}
+
+// ListRevisions returns the edits made to a proposal's payload, oldest first
+func (r *ActionRepository) ListRevisions(ctx context.Context, actionID string) ([]domain.ProposalRevision, error) {
+	query := `
+	SELECT id, original, edited, changes, actor, created_at
+	FROM proposal_revisions
+	WHERE action_id = ?
+	ORDER BY created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, actionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query proposal revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []domain.ProposalRevision{}
+	for rows.Next() {
+		rev := domain.ProposalRevision{ActionID: actionID}
+		var original, edited, changes string
+		var actor sql.NullString
+		if err := rows.Scan(&rev.ID, &original, &edited, &changes, &actor, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan proposal revision: %w", err)
+		}
+		rev.Actor = actor.String
+
+		if original, err = r.cipher.Open("proposal_revisions", "original", rev.ID, original); err != nil {
+			return nil, err
+		}
+		if edited, err = r.cipher.Open("proposal_revisions", "edited", rev.ID, edited); err != nil {
+			return nil, err
+		}
+		if changes, err = r.cipher.Open("proposal_revisions", "changes", rev.ID, changes); err != nil {
+			return nil, err
+		}
+		rev.Original, rev.Edited = json.RawMessage(original), json.RawMessage(edited)
+		if err := json.Unmarshal([]byte(changes), &rev.Changes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payload changes: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate proposal revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// openAction decrypts the sensitive fields of a scanned action proposal
+func (r *ActionRepository) openAction(action *domain.ActionProposal) error {
+	payload, err := r.cipher.Open("action_proposals", "payload", action.ID, string(action.Payload))
+	if err != nil {
+		return err
+	}
+	action.Payload = json.RawMessage(payload)
+
+	if action.UserResponse, err = r.cipher.Open("action_proposals", "user_response", action.ID, action.UserResponse); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ghost/kernel/internal/domain"
+)
+
+func TestApproveRevisionKeepsOriginal(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	actionRepo, err := NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := OpenFieldCipher(ctx, store, KeySource{Passphrase: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actionRepo.SetCipher(cipher)
+	var approved []string
+	actionRepo.OnStatusChange(func(_ context.Context, id string, status domain.ActionProposalStatus) {
+		approved = append(approved, id+" "+string(status))
+	})
+
+	action := domain.NewActionProposal("email report", 60, json.RawMessage(`{"type":"TYPE","to":"team@example.com","cc":["boss"]}`), "MAIL")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := json.RawMessage(`{"type":"TYPE","to":"manager@example.com","cc":[],"subject":"Q3"}`)
+	rev, err := domain.NewProposalRevision(action, edited, "http:human")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, change := range rev.Changes {
+		paths = append(paths, change.Path)
+	}
+	if want := []string{"/cc/0", "/subject", "/to"}; len(paths) != len(want) || paths[0] != want[0] || paths[1] != want[1] || paths[2] != want[2] {
+		t.Fatalf("changes = %v, want %v", paths, want)
+	}
+	if err := actionRepo.ApproveRevision(ctx, rev); err != nil {
+		t.Fatalf("ApproveRevision() error = %v", err)
+	}
+
+	stored, err := actionRepo.GetActionByID(ctx, action.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.ActionProposalStatusApproved || stored.ApprovedAt == nil || string(stored.Payload) != string(edited) {
+		t.Errorf("stored proposal = %s %s, want APPROVED with the edited payload", stored.Status, stored.Payload)
+	}
+	if len(approved) != 1 {
+		t.Errorf("status listeners saw %v, want the approval", approved)
+	}
+
+	revisions, err := actionRepo.ListRevisions(ctx, action.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || string(revisions[0].Original) != string(action.Payload) || len(revisions[0].Changes) != 3 || revisions[0].Actor != "http:human" {
+		t.Fatalf("ListRevisions() = %+v, want the original and its three changes", revisions)
+	}
+
+	// An approved proposal can no longer be edited
+	if err := actionRepo.ApproveRevision(ctx, rev); !errors.Is(err, ErrActionState) {
+		t.Errorf("second ApproveRevision() error = %v, want ErrActionState", err)
+	}
+}
+
+func TestRecordCorrectionReplacesReflex(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	intentRepo, err := NewIntentHistoryRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 6; i++ {
+		if err := intentRepo.RecordExecution(ctx, "open notes", "Explorer", "explorer.exe", `{"actions":[{"type":"READ","path":"old.txt"}]}`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	corrected := `{"actions":[{"type":"READ","path":"notes.txt"}]}`
+	if err := intentRepo.RecordCorrection(ctx, "open notes", "EXPLORER", corrected); err != nil {
+		t.Fatalf("RecordCorrection() error = %v", err)
+	}
+	// Executions that carry no plan keep the corrected one
+	if err := intentRepo.RecordExecution(ctx, "open notes", "Explorer", "explorer.exe", ""); err != nil {
+		t.Fatal(err)
+	}
+	if plan, trust, err := intentRepo.GetReflex(ctx, "open notes"); err != nil || plan != corrected || trust != 7 {
+		t.Errorf("GetReflex() = %s, %d, %v; want the corrected plan with trust 7", plan, trust, err)
+	}
+
+	// A corrected intent that never ran is learned but not yet trusted
+	if err := intentRepo.RecordCorrection(ctx, "send invoice", "MAIL", corrected); err != nil {
+		t.Fatal(err)
+	}
+	if plan, _, err := intentRepo.GetReflex(ctx, "send invoice"); err != nil || plan != "" {
+		t.Errorf("GetReflex(untrusted) = %q, %v; want no reflex yet", plan, err)
+	}
+}
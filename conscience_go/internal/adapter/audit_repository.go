@@ -0,0 +1,170 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ghost/kernel/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+// AuditRecord is a persistent entry in the kernel audit trail
+// Detail must never carry captured user content - only identifiers and scope
+type AuditRecord struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	Actor     string          `json:"actor,omitempty"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+	TraceID   string          `json:"trace_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Audit event names
+const (
+	AuditEventForget = "data.forget"
+	AuditEventBackup = "data.backup"
+	AuditEventExport = "data.export"
+	AuditEventImport = "data.import"
+
+	AuditEventApproval      = "action.decision"
+	AuditEventBatchApproval = "batch.decision"
+	AuditEventModeChange    = "mode.change"
+	AuditEventBreakerTrip   = "breaker.trip"
+
+	AuditEventEmergencyStop = "estop.engage"
+	AuditEventRearm         = "estop.rearm"
+
+	AuditEventGoalCancel = "goal.cancel"
+
+	AuditEventGrantCreate  = "grant.create"
+	AuditEventGrantRevoke  = "grant.revoke"
+	AuditEventAutoApproval = "action.auto_approve"
+)
+
+// AuditRepository manages the persistent audit trail
+type AuditRepository struct {
+	db     DB
+	cipher *FieldCipher
+}
+
+// NewAuditRepository creates a new AuditRepository and initializes tables
+func NewAuditRepository(db DB) (*AuditRepository, error) {
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+
+	return &AuditRepository{db: db}, nil
+}
+
+// SetCipher enables at-rest encryption of audit details
+func (r *AuditRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
+
+// Record appends an entry to the audit trail, tagged with the trace ID carried by ctx
+func (r *AuditRepository) Record(ctx context.Context, event string, actor string, detail interface{}) (*AuditRecord, error) {
+	record := &AuditRecord{
+		ID:        uuid.New().String(),
+		Event:     event,
+		Actor:     actor,
+		TraceID:   tracing.TraceID(ctx),
+		CreatedAt: time.Now(),
+	}
+
+	if detail != nil {
+		detailJSON, err := json.Marshal(detail)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit detail: %w", err)
+		}
+		record.Detail = detailJSON
+	}
+
+	detail, err := r.cipher.Seal("audit_log", "detail", record.ID, string(record.Detail))
+	if err != nil {
+		return nil, err
+	}
+
+	insertSQL := `
+	INSERT INTO audit_log (id, event, actor, detail, created_at, trace_id)
+	VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, insertSQL, record.ID, record.Event, record.Actor, detail, record.CreatedAt, record.TraceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert audit record: %w", err)
+	}
+
+	return record, nil
+}
+
+// GetRecent retrieves the most recent N audit records, newest first
+func (r *AuditRepository) GetRecent(ctx context.Context, limit int) ([]AuditRecord, error) {
+	query := `
+	SELECT id, event, actor, detail, created_at, trace_id
+	FROM audit_log
+	ORDER BY created_at DESC
+	LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	return scanAuditRecords(rows, r.cipher)
+}
+
+// GetByTraceID retrieves the audit records written while handling an intent, oldest first
+func (r *AuditRepository) GetByTraceID(ctx context.Context, traceID string) ([]AuditRecord, error) {
+	query := `
+	SELECT id, event, actor, detail, created_at, trace_id
+	FROM audit_log
+	WHERE trace_id = ?
+	ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log by trace: %w", err)
+	}
+	return scanAuditRecords(rows, r.cipher)
+}
+
+// scanAuditRecords reads audit rows, decrypting their details, and closes rows when done
+func scanAuditRecords(rows *sql.Rows, c *FieldCipher) ([]AuditRecord, error) {
+	defer rows.Close()
+
+	var records []AuditRecord
+	for rows.Next() {
+		var record AuditRecord
+		var actor sql.NullString
+		var detail sql.NullString
+		var traceID sql.NullString
+
+		if err := rows.Scan(&record.ID, &record.Event, &actor, &detail, &record.CreatedAt, &traceID); err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+
+		record.Actor = actor.String
+		record.TraceID = traceID.String
+		if detail.Valid && detail.String != "" {
+			plaintext, err := c.Open("audit_log", "detail", record.ID, detail.String)
+			if err != nil {
+				return nil, err
+			}
+			record.Detail = json.RawMessage(plaintext)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit rows: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,483 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+// ExportFormatVersion identifies the JSON-lines layout written by Export
+const ExportFormatVersion = 1
+
+// redactedValue replaces captured content in redacted exports
+const redactedValue = "[redacted]"
+
+// Export record kinds - one JSON object per line: {"kind": ..., "data": ...}
+const (
+	ExportKindHeader   = "header"
+	ExportKindGoal     = "goal"
+	ExportKindUserMode = "user_mode"
+	ExportKindIntent   = "intent_history"
+	ExportKindMemory   = "memory"
+	ExportKindAuditLog = "audit"
+)
+
+// maxImportSize bounds an import so a bad file cannot exhaust memory
+const maxImportSize = 64 << 20
+
+// ExportOptions controls what Export writes
+type ExportOptions struct {
+	// Redact replaces goal text, window titles, cached plans, memory values and audit
+	// details with a placeholder. Redacted exports are for sharing and cannot be imported.
+	Redact bool `json:"redact"`
+}
+
+// ExportHeader is the first line of every export
+type ExportHeader struct {
+	FormatVersion int       `json:"format_version"`
+	SchemaVersion int       `json:"schema_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Redacted      bool      `json:"redacted"`
+}
+
+// exportLine is a single JSON-lines record
+type exportLine struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// BackupRepository snapshots, exports and imports the kernel state
+type BackupRepository struct {
+	db     DB
+	cipher *FieldCipher
+}
+
+// NewBackupRepository creates a new BackupRepository
+func NewBackupRepository(db DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// SetCipher lets exports decrypt, and imports re-encrypt, sensitive values
+func (r *BackupRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
+
+// Backup writes a consistent, compacted copy of the live database to dest
+// The copy keeps encrypted columns encrypted, so it opens with the same key.
+func (r *BackupRepository) Backup(ctx context.Context, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("backup destination already exists: %s", dest)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check backup destination: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, "VACUUM INTO ?", dest); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// Export writes goals, modes, intent history (with reflexes), memories and the audit log as JSON lines
+// Returns the number of records written per kind.
+func (r *BackupRepository) Export(ctx context.Context, w io.Writer, opts ExportOptions) (map[string]int, error) {
+	counts := make(map[string]int)
+	enc := json.NewEncoder(w)
+
+	emit := func(kind string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", kind, err)
+		}
+		if err := enc.Encode(exportLine{Kind: kind, Data: data}); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		if kind != ExportKindHeader {
+			counts[kind]++
+		}
+		return nil
+	}
+
+	header := ExportHeader{
+		FormatVersion: ExportFormatVersion,
+		SchemaVersion: LatestSchemaVersion(),
+		ExportedAt:    time.Now(),
+		Redacted:      opts.Redact,
+	}
+	if err := emit(ExportKindHeader, header); err != nil {
+		return counts, err
+	}
+
+	goals, err := r.exportGoals(ctx)
+	if err != nil {
+		return counts, err
+	}
+	for _, goal := range goals {
+		if opts.Redact {
+			goal.GoalText = redactedValue
+		}
+		if err := emit(ExportKindGoal, goal); err != nil {
+			return counts, err
+		}
+	}
+
+	modes, err := r.exportUserModes(ctx)
+	if err != nil {
+		return counts, err
+	}
+	for _, mode := range modes {
+		if err := emit(ExportKindUserMode, mode); err != nil {
+			return counts, err
+		}
+	}
+
+	intents, err := r.exportIntentHistory(ctx)
+	if err != nil {
+		return counts, err
+	}
+	for _, entry := range intents {
+		if opts.Redact {
+			entry.FocusedWindow = redactedValue
+			entry.CachedPlan = ""
+		}
+		if err := emit(ExportKindIntent, entry); err != nil {
+			return counts, err
+		}
+	}
+
+	memories, err := (&MemoryRepository{db: r.db, cipher: r.cipher}).GetAllMemories(ctx)
+	if err != nil {
+		return counts, err
+	}
+	for _, memory := range memories {
+		if opts.Redact {
+			memory.Value = redactedValue
+			memory.Context = ""
+			memory.Embedding = nil
+		}
+		if err := emit(ExportKindMemory, memory); err != nil {
+			return counts, err
+		}
+	}
+
+	records, err := r.exportAuditLog(ctx)
+	if err != nil {
+		return counts, err
+	}
+	for _, record := range records {
+		if opts.Redact {
+			record.Detail = nil
+		}
+		if err := emit(ExportKindAuditLog, record); err != nil {
+			return counts, err
+		}
+	}
+
+	return counts, nil
+}
+
+// Import restores an export in a single transaction, merging with existing state
+// Goals, modes and memories are replaced by ID/domain/key; intent history keeps the higher trust score.
+func (r *BackupRepository) Import(ctx context.Context, src io.Reader) (map[string]int, error) {
+	dec := json.NewDecoder(io.LimitReader(src, maxImportSize))
+
+	var first exportLine
+	if err := dec.Decode(&first); err != nil {
+		return nil, fmt.Errorf("failed to read export header: %w", err)
+	}
+	var header ExportHeader
+	if first.Kind != ExportKindHeader || json.Unmarshal(first.Data, &header) != nil {
+		return nil, fmt.Errorf("not a Ghost export: missing header")
+	}
+	if header.FormatVersion != ExportFormatVersion {
+		return nil, fmt.Errorf("unsupported export format version %d", header.FormatVersion)
+	}
+	if header.Redacted {
+		return nil, fmt.Errorf("redacted exports cannot be imported")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	counts := make(map[string]int)
+	for line := 2; ; line++ {
+		var record exportLine
+		if err := dec.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read export line %d: %w", line, err)
+		}
+
+		if err := r.importRecord(ctx, tx, record); err != nil {
+			return nil, fmt.Errorf("failed to import line %d (%s): %w", line, record.Kind, err)
+		}
+		counts[record.Kind]++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return counts, nil
+}
+
+// importRecord upserts a single export record
+// Timestamps are stored in local time, matching what the repositories write.
+func (r *BackupRepository) importRecord(ctx context.Context, tx *sql.Tx, record exportLine) error {
+	switch record.Kind {
+	case ExportKindGoal:
+		var goal domain.Goal
+		if err := json.Unmarshal(record.Data, &goal); err != nil {
+			return err
+		}
+		// Plans and leases are not carried over, so a goal a planner was working on goes back in the queue
+		if goal.Status == domain.GoalStatusPlanning || goal.Status == domain.GoalStatusExecuting {
+			goal.Status = domain.GoalStatusActive
+		}
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO active_goals (id, goal_text, status, created_at, updated_at, priority, deadline, run_at, recurrence, parent_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET goal_text = excluded.goal_text, status = excluded.status,
+			created_at = excluded.created_at, updated_at = excluded.updated_at, priority = excluded.priority,
+			deadline = excluded.deadline, run_at = excluded.run_at, recurrence = excluded.recurrence, parent_id = excluded.parent_id,
+			lease_owner = NULL, lease_expires_at = NULL
+		`, goal.ID, goal.GoalText, string(goal.Status), goal.CreatedAt.Local(), goal.UpdatedAt.Local(),
+			goal.Priority, localTime(goal.Deadline), localTime(goal.RunAt), nullString(goal.Recurrence), nullString(goal.ParentID))
+		return err
+
+	case ExportKindUserMode:
+		var mode domain.UserMode
+		if err := json.Unmarshal(record.Data, &mode); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO user_modes (domain, mode, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(domain) DO UPDATE SET mode = excluded.mode, updated_at = excluded.updated_at
+		`, mode.Domain, string(mode.Mode), mode.UpdatedAt.Local())
+		return err
+
+	case ExportKindIntent:
+		var entry IntentHistoryEntry
+		if err := json.Unmarshal(record.Data, &entry); err != nil {
+			return err
+		}
+		// Keep the later execution time; compared in Go, as zone-suffixed text does not order across zones
+		rows, err := tx.QueryContext(ctx, "SELECT id, executed_at FROM intent_history WHERE intent = ? AND focused_window = ?", entry.Intent, entry.FocusedWindow)
+		if err != nil {
+			return err
+		}
+		executedAt := make(map[int64]time.Time)
+		for rows.Next() {
+			var id int64
+			var at time.Time
+			if err := rows.Scan(&id, &at); err != nil {
+				rows.Close()
+				return err
+			}
+			executedAt[id] = at
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for id, at := range executedAt {
+			if entry.ExecutedAt.After(at) {
+				at = entry.ExecutedAt
+			}
+			plan, err := r.cipher.Seal("intent_history", "cached_plan", fmt.Sprintf("%d", id), entry.CachedPlan)
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `
+			UPDATE intent_history
+			SET success_count = MAX(success_count, ?), executed_at = ?,
+				cached_plan = COALESCE(NULLIF(?, ''), cached_plan), application = COALESCE(NULLIF(?, ''), application)
+			WHERE id = ?
+			`, entry.SuccessCount, at.Local(), plan, entry.Application, id)
+			if err != nil {
+				return err
+			}
+		}
+		if len(executedAt) > 0 {
+			return nil
+		}
+		result, err := tx.ExecContext(ctx, `
+		INSERT INTO intent_history (intent, focused_window, executed_at, success_count, application)
+		VALUES (?, ?, ?, ?, ?)
+		`, entry.Intent, entry.FocusedWindow, entry.ExecutedAt.Local(), entry.SuccessCount, entry.Application)
+		if err != nil {
+			return err
+		}
+		return storeCachedPlan(ctx, tx, r.cipher, result, entry.CachedPlan)
+
+	case ExportKindMemory:
+		var memory domain.Memory
+		if err := json.Unmarshal(record.Data, &memory); err != nil {
+			return err
+		}
+		// Keep the existing row ID for this key so the sealed value stays bound to it
+		var id string
+		if err := tx.QueryRowContext(ctx, "SELECT id FROM memories WHERE key = ?", memory.Key).Scan(&id); err == nil {
+			memory.ID = id
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+		value, err := r.cipher.Seal("memories", "value", memory.ID, memory.Value)
+		if err != nil {
+			return err
+		}
+		var embeddingJSON string
+		if len(memory.Embedding) > 0 {
+			data, err := json.Marshal(memory.Embedding)
+			if err != nil {
+				return err
+			}
+			embeddingJSON = string(data)
+		}
+		memory.CreatedAt = memory.CreatedAt.Local()
+		if memory.ExpiresAt != nil {
+			expiresAt := memory.ExpiresAt.Local()
+			memory.ExpiresAt = &expiresAt
+		}
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO memories (id, key, value, context, embedding, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, context = excluded.context,
+			embedding = excluded.embedding, created_at = excluded.created_at, expires_at = excluded.expires_at
+		`, memory.ID, memory.Key, value, memory.Context, embeddingJSON, memory.CreatedAt, memory.ExpiresAt)
+		return err
+
+	case ExportKindAuditLog:
+		var audit AuditRecord
+		if err := json.Unmarshal(record.Data, &audit); err != nil {
+			return err
+		}
+		detail, err := r.cipher.Seal("audit_log", "detail", audit.ID, string(audit.Detail))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO audit_log (id, event, actor, detail, created_at, trace_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, audit.ID, audit.Event, audit.Actor, detail, audit.CreatedAt.Local(), audit.TraceID)
+		return err
+
+	default:
+		return fmt.Errorf("unknown record kind")
+	}
+}
+
+// exportGoals returns every goal, oldest first
+func (r *BackupRepository) exportGoals(ctx context.Context) ([]domain.Goal, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+goalColumns+" FROM active_goals ORDER BY created_at ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []domain.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		// Leases belong to planners of this kernel and are not carried over
+		goal.LeaseOwner, goal.LeaseExpiresAt = "", nil
+		goals = append(goals, *goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal rows: %w", err)
+	}
+	return goals, nil
+}
+
+// exportUserModes returns every automation mode
+func (r *BackupRepository) exportUserModes(ctx context.Context) ([]domain.UserMode, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT domain, mode, updated_at FROM user_modes ORDER BY domain ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user modes: %w", err)
+	}
+	defer rows.Close()
+
+	var modes []domain.UserMode
+	for rows.Next() {
+		var mode domain.UserMode
+		var modeType string
+		if err := rows.Scan(&mode.Domain, &modeType, &mode.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user mode: %w", err)
+		}
+		mode.Mode = domain.ModeType(modeType)
+		modes = append(modes, mode)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user mode rows: %w", err)
+	}
+	return modes, nil
+}
+
+// exportIntentHistory returns every intent history entry, including cached reflex plans
+func (r *BackupRepository) exportIntentHistory(ctx context.Context) ([]IntentHistoryEntry, error) {
+	query := `
+	SELECT id, intent, focused_window, application, executed_at, success_count, cached_plan
+	FROM intent_history
+	ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query intent history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []IntentHistoryEntry
+	for rows.Next() {
+		var entry IntentHistoryEntry
+		var id int
+		var application sql.NullString
+		var cachedPlan sql.NullString
+		if err := rows.Scan(&id, &entry.Intent, &entry.FocusedWindow, &application, &entry.ExecutedAt, &entry.SuccessCount, &cachedPlan); err != nil {
+			return nil, fmt.Errorf("failed to scan intent history: %w", err)
+		}
+		entry.ID = fmt.Sprintf("%d", id)
+		entry.Application = application.String
+		plan, err := r.cipher.Open("intent_history", "cached_plan", entry.ID, cachedPlan.String)
+		if err != nil {
+			return nil, err
+		}
+		entry.CachedPlan = plan
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating intent history rows: %w", err)
+	}
+	return entries, nil
+}
+
+// exportAuditLog returns the full audit trail, oldest first
+func (r *BackupRepository) exportAuditLog(ctx context.Context) ([]AuditRecord, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, event, actor, detail, created_at, trace_id FROM audit_log ORDER BY created_at ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	return scanAuditRecords(rows, r.cipher)
+}
+
+// localTime converts an optional imported timestamp to local time
+func localTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	local := t.Local()
+	return &local
+}
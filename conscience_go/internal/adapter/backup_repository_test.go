@@ -0,0 +1,186 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	source, err := OpenStore(DefaultStoreConfig(filepath.Join(dir, "source.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer source.Close()
+
+	goalRepo, err := NewGoalRepository(source)
+	if err != nil {
+		t.Fatalf("NewGoalRepository() error = %v", err)
+	}
+	actionRepo, err := NewActionRepository(source)
+	if err != nil {
+		t.Fatalf("NewActionRepository() error = %v", err)
+	}
+	intentRepo, err := NewIntentHistoryRepository(source)
+	if err != nil {
+		t.Fatalf("NewIntentHistoryRepository() error = %v", err)
+	}
+	memoryRepo, err := NewMemoryRepository(source)
+	if err != nil {
+		t.Fatalf("NewMemoryRepository() error = %v", err)
+	}
+
+	// Encrypt the source so export has to decrypt and import has to re-seal
+	keyFile := filepath.Join(dir, "db.key")
+	if err := GenerateKeyFile(keyFile); err != nil {
+		t.Fatalf("GenerateKeyFile() error = %v", err)
+	}
+	sourceCipher, err := OpenFieldCipher(ctx, source, KeySource{KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("OpenFieldCipher() error = %v", err)
+	}
+	memoryRepo.SetCipher(sourceCipher)
+
+	if err := goalRepo.SaveGoal(ctx, domain.NewGoal("file my taxes")); err != nil {
+		t.Fatalf("SaveGoal() error = %v", err)
+	}
+	if claimed, err := goalRepo.ClaimGoal(ctx, "planner", time.Minute); err != nil || claimed == nil {
+		t.Fatalf("ClaimGoal() = %v, %v; want the goal", claimed, err)
+	}
+	if err := actionRepo.SetUserMode(ctx, "email", domain.ModeTypeManual); err != nil {
+		t.Fatalf("SetUserMode() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := intentRepo.RecordExecution(ctx, "open mail", "Inbox - Outlook", "outlook.exe", `{"steps":[]}`); err != nil {
+			t.Fatalf("RecordExecution() error = %v", err)
+		}
+	}
+	if err := memoryRepo.SaveMemory(ctx, domain.NewMemory("editor", "prefers vim", "", time.Hour)); err != nil {
+		t.Fatalf("SaveMemory() error = %v", err)
+	}
+
+	backupRepo := NewBackupRepository(source)
+	backupRepo.SetCipher(sourceCipher)
+
+	var export bytes.Buffer
+	counts, err := backupRepo.Export(ctx, &export, ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if counts[ExportKindGoal] != 1 || counts[ExportKindIntent] != 1 || counts[ExportKindMemory] != 1 {
+		t.Errorf("Export() counts = %v", counts)
+	}
+
+	var redacted bytes.Buffer
+	if _, err := backupRepo.Export(ctx, &redacted, ExportOptions{Redact: true}); err != nil {
+		t.Fatalf("Export(redact) error = %v", err)
+	}
+	if strings.Contains(redacted.String(), "prefers vim") || strings.Contains(redacted.String(), "Inbox - Outlook") {
+		t.Error("redacted export leaked captured content")
+	}
+
+	target, err := OpenStore(DefaultStoreConfig(filepath.Join(dir, "target.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer target.Close()
+	if _, err := Migrate(ctx, target); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if _, err := NewBackupRepository(target).Import(ctx, &redacted); err == nil {
+		t.Error("Import() of a redacted export should fail")
+	}
+	if _, err := NewBackupRepository(target).Import(ctx, &export); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	trust, err := (&IntentHistoryRepository{db: target}).GetTrustScore(ctx, "open mail", "Inbox - Outlook")
+	if err != nil || trust != 3 {
+		t.Errorf("imported trust score = %d (err %v), want 3", trust, err)
+	}
+	targetGoals := &GoalRepository{db: target}
+	if claimed, err := targetGoals.ClaimGoal(ctx, "planner", time.Minute); err != nil || claimed == nil || claimed.GoalText != "file my taxes" {
+		t.Errorf("ClaimGoal() of a goal exported while planning = %v (err %v), want it back in the queue", claimed, err)
+	}
+	mode, err := (&ActionRepository{db: target}).GetUserMode(ctx, "email")
+	if err != nil || mode.Mode != domain.ModeTypeManual {
+		t.Errorf("imported mode = %v (err %v), want MANUAL", mode, err)
+	}
+	memories, err := (&MemoryRepository{db: target}).GetAllMemories(ctx)
+	if err != nil || len(memories) != 1 || memories[0].Value != "prefers vim" {
+		t.Errorf("imported memories = %v (err %v)", memories, err)
+	}
+
+	backupPath := filepath.Join(dir, "snapshot.db")
+	if err := backupRepo.Backup(ctx, backupPath); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if err := backupRepo.Backup(ctx, backupPath); err == nil {
+		t.Error("Backup() over an existing file should fail")
+	}
+	snapshot, err := OpenStore(DefaultStoreConfig(backupPath))
+	if err != nil {
+		t.Fatalf("OpenStore(snapshot) error = %v", err)
+	}
+	defer snapshot.Close()
+	if _, err := OpenFieldCipher(ctx, snapshot, KeySource{KeyFile: keyFile}); err != nil {
+		t.Errorf("snapshot does not open with the source key: %v", err)
+	}
+}
+
+func TestImportKeepsLaterIntentExecutionAcrossZones(t *testing.T) {
+	ctx := context.Background()
+	target, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer target.Close()
+	intentRepo, err := NewIntentHistoryRepository(target)
+	if err != nil {
+		t.Fatalf("NewIntentHistoryRepository() error = %v", err)
+	}
+	if err := intentRepo.RecordExecution(ctx, "open mail", "Inbox - Outlook", "outlook.exe", ""); err != nil {
+		t.Fatalf("RecordExecution() error = %v", err)
+	}
+
+	// Run two hours ago at +14:00, it reads as text later than the export's run now at -10:00
+	now := time.Now()
+	earlier := now.Add(-2 * time.Hour).In(time.FixedZone("LINT", 14*60*60))
+	if _, err := target.ExecContext(ctx, "UPDATE intent_history SET executed_at = ?", earlier); err != nil {
+		t.Fatal(err)
+	}
+	entry := IntentHistoryEntry{Intent: "open mail", FocusedWindow: "Inbox - Outlook", ExecutedAt: now.In(time.FixedZone("HST", -10*60*60)), SuccessCount: 4}
+
+	var export bytes.Buffer
+	enc := json.NewEncoder(&export)
+	header, _ := json.Marshal(ExportHeader{FormatVersion: ExportFormatVersion})
+	data, _ := json.Marshal(entry)
+	enc.Encode(exportLine{Kind: ExportKindHeader, Data: header})
+	enc.Encode(exportLine{Kind: ExportKindIntent, Data: data})
+	if _, err := NewBackupRepository(target).Import(ctx, &export); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	var executedAt time.Time
+	var successes int
+	if err := target.QueryRowContext(ctx, "SELECT executed_at, success_count FROM intent_history").Scan(&executedAt, &successes); err != nil {
+		t.Fatal(err)
+	}
+	if !executedAt.Equal(entry.ExecutedAt) {
+		t.Errorf("executed_at after import = %v, want the later run at %v", executedAt, entry.ExecutedAt)
+	}
+	if successes != 4 {
+		t.Errorf("success_count after import = %d, want 4", successes)
+	}
+}
@@ -0,0 +1,348 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+// Action batch errors
+var (
+	ErrBatchNotFound = errors.New("action batch not found")
+	ErrBatchState    = errors.New("action batch cannot do that in its current status")
+)
+
+// batchColumns is the column list scanBatch reads
+const batchColumns = `id, intent, domain, status, risk_score, max_step_risk, trace_id, failed_step, created_at, updated_at`
+
+// batchReader is satisfied by DB and *sql.Tx
+type batchReader interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// BatchRepository manages action batches and releases their steps in dependency order
+type BatchRepository struct {
+	db      DB
+	actions *ActionRepository
+}
+
+// NewBatchRepository creates a new BatchRepository and initializes tables
+// Step proposals are written and read through actions so they share its encryption.
+func NewBatchRepository(db DB, actions *ActionRepository) (*BatchRepository, error) {
+	repo := &BatchRepository{db: db, actions: actions}
+
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// SaveBatch persists a batch with the proposals for its steps and compensations in one transaction
+// Every proposal inherits the batch's trace ID.
+func (r *BatchRepository) SaveBatch(ctx context.Context, batch *domain.ActionBatch) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	batchSQL := `
+	INSERT INTO action_batches (` + batchColumns + `)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, batchSQL, batch.ID, batch.Intent, batch.Domain, string(batch.Status), batch.RiskScore,
+		batch.MaxStepRisk, nullString(batch.TraceID), batch.FailedStep, batch.CreatedAt, batch.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to insert action batch: %w", err)
+	}
+
+	stepSQL := `
+	INSERT INTO batch_steps (batch_id, position, depends_on, action_id, compensation_id)
+	VALUES (?, ?, ?, ?, ?)
+	`
+	for _, step := range batch.Steps {
+		var compensationID sql.NullString
+		for _, action := range []*domain.ActionProposal{step.Action, step.Compensation} {
+			if action == nil {
+				continue
+			}
+			action.TraceID = batch.TraceID
+			if action.Status == domain.ActionProposalStatusApproved && action.ApprovedAt == nil {
+				approvedAt := batch.UpdatedAt
+				action.ApprovedAt = &approvedAt
+			}
+			if err := r.actions.insertAction(ctx, tx, action); err != nil {
+				return err
+			}
+		}
+		if step.Compensation != nil {
+			compensationID = nullString(step.Compensation.ID)
+		}
+
+		dependsOn, err := json.Marshal(step.DependsOn)
+		if err != nil {
+			return fmt.Errorf("failed to marshal step dependencies: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, stepSQL, batch.ID, step.Position, string(dependsOn), step.Action.ID, compensationID); err != nil {
+			return fmt.Errorf("failed to insert batch step: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit action batch: %w", err)
+	}
+	return nil
+}
+
+// GetBatch retrieves a batch with the full proposals of its steps
+func (r *BatchRepository) GetBatch(ctx context.Context, id string) (*domain.ActionBatch, error) {
+	batch, err := loadBatch(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Swap the status-only proposals for full ones; retention may already have removed some
+	for i := range batch.Steps {
+		step := &batch.Steps[i]
+		if step.Action, err = r.fullAction(ctx, step.Action); err != nil {
+			return nil, err
+		}
+		if step.Compensation, err = r.fullAction(ctx, step.Compensation); err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
+
+// fullAction reloads a proposal read by loadBatch
+func (r *BatchRepository) fullAction(ctx context.Context, action *domain.ActionProposal) (*domain.ActionProposal, error) {
+	if action == nil {
+		return nil, nil
+	}
+	return r.actions.GetActionByID(ctx, action.ID)
+}
+
+// ListBatches returns batches newest first, optionally only those in status
+func (r *BatchRepository) ListBatches(ctx context.Context, status domain.ActionBatchStatus, limit int) ([]*domain.ActionBatch, error) {
+	query := "SELECT id FROM action_batches"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, string(status))
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query action batches: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan action batch: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating action batch rows: %w", err)
+	}
+
+	batches := make([]*domain.ActionBatch, 0, len(ids))
+	for _, id := range ids {
+		batch, err := r.GetBatch(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// DecideBatch records the user's approval or rejection of a whole batch
+// Approval releases the steps that depend on nothing; the rest follow as those complete.
+func (r *BatchRepository) DecideBatch(ctx context.Context, id string, approved bool) (*domain.ActionBatch, error) {
+	return r.advance(ctx, id, func(batch *domain.ActionBatch) ([]*domain.ActionProposal, error) {
+		if batch.Status != domain.ActionBatchStatusWaitingForUser {
+			return nil, fmt.Errorf("%w: batch is %s", ErrBatchState, batch.Status)
+		}
+		return batch.Decide(approved), nil
+	})
+}
+
+// SyncAction advances the batch holding an action after the action's status changed
+// Returns nil if the action belongs to no batch.
+func (r *BatchRepository) SyncAction(ctx context.Context, actionID string) (*domain.ActionBatch, error) {
+	var batchID string
+	query := "SELECT batch_id FROM batch_steps WHERE action_id = ? OR compensation_id = ? LIMIT 1"
+	err := r.db.QueryRowContext(ctx, query, actionID, actionID).Scan(&batchID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up batch for action: %w", err)
+	}
+	return r.advance(ctx, batchID, advanceBatch)
+}
+
+// SyncOpenBatches advances every unfinished batch, e.g. after an emergency stop cancelled their steps
+func (r *BatchRepository) SyncOpenBatches(ctx context.Context) error {
+	query := "SELECT id FROM action_batches WHERE status IN (?, ?, ?)"
+	rows, err := r.db.QueryContext(ctx, query,
+		string(domain.ActionBatchStatusWaitingForUser),
+		string(domain.ActionBatchStatusRunning),
+		string(domain.ActionBatchStatusCompensating))
+	if err != nil {
+		return fmt.Errorf("failed to query open batches: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan open batch: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating open batch rows: %w", err)
+	}
+
+	for _, id := range ids {
+		if _, err := r.advance(ctx, id, advanceBatch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceBatch is the change SyncAction and SyncOpenBatches apply
+func advanceBatch(batch *domain.ActionBatch) ([]*domain.ActionProposal, error) {
+	return batch.Advance(), nil
+}
+
+// advance loads a batch, applies change and writes back the batch and the proposals it changed
+// Runs in one transaction so concurrent status updates cannot release a step twice; the status
+// listeners hear about each changed proposal once it has committed.
+func (r *BatchRepository) advance(ctx context.Context, id string, change func(batch *domain.ActionBatch) ([]*domain.ActionProposal, error)) (*domain.ActionBatch, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin batch transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	batch, err := loadBatch(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	before, failedBefore := batch.Status, batch.FailedStep
+
+	changed, err := change(batch)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 && batch.Status == before && batch.FailedStep == failedBefore {
+		return r.GetBatch(ctx, id)
+	}
+
+	now := time.Now()
+	actionSQL := "UPDATE action_proposals SET status = ?, updated_at = ?, approved_at = COALESCE(?, approved_at) WHERE id = ?"
+	for _, action := range changed {
+		var approvedAt *time.Time
+		if action.Status == domain.ActionProposalStatusApproved {
+			approvedAt = &now
+		}
+		if _, err := tx.ExecContext(ctx, actionSQL, string(action.Status), now, approvedAt, action.ID); err != nil {
+			return nil, fmt.Errorf("failed to update batch proposal: %w", err)
+		}
+	}
+
+	batchSQL := "UPDATE action_batches SET status = ?, failed_step = ?, updated_at = ? WHERE id = ?"
+	if _, err := tx.ExecContext(ctx, batchSQL, string(batch.Status), batch.FailedStep, now, id); err != nil {
+		return nil, fmt.Errorf("failed to update action batch: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit action batch: %w", err)
+	}
+
+	// Goal progress follows batch steps like any other proposal
+	for _, action := range changed {
+		r.actions.notifyStatus(ctx, action.ID, action.Status)
+	}
+	return r.GetBatch(ctx, id)
+}
+
+// loadBatch reads a batch whose step proposals carry only their ID and status
+func loadBatch(ctx context.Context, db batchReader, id string) (*domain.ActionBatch, error) {
+	var batch domain.ActionBatch
+	var status string
+	var batchDomain, traceID sql.NullString
+	var failedStep sql.NullInt64
+
+	err := db.QueryRowContext(ctx, "SELECT "+batchColumns+" FROM action_batches WHERE id = ?", id).Scan(
+		&batch.ID, &batch.Intent, &batchDomain, &status, &batch.RiskScore, &batch.MaxStepRisk,
+		&traceID, &failedStep, &batch.CreatedAt, &batch.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrBatchNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query action batch: %w", err)
+	}
+	batch.Status = domain.ActionBatchStatus(status)
+	batch.Domain = batchDomain.String
+	batch.TraceID = traceID.String
+	if failedStep.Valid {
+		position := int(failedStep.Int64)
+		batch.FailedStep = &position
+	}
+
+	query := `
+	SELECT s.position, s.depends_on, s.action_id, a.status, s.compensation_id, c.status
+	FROM batch_steps s
+	LEFT JOIN action_proposals a ON a.id = s.action_id
+	LEFT JOIN action_proposals c ON c.id = s.compensation_id
+	WHERE s.batch_id = ?
+	ORDER BY s.position ASC
+	`
+	rows, err := db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch steps: %w", err)
+	}
+	defer rows.Close()
+
+	batch.Steps = []domain.BatchStep{}
+	for rows.Next() {
+		var step domain.BatchStep
+		var dependsOn, actionID string
+		var actionStatus, compensationID, compensationStatus sql.NullString
+		if err := rows.Scan(&step.Position, &dependsOn, &actionID, &actionStatus, &compensationID, &compensationStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan batch step: %w", err)
+		}
+		if err := json.Unmarshal([]byte(dependsOn), &step.DependsOn); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal step dependencies: %w", err)
+		}
+		if actionStatus.Valid {
+			step.Action = &domain.ActionProposal{ID: actionID, Status: domain.ActionProposalStatus(actionStatus.String)}
+		}
+		if compensationStatus.Valid {
+			step.Compensation = &domain.ActionProposal{ID: compensationID.String, Status: domain.ActionProposalStatus(compensationStatus.String)}
+		}
+		batch.Steps = append(batch.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch step rows: %w", err)
+	}
+	return &batch, nil
+}
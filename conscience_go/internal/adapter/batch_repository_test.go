@@ -0,0 +1,211 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ghost/kernel/internal/domain"
+)
+
+// newBatchRepos opens a fresh store with batches following their proposals
+func newBatchRepos(t *testing.T) (*BatchRepository, *ActionRepository) {
+	t.Helper()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	actionRepo, err := NewActionRepository(store)
+	if err != nil {
+		t.Fatalf("NewActionRepository() error = %v", err)
+	}
+	batchRepo, err := NewBatchRepository(store, actionRepo)
+	if err != nil {
+		t.Fatalf("NewBatchRepository() error = %v", err)
+	}
+	actionRepo.OnStatusChange(func(ctx context.Context, id string, _ domain.ActionProposalStatus) {
+		if _, err := batchRepo.SyncAction(ctx, id); err != nil {
+			t.Errorf("SyncAction() error = %v", err)
+		}
+	})
+	return batchRepo, actionRepo
+}
+
+// editorBatch is "open editor, type paragraph, save" with undo steps for the first two
+func editorBatch(t *testing.T) *domain.ActionBatch {
+	t.Helper()
+	step := func(intent string, risk int) *domain.ActionProposal {
+		return domain.NewActionProposal(intent, risk, json.RawMessage(`{}`), "EDITOR")
+	}
+	batch, err := domain.NewActionBatch("write a note", "EDITOR", []domain.BatchStep{
+		{Action: step("open editor", 10), Compensation: step("close editor", 5)},
+		{Action: step("type paragraph", 20), Compensation: step("undo typing", 5)},
+		{Action: step("save", 30)},
+	})
+	if err != nil {
+		t.Fatalf("NewActionBatch() error = %v", err)
+	}
+	return batch
+}
+
+func TestBatchStopsAndCompensatesOnFailure(t *testing.T) {
+	ctx := context.Background()
+	batchRepo, actionRepo := newBatchRepos(t)
+
+	// The last status each proposal was announced with, as goal progress sees it
+	notified := map[string]domain.ActionProposalStatus{}
+	actionRepo.OnStatusChange(func(_ context.Context, id string, status domain.ActionProposalStatus) {
+		notified[id] = status
+	})
+
+	batch := editorBatch(t)
+	if batch.RiskScore != 55 || batch.MaxStepRisk != 30 {
+		t.Errorf("risk = %d (max step %d), want aggregate 55 and max step 30", batch.RiskScore, batch.MaxStepRisk)
+	}
+	if err := batchRepo.SaveBatch(ctx, batch); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+	if approved, _ := actionRepo.GetApprovedActions(ctx); len(approved) != 0 {
+		t.Fatalf("GetApprovedActions() = %d before approval, want none", len(approved))
+	}
+
+	statuses := func(b *domain.ActionBatch) []domain.ActionProposalStatus {
+		var out []domain.ActionProposalStatus
+		for _, step := range b.Steps {
+			out = append(out, step.Action.Status)
+			if step.Compensation != nil {
+				out = append(out, step.Compensation.Status)
+			}
+		}
+		return out
+	}
+	expect := func(want domain.ActionBatchStatus, steps ...domain.ActionProposalStatus) *domain.ActionBatch {
+		t.Helper()
+		got, err := batchRepo.GetBatch(ctx, batch.ID)
+		if err != nil {
+			t.Fatalf("GetBatch() error = %v", err)
+		}
+		have := statuses(got)
+		if got.Status != want || len(have) != len(steps) {
+			t.Fatalf("batch = %s %v, want %s %v", got.Status, have, want, steps)
+		}
+		for i := range steps {
+			if have[i] != steps[i] {
+				t.Fatalf("batch = %s %v, want %s %v", got.Status, have, want, steps)
+			}
+		}
+		return got
+	}
+	const (
+		blocked   = domain.ActionProposalStatusBlocked
+		approved  = domain.ActionProposalStatusApproved
+		completed = domain.ActionProposalStatusCompleted
+		failed    = domain.ActionProposalStatusFailed
+		skipped   = domain.ActionProposalStatusSkipped
+	)
+
+	if _, err := batchRepo.DecideBatch(ctx, batch.ID, true); err != nil {
+		t.Fatalf("DecideBatch() error = %v", err)
+	}
+	expect(domain.ActionBatchStatusRunning, approved, blocked, blocked, blocked, blocked)
+	if _, err := batchRepo.DecideBatch(ctx, batch.ID, true); !errors.Is(err, ErrBatchState) {
+		t.Errorf("second DecideBatch() error = %v, want ErrBatchState", err)
+	}
+
+	// Step 2 only runs once step 1 has completed
+	if err := actionRepo.UpdateActionStatus(ctx, batch.Steps[0].Action.ID, completed); err != nil {
+		t.Fatalf("UpdateActionStatus() error = %v", err)
+	}
+	expect(domain.ActionBatchStatusRunning, completed, blocked, approved, blocked, blocked)
+
+	// A failure skips the save and undoes only what completed
+	if err := actionRepo.UpdateActionStatus(ctx, batch.Steps[1].Action.ID, failed); err != nil {
+		t.Fatalf("UpdateActionStatus() error = %v", err)
+	}
+	got := expect(domain.ActionBatchStatusCompensating, completed, approved, failed, blocked, skipped)
+	if got.FailedStep == nil || *got.FailedStep != 1 {
+		t.Errorf("FailedStep = %v, want 1", got.FailedStep)
+	}
+
+	if err := actionRepo.UpdateActionStatus(ctx, batch.Steps[0].Compensation.ID, completed); err != nil {
+		t.Fatalf("UpdateActionStatus() error = %v", err)
+	}
+	got = expect(domain.ActionBatchStatusFailed, completed, completed, failed, skipped, skipped)
+	for _, step := range got.Steps {
+		for _, action := range []*domain.ActionProposal{step.Action, step.Compensation} {
+			if action != nil && notified[action.ID] != action.Status {
+				t.Errorf("%q was last announced as %q, want %s", action.Intent, notified[action.ID], action.Status)
+			}
+		}
+	}
+}
+
+func TestBatchCompletesAndSkipsCompensation(t *testing.T) {
+	ctx := context.Background()
+	batchRepo, actionRepo := newBatchRepos(t)
+
+	batch := editorBatch(t)
+	if err := batchRepo.SaveBatch(ctx, batch); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+	if _, err := batchRepo.DecideBatch(ctx, batch.ID, true); err != nil {
+		t.Fatalf("DecideBatch() error = %v", err)
+	}
+	for _, step := range batch.Steps {
+		if err := actionRepo.UpdateActionStatus(ctx, step.Action.ID, domain.ActionProposalStatusCompleted); err != nil {
+			t.Fatalf("UpdateActionStatus() error = %v", err)
+		}
+	}
+
+	got, err := batchRepo.GetBatch(ctx, batch.ID)
+	if err != nil {
+		t.Fatalf("GetBatch() error = %v", err)
+	}
+	if got.Status != domain.ActionBatchStatusCompleted {
+		t.Fatalf("batch status = %s, want COMPLETED", got.Status)
+	}
+	for _, step := range got.Steps {
+		if step.Compensation != nil && step.Compensation.Status != domain.ActionProposalStatusSkipped {
+			t.Errorf("compensation %q = %s, want SKIPPED", step.Compensation.Intent, step.Compensation.Status)
+		}
+	}
+
+	rejected := editorBatch(t)
+	if err := batchRepo.SaveBatch(ctx, rejected); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+	got, err = batchRepo.DecideBatch(ctx, rejected.ID, false)
+	if err != nil {
+		t.Fatalf("DecideBatch() error = %v", err)
+	}
+	if got.Status != domain.ActionBatchStatusRejected || got.Steps[2].Action.Status != domain.ActionProposalStatusRejected {
+		t.Errorf("rejected batch = %s with last step %s, want REJECTED", got.Status, got.Steps[2].Action.Status)
+	}
+
+	if _, err := domain.NewActionBatch("loop", "EDITOR", []domain.BatchStep{
+		{Action: domain.NewActionProposal("a", 0, nil, "EDITOR"), DependsOn: []int{1}},
+		{Action: domain.NewActionProposal("b", 0, nil, "EDITOR")},
+	}); err == nil {
+		t.Error("NewActionBatch() accepted a step depending on a later step")
+	}
+}
+
+func TestBatchRiskCountsCompensations(t *testing.T) {
+	step := func(intent string, risk int) *domain.ActionProposal {
+		return domain.NewActionProposal(intent, risk, json.RawMessage(`{}`), "FILES")
+	}
+	batch, err := domain.NewActionBatch("tidy downloads", "FILES", []domain.BatchStep{
+		{Action: step("list downloads", 5), Compensation: step("delete every download", 90)},
+		{Action: step("open folder", 5)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.MaxStepRisk != 90 || batch.RiskScore < 90 {
+		t.Errorf("risk = %d (max step %d), want the compensation's 90 counted in both", batch.RiskScore, batch.MaxStepRisk)
+	}
+}
@@ -12,47 +12,46 @@ import (
 
 // CommandRepository manages command persistence and retrieval
 type CommandRepository struct {
-	db *sql.DB
+	db     DB
+	cipher *FieldCipher
 }
 
 // NewCommandRepository creates a new command repository
-func NewCommandRepository(db *sql.DB) (*CommandRepository, error) {
-	// Create commands table if not exists
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS commands (
-		id TEXT PRIMARY KEY,
-		action TEXT NOT NULL,
-		target TEXT NOT NULL,
-		payload TEXT NOT NULL,
-		status TEXT NOT NULL,
-		created_at DATETIME NOT NULL,
-		executed_at DATETIME
-	);
-	`
-
-	if _, err := db.Exec(createTableSQL); err != nil {
-		return nil, fmt.Errorf("failed to create commands table: %w", err)
+func NewCommandRepository(db DB) (*CommandRepository, error) {
+	if err := ensureSchema(db); err != nil {
+		return nil, err
 	}
 
 	return &CommandRepository{db: db}, nil
 }
 
+// SetCipher enables at-rest encryption of command payloads
+func (r *CommandRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
+
 // SaveCommand persists a command to the database
 func (r *CommandRepository) SaveCommand(ctx context.Context, cmd *domain.Command) error {
+	payload, err := r.cipher.Seal("commands", "payload", cmd.ID, cmd.Payload)
+	if err != nil {
+		return err
+	}
+
 	insertSQL := `
-	INSERT INTO commands (id, action, target, payload, status, created_at)
-	VALUES (?, ?, ?, ?, ?, ?)
+	INSERT INTO commands (id, action, target, payload, status, created_at, trace_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err = r.db.ExecContext(
 		ctx,
 		insertSQL,
 		cmd.ID,
 		string(cmd.Action),
 		cmd.Target,
-		cmd.Payload,
+		payload,
 		string(cmd.Status),
 		cmd.CreatedAt,
+		cmd.TraceID,
 	)
 
 	if err != nil {
@@ -65,7 +64,7 @@ func (r *CommandRepository) SaveCommand(ctx context.Context, cmd *domain.Command
 // GetPendingCommands retrieves all pending commands
 func (r *CommandRepository) GetPendingCommands(ctx context.Context) ([]domain.Command, error) {
 	query := `
-	SELECT id, action, target, payload, status, created_at, executed_at
+	SELECT id, action, target, payload, status, created_at, executed_at, trace_id
 	FROM commands
 	WHERE status = ?
 	ORDER BY created_at ASC
@@ -75,6 +74,49 @@ func (r *CommandRepository) GetPendingCommands(ctx context.Context) ([]domain.Co
 	if err != nil {
 		return nil, fmt.Errorf("failed to query pending commands: %w", err)
 	}
+	return r.scanCommands(rows)
+}
+
+// GetCommandsByTraceID retrieves the commands an intent produced, oldest first
+func (r *CommandRepository) GetCommandsByTraceID(ctx context.Context, traceID string) ([]domain.Command, error) {
+	query := `
+	SELECT id, action, target, payload, status, created_at, executed_at, trace_id
+	FROM commands
+	WHERE trace_id = ?
+	ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commands by trace: %w", err)
+	}
+	return r.scanCommands(rows)
+}
+
+// GetCommandByID retrieves a single command
+func (r *CommandRepository) GetCommandByID(ctx context.Context, id string) (*domain.Command, error) {
+	query := `
+	SELECT id, action, target, payload, status, created_at, executed_at, trace_id
+	FROM commands
+	WHERE id = ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query command: %w", err)
+	}
+	commands, err := r.scanCommands(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("command not found: %s", id)
+	}
+	return &commands[0], nil
+}
+
+// scanCommands reads and decrypts command rows, closing rows when done
+func (r *CommandRepository) scanCommands(rows *sql.Rows) ([]domain.Command, error) {
 	defer rows.Close()
 
 	var commands []domain.Command
@@ -83,6 +125,7 @@ func (r *CommandRepository) GetPendingCommands(ctx context.Context) ([]domain.Co
 		var action string
 		var status string
 		var executedAt sql.NullTime
+		var traceID sql.NullString
 
 		err := rows.Scan(
 			&cmd.ID,
@@ -92,6 +135,7 @@ func (r *CommandRepository) GetPendingCommands(ctx context.Context) ([]domain.Co
 			&status,
 			&cmd.CreatedAt,
 			&executedAt,
+			&traceID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan command: %w", err)
@@ -99,9 +143,13 @@ func (r *CommandRepository) GetPendingCommands(ctx context.Context) ([]domain.Co
 
 		cmd.Action = domain.CommandAction(action)
 		cmd.Status = domain.CommandStatus(status)
+		cmd.TraceID = traceID.String
 		if executedAt.Valid {
 			cmd.ExecutedAt = &executedAt.Time
 		}
+		if cmd.Payload, err = r.cipher.Open("commands", "payload", cmd.ID, cmd.Payload); err != nil {
+			return nil, err
+		}
 
 		commands = append(commands, cmd)
 	}
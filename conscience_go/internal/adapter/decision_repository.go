@@ -0,0 +1,90 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+// DecisionLogRepository keeps the requests the permission policy decided, so a candidate policy can be replayed against them
+type DecisionLogRepository struct {
+	db     DB
+	cipher *FieldCipher
+}
+
+// NewDecisionLogRepository creates a new decision log repository
+func NewDecisionLogRepository(db DB) (*DecisionLogRepository, error) {
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+
+	return &DecisionLogRepository{db: db}, nil
+}
+
+// SetCipher enables at-rest encryption of the recorded requests
+func (r *DecisionLogRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
+
+// Record stores one decision
+func (r *DecisionLogRepository) Record(ctx context.Context, record *domain.DecisionRecord) error {
+	request, err := r.cipher.Seal("decision_log", "request", record.ID, string(record.Request))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+	INSERT INTO decision_log (id, path, intent, domain, request, decision, rule, trace_id, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, record.ID, record.Path, record.Intent, record.Domain, request, record.Decision, record.Rule, record.TraceID, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert decision: %w", err)
+	}
+	return nil
+}
+
+// List returns decisions made since the given time, oldest first
+// A limit of zero or less returns them all.
+func (r *DecisionLogRepository) List(ctx context.Context, since time.Time, limit int) ([]domain.DecisionRecord, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := r.db.QueryContext(ctx, `
+	SELECT id, path, intent, domain, request, decision, rule, trace_id, created_at
+	FROM decision_log
+	WHERE created_at >= ?
+	ORDER BY created_at ASC
+	LIMIT ?
+	`, since.Local(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var records []domain.DecisionRecord
+	for rows.Next() {
+		var record domain.DecisionRecord
+		var domainName, traceID sql.NullString
+		var request string
+		if err := rows.Scan(&record.ID, &record.Path, &record.Intent, &domainName, &request,
+			&record.Decision, &record.Rule, &traceID, &record.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		if request, err = r.cipher.Open("decision_log", "request", record.ID, request); err != nil {
+			return nil, err
+		}
+		record.Request = json.RawMessage(request)
+		record.Domain = domainName.String
+		record.TraceID = traceID.String
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate decisions: %w", err)
+	}
+	return records, nil
+}
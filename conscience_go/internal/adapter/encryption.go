@@ -0,0 +1,488 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Encryption errors - the kernel refuses to serve when any of these occur at startup
+var (
+	ErrEncryptionKeyRequired = errors.New("database is encrypted: a passphrase or key file is required")
+	ErrWrongEncryptionKey    = errors.New("encryption key does not match this database")
+	ErrKeyRotationIncomplete = errors.New("a previous key rotation did not finish: rerun it with the same new key")
+)
+
+const (
+	// encryptedPrefix marks a column value sealed by FieldCipher: enc:v1:<key id>:<base64 nonce|ciphertext>
+	encryptedPrefix = "enc:v1:"
+
+	// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	pbkdf2Iterations = 600000
+
+	// keyCheckPlaintext is sealed with the active key so a wrong key is detected before serving
+	keyCheckPlaintext = "ghost-kernel-key-check"
+)
+
+// KeySource describes where the database key comes from
+// Exactly one of Passphrase or KeyFile should be set; both empty disables encryption
+type KeySource struct {
+	Passphrase string
+	KeyFile    string
+}
+
+// IsZero reports whether no key material was supplied
+func (k KeySource) IsZero() bool {
+	return k.Passphrase == "" && k.KeyFile == ""
+}
+
+// deriveKey turns the key source into a 256-bit AES key bound to the database salt
+func (k KeySource) deriveKey(salt []byte) ([]byte, error) {
+	switch {
+	case k.Passphrase != "" && k.KeyFile != "":
+		return nil, fmt.Errorf("specify either a passphrase or a key file, not both")
+	case k.Passphrase != "":
+		return pbkdf2.Key(sha256.New, k.Passphrase, salt, pbkdf2Iterations, 32)
+	case k.KeyFile != "":
+		material, err := os.ReadFile(k.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		material = []byte(strings.TrimSpace(string(material)))
+		if len(material) < 32 {
+			return nil, fmt.Errorf("key file must contain at least 32 bytes of key material")
+		}
+		return hkdf.Key(sha256.New, material, salt, "ghost-kernel-db", 32)
+	default:
+		return nil, ErrEncryptionKeyRequired
+	}
+}
+
+// GenerateKeyFile writes 32 random bytes (base64) to path with owner-only permissions
+func GenerateKeyFile(path string) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(key) + "\n"
+	if err := os.WriteFile(path, []byte(encoded), 0600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+// FieldCipher seals individual column values with AES-256-GCM
+// Each value is bound to its table, column and row ID so ciphertexts cannot be swapped between rows.
+// A nil *FieldCipher is valid and stores values in plaintext.
+type FieldCipher struct {
+	primaryID string
+	aeads     map[string]cipher.AEAD // key id -> AEAD (primary plus keys being rotated out)
+}
+
+// newFieldCipher creates a cipher that seals with primary and can open values sealed with any key
+func newFieldCipher(primary []byte, previous ...[]byte) (*FieldCipher, error) {
+	c := &FieldCipher{aeads: make(map[string]cipher.AEAD)}
+	for i, key := range append([][]byte{primary}, previous...) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCM: %w", err)
+		}
+		id := keyID(key)
+		if i == 0 {
+			c.primaryID = id
+		}
+		c.aeads[id] = aead
+	}
+	return c, nil
+}
+
+// keyID fingerprints a key without revealing it
+func keyID(key []byte) string {
+	sum := sha256.Sum256(append([]byte("ghost-key-id:"), key...))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fieldAD builds the associated data binding a value to its location
+func fieldAD(table, column, rowID string) []byte {
+	return []byte(table + "." + column + ":" + rowID)
+}
+
+// Seal encrypts a column value. Empty values and a nil cipher pass through unchanged.
+func (c *FieldCipher) Seal(table, column, rowID, plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	aead := c.aeads[c.primaryID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), fieldAD(table, column, rowID))
+	return encryptedPrefix + c.primaryID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a column value. Values written before encryption was enabled are returned as-is.
+func (c *FieldCipher) Open(table, column, rowID, stored string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return stored, nil
+	}
+	if c == nil {
+		return "", ErrEncryptionKeyRequired
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(stored, encryptedPrefix), ":")
+	if !ok {
+		return "", fmt.Errorf("malformed encrypted value in %s.%s", table, column)
+	}
+	aead, ok := c.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("%s.%s %s: %w", table, column, rowID, ErrWrongEncryptionKey)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value in %s.%s", table, column)
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], fieldAD(table, column, rowID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s.%s %s: %w", table, column, rowID, err)
+	}
+	return string(plaintext), nil
+}
+
+// encryptedColumn is a sensitive column protected at rest
+type encryptedColumn struct {
+	table  string
+	column string
+}
+
+// encryptedColumns lists every column sealed by FieldCipher (all tables use "id" as the row key)
+// Columns the kernel looks rows up by stay in plaintext, since a sealed value matches nothing:
+// intent_history.intent and focused_window (trust scores and reflexes are found by equality on them),
+// and the application and window title columns a forget scope matches by name or pattern
+// (artifacts.source_app and window_title, intent_history.application, the domain columns).
+var encryptedColumns = []encryptedColumn{
+	{table: "artifacts", column: "content"},
+	{table: "artifacts", column: "summary"},
+	{table: "action_proposals", column: "payload"},
+	{table: "action_proposals", column: "user_response"},
+	{table: "commands", column: "payload"},
+	{table: "memories", column: "value"},
+	{table: "decision_log", column: "request"},
+	{table: "proposal_messages", column: "text"},
+	{table: "proposal_revisions", column: "original"},
+	{table: "proposal_revisions", column: "edited"},
+	{table: "proposal_revisions", column: "changes"},
+	{table: "intent_history", column: "cached_plan"},
+	{table: "audit_log", column: "detail"},
+}
+
+// encryptionMeta is the single-row key verification record
+type encryptionMeta struct {
+	salt        []byte
+	keyCheck    string
+	pendingSalt []byte // Set while a rotation is in progress
+}
+
+// loadEncryptionMeta returns nil when encryption has never been enabled
+func loadEncryptionMeta(ctx context.Context, db DB) (*encryptionMeta, error) {
+	var salt, keyCheck string
+	var pendingSalt sql.NullString
+
+	err := db.QueryRowContext(ctx, "SELECT salt, key_check, pending_salt FROM encryption_meta WHERE id = 1").Scan(&salt, &keyCheck, &pendingSalt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption metadata: %w", err)
+	}
+
+	meta := &encryptionMeta{keyCheck: keyCheck}
+	if meta.salt, err = hex.DecodeString(salt); err != nil {
+		return nil, fmt.Errorf("corrupt encryption salt: %w", err)
+	}
+	if pendingSalt.Valid && pendingSalt.String != "" {
+		if meta.pendingSalt, err = hex.DecodeString(pendingSalt.String); err != nil {
+			return nil, fmt.Errorf("corrupt pending encryption salt: %w", err)
+		}
+	}
+	return meta, nil
+}
+
+// newSalt returns 16 random bytes
+func newSalt() ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return salt, nil
+}
+
+// verifyKey derives the key for the stored salt and checks it against the key check value
+func verifyKey(src KeySource, meta *encryptionMeta) ([]byte, error) {
+	key, err := src.deriveKey(meta.salt)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newFieldCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	check, err := c.Open("encryption_meta", "key_check", "1", meta.keyCheck)
+	if err != nil || check != keyCheckPlaintext {
+		return nil, ErrWrongEncryptionKey
+	}
+	return key, nil
+}
+
+// OpenFieldCipher verifies the key against the database and returns the active cipher
+// Returns a nil cipher (plaintext mode) when no key is supplied and the database was never encrypted.
+// The first start with a key enables encryption for this database.
+func OpenFieldCipher(ctx context.Context, db DB, src KeySource) (*FieldCipher, error) {
+	if _, err := Migrate(ctx, db); err != nil {
+		return nil, err
+	}
+
+	meta, err := loadEncryptionMeta(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if src.IsZero() {
+		if meta != nil {
+			return nil, ErrEncryptionKeyRequired
+		}
+		return nil, nil
+	}
+
+	if meta == nil {
+		return enableEncryption(ctx, db, src)
+	}
+	if meta.pendingSalt != nil {
+		return nil, ErrKeyRotationIncomplete
+	}
+
+	key, err := verifyKey(src, meta)
+	if err != nil {
+		return nil, err
+	}
+	return newFieldCipher(key)
+}
+
+// enableEncryption generates a salt and stores the key check for a newly encrypted database
+func enableEncryption(ctx context.Context, db DB, src KeySource) (*FieldCipher, error) {
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := src.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newFieldCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	check, err := c.Seal("encryption_meta", "key_check", "1", keyCheckPlaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	insertSQL := `INSERT INTO encryption_meta (id, salt, key_check, updated_at) VALUES (1, ?, ?, ?)`
+	if _, err := db.ExecContext(ctx, insertSQL, hex.EncodeToString(salt), check, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to store encryption metadata: %w", err)
+	}
+
+	return c, nil
+}
+
+// RotateEncryptionKey re-encrypts every sensitive column from the current key to next
+// The new salt is persisted before any row changes, so an interrupted rotation can be resumed
+// by running it again with the same keys.
+func RotateEncryptionKey(ctx context.Context, db DB, current, next KeySource, batchSize int) (*FieldCipher, error) {
+	if _, err := Migrate(ctx, db); err != nil {
+		return nil, err
+	}
+
+	meta, err := loadEncryptionMeta(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		// Nothing to rotate from - enabling encryption with the next key is equivalent
+		return enableEncryption(ctx, db, next)
+	}
+
+	oldKey, err := verifyKey(current, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingSalt := meta.pendingSalt
+	if pendingSalt == nil {
+		if pendingSalt, err = newSalt(); err != nil {
+			return nil, err
+		}
+		updateSQL := `UPDATE encryption_meta SET pending_salt = ?, updated_at = ? WHERE id = 1`
+		if _, err := db.ExecContext(ctx, updateSQL, hex.EncodeToString(pendingSalt), time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to record pending rotation: %w", err)
+		}
+	}
+
+	newKey, err := next.deriveKey(pendingSalt)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newFieldCipher(newKey, oldKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := EncryptExistingRows(ctx, db, c, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("key rotation interrupted after %d rows: %w", rotated, err)
+	}
+
+	check, err := c.Seal("encryption_meta", "key_check", "1", keyCheckPlaintext)
+	if err != nil {
+		return nil, err
+	}
+	finalizeSQL := `UPDATE encryption_meta SET salt = ?, key_check = ?, pending_salt = NULL, updated_at = ? WHERE id = 1`
+	if _, err := db.ExecContext(ctx, finalizeSQL, hex.EncodeToString(pendingSalt), check, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to finalize key rotation: %w", err)
+	}
+
+	return newFieldCipher(newKey)
+}
+
+// EncryptExistingRows seals every sensitive value not yet under the cipher's primary key
+// Works in batches, one transaction per batch, and is safe to re-run. Returns rows rewritten.
+// Rewriting a row leaves its old plaintext or old-key ciphertext in freed pages and the WAL, so
+// when any row changed the file is scrubbed afterwards (see scrubFreedPages).
+func EncryptExistingRows(ctx context.Context, db DB, c *FieldCipher, batchSize int) (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	total := 0
+	for _, col := range encryptedColumns {
+		exists, err := tableExists(ctx, db, col.table)
+		if err != nil {
+			return total, err
+		}
+		if !exists {
+			continue
+		}
+
+		for {
+			n, err := reencryptBatch(ctx, db, c, col, batchSize)
+			total += n
+			if err != nil {
+				return total, err
+			}
+			if n < batchSize {
+				break
+			}
+		}
+	}
+
+	if total > 0 {
+		if err := scrubFreedPages(ctx, db); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// scrubFreedPages leaves no copy of rewritten values in the database file or its WAL
+// The WAL is checkpointed and truncated, VACUUM rebuilds the file without the freed pages, and the
+// rebuilt pages it wrote to the WAL are checkpointed back in turn.
+func scrubFreedPages(ctx context.Context, db DB) error {
+	for _, step := range []string{"PRAGMA wal_checkpoint(TRUNCATE)", "VACUUM", "PRAGMA wal_checkpoint(TRUNCATE)"} {
+		if _, err := db.ExecContext(ctx, step); err != nil {
+			return fmt.Errorf("failed to scrub old values (%s): %w", step, err)
+		}
+	}
+	return nil
+}
+
+// reencryptBatch rewrites up to batchSize values of one column in a single transaction
+func reencryptBatch(ctx context.Context, db DB, c *FieldCipher, col encryptedColumn, batchSize int) (int, error) {
+	query := fmt.Sprintf(`
+	SELECT id, %[2]s FROM %[1]s
+	WHERE %[2]s IS NOT NULL AND %[2]s != '' AND %[2]s NOT LIKE ?
+	LIMIT ?
+	`, col.table, col.column)
+
+	rows, err := db.QueryContext(ctx, query, encryptedPrefix+c.primaryID+":%", batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan %s.%s for re-encryption: %w", col.table, col.column, err)
+	}
+
+	type pending struct{ id, value string }
+	var batch []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.value); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan %s row: %w", col.table, err)
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating %s rows: %w", col.table, err)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin re-encryption batch: %w", err)
+	}
+	defer tx.Rollback()
+
+	updateSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", col.table, col.column)
+	for _, p := range batch {
+		plaintext, err := c.Open(col.table, col.column, p.id, p.value)
+		if err != nil {
+			return 0, err
+		}
+		sealed, err := c.Seal(col.table, col.column, p.id, plaintext)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, updateSQL, sealed, p.id); err != nil {
+			return 0, fmt.Errorf("failed to rewrite %s.%s: %w", col.table, col.column, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit re-encryption batch: %w", err)
+	}
+	return len(batch), nil
+}
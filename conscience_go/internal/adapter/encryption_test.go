@@ -0,0 +1,216 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ghost/kernel/internal/domain"
+)
+
+func TestFieldEncryptionRoundTripAndRotation(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	memoryRepo, err := NewSQLiteRepository(filepath.Join(dir, "kernel.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	defer memoryRepo.Close()
+	db := memoryRepo.GetDB()
+
+	// A plaintext row written before encryption was enabled
+	legacy := domain.NewArtifact(domain.ArtifactTypeText, "legacy secret", domain.BoundingBox{})
+	if err := memoryRepo.Save(ctx, legacy); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	oldKey := KeySource{KeyFile: filepath.Join(dir, "old.key")}
+	newKey := KeySource{KeyFile: filepath.Join(dir, "new.key")}
+	for _, k := range []KeySource{oldKey, newKey} {
+		if err := GenerateKeyFile(k.KeyFile); err != nil {
+			t.Fatalf("GenerateKeyFile() error = %v", err)
+		}
+	}
+
+	c, err := OpenFieldCipher(ctx, db, oldKey)
+	if err != nil {
+		t.Fatalf("OpenFieldCipher() error = %v", err)
+	}
+	if _, err := EncryptExistingRows(ctx, db, c, 1); err != nil {
+		t.Fatalf("EncryptExistingRows() error = %v", err)
+	}
+	memoryRepo.SetCipher(c)
+
+	fresh := domain.NewArtifact(domain.ArtifactTypeText, "fresh secret", domain.BoundingBox{})
+	if err := memoryRepo.Save(ctx, fresh); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRowContext(ctx, "SELECT content FROM artifacts WHERE id = ?", legacy.ID).Scan(&stored); err != nil {
+		t.Fatalf("query error = %v", err)
+	}
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		t.Errorf("stored content = %q, want it encrypted", stored)
+	}
+
+	if _, err := OpenFieldCipher(ctx, db, KeySource{}); !errors.Is(err, ErrEncryptionKeyRequired) {
+		t.Errorf("OpenFieldCipher() without key error = %v, want ErrEncryptionKeyRequired", err)
+	}
+	if _, err := OpenFieldCipher(ctx, db, newKey); !errors.Is(err, ErrWrongEncryptionKey) {
+		t.Errorf("OpenFieldCipher() with wrong key error = %v, want ErrWrongEncryptionKey", err)
+	}
+
+	rotated, err := RotateEncryptionKey(ctx, db, oldKey, newKey, 1)
+	if err != nil {
+		t.Fatalf("RotateEncryptionKey() error = %v", err)
+	}
+	if _, err := OpenFieldCipher(ctx, db, oldKey); !errors.Is(err, ErrWrongEncryptionKey) {
+		t.Errorf("OpenFieldCipher() with retired key error = %v, want ErrWrongEncryptionKey", err)
+	}
+	memoryRepo.SetCipher(rotated)
+
+	artifacts, err := memoryRepo.GetLastArtifacts(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetLastArtifacts() error = %v", err)
+	}
+	contents := map[string]string{}
+	for _, a := range artifacts {
+		contents[a.ID] = a.Content
+	}
+	if contents[legacy.ID] != "legacy secret" || contents[fresh.ID] != "fresh secret" {
+		t.Errorf("decrypted contents = %v, want original plaintext", contents)
+	}
+}
+
+func TestFieldCipherBindsRow(t *testing.T) {
+	c, err := newFieldCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("newFieldCipher() error = %v", err)
+	}
+	sealed, err := c.Seal("artifacts", "content", "row-1", "secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if _, err := c.Open("artifacts", "content", "row-2", sealed); err == nil {
+		t.Error("Open() of a value moved to another row should fail")
+	}
+	var plaintext *FieldCipher
+	if _, err := plaintext.Open("artifacts", "content", "row-1", sealed); err == nil {
+		t.Error("Open() of an encrypted value without a cipher should fail")
+	}
+}
+
+func TestEncryptionLeavesNoPlaintextInFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kernel.db")
+	store, err := OpenStore(DefaultStoreConfig(path))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	memoryRepo, err := NewSQLiteRepositoryWithDB(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := "account number 4929-0000-1111-2222"
+	for i := 0; i < 50; i++ {
+		if err := memoryRepo.Save(ctx, domain.NewArtifact(domain.ArtifactTypeText, secret, domain.BoundingBox{})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The plaintext is on disk before encryption
+	if err := store.Checkpoint(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	key := KeySource{KeyFile: filepath.Join(dir, "db.key")}
+	if err := GenerateKeyFile(key.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	c, err := OpenFieldCipher(ctx, store, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := EncryptExistingRows(ctx, store, c, 7); err != nil || n != 50 {
+		t.Fatalf("EncryptExistingRows() = %d, %v; want 50 rows", n, err)
+	}
+
+	for _, file := range []string{path, path + "-wal"} {
+		data, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("%s still holds the plaintext after encryption", filepath.Base(file))
+		}
+	}
+}
+
+func TestCachedPlansAndAuditDetailsEncrypted(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(dir, "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	intentRepo, err := NewIntentHistoryRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo, err := NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := KeySource{KeyFile: filepath.Join(dir, "db.key")}
+	if err := GenerateKeyFile(key.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	c, err := OpenFieldCipher(ctx, store, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intentRepo.SetCipher(c)
+	auditRepo.SetCipher(c)
+
+	plan := `[{"type":"type","text":"account number 4929"}]`
+	for i := 0; i < 6; i++ {
+		if err := intentRepo.RecordExecution(ctx, "fill form", "Bank", "browser", plan); err != nil {
+			t.Fatalf("RecordExecution() error = %v", err)
+		}
+	}
+	if _, err := auditRepo.Record(ctx, AuditEventForget, "user", map[string]string{"scope": "browser"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	var storedPlan, storedDetail string
+	if err := store.QueryRowContext(ctx, "SELECT cached_plan FROM intent_history").Scan(&storedPlan); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.QueryRowContext(ctx, "SELECT detail FROM audit_log").Scan(&storedDetail); err != nil {
+		t.Fatal(err)
+	}
+	for _, stored := range []string{storedPlan, storedDetail} {
+		if !strings.HasPrefix(stored, encryptedPrefix) {
+			t.Errorf("stored value = %q, want it encrypted", stored)
+		}
+	}
+
+	reflex, _, err := intentRepo.GetReflex(ctx, "fill form")
+	if err != nil || reflex != plan {
+		t.Errorf("GetReflex() = %q, %v; want the original plan", reflex, err)
+	}
+	records, err := auditRepo.GetRecent(ctx, 1)
+	if err != nil || len(records) != 1 || string(records[0].Detail) != `{"scope":"browser"}` {
+		t.Errorf("GetRecent() = %v, %v; want the original detail", records, err)
+	}
+}
@@ -4,34 +4,82 @@ package adapter
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"ghost/kernel/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Goal plan errors
+var (
+	ErrGoalNotFound     = errors.New("goal not found")
+	ErrPlanStarted      = errors.New("goal plan already has proposed steps")
+	ErrStepNotAvailable = errors.New("plan step does not exist or was already proposed")
+	ErrLeaseHeld        = errors.New("goal is leased to another planner")
+	ErrLeaseLost        = errors.New("goal lease expired or is held by another planner")
+	ErrGoalState        = errors.New("goal cannot do that in its current status")
 )
 
+// goalColumns is the column list scanGoal reads
+const goalColumns = `id, goal_text, status, created_at, updated_at, priority, deadline, run_at, recurrence, parent_id, lease_owner, lease_expires_at`
+
+// goalQueueOrder is the order planners take ACTIVE goals in
+const goalQueueOrder = `ORDER BY priority DESC, deadline IS NULL, deadline ASC, created_at ASC`
+
+// nullString stores an empty string as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanGoal reads a row selected with goalColumns
+func scanGoal(row rowScanner) (*domain.Goal, error) {
+	var goal domain.Goal
+	var status string
+	var deadline, runAt, leaseExpiresAt sql.NullTime
+	var recurrence, parentID, leaseOwner sql.NullString
+
+	err := row.Scan(&goal.ID, &goal.GoalText, &status, &goal.CreatedAt, &goal.UpdatedAt,
+		&goal.Priority, &deadline, &runAt, &recurrence, &parentID, &leaseOwner, &leaseExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.Status = domain.GoalStatus(status)
+	goal.Recurrence = recurrence.String
+	goal.ParentID = parentID.String
+	goal.LeaseOwner = leaseOwner.String
+	if deadline.Valid {
+		goal.Deadline = &deadline.Time
+	}
+	if runAt.Valid {
+		goal.RunAt = &runAt.Time
+	}
+	if leaseExpiresAt.Valid {
+		goal.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	return &goal, nil
+}
+
 // GoalRepository manages goal persistence for the Agentic Planner
 type GoalRepository struct {
-	db *sql.DB
+	db DB
 }
 
 // NewGoalRepository creates a new GoalRepository and initializes tables
-func NewGoalRepository(db *sql.DB) (*GoalRepository, error) {
+func NewGoalRepository(db DB) (*GoalRepository, error) {
 	repo := &GoalRepository{db: db}
 
-	// Create active_goals table
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS active_goals (
-		id TEXT PRIMARY KEY,
-		goal_text TEXT NOT NULL,
-		status TEXT NOT NULL,
-		created_at DATETIME NOT NULL,
-		updated_at DATETIME NOT NULL
-	);
-	`
-
-	if _, err := db.Exec(createTableSQL); err != nil {
-		return nil, fmt.Errorf("failed to create active_goals table: %w", err)
+	if err := ensureSchema(db); err != nil {
+		return nil, err
 	}
 
 	return repo, nil
@@ -40,8 +88,8 @@ func NewGoalRepository(db *sql.DB) (*GoalRepository, error) {
 // SaveGoal persists a goal to the database
 func (r *GoalRepository) SaveGoal(ctx context.Context, goal *domain.Goal) error {
 	insertSQL := `
-	INSERT INTO active_goals (id, goal_text, status, created_at, updated_at)
-	VALUES (?, ?, ?, ?, ?)
+	INSERT INTO active_goals (id, goal_text, status, created_at, updated_at, priority, deadline, run_at, recurrence, parent_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := r.db.ExecContext(
@@ -52,6 +100,11 @@ func (r *GoalRepository) SaveGoal(ctx context.Context, goal *domain.Goal) error
 		string(goal.Status),
 		goal.CreatedAt,
 		goal.UpdatedAt,
+		goal.Priority,
+		goal.Deadline,
+		goal.RunAt,
+		nullString(goal.Recurrence),
+		nullString(goal.ParentID),
 	)
 
 	if err != nil {
@@ -61,27 +114,12 @@ func (r *GoalRepository) SaveGoal(ctx context.Context, goal *domain.Goal) error
 	return nil
 }
 
-// GetActiveGoal retrieves the first active goal ready for planning
+// GetActiveGoal retrieves the first active goal ready for planning, without claiming it
+// It only reads: goals abandoned under a lapsed lease come back once ClaimGoal or the scheduler expires the lease.
 func (r *GoalRepository) GetActiveGoal(ctx context.Context) (*domain.Goal, error) {
-	query := `
-	SELECT id, goal_text, status, created_at, updated_at
-	FROM active_goals
-	WHERE status = ?
-	ORDER BY created_at ASC
-	LIMIT 1
-	`
-
-	var goal domain.Goal
-	var status string
-
-	err := r.db.QueryRowContext(ctx, query, string(domain.GoalStatusActive)).Scan(
-		&goal.ID,
-		&goal.GoalText,
-		&status,
-		&goal.CreatedAt,
-		&goal.UpdatedAt,
-	)
+	query := `SELECT ` + goalColumns + ` FROM active_goals WHERE status = ? ` + goalQueueOrder + ` LIMIT 1`
 
+	goal, err := scanGoal(r.db.QueryRowContext(ctx, query, string(domain.GoalStatusActive)))
 	if err == sql.ErrNoRows {
 		return nil, nil // No active goal found (not an error)
 	}
@@ -89,9 +127,40 @@ func (r *GoalRepository) GetActiveGoal(ctx context.Context) (*domain.Goal, error
 		return nil, fmt.Errorf("failed to query active goal: %w", err)
 	}
 
-	goal.Status = domain.GoalStatus(status)
+	return goal, nil
+}
 
-	return &goal, nil
+// ListGoals returns the newest goals first, optionally only those in status
+func (r *GoalRepository) ListGoals(ctx context.Context, status domain.GoalStatus, limit int) ([]domain.Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM active_goals`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, string(status))
+	}
+	query += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []domain.Goal{}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, *goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal rows: %w", err)
+	}
+
+	return goals, nil
 }
 
 // UpdateGoalStatus updates the status of a goal
@@ -141,3 +210,800 @@ func (r *GoalRepository) DeleteGoal(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// GetGoalByID retrieves a goal, or nil if it does not exist
+func (r *GoalRepository) GetGoalByID(ctx context.Context, id string) (*domain.Goal, error) {
+	query := `SELECT ` + goalColumns + ` FROM active_goals WHERE id = ?`
+
+	goal, err := scanGoal(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goal: %w", err)
+	}
+	return goal, nil
+}
+
+// ClaimGoal atomically moves the next ACTIVE goal to PLANNING under a lease held by worker
+// Returns nil if no goal is waiting or another planner took it first. The worker keeps the goal
+// by calling Heartbeat before the lease expires.
+func (r *GoalRepository) ClaimGoal(ctx context.Context, worker string, lease time.Duration) (*domain.Goal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin goal claim: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := expireLeases(ctx, tx, now); err != nil {
+		return nil, err
+	}
+
+	var id string
+	query := "SELECT id FROM active_goals WHERE status = ? " + goalQueueOrder + " LIMIT 1"
+	err = tx.QueryRowContext(ctx, query, string(domain.GoalStatusActive)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, tx.Commit()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active goal: %w", err)
+	}
+
+	claimSQL := `
+	UPDATE active_goals
+	SET status = ?, lease_owner = ?, lease_expires_at = ?, updated_at = ?
+	WHERE id = ? AND status = ?
+	`
+	result, err := tx.ExecContext(ctx, claimSQL, string(domain.GoalStatusPlanning), worker, now.Add(lease), now, id, string(domain.GoalStatusActive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim goal: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if claimed != 1 {
+		return nil, tx.Commit()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit goal claim: %w", err)
+	}
+
+	return r.GetGoalByID(ctx, id)
+}
+
+// Heartbeat extends worker's lease on a goal; ErrLeaseLost means another planner may now own it
+func (r *GoalRepository) Heartbeat(ctx context.Context, goalID, worker string, lease time.Duration) (*domain.Goal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin goal heartbeat: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	var leaseOwner sql.NullString
+	var leaseExpiresAt sql.NullTime
+	query := "SELECT status, lease_owner, lease_expires_at FROM active_goals WHERE id = ?"
+	err = tx.QueryRowContext(ctx, query, goalID).Scan(&status, &leaseOwner, &leaseExpiresAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to query goal lease: %w", err)
+	}
+
+	now := time.Now()
+	held := err == nil && leaseOwner.String == worker && leaseExpiresAt.Valid && !leaseExpiresAt.Time.Before(now) &&
+		(status == string(domain.GoalStatusPlanning) || status == string(domain.GoalStatusExecuting))
+	if !held {
+		return nil, fmt.Errorf("%w: goal %s, worker %s", ErrLeaseLost, goalID, worker)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE active_goals SET lease_expires_at = ? WHERE id = ? AND lease_owner = ?", now.Add(lease), goalID, worker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extend goal lease: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected != 1 {
+		return nil, fmt.Errorf("%w: goal %s, worker %s", ErrLeaseLost, goalID, worker)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit goal heartbeat: %w", err)
+	}
+	return r.GetGoalByID(ctx, goalID)
+}
+
+// ExpireLeases releases lapsed leases and returns how many goals went back to ACTIVE
+// A goal whose plan has no proposed step is re-queued and its unstarted plan discarded; one already
+// executing is re-queued with its plan, so the planner that claims it next resumes at the first
+// unproposed step.
+func (r *GoalRepository) ExpireLeases(ctx context.Context) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin lease expiry: %w", err)
+	}
+	defer tx.Rollback()
+
+	requeued, err := expireLeases(ctx, tx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit lease expiry: %w", err)
+	}
+	return requeued, nil
+}
+
+// expireLeases is ExpireLeases inside the caller's transaction
+// Expiry times are compared in Go: the driver stores them as text, which does not order across time zones.
+func expireLeases(ctx context.Context, tx *sql.Tx, now time.Time) (int64, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id, status, lease_expires_at FROM active_goals WHERE lease_expires_at IS NOT NULL")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query goal leases: %w", err)
+	}
+	var lapsed, abandoned []string
+	for rows.Next() {
+		var id, status string
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&id, &status, &expiresAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan goal lease: %w", err)
+		}
+		if !expiresAt.Valid || !expiresAt.Time.Before(now) {
+			continue
+		}
+		lapsed = append(lapsed, id)
+		if status == string(domain.GoalStatusPlanning) || status == string(domain.GoalStatusExecuting) {
+			abandoned = append(abandoned, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("failed to read goal leases: %w", err)
+	}
+	rows.Close()
+
+	for _, id := range abandoned {
+		var proposed bool
+		query := "SELECT EXISTS (SELECT 1 FROM goal_steps WHERE goal_id = ? AND action_id IS NOT NULL)"
+		if err := tx.QueryRowContext(ctx, query, id).Scan(&proposed); err != nil {
+			return 0, fmt.Errorf("failed to query goal steps: %w", err)
+		}
+		if !proposed {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM goal_steps WHERE goal_id = ?", id); err != nil {
+				return 0, fmt.Errorf("failed to discard abandoned plan: %w", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE active_goals SET status = ?, updated_at = ? WHERE id = ?", string(domain.GoalStatusActive), now, id); err != nil {
+			return 0, fmt.Errorf("failed to requeue abandoned goal: %w", err)
+		}
+	}
+
+	for _, id := range lapsed {
+		if _, err := tx.ExecContext(ctx, "UPDATE active_goals SET lease_owner = NULL, lease_expires_at = NULL WHERE id = ?", id); err != nil {
+			return 0, fmt.Errorf("failed to clear expired lease: %w", err)
+		}
+	}
+
+	return int64(len(abandoned)), nil
+}
+
+// checkLease refuses a change from worker while another planner holds a live lease on the goal
+func checkLease(owner string, expiresAt *time.Time, worker string, now time.Time) error {
+	if owner == "" || owner == worker || expiresAt == nil || expiresAt.Before(now) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrLeaseHeld, owner)
+}
+
+// SavePlan replaces a goal's plan with one step per intent, in order, and moves the goal to PLANNING
+// Refused once a step has been proposed, so a running plan cannot be rewritten under the planner,
+// and while another worker holds the goal's lease.
+func (r *GoalRepository) SavePlan(ctx context.Context, goalID, worker string, intents []string) (*domain.GoalPlan, error) {
+	if len(intents) == 0 {
+		return nil, fmt.Errorf("a plan needs at least one step")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin plan transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	var leaseOwner sql.NullString
+	var leaseExpiresAt sql.NullTime
+	query := "SELECT status, lease_owner, lease_expires_at FROM active_goals WHERE id = ?"
+	err = tx.QueryRowContext(ctx, query, goalID).Scan(&status, &leaseOwner, &leaseExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrGoalNotFound, goalID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goal: %w", err)
+	}
+	now := time.Now()
+	var expiresAt *time.Time
+	if leaseExpiresAt.Valid {
+		expiresAt = &leaseExpiresAt.Time
+	}
+	if err := checkLease(leaseOwner.String, expiresAt, worker, now); err != nil {
+		return nil, err
+	}
+	switch domain.GoalStatus(status) {
+	case domain.GoalStatusActive, domain.GoalStatusPlanning, domain.GoalStatusExecuting:
+	default:
+		return nil, fmt.Errorf("%w: goal is %s", ErrGoalState, status)
+	}
+
+	var proposed int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM goal_steps WHERE goal_id = ? AND action_id IS NOT NULL", goalID).Scan(&proposed); err != nil {
+		return nil, fmt.Errorf("failed to count proposed steps: %w", err)
+	}
+	if proposed > 0 {
+		return nil, ErrPlanStarted
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM goal_steps WHERE goal_id = ?", goalID); err != nil {
+		return nil, fmt.Errorf("failed to clear goal plan: %w", err)
+	}
+
+	insertSQL := `
+	INSERT INTO goal_steps (id, goal_id, position, intent, status, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	for i, intent := range intents {
+		if _, err := tx.ExecContext(ctx, insertSQL, uuid.New().String(), goalID, i, intent, string(domain.PlanStepStatusPending), now, now); err != nil {
+			return nil, fmt.Errorf("failed to insert plan step: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE active_goals SET status = ?, updated_at = ? WHERE id = ?", string(domain.GoalStatusPlanning), now, goalID); err != nil {
+		return nil, fmt.Errorf("failed to update goal status: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit goal plan: %w", err)
+	}
+
+	return r.GetPlan(ctx, goalID)
+}
+
+// AttachAction links the proposal generated for the step at position and re-derives the goal's status
+func (r *GoalRepository) AttachAction(ctx context.Context, goalID string, position int, action *domain.ActionProposal) (*domain.GoalPlan, error) {
+	updateSQL := `
+	UPDATE goal_steps
+	SET action_id = ?, status = ?, updated_at = ?
+	WHERE goal_id = ? AND position = ? AND action_id IS NULL
+	AND goal_id IN (SELECT id FROM active_goals WHERE status IN (?, ?))
+	`
+
+	result, err := r.db.ExecContext(ctx, updateSQL, action.ID, string(domain.StepStatusFor(action.Status)), time.Now(), goalID, position,
+		string(domain.GoalStatusPlanning), string(domain.GoalStatusExecuting))
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach action to plan step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: goal %s step %d", ErrStepNotAvailable, goalID, position)
+	}
+
+	return r.GetPlan(ctx, goalID)
+}
+
+// StepAvailable reports whether the step at position exists, has not been proposed yet and
+// belongs to a goal that is still being planned or executed
+func (r *GoalRepository) StepAvailable(ctx context.Context, goalID string, position int) (bool, error) {
+	var count int
+	query := `
+	SELECT COUNT(*) FROM goal_steps
+	WHERE goal_id = ? AND position = ? AND action_id IS NULL
+	AND goal_id IN (SELECT id FROM active_goals WHERE status IN (?, ?))
+	`
+	err := r.db.QueryRowContext(ctx, query, goalID, position, string(domain.GoalStatusPlanning), string(domain.GoalStatusExecuting)).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to query plan step: %w", err)
+	}
+	return count > 0, nil
+}
+
+// FailGoal marks a goal FAILED, e.g. when the planner cannot produce a plan for it
+// Final goals are left unchanged; a goal leased to another worker is refused.
+func (r *GoalRepository) FailGoal(ctx context.Context, id, worker string) (*domain.Goal, error) {
+	goal, err := r.GetGoalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if goal == nil {
+		return nil, fmt.Errorf("%w: %s", ErrGoalNotFound, id)
+	}
+	if err := checkLease(goal.LeaseOwner, goal.LeaseExpiresAt, worker, time.Now()); err != nil {
+		return nil, err
+	}
+	if goal.Status.IsFinal() {
+		return goal, nil
+	}
+
+	if err := r.UpdateGoalStatus(ctx, id, domain.GoalStatusFailed); err != nil {
+		return nil, err
+	}
+	goal.Status = domain.GoalStatusFailed
+	return goal, nil
+}
+
+// GetPlan returns a goal with its ordered steps and progress, re-derived from the current
+// status of each step's proposal
+func (r *GoalRepository) GetPlan(ctx context.Context, goalID string) (*domain.GoalPlan, error) {
+	goal, steps, err := r.refresh(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewGoalPlan(goal, steps), nil
+}
+
+// SyncAction re-derives the step linked to actionID and its goal after the proposal changes status
+// Returns nil if the proposal is not part of a plan.
+func (r *GoalRepository) SyncAction(ctx context.Context, actionID string) (*domain.Goal, error) {
+	var goalID string
+	err := r.db.QueryRowContext(ctx, "SELECT goal_id FROM goal_steps WHERE action_id = ?", actionID).Scan(&goalID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plan step: %w", err)
+	}
+
+	goal, _, err := r.refresh(ctx, goalID)
+	return goal, err
+}
+
+// SyncOpenGoals re-derives every planning or executing goal, for bulk proposal changes such as
+// an emergency stop cancelling everything in flight
+func (r *GoalRepository) SyncOpenGoals(ctx context.Context) error {
+	query := "SELECT id FROM active_goals WHERE status IN (?, ?)"
+	rows, err := r.db.QueryContext(ctx, query, string(domain.GoalStatusPlanning), string(domain.GoalStatusExecuting))
+	if err != nil {
+		return fmt.Errorf("failed to query open goals: %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan goal id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating goal rows: %w", err)
+	}
+
+	for _, id := range ids {
+		if _, _, err := r.refresh(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refresh loads a goal's steps, updates each from its proposal's status and stores the derived goal status
+// A step keeps its last status if its proposal has since been purged by retention. The derived status is
+// only written if the goal's status has not changed since it was read.
+func (r *GoalRepository) refresh(ctx context.Context, goalID string) (*domain.Goal, []domain.PlanStep, error) {
+	goal, err := r.GetGoalByID(ctx, goalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if goal == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrGoalNotFound, goalID)
+	}
+
+	steps, err := r.getSteps(ctx, goalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	for i := range steps {
+		step := &steps[i]
+		if step.ActionStatus == "" {
+			continue
+		}
+		derived := domain.StepStatusFor(step.ActionStatus)
+		if derived == step.Status {
+			continue
+		}
+		if _, err := r.db.ExecContext(ctx, "UPDATE goal_steps SET status = ?, updated_at = ? WHERE id = ?", string(derived), now, step.ID); err != nil {
+			return nil, nil, fmt.Errorf("failed to update plan step status: %w", err)
+		}
+		step.Status = derived
+		step.UpdatedAt = now
+	}
+
+	if status := domain.DeriveGoalStatus(goal.Status, steps); status != goal.Status {
+		// Only over the status derived from, so a goal cancelled or failed meanwhile keeps that status
+		updateSQL := "UPDATE active_goals SET status = ?, updated_at = ? WHERE id = ? AND status = ?"
+		result, err := r.db.ExecContext(ctx, updateSQL, string(status), now, goalID, string(goal.Status))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update goal status: %w", err)
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if updated == 0 {
+			if goal, err = r.GetGoalByID(ctx, goalID); err != nil {
+				return nil, nil, err
+			}
+			if goal == nil {
+				return nil, nil, fmt.Errorf("%w: %s", ErrGoalNotFound, goalID)
+			}
+			return goal, steps, nil
+		}
+		goal.Status = status
+		goal.UpdatedAt = now
+	}
+
+	return goal, steps, nil
+}
+
+// getSteps returns a goal's plan in order, with the current status of each linked proposal
+func (r *GoalRepository) getSteps(ctx context.Context, goalID string) ([]domain.PlanStep, error) {
+	query := `
+	SELECT s.id, s.goal_id, s.position, s.intent, s.action_id, s.status, s.created_at, s.updated_at, a.status
+	FROM goal_steps s
+	LEFT JOIN action_proposals a ON a.id = s.action_id
+	WHERE s.goal_id = ?
+	ORDER BY s.position ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plan steps: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []domain.PlanStep
+	for rows.Next() {
+		var step domain.PlanStep
+		var actionID, actionStatus sql.NullString
+		var status string
+
+		if err := rows.Scan(&step.ID, &step.GoalID, &step.Position, &step.Intent, &actionID, &status, &step.CreatedAt, &step.UpdatedAt, &actionStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan plan step: %w", err)
+		}
+
+		step.ActionID = actionID.String
+		step.ActionStatus = domain.ActionProposalStatus(actionStatus.String)
+		step.Status = domain.PlanStepStatus(status)
+		steps = append(steps, step)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating plan step rows: %w", err)
+	}
+
+	return steps, nil
+}
+
+// PauseGoal holds a scheduled or queued goal so it is neither fired nor handed to a planner
+func (r *GoalRepository) PauseGoal(ctx context.Context, id string) (*domain.Goal, error) {
+	return r.transition(ctx, id, func(goal *domain.Goal, _ time.Time) error {
+		if goal.Status != domain.GoalStatusScheduled && goal.Status != domain.GoalStatusActive {
+			return fmt.Errorf("%w: only scheduled or queued goals can be paused, goal is %s", ErrGoalState, goal.Status)
+		}
+		goal.Status = domain.GoalStatusPaused
+		return nil
+	}, nil)
+}
+
+// ResumeGoal releases a paused goal: back to SCHEDULED if it still has a run time ahead or recurs,
+// otherwise straight to ACTIVE. A recurring goal skips the firings it missed while paused.
+func (r *GoalRepository) ResumeGoal(ctx context.Context, id string) (*domain.Goal, error) {
+	return r.transition(ctx, id, func(goal *domain.Goal, now time.Time) error {
+		if goal.Status != domain.GoalStatusPaused {
+			return fmt.Errorf("%w: only paused goals can be resumed, goal is %s", ErrGoalState, goal.Status)
+		}
+		switch {
+		case goal.Recurrence != "":
+			if goal.RunAt == nil || goal.RunAt.Before(now) {
+				next, err := domain.NextRun(goal.Recurrence, now)
+				if err != nil {
+					return err
+				}
+				goal.RunAt = &next
+			}
+			goal.Status = domain.GoalStatusScheduled
+		case goal.RunAt != nil && goal.RunAt.After(now):
+			goal.Status = domain.GoalStatusScheduled
+		default:
+			goal.Status = domain.GoalStatusActive
+		}
+		return nil
+	}, nil)
+}
+
+// GoalCancellation is what cancelling a goal withdrew
+type GoalCancellation struct {
+	Goal              *domain.Goal `json:"goal"`
+	RejectedActions   int64        `json:"rejected_actions"`
+	CancelledActions  int64        `json:"cancelled_actions"`
+	CancelledCommands int64        `json:"cancelled_commands"`
+	// ExecutingActions were running on the Body when the goal was cancelled
+	ExecutingActions []string `json:"executing_actions,omitempty"`
+	// TraceIDs are the traces of the goal's proposals; commands still queued for the Body under them are stale
+	TraceIDs []string `json:"trace_ids,omitempty"`
+}
+
+// CancelGoal withdraws a goal that has not finished; its planner loses the lease and cannot propose
+// further steps. In the same transaction, proposals waiting on the user or on context are rejected,
+// approved and executing ones are cancelled, and Sentinel commands under their traces are cancelled.
+func (r *GoalRepository) CancelGoal(ctx context.Context, id string) (*GoalCancellation, error) {
+	result := &GoalCancellation{}
+	goal, err := r.transition(ctx, id, func(goal *domain.Goal, _ time.Time) error {
+		if goal.Status.IsFinal() {
+			return fmt.Errorf("%w: goal is already %s", ErrGoalState, goal.Status)
+		}
+		goal.Status = domain.GoalStatusCancelled
+		goal.LeaseOwner, goal.LeaseExpiresAt = "", nil
+		return nil
+	}, func(tx *sql.Tx, goal *domain.Goal, now time.Time) error {
+		return cancelGoalWork(ctx, tx, goal.ID, now, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Goal = goal
+	return result, nil
+}
+
+// cancelGoalWork withdraws the proposals and commands a goal's steps spawned
+func cancelGoalWork(ctx context.Context, tx *sql.Tx, goalID string, now time.Time, result *GoalCancellation) error {
+	query := `
+	SELECT a.id, a.status, COALESCE(a.trace_id, '')
+	FROM goal_steps s
+	JOIN action_proposals a ON a.id = s.action_id
+	WHERE s.goal_id = ?
+	`
+	rows, err := tx.QueryContext(ctx, query, goalID)
+	if err != nil {
+		return fmt.Errorf("failed to query goal proposals: %w", err)
+	}
+	seen := map[string]bool{}
+	for rows.Next() {
+		var actionID, status, traceID string
+		if err := rows.Scan(&actionID, &status, &traceID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan goal proposal: %w", err)
+		}
+		if domain.ActionProposalStatus(status) == domain.ActionProposalStatusExecuting {
+			result.ExecutingActions = append(result.ExecutingActions, actionID)
+		}
+		if traceID != "" && !seen[traceID] {
+			seen[traceID] = true
+			result.TraceIDs = append(result.TraceIDs, traceID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating goal proposals: %w", err)
+	}
+
+	goalActions := "id IN (SELECT action_id FROM goal_steps WHERE goal_id = ? AND action_id IS NOT NULL)"
+
+	rejected, err := tx.ExecContext(ctx, "UPDATE action_proposals SET status = ?, updated_at = ? WHERE "+goalActions+" AND status IN (?, ?, ?)",
+		string(domain.ActionProposalStatusRejected), now, goalID,
+		string(domain.ActionProposalStatusPending),
+		string(domain.ActionProposalStatusWaitingForUser),
+		string(domain.ActionProposalStatusWaitingForContext))
+	if err != nil {
+		return fmt.Errorf("failed to reject goal proposals: %w", err)
+	}
+	result.RejectedActions, _ = rejected.RowsAffected()
+
+	cancelled, err := tx.ExecContext(ctx, "UPDATE action_proposals SET status = ?, updated_at = ? WHERE "+goalActions+" AND status IN (?, ?)",
+		string(domain.ActionProposalStatusCancelled), now, goalID,
+		string(domain.ActionProposalStatusApproved),
+		string(domain.ActionProposalStatusExecuting))
+	if err != nil {
+		return fmt.Errorf("failed to cancel goal proposals: %w", err)
+	}
+	result.CancelledActions, _ = cancelled.RowsAffected()
+
+	commandsSQL := `
+	UPDATE commands SET status = ?
+	WHERE status IN (?, ?)
+	AND trace_id IN (SELECT trace_id FROM action_proposals WHERE ` + goalActions + ` AND trace_id IS NOT NULL AND trace_id != '')
+	`
+	commands, err := tx.ExecContext(ctx, commandsSQL,
+		string(domain.CommandStatusCancelled),
+		string(domain.CommandStatusPending),
+		string(domain.CommandStatusExecuting),
+		goalID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel goal commands: %w", err)
+	}
+	result.CancelledCommands, _ = commands.RowsAffected()
+
+	// Withdrawn steps fail, as they would if their proposals had been rejected one by one
+	stepsSQL := `
+	UPDATE goal_steps SET status = ?, updated_at = ?
+	WHERE goal_id = ? AND status = ?
+	AND action_id IN (SELECT id FROM action_proposals WHERE status IN (?, ?))
+	`
+	if _, err := tx.ExecContext(ctx, stepsSQL, string(domain.PlanStepStatusFailed), now, goalID, string(domain.PlanStepStatusProposed),
+		string(domain.ActionProposalStatusRejected), string(domain.ActionProposalStatusCancelled)); err != nil {
+		return fmt.Errorf("failed to update goal steps: %w", err)
+	}
+	return nil
+}
+
+// transition applies change to a goal and stores its status, run time and lease in one transaction
+// cascade, if set, runs in the same transaction after the goal is updated.
+func (r *GoalRepository) transition(ctx context.Context, id string, change func(goal *domain.Goal, now time.Time) error,
+	cascade func(tx *sql.Tx, goal *domain.Goal, now time.Time) error) (*domain.Goal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin goal transition: %w", err)
+	}
+	defer tx.Rollback()
+
+	goal, err := scanGoal(tx.QueryRowContext(ctx, `SELECT `+goalColumns+` FROM active_goals WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrGoalNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goal: %w", err)
+	}
+
+	now := time.Now()
+	if err := change(goal, now); err != nil {
+		return nil, err
+	}
+	goal.UpdatedAt = now
+
+	updateSQL := `
+	UPDATE active_goals
+	SET status = ?, run_at = ?, lease_owner = ?, lease_expires_at = ?, updated_at = ?
+	WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, updateSQL, string(goal.Status), goal.RunAt, nullString(goal.LeaseOwner), goal.LeaseExpiresAt, now, id); err != nil {
+		return nil, fmt.Errorf("failed to update goal: %w", err)
+	}
+	if cascade != nil {
+		if err := cascade(tx, goal, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit goal transition: %w", err)
+	}
+	return goal, nil
+}
+
+// FireDueGoals queues every SCHEDULED goal whose run time has come and returns the goals queued
+// A one-shot goal becomes ACTIVE itself; a recurring goal queues a new occurrence and moves its
+// run time to the next firing after now, so firings missed while the kernel was off are skipped.
+func (r *GoalRepository) FireDueGoals(ctx context.Context, now time.Time) ([]domain.Goal, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin goal firing: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Run times are compared in Go, like lease expiry: stored as text, they do not order across zone offsets
+	query := `SELECT ` + goalColumns + ` FROM active_goals WHERE status = ? AND run_at IS NOT NULL`
+	rows, err := tx.QueryContext(ctx, query, string(domain.GoalStatusScheduled))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due goals: %w", err)
+	}
+	var due []*domain.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		if goal.RunAt != nil && !goal.RunAt.After(now) {
+			due = append(due, goal)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal rows: %w", err)
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].RunAt.Before(*due[j].RunAt) })
+
+	fired := []domain.Goal{}
+	for _, goal := range due {
+		if goal.Recurrence == "" {
+			if _, err := tx.ExecContext(ctx, "UPDATE active_goals SET status = ?, updated_at = ? WHERE id = ?", string(domain.GoalStatusActive), now, goal.ID); err != nil {
+				return nil, fmt.Errorf("failed to queue scheduled goal: %w", err)
+			}
+			goal.Status, goal.UpdatedAt = domain.GoalStatusActive, now
+			fired = append(fired, *goal)
+			continue
+		}
+
+		occurrence := domain.NewGoal(goal.GoalText)
+		occurrence.Priority = goal.Priority
+		occurrence.ParentID = goal.ID
+		insertSQL := `
+		INSERT INTO active_goals (id, goal_text, status, created_at, updated_at, priority, parent_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		`
+		if _, err := tx.ExecContext(ctx, insertSQL, occurrence.ID, occurrence.GoalText, string(occurrence.Status), occurrence.CreatedAt, occurrence.UpdatedAt, occurrence.Priority, occurrence.ParentID); err != nil {
+			return nil, fmt.Errorf("failed to queue goal occurrence: %w", err)
+		}
+
+		next, err := domain.NextRun(goal.Recurrence, now)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE active_goals SET run_at = ?, updated_at = ? WHERE id = ?", next, now, goal.ID); err != nil {
+			return nil, fmt.Errorf("failed to reschedule recurring goal: %w", err)
+		}
+		fired = append(fired, *occurrence)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit goal firing: %w", err)
+	}
+	return fired, nil
+}
+
+// FailOverdueGoals fails goals whose deadline passed before a planner started on them
+// Deadlines are compared in Go, like lease expiry: stored as text, they do not order across zone offsets.
+func (r *GoalRepository) FailOverdueGoals(ctx context.Context, now time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin overdue goal check: %w", err)
+	}
+	defer tx.Rollback()
+
+	waiting := []interface{}{string(domain.GoalStatusScheduled), string(domain.GoalStatusActive), string(domain.GoalStatusPaused)}
+	query := "SELECT id, deadline FROM active_goals WHERE status IN (?, ?, ?) AND deadline IS NOT NULL"
+	rows, err := tx.QueryContext(ctx, query, waiting...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query goal deadlines: %w", err)
+	}
+	var overdue []string
+	for rows.Next() {
+		var id string
+		var deadline sql.NullTime
+		if err := rows.Scan(&id, &deadline); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan goal deadline: %w", err)
+		}
+		if deadline.Valid && deadline.Time.Before(now) {
+			overdue = append(overdue, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating goal rows: %w", err)
+	}
+
+	updateSQL := "UPDATE active_goals SET status = ?, updated_at = ? WHERE id = ?"
+	for _, id := range overdue {
+		if _, err := tx.ExecContext(ctx, updateSQL, string(domain.GoalStatusFailed), now, id); err != nil {
+			return 0, fmt.Errorf("failed to fail overdue goal: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit overdue goals: %w", err)
+	}
+	return int64(len(overdue)), nil
+}
@@ -0,0 +1,514 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+func TestGoalStatusFollowsPlan(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	goalRepo, err := NewGoalRepository(store)
+	if err != nil {
+		t.Fatalf("NewGoalRepository() error = %v", err)
+	}
+	actionRepo, err := NewActionRepository(store)
+	if err != nil {
+		t.Fatalf("NewActionRepository() error = %v", err)
+	}
+	actionRepo.OnStatusChange(func(ctx context.Context, id string, _ domain.ActionProposalStatus) {
+		if _, err := goalRepo.SyncAction(ctx, id); err != nil {
+			t.Errorf("SyncAction() error = %v", err)
+		}
+	})
+
+	goal := domain.NewGoal("book a table")
+	if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatalf("SaveGoal() error = %v", err)
+	}
+	plan, err := goalRepo.SavePlan(ctx, goal.ID, "", []string{"open site", "pick time", "confirm"})
+	if err != nil {
+		t.Fatalf("SavePlan() error = %v", err)
+	}
+	if plan.Goal.Status != domain.GoalStatusPlanning || plan.Total != 3 {
+		t.Fatalf("plan = %s with %d steps, want PLANNING with 3", plan.Goal.Status, plan.Total)
+	}
+	if active, _ := goalRepo.GetActiveGoal(ctx); active != nil {
+		t.Errorf("GetActiveGoal() = %s, want nothing once the goal is planned", active.ID)
+	}
+
+	propose := func(position int, status domain.ActionProposalStatus) *domain.ActionProposal {
+		t.Helper()
+		action := domain.NewActionProposal(plan.Steps[position].Intent, 40, json.RawMessage(`{}`), "PLANNER")
+		action.Status = status
+		if err := actionRepo.SaveActionProposal(ctx, action); err != nil {
+			t.Fatalf("SaveActionProposal() error = %v", err)
+		}
+		if _, err := goalRepo.AttachAction(ctx, goal.ID, position, action); err != nil {
+			t.Fatalf("AttachAction(%d) error = %v", position, err)
+		}
+		return action
+	}
+
+	first := propose(0, domain.ActionProposalStatusExecuting)
+	if err := actionRepo.UpdateActionStatus(ctx, first.ID, domain.ActionProposalStatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = goalRepo.GetPlan(ctx, goal.ID)
+	if err != nil {
+		t.Fatalf("GetPlan() error = %v", err)
+	}
+	if plan.Goal.Status != domain.GoalStatusExecuting || plan.Completed != 1 {
+		t.Errorf("after step 0 = %s with %d completed, want EXECUTING with 1", plan.Goal.Status, plan.Completed)
+	}
+
+	if _, err := goalRepo.SavePlan(ctx, goal.ID, "", []string{"start over"}); !errors.Is(err, ErrPlanStarted) {
+		t.Errorf("SavePlan() on a running plan error = %v, want ErrPlanStarted", err)
+	}
+	if _, err := goalRepo.AttachAction(ctx, goal.ID, 0, first); !errors.Is(err, ErrStepNotAvailable) {
+		t.Errorf("AttachAction() on a proposed step error = %v, want ErrStepNotAvailable", err)
+	}
+
+	// Rejecting the second step fails the goal and points at it
+	second := propose(1, domain.ActionProposalStatusWaitingForUser)
+	if err := actionRepo.UpdateActionStatus(ctx, second.ID, domain.ActionProposalStatusRejected); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = goalRepo.GetPlan(ctx, goal.ID)
+	if err != nil {
+		t.Fatalf("GetPlan() error = %v", err)
+	}
+	if plan.Goal.Status != domain.GoalStatusFailed {
+		t.Errorf("goal status = %s, want FAILED", plan.Goal.Status)
+	}
+	if plan.FailedStep == nil || plan.FailedStep.Position != 1 || plan.FailedStep.ActionStatus != domain.ActionProposalStatusRejected {
+		t.Errorf("failed step = %+v, want step 1 rejected", plan.FailedStep)
+	}
+
+	// Steps go with their goal
+	if err := goalRepo.DeleteGoal(ctx, goal.ID); err != nil {
+		t.Fatalf("DeleteGoal() error = %v", err)
+	}
+	var steps int
+	if err := store.QueryRowContext(ctx, "SELECT COUNT(*) FROM goal_steps").Scan(&steps); err != nil {
+		t.Fatal(err)
+	}
+	if steps != 0 {
+		t.Errorf("%d plan steps left after deleting the goal, want 0", steps)
+	}
+}
+
+func TestEmergencyStopFailsRunningGoals(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	goalRepo, err := NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actionRepo, err := NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateRepo, err := NewStateRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goal := domain.NewGoal("tidy downloads")
+	if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := goalRepo.SavePlan(ctx, goal.ID, "", []string{"list files", "move files"}); err != nil {
+		t.Fatal(err)
+	}
+	action := domain.NewActionProposal("list files", 10, json.RawMessage(`{}`), "PLANNER")
+	action.Status = domain.ActionProposalStatusApproved
+	if err := actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := goalRepo.AttachAction(ctx, goal.ID, 0, action); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stateRepo.Halt(ctx, "test", "runaway"); err != nil {
+		t.Fatalf("Halt() error = %v", err)
+	}
+	if err := goalRepo.SyncOpenGoals(ctx); err != nil {
+		t.Fatalf("SyncOpenGoals() error = %v", err)
+	}
+
+	stored, err := goalRepo.GetGoalByID(ctx, goal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.GoalStatusFailed {
+		t.Errorf("goal status after emergency stop = %s, want FAILED", stored.Status)
+	}
+}
+
+func TestGoalLeases(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	goalRepo, err := NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goal := domain.NewGoal("file taxes")
+	if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+
+	// Planners racing for one goal: exactly one wins it
+	claims := make(chan *domain.Goal, 8)
+	for i := 0; i < cap(claims); i++ {
+		go func(worker string) {
+			claimed, err := goalRepo.ClaimGoal(ctx, worker, time.Minute)
+			if err != nil {
+				t.Errorf("ClaimGoal() error = %v", err)
+			}
+			claims <- claimed
+		}(fmt.Sprintf("planner-%d", i))
+	}
+	var winner string
+	for i := 0; i < cap(claims); i++ {
+		if claimed := <-claims; claimed != nil {
+			if winner != "" {
+				t.Fatalf("goal claimed by both %s and %s", winner, claimed.LeaseOwner)
+			}
+			winner = claimed.LeaseOwner
+			if claimed.Status != domain.GoalStatusPlanning || claimed.LeaseExpiresAt == nil {
+				t.Errorf("claimed goal = %s expiring %v, want PLANNING under a lease", claimed.Status, claimed.LeaseExpiresAt)
+			}
+		}
+	}
+	if winner == "" {
+		t.Fatal("no planner claimed the goal")
+	}
+
+	if _, err := goalRepo.SavePlan(ctx, goal.ID, "intruder", []string{"fill form"}); !errors.Is(err, ErrLeaseHeld) {
+		t.Errorf("SavePlan() by another worker error = %v, want ErrLeaseHeld", err)
+	}
+	if _, err := goalRepo.SavePlan(ctx, goal.ID, winner, []string{"fill form"}); err != nil {
+		t.Fatalf("SavePlan() by the lease holder error = %v", err)
+	}
+	if _, err := goalRepo.Heartbeat(ctx, goal.ID, "intruder", time.Minute); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Heartbeat() by another worker error = %v, want ErrLeaseLost", err)
+	}
+	if _, err := goalRepo.Heartbeat(ctx, goal.ID, winner, time.Minute); err != nil {
+		t.Errorf("Heartbeat() by the lease holder error = %v", err)
+	}
+
+	// Leases are compared as instants: a live lease written in a zone behind ours is still held
+	behind := time.FixedZone("AOE", -12*60*60)
+	if _, err := store.ExecContext(ctx, "UPDATE active_goals SET lease_expires_at = ? WHERE id = ?", time.Now().Add(time.Minute).In(behind), goal.ID); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err := goalRepo.ClaimGoal(ctx, "successor", time.Minute); err != nil || claimed != nil {
+		t.Fatalf("ClaimGoal() under a live lease = %+v, %v; want nothing", claimed, err)
+	}
+	if _, err := goalRepo.Heartbeat(ctx, goal.ID, winner, time.Minute); err != nil {
+		t.Errorf("Heartbeat() of a lease written in another zone error = %v", err)
+	}
+
+	// The holder dies: once the lease lapses the goal is re-queued without its unstarted plan,
+	// even when the expiry was written in a zone ahead of ours
+	ahead := time.FixedZone("LINT", 14*60*60)
+	if _, err := store.ExecContext(ctx, "UPDATE active_goals SET lease_expires_at = ? WHERE id = ?", time.Now().Add(-time.Second).In(ahead), goal.ID); err != nil {
+		t.Fatal(err)
+	}
+	if active, err := goalRepo.GetActiveGoal(ctx); err != nil || active != nil {
+		t.Fatalf("GetActiveGoal() = %+v, %v; want it to leave the lapsed lease to a claim", active, err)
+	}
+	reclaimed, err := goalRepo.ClaimGoal(ctx, "successor", time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimGoal() error = %v", err)
+	}
+	if reclaimed == nil || reclaimed.ID != goal.ID || reclaimed.LeaseOwner != "successor" {
+		t.Fatalf("ClaimGoal() after expiry = %+v, want the goal leased to successor", reclaimed)
+	}
+	plan, err := goalRepo.GetPlan(ctx, goal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Total != 0 {
+		t.Errorf("re-queued goal kept %d plan steps, want 0", plan.Total)
+	}
+	if _, err := goalRepo.Heartbeat(ctx, goal.ID, winner, time.Minute); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Heartbeat() by the expired holder error = %v, want ErrLeaseLost", err)
+	}
+}
+
+func TestGoalLeaseExpiryResumesStartedPlan(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	goalRepo, err := NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actionRepo, err := NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actionRepo.OnStatusChange(func(ctx context.Context, id string, _ domain.ActionProposalStatus) {
+		if _, err := goalRepo.SyncAction(ctx, id); err != nil {
+			t.Errorf("SyncAction() error = %v", err)
+		}
+	})
+
+	goal := domain.NewGoal("move house")
+	if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err := goalRepo.ClaimGoal(ctx, "crashes", time.Minute); err != nil || claimed == nil {
+		t.Fatalf("ClaimGoal() = %+v, %v; want the goal", claimed, err)
+	}
+	if _, err := goalRepo.SavePlan(ctx, goal.ID, "crashes", []string{"book van", "pack boxes"}); err != nil {
+		t.Fatalf("SavePlan() error = %v", err)
+	}
+	first := domain.NewActionProposal("book van", 40, json.RawMessage(`{}`), "PLANNER")
+	if err := actionRepo.SaveActionProposal(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := goalRepo.AttachAction(ctx, goal.ID, 0, first)
+	if err != nil {
+		t.Fatalf("AttachAction() error = %v", err)
+	}
+	if plan.Goal.Status != domain.GoalStatusExecuting {
+		t.Fatalf("goal after the first proposal = %s, want EXECUTING", plan.Goal.Status)
+	}
+
+	// The planner dies after proposing a step and its lease lapses
+	if _, err := store.ExecContext(ctx, "UPDATE active_goals SET lease_expires_at = ? WHERE id = ?", time.Now().Add(-time.Second), goal.ID); err != nil {
+		t.Fatal(err)
+	}
+	if requeued, err := goalRepo.ExpireLeases(ctx); err != nil || requeued != 1 {
+		t.Fatalf("ExpireLeases() = %d, %v; want the executing goal re-queued", requeued, err)
+	}
+
+	// Its proposal moving on meanwhile does not take the goal out of the queue
+	if err := actionRepo.UpdateActionStatus(ctx, first.ID, domain.ActionProposalStatusCompleted); err != nil {
+		t.Fatalf("UpdateActionStatus() error = %v", err)
+	}
+	if stored, err := goalRepo.GetGoalByID(ctx, goal.ID); err != nil || stored.Status != domain.GoalStatusActive {
+		t.Fatalf("abandoned goal = %+v, %v; want ACTIVE until another planner claims it", stored, err)
+	}
+
+	resumed, err := goalRepo.ClaimGoal(ctx, "successor", time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimGoal() error = %v", err)
+	}
+	if resumed == nil || resumed.ID != goal.ID || resumed.LeaseOwner != "successor" {
+		t.Fatalf("ClaimGoal() after the holder died = %+v, want the goal leased to successor", resumed)
+	}
+	second := domain.NewActionProposal("pack boxes", 40, json.RawMessage(`{}`), "PLANNER")
+	if err := actionRepo.SaveActionProposal(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = goalRepo.AttachAction(ctx, goal.ID, 1, second)
+	if err != nil {
+		t.Fatalf("AttachAction() by the successor error = %v", err)
+	}
+	if plan.Total != 2 || plan.Completed != 1 || plan.Goal.Status != domain.GoalStatusExecuting {
+		t.Errorf("resumed plan = %s with %d/%d steps done, want EXECUTING with 1/2", plan.Goal.Status, plan.Completed, plan.Total)
+	}
+}
+
+func TestGoalScheduling(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	goalRepo, err := NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func(goal *domain.Goal) {
+		t.Helper()
+		if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+			t.Fatalf("SaveGoal() error = %v", err)
+		}
+	}
+
+	// Higher priority first, then the nearer deadline, then the older goal
+	now := time.Now()
+	soon := now.Add(time.Hour)
+	routine := domain.NewGoal("sort email")
+	save(routine)
+	due := domain.NewGoal("send invoice")
+	due.Deadline = &soon
+	save(due)
+	urgent := domain.NewGoal("renew passport")
+	urgent.Priority = 5
+	save(urgent)
+	for _, want := range []*domain.Goal{urgent, due, routine} {
+		claimed, err := goalRepo.ClaimGoal(ctx, "planner", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claimed == nil || claimed.ID != want.ID {
+			t.Fatalf("ClaimGoal() = %v, want %q", claimed, want.GoalText)
+		}
+	}
+
+	// A recurring goal queues an occurrence and moves on to its next firing
+	daily := domain.NewGoal("back up photos")
+	daily.Recurrence = "0 3 * * *"
+	daily.Priority = 2
+	first, err := domain.NextRun(daily.Recurrence, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily.RunAt = &first
+	daily.Status = domain.GoalStatusScheduled
+	save(daily)
+
+	if fired, err := goalRepo.FireDueGoals(ctx, first.Add(-time.Minute)); err != nil || len(fired) != 0 {
+		t.Fatalf("FireDueGoals() before the run time = %d goals, %v; want none", len(fired), err)
+	}
+	fired, err := goalRepo.FireDueGoals(ctx, first.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("FireDueGoals() error = %v", err)
+	}
+	if len(fired) != 1 || fired[0].ParentID != daily.ID || fired[0].Priority != 2 || fired[0].Status != domain.GoalStatusActive {
+		t.Fatalf("fired = %+v, want one ACTIVE occurrence of the daily goal", fired)
+	}
+	template, err := goalRepo.GetGoalByID(ctx, daily.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.Status != domain.GoalStatusScheduled || !template.RunAt.After(first) {
+		t.Errorf("recurring goal = %s at %v, want SCHEDULED after %v", template.Status, template.RunAt, first)
+	}
+
+	// Paused goals are neither fired nor claimed; resuming a recurring goal skips missed firings
+	if _, err := goalRepo.PauseGoal(ctx, daily.ID); err != nil {
+		t.Fatalf("PauseGoal() error = %v", err)
+	}
+	if fired, _ := goalRepo.FireDueGoals(ctx, template.RunAt.Add(48*time.Hour)); len(fired) != 0 {
+		t.Errorf("FireDueGoals() fired %d paused goals", len(fired))
+	}
+	if _, err := goalRepo.PauseGoal(ctx, daily.ID); !errors.Is(err, ErrGoalState) {
+		t.Errorf("PauseGoal() twice error = %v, want ErrGoalState", err)
+	}
+	resumed, err := goalRepo.ResumeGoal(ctx, daily.ID)
+	if err != nil {
+		t.Fatalf("ResumeGoal() error = %v", err)
+	}
+	if resumed.Status != domain.GoalStatusScheduled || resumed.RunAt == nil {
+		t.Errorf("resumed recurring goal = %s at %v, want SCHEDULED", resumed.Status, resumed.RunAt)
+	}
+
+	// Cancelling is final and stops the planner working on the goal
+	if _, err := goalRepo.SavePlan(ctx, fired[0].ID, "", []string{"copy photos"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := goalRepo.CancelGoal(ctx, fired[0].ID); err != nil {
+		t.Fatalf("CancelGoal() error = %v", err)
+	}
+	if ok, _ := goalRepo.StepAvailable(ctx, fired[0].ID, 0); ok {
+		t.Error("StepAvailable() = true on a cancelled goal")
+	}
+	if _, err := goalRepo.ResumeGoal(ctx, fired[0].ID); !errors.Is(err, ErrGoalState) {
+		t.Errorf("ResumeGoal() on a cancelled goal error = %v, want ErrGoalState", err)
+	}
+	if _, err := goalRepo.CancelGoal(ctx, fired[0].ID); !errors.Is(err, ErrGoalState) {
+		t.Errorf("CancelGoal() twice error = %v, want ErrGoalState", err)
+	}
+
+	// A queued goal that misses its deadline fails
+	late := domain.NewGoal("reply to landlord")
+	past := now.Add(-time.Minute)
+	late.Deadline = &past
+	save(late)
+	if n, err := goalRepo.FailOverdueGoals(ctx, now); err != nil || n != 1 {
+		t.Errorf("FailOverdueGoals() = %d, %v; want 1", n, err)
+	}
+}
+
+func TestGoalTimesAcrossZones(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	goalRepo, err := NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Written in zones far from the caller's, past times read later as text and future times earlier
+	now := time.Now().UTC()
+	ahead := time.FixedZone("LINT", 14*60*60)
+	behind := time.FixedZone("AOE", -12*60*60)
+	schedule := func(text string, runAt time.Time) *domain.Goal {
+		t.Helper()
+		goal := domain.NewGoal(text)
+		goal.RunAt = &runAt
+		goal.Status = domain.GoalStatusScheduled
+		if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+			t.Fatalf("SaveGoal() error = %v", err)
+		}
+		return goal
+	}
+	due := schedule("water plants", now.Add(-time.Hour).In(ahead))
+	schedule("call dentist", now.Add(time.Hour).In(behind))
+	fired, err := goalRepo.FireDueGoals(ctx, now)
+	if err != nil {
+		t.Fatalf("FireDueGoals() error = %v", err)
+	}
+	if len(fired) != 1 || fired[0].ID != due.ID {
+		t.Fatalf("FireDueGoals() = %+v, want only %q", fired, due.GoalText)
+	}
+
+	late := domain.NewGoal("file taxes")
+	past := now.Add(-time.Hour).In(ahead)
+	late.Deadline = &past
+	pending := domain.NewGoal("book flights")
+	future := now.Add(time.Hour).In(behind)
+	pending.Deadline = &future
+	for _, goal := range []*domain.Goal{late, pending} {
+		if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+			t.Fatalf("SaveGoal() error = %v", err)
+		}
+	}
+	if n, err := goalRepo.FailOverdueGoals(ctx, now); err != nil || n != 1 {
+		t.Fatalf("FailOverdueGoals() = %d, %v; want 1", n, err)
+	}
+	for goal, want := range map[*domain.Goal]domain.GoalStatus{late: domain.GoalStatusFailed, pending: domain.GoalStatusActive} {
+		got, err := goalRepo.GetGoalByID(ctx, goal.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != want {
+			t.Errorf("%q status = %s, want %s", goal.GoalText, got.Status, want)
+		}
+	}
+}
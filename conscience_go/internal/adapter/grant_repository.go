@@ -0,0 +1,126 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+// Grant errors
+var (
+	ErrGrantNotFound = errors.New("grant not found")
+	ErrGrantRevoked  = errors.New("grant is already revoked")
+)
+
+// grantColumns is the column list scanGrants reads
+const grantColumns = `id, action_type, process, path_prefix, expires_at, created_by, created_at, revoked_at`
+
+// GrantRepository manages standing approvals
+type GrantRepository struct {
+	db DB
+}
+
+// NewGrantRepository creates a new GrantRepository and initializes tables
+func NewGrantRepository(db DB) (*GrantRepository, error) {
+	repo := &GrantRepository{db: db}
+
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// SaveGrant persists a new grant
+func (r *GrantRepository) SaveGrant(ctx context.Context, grant *domain.Grant) error {
+	insertSQL := `
+	INSERT INTO grants (` + grantColumns + `)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, insertSQL, grant.ID, grant.ActionType, nullString(grant.Process), nullString(grant.PathPrefix),
+		grant.ExpiresAt, nullString(grant.CreatedBy), grant.CreatedAt, grant.RevokedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert grant: %w", err)
+	}
+	return nil
+}
+
+// RevokeGrant ends a grant now and returns it
+func (r *GrantRepository) RevokeGrant(ctx context.Context, id string) (*domain.Grant, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE grants SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke grant: %w", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	grants, err := r.query(ctx, "WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(grants) == 0 {
+		return nil, ErrGrantNotFound
+	}
+	if revoked == 0 {
+		return nil, ErrGrantRevoked
+	}
+	return &grants[0], nil
+}
+
+// ListGrants returns grants newest first; unless all is set, only those in force at now
+func (r *GrantRepository) ListGrants(ctx context.Context, all bool, now time.Time) ([]domain.Grant, error) {
+	if all {
+		return r.query(ctx, "")
+	}
+
+	// Expiry is compared in Go so stored and current times need not share a location
+	grants, err := r.query(ctx, "WHERE revoked_at IS NULL")
+	if err != nil {
+		return nil, err
+	}
+	active := grants[:0]
+	for _, grant := range grants {
+		if grant.Active(now) {
+			active = append(active, grant)
+		}
+	}
+	return active, nil
+}
+
+// query reads the grants matching where, newest first
+func (r *GrantRepository) query(ctx context.Context, where string, args ...interface{}) ([]domain.Grant, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+grantColumns+" FROM grants "+where+" ORDER BY created_at DESC", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query grants: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []domain.Grant{}
+	for rows.Next() {
+		var grant domain.Grant
+		var process, pathPrefix, createdBy sql.NullString
+		var expiresAt, revokedAt sql.NullTime
+		if err := rows.Scan(&grant.ID, &grant.ActionType, &process, &pathPrefix, &expiresAt, &createdBy, &grant.CreatedAt, &revokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan grant: %w", err)
+		}
+		grant.Process, grant.PathPrefix, grant.CreatedBy = process.String, pathPrefix.String, createdBy.String
+		if expiresAt.Valid {
+			grant.ExpiresAt = &expiresAt.Time
+		}
+		if revokedAt.Valid {
+			grant.RevokedAt = &revokedAt.Time
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate grants: %w", err)
+	}
+	return grants, nil
+}
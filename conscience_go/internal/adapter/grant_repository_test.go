@@ -0,0 +1,74 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+func TestGrantsExpireAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	grantRepo, err := NewGrantRepository(store)
+	if err != nil {
+		t.Fatalf("NewGrantRepository() error = %v", err)
+	}
+
+	inHalfAnHour := time.Now().Add(30 * time.Minute)
+	typing, err := domain.NewGrant("type", "code.exe", "", &inHalfAnHour, "grpc:human")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reading, err := domain.NewGrant("READ", "", "docs/", nil, "grpc:human")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, grant := range []*domain.Grant{typing, reading} {
+		if err := grantRepo.SaveGrant(ctx, grant); err != nil {
+			t.Fatalf("SaveGrant() error = %v", err)
+		}
+	}
+
+	active, err := grantRepo.ListGrants(ctx, false, time.Now())
+	if err != nil || len(active) != 2 {
+		t.Fatalf("ListGrants() = %v, %v; want both grants", active, err)
+	}
+	if active[1].ActionType != "TYPE" || active[1].ExpiresAt == nil || active[0].PathPrefix != "docs" || active[0].ExpiresAt != nil {
+		t.Errorf("ListGrants() = %+v, want the scopes as created", active)
+	}
+
+	// An hour later the timed grant has lapsed
+	later, err := grantRepo.ListGrants(ctx, false, time.Now().Add(time.Hour))
+	if err != nil || len(later) != 1 || later[0].ID != reading.ID {
+		t.Fatalf("ListGrants(an hour later) = %v, %v; want only the grant until revoked", later, err)
+	}
+
+	revoked, err := grantRepo.RevokeGrant(ctx, reading.ID)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("RevokeGrant() = %v, %v; want it revoked", revoked, err)
+	}
+	if _, err := grantRepo.RevokeGrant(ctx, reading.ID); !errors.Is(err, ErrGrantRevoked) {
+		t.Errorf("revoking twice: error = %v, want ErrGrantRevoked", err)
+	}
+	if _, err := grantRepo.RevokeGrant(ctx, "missing"); !errors.Is(err, ErrGrantNotFound) {
+		t.Errorf("revoking a missing grant: error = %v, want ErrGrantNotFound", err)
+	}
+
+	active, err = grantRepo.ListGrants(ctx, false, time.Now())
+	if err != nil || len(active) != 1 || active[0].ID != typing.ID {
+		t.Fatalf("ListGrants() after revoking = %v, %v; want the timed grant", active, err)
+	}
+	all, err := grantRepo.ListGrants(ctx, true, time.Now())
+	if err != nil || len(all) != 2 {
+		t.Fatalf("ListGrants(all) = %v, %v; want the revoked grant too", all, err)
+	}
+}
@@ -13,6 +13,7 @@ type IntentHistoryEntry struct {
 	ID            string    `json:"id"`
 	Intent        string    `json:"intent"`
 	FocusedWindow string    `json:"focused_window"`
+	Application   string    `json:"application,omitempty"`
 	ExecutedAt    time.Time `json:"executed_at"`
 	SuccessCount  int       `json:"success_count"`
 	CachedPlan    string    `json:"cached_plan,omitempty"`
@@ -20,44 +21,43 @@ type IntentHistoryEntry struct {
 
 // IntentHistoryRepository manages intent history for trust scoring
 type IntentHistoryRepository struct {
-	db *sql.DB
+	db     DB
+	cipher *FieldCipher
 }
 
 // NewIntentHistoryRepository creates a new IntentHistoryRepository and initializes tables
-func NewIntentHistoryRepository(db *sql.DB) (*IntentHistoryRepository, error) {
+func NewIntentHistoryRepository(db DB) (*IntentHistoryRepository, error) {
 	repo := &IntentHistoryRepository{db: db}
 
-	// Create intent_history table
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS intent_history (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		intent TEXT NOT NULL,
-		focused_window TEXT NOT NULL,
-		executed_at DATETIME NOT NULL,
-		success_count INTEGER DEFAULT 1,
-		cached_plan TEXT
-	);
-	`
-
-	if _, err := db.Exec(createTableSQL); err != nil {
-		return nil, fmt.Errorf("failed to create intent_history table: %w", err)
+	if err := ensureSchema(db); err != nil {
+		return nil, err
 	}
 
-	// Migrate existing tables to add cached_plan column if it doesn't exist
-	migrateSQL := "ALTER TABLE intent_history ADD COLUMN cached_plan TEXT;"
-	// Ignore error if column already exists
-	_, _ = db.Exec(migrateSQL)
+	return repo, nil
+}
 
-	// Create index for fast lookups by intent and window
-	createIndexSQL := `
-	CREATE INDEX IF NOT EXISTS idx_intent_window ON intent_history(intent, focused_window);
-	`
+// SetCipher enables at-rest encryption of cached plans
+func (r *IntentHistoryRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
 
-	if _, err := db.Exec(createIndexSQL); err != nil {
-		return nil, fmt.Errorf("failed to create intent_history index: %w", err)
+// storeCachedPlan seals a plan to the row just inserted and stores it; an empty plan stores nothing
+func storeCachedPlan(ctx context.Context, tx *sql.Tx, c *FieldCipher, inserted sql.Result, planJSON string) error {
+	if planJSON == "" {
+		return nil
 	}
-
-	return repo, nil
+	id, err := inserted.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get intent history id: %w", err)
+	}
+	plan, err := c.Seal("intent_history", "cached_plan", fmt.Sprintf("%d", id), planJSON)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE intent_history SET cached_plan = ? WHERE id = ?", plan, id); err != nil {
+		return fmt.Errorf("failed to store cached plan: %w", err)
+	}
+	return nil
 }
 
 // RecordSuccess records a successful intent execution with optional plan caching
@@ -66,6 +66,19 @@ func (r *IntentHistoryRepository) RecordSuccess(ctx context.Context, intent stri
 	if len(cachedPlan) > 0 {
 		planJSON = cachedPlan[0]
 	}
+	return r.RecordExecution(ctx, intent, focusedWindow, "", planJSON)
+}
+
+// RecordExecution records a successful intent execution along with the application
+// that owned the focused window, so the entry can later be found by a forget request
+func (r *IntentHistoryRepository) RecordExecution(ctx context.Context, intent string, focusedWindow string, application string, planJSON string) error {
+	// Read-modify-write in one transaction so concurrent executions don't lose increments
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin intent history transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Check if this intent/window combination already exists
 	var existingID int
 	var successCount int
@@ -76,37 +89,103 @@ func (r *IntentHistoryRepository) RecordSuccess(ctx context.Context, intent stri
 	LIMIT 1
 	`
 
-	err := r.db.QueryRowContext(ctx, querySQL, intent, focusedWindow).Scan(&existingID, &successCount)
+	err = tx.QueryRowContext(ctx, querySQL, intent, focusedWindow).Scan(&existingID, &successCount)
 
 	if err == sql.ErrNoRows {
 		// First time this intent/window combo was used - insert new record
 		insertSQL := `
-		INSERT INTO intent_history (intent, focused_window, executed_at, success_count, cached_plan)
+		INSERT INTO intent_history (intent, focused_window, executed_at, success_count, application)
 		VALUES (?, ?, ?, 1, ?)
 		`
 
-		_, err := r.db.ExecContext(ctx, insertSQL, intent, focusedWindow, time.Now(), planJSON)
+		result, err := tx.ExecContext(ctx, insertSQL, intent, focusedWindow, time.Now(), application)
 		if err != nil {
 			return fmt.Errorf("failed to insert intent history: %w", err)
 		}
-		return nil
+		if err := storeCachedPlan(ctx, tx, r.cipher, result, planJSON); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to query existing intent history: %w", err)
+	} else {
+		// Already exists - increment success count and update timestamp
+		// Also update cached_plan if provided; an execution without a plan keeps the one learned so far
+		updateSQL := `
+		UPDATE intent_history
+		SET success_count = ?, executed_at = ?, cached_plan = COALESCE(NULLIF(?, ''), cached_plan), application = COALESCE(NULLIF(?, ''), application)
+		WHERE id = ?
+		`
+
+		plan, err := r.cipher.Seal("intent_history", "cached_plan", fmt.Sprintf("%d", existingID), planJSON)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, updateSQL, successCount+1, time.Now(), plan, application, existingID); err != nil {
+			return fmt.Errorf("failed to update intent history: %w", err)
+		}
 	}
 
-	if err != nil {
-		return fmt.Errorf("failed to query existing intent history: %w", err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit intent history: %w", err)
 	}
 
-	// Already exists - increment success count and update timestamp
-	// Also update cached_plan if provided
-	updateSQL := `
-	UPDATE intent_history
-	SET success_count = ?, executed_at = ?, cached_plan = ?
-	WHERE id = ?
-	`
+	return nil
+}
 
-	_, err = r.db.ExecContext(ctx, updateSQL, successCount+1, time.Now(), planJSON, existingID)
+// RecordCorrection replaces the cached plan for an intent with one a person corrected
+// Trust earned so far is kept, since the person approved the corrected plan; an intent never
+// executed before starts with no trust, so the plan is not replayed until it has earned some.
+func (r *IntentHistoryRepository) RecordCorrection(ctx context.Context, intent string, application string, planJSON string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to update intent history: %w", err)
+		return fmt.Errorf("failed to begin intent history transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Each row's plan is sealed to that row, so they are rewritten one by one
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM intent_history WHERE intent = ?", intent)
+	if err != nil {
+		return fmt.Errorf("failed to query intent history: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan intent history id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating intent history rows: %w", err)
+	}
+
+	for _, id := range ids {
+		plan, err := r.cipher.Seal("intent_history", "cached_plan", fmt.Sprintf("%d", id), planJSON)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE intent_history SET cached_plan = ? WHERE id = ?", plan, id); err != nil {
+			return fmt.Errorf("failed to update cached plan: %w", err)
+		}
+	}
+	if len(ids) == 0 {
+		insertSQL := `
+		INSERT INTO intent_history (intent, focused_window, executed_at, success_count, application)
+		VALUES (?, '', ?, 0, ?)
+		`
+		result, err := tx.ExecContext(ctx, insertSQL, intent, time.Now(), application)
+		if err != nil {
+			return fmt.Errorf("failed to insert intent history: %w", err)
+		}
+		if err := storeCachedPlan(ctx, tx, r.cipher, result, planJSON); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit intent history: %w", err)
 	}
 
 	return nil
@@ -140,17 +219,18 @@ func (r *IntentHistoryRepository) GetTrustScore(ctx context.Context, intent stri
 // Returns the cached plan JSON and trust score, or empty string if not found or trust too low
 func (r *IntentHistoryRepository) GetReflex(ctx context.Context, intent string) (string, int, error) {
 	querySQL := `
-	SELECT cached_plan, success_count
+	SELECT id, cached_plan, success_count
 	FROM intent_history
 	WHERE intent = ? AND success_count > 5 AND cached_plan IS NOT NULL AND cached_plan != ''
 	ORDER BY executed_at DESC
 	LIMIT 1
 	`
 
+	var id int64
 	var cachedPlan sql.NullString
 	var successCount int
 
-	err := r.db.QueryRowContext(ctx, querySQL, intent).Scan(&cachedPlan, &successCount)
+	err := r.db.QueryRowContext(ctx, querySQL, intent).Scan(&id, &cachedPlan, &successCount)
 
 	if err == sql.ErrNoRows {
 		// No reflex found - return empty
@@ -165,7 +245,11 @@ func (r *IntentHistoryRepository) GetReflex(ctx context.Context, intent string)
 		return "", successCount, nil
 	}
 
-	return cachedPlan.String, successCount, nil
+	plan, err := r.cipher.Open("intent_history", "cached_plan", fmt.Sprintf("%d", id), cachedPlan.String)
+	if err != nil {
+		return "", 0, err
+	}
+	return plan, successCount, nil
 }
 
 // InvalidateReflex removes the cached plan for a specific intent
@@ -0,0 +1,161 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+// MemoryRepository manages long-term memories stored by the Brain
+type MemoryRepository struct {
+	db     DB
+	cipher *FieldCipher
+}
+
+// NewMemoryRepository creates a new MemoryRepository and initializes tables
+func NewMemoryRepository(db DB) (*MemoryRepository, error) {
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+
+	return &MemoryRepository{db: db}, nil
+}
+
+// SetCipher enables at-rest encryption of memory values
+func (r *MemoryRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
+
+// SaveMemory stores a memory, replacing any existing memory with the same key
+func (r *MemoryRepository) SaveMemory(ctx context.Context, memory *domain.Memory) error {
+	var embeddingJSON string
+	if len(memory.Embedding) > 0 {
+		data, err := json.Marshal(memory.Embedding)
+		if err != nil {
+			return fmt.Errorf("failed to marshal embedding: %w", err)
+		}
+		embeddingJSON = string(data)
+	}
+
+	// The row ID is kept on replace so the sealed value stays bound to it
+	var id string
+	err := r.db.QueryRowContext(ctx, "SELECT id FROM memories WHERE key = ?", memory.Key).Scan(&id)
+	if err == nil {
+		memory.ID = id
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to query existing memory: %w", err)
+	}
+
+	value, err := r.cipher.Seal("memories", "value", memory.ID, memory.Value)
+	if err != nil {
+		return err
+	}
+
+	upsertSQL := `
+	INSERT INTO memories (id, key, value, context, embedding, created_at, expires_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(key) DO UPDATE SET value = excluded.value, context = excluded.context,
+		embedding = excluded.embedding, created_at = excluded.created_at, expires_at = excluded.expires_at
+	`
+
+	_, err = r.db.ExecContext(ctx, upsertSQL, memory.ID, memory.Key, value, memory.Context, embeddingJSON, memory.CreatedAt, memory.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save memory: %w", err)
+	}
+
+	return nil
+}
+
+// SearchMemories returns the unexpired memories most similar to the query embedding
+func (r *MemoryRepository) SearchMemories(ctx context.Context, queryEmbedding []float32, limit int) ([]domain.Memory, []float32, error) {
+	memories, err := r.GetAllMemories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	similarities := make([]float32, len(memories))
+	for i := range memories {
+		similarities[i] = cosineSimilarity(queryEmbedding, memories[i].Embedding)
+	}
+
+	order := make([]int, len(memories))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return similarities[order[a]] > similarities[order[b]]
+	})
+
+	if limit > len(order) {
+		limit = len(order)
+	}
+
+	results := make([]domain.Memory, 0, limit)
+	scores := make([]float32, 0, limit)
+	for _, idx := range order[:limit] {
+		results = append(results, memories[idx])
+		scores = append(scores, similarities[idx])
+	}
+
+	return results, scores, nil
+}
+
+// GetAllMemories returns every unexpired memory, newest first
+func (r *MemoryRepository) GetAllMemories(ctx context.Context) ([]domain.Memory, error) {
+	query := `
+	SELECT id, key, value, context, embedding, created_at, expires_at
+	FROM memories
+	ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var memories []domain.Memory
+	for rows.Next() {
+		var memory domain.Memory
+		var memoryContext sql.NullString
+		var embeddingJSON sql.NullString
+		var expiresAt sql.NullTime
+
+		if err := rows.Scan(&memory.ID, &memory.Key, &memory.Value, &memoryContext, &embeddingJSON, &memory.CreatedAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan memory: %w", err)
+		}
+
+		if expiresAt.Valid {
+			if expiresAt.Time.Before(now) {
+				continue
+			}
+			memory.ExpiresAt = &expiresAt.Time
+		}
+
+		if memory.Value, err = r.cipher.Open("memories", "value", memory.ID, memory.Value); err != nil {
+			return nil, err
+		}
+		memory.Context = memoryContext.String
+
+		if embeddingJSON.Valid && embeddingJSON.String != "" {
+			if err := json.Unmarshal([]byte(embeddingJSON.String), &memory.Embedding); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal embedding: %w", err)
+			}
+		}
+
+		memories = append(memories, memory)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating memory rows: %w", err)
+	}
+
+	return memories, nil
+}
@@ -0,0 +1,247 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"ghost/kernel/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Clarification thread errors
+var (
+	ErrConversationClosed = errors.New("action proposal no longer takes messages")
+	ErrInvalidAnswer      = errors.New("message does not answer the open question")
+)
+
+// MessageRepository keeps the clarification threads of action proposals
+type MessageRepository struct {
+	db      DB
+	actions *ActionRepository
+}
+
+// NewMessageRepository creates a new MessageRepository and initializes tables
+// Status changes go through actions so its listeners see them.
+func NewMessageRepository(db DB, actions *ActionRepository) (*MessageRepository, error) {
+	repo := &MessageRepository{db: db, actions: actions}
+
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// AppendMessage adds msg to its proposal's thread and moves the proposal to the next status
+// A user message must answer the question the agent is waiting on, if any. The latest agent
+// and user texts are mirrored into agent_message and user_response for older clients.
+func (r *MessageRepository) AppendMessage(ctx context.Context, msg *domain.ProposalMessage) (domain.ActionProposalStatus, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin message transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	action := &domain.ActionProposal{ID: msg.ActionID}
+	var current string
+	var agentMessage, userResponse sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT status, agent_message, user_response, updated_at FROM action_proposals WHERE id = ?", msg.ActionID).
+		Scan(&current, &agentMessage, &userResponse, &action.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return "", ErrActionNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read action proposal: %w", err)
+	}
+
+	status := domain.ActionProposalStatus(current)
+	next, open := msg.NextStatus(status)
+	if !open {
+		return "", fmt.Errorf("%w: it is %s", ErrConversationClosed, status)
+	}
+	if msg.Role == domain.MessageRoleUser && status == domain.ActionProposalStatusWaitingForContext {
+		question, err := openQuestion(ctx, tx, msg.ActionID)
+		if err != nil {
+			return "", err
+		}
+		if question != nil {
+			if err := question.CheckAnswer(msg.Text); err != nil {
+				return "", fmt.Errorf("%w: %v", ErrInvalidAnswer, err)
+			}
+		}
+	}
+
+	var last int
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(seq), 0) FROM proposal_messages WHERE action_id = ?", msg.ActionID).Scan(&last); err != nil {
+		return "", fmt.Errorf("failed to number message: %w", err)
+	}
+	// A proposal from before threads starts its thread with the exchange it already had
+	if last == 0 {
+		action.AgentMessage = agentMessage.String
+		if action.UserResponse, err = r.actions.cipher.Open("action_proposals", "user_response", action.ID, userResponse.String); err != nil {
+			return "", err
+		}
+		for _, earlier := range legacyThread(action) {
+			earlier.ID = uuid.New().String()
+			if err := r.insertMessage(ctx, tx, &earlier); err != nil {
+				return "", err
+			}
+			last = earlier.Seq
+		}
+	}
+	msg.Seq = last + 1
+	if err := r.insertMessage(ctx, tx, msg); err != nil {
+		return "", err
+	}
+
+	if msg.Role == domain.MessageRoleAgent {
+		_, err = tx.ExecContext(ctx, "UPDATE action_proposals SET status = ?, agent_message = ?, updated_at = ? WHERE id = ?",
+			string(next), msg.Text, msg.CreatedAt, msg.ActionID)
+	} else {
+		var response string
+		if response, err = r.actions.cipher.Seal("action_proposals", "user_response", msg.ActionID, msg.Text); err != nil {
+			return "", err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE action_proposals SET status = ?, user_response = ?, updated_at = ? WHERE id = ?",
+			string(next), response, msg.CreatedAt, msg.ActionID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update action proposal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit message: %w", err)
+	}
+
+	if next != status {
+		r.actions.notifyStatus(ctx, msg.ActionID, next)
+	}
+	return next, nil
+}
+
+// insertMessage writes one thread message within tx
+func (r *MessageRepository) insertMessage(ctx context.Context, tx *sql.Tx, msg *domain.ProposalMessage) error {
+	var question sql.NullString
+	if msg.Question != nil {
+		questionJSON, err := json.Marshal(msg.Question)
+		if err != nil {
+			return fmt.Errorf("failed to marshal question: %w", err)
+		}
+		question = nullString(string(questionJSON))
+	}
+	text, err := r.actions.cipher.Seal("proposal_messages", "text", msg.ID, msg.Text)
+	if err != nil {
+		return err
+	}
+
+	insertSQL := `
+	INSERT INTO proposal_messages (id, action_id, seq, role, text, question, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, insertSQL, msg.ID, msg.ActionID, msg.Seq, string(msg.Role), text, question, msg.CreatedAt); err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+	return nil
+}
+
+// openQuestion returns the question of the latest agent message, if it asked one
+func openQuestion(ctx context.Context, tx *sql.Tx, actionID string) (*domain.Question, error) {
+	query := `
+	SELECT question FROM proposal_messages
+	WHERE action_id = ? AND role = ?
+	ORDER BY seq DESC LIMIT 1
+	`
+	var questionJSON sql.NullString
+	err := tx.QueryRowContext(ctx, query, actionID, string(domain.MessageRoleAgent)).Scan(&questionJSON)
+	if err == sql.ErrNoRows || !questionJSON.Valid {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read open question: %w", err)
+	}
+
+	var question domain.Question
+	if err := json.Unmarshal([]byte(questionJSON.String), &question); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal question: %w", err)
+	}
+	return &question, nil
+}
+
+// ListMessages returns the thread of a proposal in order
+// A proposal created before threads existed lists its agent_message and user_response instead.
+func (r *MessageRepository) ListMessages(ctx context.Context, actionID string) ([]domain.ProposalMessage, error) {
+	action, err := r.actions.GetActionByID(ctx, actionID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT id, seq, role, text, question, created_at
+	FROM proposal_messages
+	WHERE action_id = ?
+	ORDER BY seq
+	`
+	rows, err := r.db.QueryContext(ctx, query, actionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []domain.ProposalMessage{}
+	for rows.Next() {
+		msg := domain.ProposalMessage{ActionID: actionID}
+		var role string
+		var questionJSON sql.NullString
+		if err := rows.Scan(&msg.ID, &msg.Seq, &role, &msg.Text, &questionJSON, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		msg.Role = domain.MessageRole(role)
+		if msg.Text, err = r.actions.cipher.Open("proposal_messages", "text", msg.ID, msg.Text); err != nil {
+			return nil, err
+		}
+		if questionJSON.Valid {
+			msg.Question = &domain.Question{}
+			if err := json.Unmarshal([]byte(questionJSON.String), msg.Question); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal question: %w", err)
+			}
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate messages: %w", err)
+	}
+
+	if len(messages) == 0 {
+		messages = legacyThread(action)
+	}
+	return messages, nil
+}
+
+// legacyThread presents the single-field conversation of an older proposal as a thread
+func legacyThread(action *domain.ActionProposal) []domain.ProposalMessage {
+	messages := []domain.ProposalMessage{}
+	for _, turn := range []struct {
+		role domain.MessageRole
+		text string
+	}{
+		{domain.MessageRoleAgent, action.AgentMessage},
+		{domain.MessageRoleUser, action.UserResponse},
+	} {
+		if turn.text == "" {
+			continue
+		}
+		messages = append(messages, domain.ProposalMessage{
+			ActionID:  action.ID,
+			Seq:       len(messages) + 1,
+			Role:      turn.role,
+			Text:      turn.text,
+			CreatedAt: action.UpdatedAt,
+		})
+	}
+	return messages
+}
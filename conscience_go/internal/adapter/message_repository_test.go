@@ -0,0 +1,144 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"ghost/kernel/internal/domain"
+)
+
+// newMessageRepos opens a fresh store with threads recording every status change they cause
+func newMessageRepos(t *testing.T) (*MessageRepository, *ActionRepository, *[]domain.ActionProposalStatus) {
+	t.Helper()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	actionRepo, err := NewActionRepository(store)
+	if err != nil {
+		t.Fatalf("NewActionRepository() error = %v", err)
+	}
+	messageRepo, err := NewMessageRepository(store, actionRepo)
+	if err != nil {
+		t.Fatalf("NewMessageRepository() error = %v", err)
+	}
+	var changes []domain.ActionProposalStatus
+	actionRepo.OnStatusChange(func(_ context.Context, _ string, status domain.ActionProposalStatus) {
+		changes = append(changes, status)
+	})
+	return messageRepo, actionRepo, &changes
+}
+
+func TestThreadKeepsEveryTurn(t *testing.T) {
+	ctx := context.Background()
+	messageRepo, actionRepo, changes := newMessageRepos(t)
+
+	action := domain.NewActionProposal("send report", 60, json.RawMessage(`{}`), "MAIL")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(role domain.MessageRole, text string, question *domain.Question) (domain.ActionProposalStatus, error) {
+		t.Helper()
+		msg, err := domain.NewProposalMessage(action.ID, role, text, question)
+		if err != nil {
+			t.Fatalf("NewProposalMessage(%q) error = %v", text, err)
+		}
+		return messageRepo.AppendMessage(ctx, msg)
+	}
+	choice := &domain.Question{Type: domain.QuestionTypeSingleChoice, Options: []string{"team", "manager"}}
+
+	turns := []struct {
+		role     domain.MessageRole
+		text     string
+		question *domain.Question
+		want     domain.ActionProposalStatus
+	}{
+		{domain.MessageRoleAgent, "Who should receive it?", choice, domain.ActionProposalStatusWaitingForContext},
+		{domain.MessageRoleUser, "manager", nil, domain.ActionProposalStatusPending},
+		{domain.MessageRoleAgent, "Attach the draft?", &domain.Question{Type: domain.QuestionTypeConfirm}, domain.ActionProposalStatusWaitingForContext},
+		{domain.MessageRoleUser, "Yes", nil, domain.ActionProposalStatusPending},
+		{domain.MessageRoleAgent, "Ready to send to your manager with the draft", nil, domain.ActionProposalStatusWaitingForUser},
+	}
+	for i, turn := range turns {
+		if turn.role == domain.MessageRoleUser && i == 1 {
+			// An answer outside the options is refused and leaves the question open
+			if _, err := post(turn.role, "everyone", nil); !errors.Is(err, ErrInvalidAnswer) {
+				t.Fatalf("answering with a missing option: error = %v, want ErrInvalidAnswer", err)
+			}
+		}
+		status, err := post(turn.role, turn.text, turn.question)
+		if err != nil || status != turn.want {
+			t.Fatalf("turn %d (%q) = %s, %v; want %s", i, turn.text, status, err, turn.want)
+		}
+	}
+
+	messages, err := messageRepo.ListMessages(ctx, action.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != len(turns) {
+		t.Fatalf("thread has %d messages, want %d", len(messages), len(turns))
+	}
+	for i, msg := range messages {
+		if msg.Seq != i+1 || msg.Text != turns[i].text || msg.Role != turns[i].role || (msg.Question != nil) != (turns[i].question != nil) {
+			t.Errorf("message %d = %+v, want %q from %s", i, msg, turns[i].text, turns[i].role)
+		}
+	}
+	if len(*changes) != len(turns) {
+		t.Errorf("status listeners saw %v, want one change per turn", *changes)
+	}
+
+	// Older clients still see the latest exchange
+	stored, err := actionRepo.GetActionByID(ctx, action.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.AgentMessage != turns[4].text || stored.UserResponse != "Yes" {
+		t.Errorf("agent_message, user_response = %q, %q; want the latest turns", stored.AgentMessage, stored.UserResponse)
+	}
+
+	// Once decided the thread is closed
+	if err := actionRepo.UpdateActionStatus(ctx, action.ID, domain.ActionProposalStatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := post(domain.MessageRoleUser, "wait", nil); !errors.Is(err, ErrConversationClosed) {
+		t.Errorf("message after approval: error = %v, want ErrConversationClosed", err)
+	}
+}
+
+func TestThreadStartsWithLegacyExchange(t *testing.T) {
+	ctx := context.Background()
+	messageRepo, actionRepo, _ := newMessageRepos(t)
+
+	action := domain.NewClarificationRequest("book a table", "Which restaurant?", json.RawMessage(`{}`), "BROWSER")
+	if err := actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+	messages, err := messageRepo.ListMessages(ctx, action.ID)
+	if err != nil || len(messages) != 1 || messages[0].Role != domain.MessageRoleAgent {
+		t.Fatalf("ListMessages() = %v, %v; want the agent_message", messages, err)
+	}
+
+	msg, err := domain.NewProposalMessage(action.ID, domain.MessageRoleUser, "The usual one", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, err := messageRepo.AppendMessage(ctx, msg); err != nil || status != domain.ActionProposalStatusPending {
+		t.Fatalf("AppendMessage() = %s, %v; want PENDING", status, err)
+	}
+	messages, err = messageRepo.ListMessages(ctx, action.ID)
+	if err != nil || len(messages) != 2 || messages[0].Text != "Which restaurant?" || messages[1].Seq != 2 {
+		t.Fatalf("ListMessages() = %v, %v; want the question then the reply", messages, err)
+	}
+
+	if _, err := messageRepo.ListMessages(ctx, "missing"); !errors.Is(err, ErrActionNotFound) {
+		t.Errorf("ListMessages(missing) error = %v, want ErrActionNotFound", err)
+	}
+}
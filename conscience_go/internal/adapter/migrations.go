@@ -0,0 +1,555 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when the database was migrated by a newer kernel
+var ErrSchemaTooNew = errors.New("database schema is newer than this kernel supports")
+
+// Migration is a numbered, forward-only schema change applied in a single transaction
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx) error
+}
+
+// AppliedMigration is a row of the schema_migrations table
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// SchemaStatus describes where a database stands relative to the known migrations
+type SchemaStatus struct {
+	Unversioned bool               `json:"unversioned"` // No schema_migrations table yet: created by an older kernel, or empty
+	Current     int                `json:"current"`
+	Latest      int                `json:"latest"`
+	Applied     []AppliedMigration `json:"applied"`
+	Pending     []Migration        `json:"-"`
+}
+
+// migrations lists every schema change in order. Never edit or renumber an entry
+// once it has shipped - append a new migration instead.
+var migrations = []Migration{
+	{Version: 1, Name: "initial schema", Up: migrateInitialSchema},
+	{Version: 2, Name: "capture provenance", Up: migrateCaptureProvenance},
+	{Version: 3, Name: "audit log", Up: migrateAuditLog},
+	{Version: 4, Name: "memories and encryption metadata", Up: migrateMemoriesAndEncryption},
+	{Version: 5, Name: "emergency stop latch", Up: migrateEmergencyStop},
+	{Version: 6, Name: "trace correlation", Up: migrateTraceCorrelation},
+	{Version: 7, Name: "goal plans", Up: migrateGoalPlans},
+	{Version: 8, Name: "goal leases", Up: migrateGoalLeases},
+	{Version: 9, Name: "goal scheduling", Up: migrateGoalScheduling},
+	{Version: 10, Name: "action batches", Up: migrateActionBatches},
+	{Version: 11, Name: "decision log", Up: migrateDecisionLog},
+	{Version: 12, Name: "proposal messages", Up: migrateProposalMessages},
+	{Version: 13, Name: "proposal revisions", Up: migrateProposalRevisions},
+	{Version: 14, Name: "standing grants", Up: migrateGrants},
+}
+
+// LatestSchemaVersion returns the version this kernel migrates databases to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate applies every pending migration in order, each in its own transaction
+// Returns the migrations applied; refuses to touch a database with a newer schema.
+func Migrate(ctx context.Context, db DB) ([]Migration, error) {
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return nil, err
+	}
+	status, err := GetSchemaStatus(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if status.Current > status.Latest {
+		return nil, fmt.Errorf("%w (database v%d, kernel v%d)", ErrSchemaTooNew, status.Current, status.Latest)
+	}
+
+	var applied []Migration
+	for _, m := range status.Pending {
+		if err := applyMigration(ctx, db, m); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// GetSchemaStatus reports the applied and pending migrations without writing to the database
+func GetSchemaStatus(ctx context.Context, db DB) (*SchemaStatus, error) {
+	status := &SchemaStatus{Latest: LatestSchemaVersion()}
+
+	var tables int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&tables); err != nil {
+		return nil, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+	if tables == 0 {
+		status.Unversioned = true
+		status.Pending = migrations
+		return status, nil
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int]bool)
+	for rows.Next() {
+		var m AppliedMigration
+		if err := rows.Scan(&m.Version, &m.Name, &m.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema migration: %w", err)
+		}
+		status.Applied = append(status.Applied, m)
+		done[m.Version] = true
+		if m.Version > status.Current {
+			status.Current = m.Version
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating schema_migrations rows: %w", err)
+	}
+
+	for _, m := range migrations {
+		if !done[m.Version] {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+
+	return status, nil
+}
+
+// ensureMigrationsTable creates the schema_migrations bookkeeping table
+func ensureMigrationsTable(ctx context.Context, db DB) error {
+	createTableSQL := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	);
+	`
+	if _, err := db.ExecContext(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it atomically
+func applyMigration(ctx context.Context, db DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	if err := m.Up(ctx, tx); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+	}
+
+	insertSQL := `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, insertSQL, m.Version, m.Name, time.Now()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+	return nil
+}
+
+// ensureSchema migrates the database so a repository can rely on its tables
+func ensureSchema(db DB) error {
+	_, err := Migrate(context.Background(), db)
+	return err
+}
+
+// execAll runs statements in order, stopping at the first failure
+func execAll(ctx context.Context, tx *sql.Tx, statements ...string) error {
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumnIfMissing adds a column unless a pre-migration kernel already created it
+func addColumnIfMissing(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+
+	found := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan %s columns: %w", table, err)
+		}
+		if name == column {
+			found = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating %s columns: %w", table, err)
+	}
+
+	if found {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// migrateInitialSchema creates the tables that predate versioned migrations
+// Databases created by older kernels already have some of these, possibly without later columns.
+func migrateInitialSchema(ctx context.Context, tx *sql.Tx) error {
+	err := execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS artifacts (
+			id TEXT PRIMARY KEY,
+			timestamp DATETIME NOT NULL,
+			content TEXT NOT NULL,
+			type TEXT NOT NULL,
+			bounding_box TEXT NOT NULL,
+			classification TEXT,
+			summary TEXT,
+			embedding TEXT
+		);`,
+		`CREATE TABLE IF NOT EXISTS action_proposals (
+			id TEXT PRIMARY KEY,
+			intent TEXT NOT NULL,
+			risk_score INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			domain TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			approved_at DATETIME,
+			interaction_type TEXT NOT NULL DEFAULT 'PERMISSION',
+			agent_message TEXT,
+			user_response TEXT
+		);`,
+		`CREATE TABLE IF NOT EXISTS user_modes (
+			domain TEXT PRIMARY KEY,
+			mode TEXT NOT NULL,
+			updated_at DATETIME NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS intent_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			intent TEXT NOT NULL,
+			focused_window TEXT NOT NULL,
+			executed_at DATETIME NOT NULL,
+			success_count INTEGER DEFAULT 1,
+			cached_plan TEXT
+		);`,
+		`CREATE TABLE IF NOT EXISTS app_state (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			state TEXT NOT NULL,
+			updated_at DATETIME NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS commands (
+			id TEXT PRIMARY KEY,
+			action TEXT NOT NULL,
+			target TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			executed_at DATETIME
+		);`,
+		`CREATE TABLE IF NOT EXISTS active_goals (
+			id TEXT PRIMARY KEY,
+			goal_text TEXT NOT NULL,
+			status TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);`,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Columns previously added by fire-and-forget ALTER statements
+	legacyColumns := []struct{ table, column, definition string }{
+		{"artifacts", "classification", "TEXT"},
+		{"artifacts", "summary", "TEXT"},
+		{"artifacts", "embedding", "TEXT"},
+		{"action_proposals", "interaction_type", "TEXT NOT NULL DEFAULT 'PERMISSION'"},
+		{"action_proposals", "agent_message", "TEXT"},
+		{"action_proposals", "user_response", "TEXT"},
+		{"intent_history", "cached_plan", "TEXT"},
+	}
+	for _, c := range legacyColumns {
+		if err := addColumnIfMissing(ctx, tx, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_intent_window ON intent_history(intent, focused_window);"); err != nil {
+		return err
+	}
+
+	// Default rows: AUTO mode for all domains, SHADOW (safe) application state
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO user_modes (domain, mode, updated_at) VALUES ('*', 'AUTO', ?);", now); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO app_state (id, state, updated_at) VALUES (1, 'SHADOW', datetime('now'));"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// migrateCaptureProvenance records which application and window produced captured data
+func migrateCaptureProvenance(ctx context.Context, tx *sql.Tx) error {
+	columns := []struct{ table, column string }{
+		{"artifacts", "source_app"},
+		{"artifacts", "window_title"},
+		{"intent_history", "application"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(ctx, tx, c.table, c.column, "TEXT"); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_artifacts_timestamp ON artifacts(timestamp);")
+	return err
+}
+
+// migrateAuditLog adds the persistent audit trail
+func migrateAuditLog(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id TEXT PRIMARY KEY,
+			event TEXT NOT NULL,
+			actor TEXT,
+			detail TEXT,
+			created_at DATETIME NOT NULL
+		);`,
+		"CREATE INDEX IF NOT EXISTS idx_audit_created ON audit_log(created_at);",
+	)
+}
+
+// migrateMemoriesAndEncryption adds long-term memories and the at-rest encryption key check
+func migrateMemoriesAndEncryption(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS memories (
+			id TEXT PRIMARY KEY,
+			key TEXT NOT NULL UNIQUE,
+			value TEXT NOT NULL,
+			context TEXT,
+			embedding TEXT,
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME
+		);`,
+		`CREATE TABLE IF NOT EXISTS encryption_meta (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			salt TEXT NOT NULL,
+			key_check TEXT NOT NULL,
+			pending_salt TEXT,
+			updated_at DATETIME NOT NULL
+		);`,
+	)
+}
+
+// migrateEmergencyStop records the kill switch on the state row so it survives a restart
+func migrateEmergencyStop(ctx context.Context, tx *sql.Tx) error {
+	columns := []struct{ column, definition string }{
+		{"estop_engaged", "INTEGER NOT NULL DEFAULT 0"},
+		{"estop_reason", "TEXT"},
+		{"estop_actor", "TEXT"},
+		{"estop_at", "DATETIME"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(ctx, tx, "app_state", c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateTraceCorrelation tags proposals, commands and audit entries with the intent's trace ID
+// and adds the lifecycle events /api/trace/{id} replays
+func migrateTraceCorrelation(ctx context.Context, tx *sql.Tx) error {
+	for _, table := range []string{"action_proposals", "commands", "audit_log"} {
+		if err := addColumnIfMissing(ctx, tx, table, "trace_id", "TEXT"); err != nil {
+			return err
+		}
+	}
+	return execAll(ctx, tx,
+		"CREATE INDEX IF NOT EXISTS idx_proposals_trace ON action_proposals(trace_id);",
+		"CREATE INDEX IF NOT EXISTS idx_commands_trace ON commands(trace_id);",
+		"CREATE INDEX IF NOT EXISTS idx_audit_trace ON audit_log(trace_id);",
+		`CREATE TABLE IF NOT EXISTS trace_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trace_id TEXT NOT NULL,
+			stage TEXT NOT NULL,
+			detail TEXT,
+			created_at DATETIME NOT NULL
+		);`,
+		"CREATE INDEX IF NOT EXISTS idx_trace_events_trace ON trace_events(trace_id, id);",
+	)
+}
+
+// migrateGoalPlans adds the ordered steps a goal is planned into, each linked to the proposal that runs it
+func migrateGoalPlans(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS goal_steps (
+			id TEXT PRIMARY KEY,
+			goal_id TEXT NOT NULL REFERENCES active_goals(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			intent TEXT NOT NULL,
+			action_id TEXT,
+			status TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			UNIQUE (goal_id, position)
+		);`,
+		"CREATE INDEX IF NOT EXISTS idx_goal_steps_action ON goal_steps(action_id);",
+	)
+}
+
+// migrateGoalLeases adds the planner lease owner and expiry to goals, indexed by expiry so lapsed leases are found quickly
+func migrateGoalLeases(ctx context.Context, tx *sql.Tx) error {
+	if err := addColumnIfMissing(ctx, tx, "active_goals", "lease_owner", "TEXT"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(ctx, tx, "active_goals", "lease_expires_at", "DATETIME"); err != nil {
+		return err
+	}
+	return execAll(ctx, tx,
+		"CREATE INDEX IF NOT EXISTS idx_goals_lease ON active_goals(lease_expires_at);",
+	)
+}
+
+// migrateGoalScheduling adds priorities, deadlines and one-shot or recurring run times to goals
+func migrateGoalScheduling(ctx context.Context, tx *sql.Tx) error {
+	columns := []struct{ name, definition string }{
+		{"priority", "INTEGER NOT NULL DEFAULT 0"},
+		{"deadline", "DATETIME"},
+		{"run_at", "DATETIME"},
+		{"recurrence", "TEXT"},
+		{"parent_id", "TEXT"},
+	}
+	for _, column := range columns {
+		if err := addColumnIfMissing(ctx, tx, "active_goals", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	return execAll(ctx, tx,
+		"CREATE INDEX IF NOT EXISTS idx_goals_queue ON active_goals(status, priority, created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_goals_run_at ON active_goals(status, run_at);",
+	)
+}
+
+// migrateActionBatches adds batches of proposals approved together and run in dependency order
+func migrateActionBatches(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS action_batches (
+			id TEXT PRIMARY KEY,
+			intent TEXT NOT NULL,
+			domain TEXT,
+			status TEXT NOT NULL,
+			risk_score INTEGER NOT NULL,
+			max_step_risk INTEGER NOT NULL,
+			trace_id TEXT,
+			failed_step INTEGER,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS batch_steps (
+			batch_id TEXT NOT NULL REFERENCES action_batches(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			depends_on TEXT NOT NULL,
+			action_id TEXT NOT NULL,
+			compensation_id TEXT,
+			PRIMARY KEY (batch_id, position)
+		);`,
+		"CREATE INDEX IF NOT EXISTS idx_batches_status ON action_batches(status, created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_batch_steps_action ON batch_steps(action_id);",
+		"CREATE INDEX IF NOT EXISTS idx_batch_steps_compensation ON batch_steps(compensation_id);",
+	)
+}
+
+// migrateDecisionLog records what the policy decided for each permission request, for replay
+func migrateDecisionLog(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS decision_log (
+			id TEXT PRIMARY KEY,
+			path TEXT NOT NULL,
+			intent TEXT NOT NULL,
+			domain TEXT,
+			request TEXT NOT NULL,
+			decision TEXT NOT NULL,
+			rule TEXT NOT NULL,
+			trace_id TEXT,
+			created_at DATETIME NOT NULL
+		);`,
+		"CREATE INDEX IF NOT EXISTS idx_decision_log_created ON decision_log(created_at);",
+	)
+}
+
+// migrateProposalMessages keeps the clarification thread of each proposal instead of a single reply
+func migrateProposalMessages(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS proposal_messages (
+			id TEXT PRIMARY KEY,
+			action_id TEXT NOT NULL REFERENCES action_proposals(id) ON DELETE CASCADE,
+			seq INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			text TEXT NOT NULL,
+			question TEXT,
+			created_at DATETIME NOT NULL,
+			UNIQUE (action_id, seq)
+		);`,
+	)
+}
+
+// migrateProposalRevisions keeps the original and edited payload of proposals approved with modifications
+func migrateProposalRevisions(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS proposal_revisions (
+			id TEXT PRIMARY KEY,
+			action_id TEXT NOT NULL REFERENCES action_proposals(id) ON DELETE CASCADE,
+			original TEXT NOT NULL,
+			edited TEXT NOT NULL,
+			changes TEXT NOT NULL,
+			actor TEXT,
+			created_at DATETIME NOT NULL
+		);`,
+		"CREATE INDEX IF NOT EXISTS idx_proposal_revisions_action ON proposal_revisions(action_id, created_at);",
+	)
+}
+
+// migrateGrants stores the standing approvals the policy checks before prompting
+func migrateGrants(ctx context.Context, tx *sql.Tx) error {
+	return execAll(ctx, tx,
+		`CREATE TABLE IF NOT EXISTS grants (
+			id TEXT PRIMARY KEY,
+			action_type TEXT NOT NULL,
+			process TEXT,
+			path_prefix TEXT,
+			expires_at DATETIME,
+			created_by TEXT,
+			created_at DATETIME NOT NULL,
+			revoked_at DATETIME
+		);`,
+	)
+}
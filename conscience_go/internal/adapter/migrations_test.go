@@ -0,0 +1,147 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	return openTestDBAt(t, filepath.Join(t.TempDir(), "kernel.db"))
+}
+
+func openTestDBAt(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	applied, err := Migrate(ctx, db)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Migrate() applied %d migrations, want %d", len(applied), len(migrations))
+	}
+
+	applied, err = Migrate(ctx, db)
+	if err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Migrate() applied %d migrations, want 0", len(applied))
+	}
+
+	status, err := GetSchemaStatus(ctx, db)
+	if err != nil {
+		t.Fatalf("GetSchemaStatus() error = %v", err)
+	}
+	if status.Current != LatestSchemaVersion() || len(status.Pending) != 0 {
+		t.Errorf("status = v%d with %d pending, want v%d with none", status.Current, len(status.Pending), LatestSchemaVersion())
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	// Schema as created by kernels that predate versioned migrations
+	legacy := []string{
+		`CREATE TABLE artifacts (id TEXT PRIMARY KEY, timestamp DATETIME NOT NULL, content TEXT NOT NULL, type TEXT NOT NULL, bounding_box TEXT NOT NULL);`,
+		`CREATE TABLE intent_history (id INTEGER PRIMARY KEY AUTOINCREMENT, intent TEXT NOT NULL, focused_window TEXT NOT NULL, executed_at DATETIME NOT NULL, success_count INTEGER DEFAULT 1);`,
+		`INSERT INTO artifacts VALUES ('a1', '2026-01-01 00:00:00', 'hello', 'TEXT', '{}');`,
+	}
+	for _, stmt := range legacy {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("legacy setup error = %v", err)
+		}
+	}
+
+	if _, err := Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	var content string
+	var sourceApp sql.NullString
+	if err := db.QueryRow("SELECT content, source_app FROM artifacts WHERE id = 'a1'").Scan(&content, &sourceApp); err != nil {
+		t.Fatalf("query migrated artifact error = %v", err)
+	}
+	if content != "hello" {
+		t.Errorf("content = %q, want existing data preserved", content)
+	}
+	if _, err := db.Exec("UPDATE intent_history SET cached_plan = '', application = ''"); err != nil {
+		t.Errorf("legacy intent_history missing migrated columns: %v", err)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	if _, err := Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'from the future', ?)", LatestSchemaVersion()+1, time.Now()); err != nil {
+		t.Fatalf("insert future migration error = %v", err)
+	}
+
+	if _, err := Migrate(ctx, db); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Migrate() error = %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := NewActionRepository(db); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("NewActionRepository() error = %v, want ErrSchemaTooNew", err)
+	}
+}
+
+func TestSchemaStatusDoesNotWrite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "kernel.db")
+	if _, err := OpenStore(StoreConfig{Path: filepath.Join(t.TempDir(), "missing.db"), Synchronous: "NORMAL", ReadOnly: true}); err == nil {
+		t.Error("OpenStore() read-only created a missing database")
+	}
+
+	legacy := openTestDBAt(t, path)
+	if _, err := legacy.Exec(`CREATE TABLE artifacts (id TEXT PRIMARY KEY);`); err != nil {
+		t.Fatalf("legacy setup error = %v", err)
+	}
+	legacy.Close()
+
+	cfg := DefaultStoreConfig(path)
+	cfg.ReadOnly = true
+	store, err := OpenStore(cfg)
+	if err != nil {
+		t.Fatalf("OpenStore() read-only error = %v", err)
+	}
+	defer store.Close()
+
+	status, err := GetSchemaStatus(ctx, store)
+	if err != nil {
+		t.Fatalf("GetSchemaStatus() error = %v", err)
+	}
+	if !status.Unversioned || status.Current != 0 || len(status.Pending) != len(migrations) {
+		t.Errorf("status = %+v, want unversioned with every migration pending", status)
+	}
+	var tables int
+	if err := store.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Error("GetSchemaStatus() created schema_migrations")
+	}
+	if _, err := store.ExecContext(ctx, "CREATE TABLE probe (id INTEGER)"); err == nil {
+		t.Error("read-only store accepted a write")
+	}
+}
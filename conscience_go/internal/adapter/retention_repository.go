@@ -0,0 +1,541 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy defines how long rows are kept in each table
+// A zero duration keeps rows forever
+type RetentionPolicy struct {
+	Artifacts       time.Duration `json:"artifacts"`
+	IntentHistory   time.Duration `json:"intent_history"`
+	ActionProposals time.Duration `json:"action_proposals"`
+	Goals           time.Duration `json:"goals"`
+	Commands        time.Duration `json:"commands"`
+	TraceEvents     time.Duration `json:"trace_events"`
+}
+
+// DefaultRetentionPolicy returns conservative privacy-first defaults
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		Artifacts:       7 * 24 * time.Hour,   // Raw screen captures are the most sensitive
+		IntentHistory:   180 * 24 * time.Hour, // Reflexes need history to earn trust
+		ActionProposals: 30 * 24 * time.Hour,
+		Goals:           30 * 24 * time.Hour,
+		Commands:        7 * 24 * time.Hour,
+		TraceEvents:     7 * 24 * time.Hour,
+	}
+}
+
+// ForgetScope selects the captured data to delete for a right-to-forget request
+// All populated criteria must match (logical AND). A time range alone reaches every table.
+// Application and window criteria match artifacts and intent history by their own columns;
+// proposals, batches and the decision log by domain and by the intents run in matching windows;
+// goals through the intents of their plan steps; commands and trace events through the trace IDs
+// of the rows forgotten above. Memories record no capture source, so those scopes report them unreached.
+type ForgetScope struct {
+	Application   string     `json:"application,omitempty"`    // Exact process name, e.g. "slack.exe"
+	WindowPattern string     `json:"window_pattern,omitempty"` // Glob over window titles, e.g. "*Bank*"
+	Since         *time.Time `json:"since,omitempty"`
+	Until         *time.Time `json:"until,omitempty"`
+}
+
+// Validate ensures the scope cannot accidentally match every row
+func (s ForgetScope) Validate() error {
+	if s.Application == "" && s.WindowPattern == "" && s.Since == nil && s.Until == nil {
+		return fmt.Errorf("forget scope requires an application, window pattern or time range")
+	}
+	if s.Since != nil && s.Until != nil && s.Until.Before(*s.Since) {
+		return fmt.Errorf("forget scope 'until' must not be before 'since'")
+	}
+	return nil
+}
+
+// covers reports whether a capture time falls within the scope's time range
+func (s ForgetScope) covers(t time.Time) bool {
+	if s.Since != nil && t.Before(*s.Since) {
+		return false
+	}
+	if s.Until != nil && t.After(*s.Until) {
+		return false
+	}
+	return true
+}
+
+// ForgetResult reports how many rows were removed per table
+type ForgetResult struct {
+	Scope     ForgetScope      `json:"scope"`
+	Deleted   map[string]int64 `json:"deleted"`
+	Unreached []string         `json:"unreached,omitempty"` // Tables the scope cannot attribute rows of
+}
+
+// retentionTable describes how a table participates in retention and forget
+type retentionTable struct {
+	name        string
+	retainCol   string   // Timestamp compared against the retention window
+	statusCol   string   // Optional status column gating expiry
+	terminal    []string // Only rows in these statuses expire (empty = all rows)
+	capturedCol string   // Timestamp used for forget time ranges
+	appCol      string   // Column holding the source application, if any
+	windowCol   string   // Column holding the window title, if any
+	intentLink  string   // Condition reaching rows by intent, with %s for the intents' placeholders
+	traceCol    string   // Column holding the trace ID, if any
+	byTrace     bool     // Reached by application or window only through the traces of forgotten rows
+}
+
+// retentionTables lists every table holding captured user data
+var retentionTables = []retentionTable{
+	{
+		name:        "artifacts",
+		retainCol:   "timestamp",
+		capturedCol: "timestamp",
+		appCol:      "source_app",
+		windowCol:   "window_title",
+	},
+	{
+		name:        "intent_history",
+		retainCol:   "executed_at",
+		capturedCol: "executed_at",
+		appCol:      "application",
+		windowCol:   "focused_window",
+	},
+	{
+		name:        "action_proposals",
+		retainCol:   "updated_at",
+		statusCol:   "status",
+		terminal:    []string{"REJECTED", "COMPLETED", "FAILED", "CANCELLED", "SKIPPED"},
+		capturedCol: "created_at",
+		appCol:      "domain",
+		intentLink:  "intent IN (%s)",
+		traceCol:    "trace_id",
+	},
+	{
+		name:        "action_batches",
+		retainCol:   "updated_at",
+		statusCol:   "status",
+		terminal:    []string{"REJECTED", "COMPLETED", "FAILED", "CANCELLED"},
+		capturedCol: "created_at",
+		appCol:      "domain",
+		intentLink:  "intent IN (%s)",
+		traceCol:    "trace_id",
+	},
+	{
+		name:        "decision_log",
+		retainCol:   "created_at",
+		capturedCol: "created_at",
+		appCol:      "domain",
+		intentLink:  "intent IN (%s)",
+		traceCol:    "trace_id",
+	},
+	{
+		name:        "active_goals",
+		retainCol:   "updated_at",
+		statusCol:   "status",
+		terminal:    []string{"COMPLETED", "FAILED", "CANCELLED"},
+		capturedCol: "created_at",
+		intentLink:  "id IN (SELECT goal_id FROM goal_steps WHERE intent IN (%s))",
+	},
+	{
+		name:        "commands",
+		retainCol:   "created_at",
+		statusCol:   "status",
+		terminal:    []string{"completed", "failed", "cancelled"},
+		capturedCol: "created_at",
+		traceCol:    "trace_id",
+		byTrace:     true,
+	},
+	{
+		name:        "trace_events",
+		retainCol:   "created_at",
+		capturedCol: "created_at",
+		traceCol:    "trace_id",
+		byTrace:     true,
+	},
+	{
+		name:        "memories",
+		retainCol:   "created_at", // Memories expire by their own TTL instead of a window
+		capturedCol: "created_at",
+	},
+}
+
+// window returns the retention window configured for a table
+func (p RetentionPolicy) window(table string) time.Duration {
+	switch table {
+	case "artifacts":
+		return p.Artifacts
+	case "intent_history":
+		return p.IntentHistory
+	case "action_proposals", "action_batches", "decision_log":
+		return p.ActionProposals
+	case "active_goals":
+		return p.Goals
+	case "commands":
+		return p.Commands
+	case "trace_events":
+		return p.TraceEvents
+	default:
+		return 0
+	}
+}
+
+// RetentionRepository enforces retention windows and right-to-forget deletions
+type RetentionRepository struct {
+	db DB
+}
+
+// NewRetentionRepository creates a new RetentionRepository
+// The tables it manages are owned (and created) by their own repositories
+func NewRetentionRepository(db DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// Compact deletes rows older than their table's retention window
+// Returns the number of rows removed per table
+func (r *RetentionRepository) Compact(ctx context.Context, policy RetentionPolicy, now time.Time) (map[string]int64, error) {
+	deleted := make(map[string]int64)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to begin compaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range retentionTables {
+		window := policy.window(table.name)
+		if window <= 0 {
+			continue
+		}
+
+		exists, err := tableExists(ctx, tx, table.name)
+		if err != nil {
+			return deleted, err
+		}
+		if !exists {
+			continue
+		}
+
+		var conditions []string
+		var args []interface{}
+		if table.statusCol != "" && len(table.terminal) > 0 {
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", table.statusCol, placeholders(len(table.terminal))))
+			for _, status := range table.terminal {
+				args = append(args, status)
+			}
+		}
+
+		cutoff := now.Add(-window)
+		n, err := deleteRows(ctx, tx, table.name, table.retainCol, conditions, args, func(t time.Time) bool {
+			return t.Before(cutoff)
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to compact %s: %w", table.name, err)
+		}
+		deleted[table.name] = n
+	}
+
+	// Memories carry a per-row TTL set by the Brain
+	exists, err := tableExists(ctx, tx, "memories")
+	if err != nil {
+		return deleted, err
+	}
+	if exists {
+		n, err := deleteRows(ctx, tx, "memories", "expires_at", []string{"expires_at IS NOT NULL"}, nil, func(t time.Time) bool {
+			return t.Before(now)
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to compact memories: %w", err)
+		}
+		deleted["memories"] = n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return deleted, fmt.Errorf("failed to commit compaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// Forget deletes everything captured within the scope, across all tables, in one transaction
+// Tables the scope cannot attribute rows of are listed in the result as unreached.
+// If anything was deleted, the WAL is checkpointed and the file vacuumed so no copy stays on disk.
+func (r *RetentionRepository) Forget(ctx context.Context, scope ForgetScope) (*ForgetResult, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin forget transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &ForgetResult{Scope: scope, Deleted: make(map[string]int64)}
+	bySource := scope.Application != "" || scope.WindowPattern != ""
+
+	// Intents run in the scope's applications and windows reach the tables that record neither;
+	// they are read before intent_history itself is forgotten
+	var intents []interface{}
+	if bySource {
+		if intents, err = forgottenIntents(ctx, tx, scope); err != nil {
+			return nil, err
+		}
+	}
+	var traces []interface{}
+	seenTraces := make(map[interface{}]bool)
+
+	for _, table := range retentionTables {
+		exists, err := tableExists(ctx, tx, table.name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		var conditions []string
+		var args []interface{}
+		match := scope.covers
+		if scope.Since == nil && scope.Until == nil {
+			match = nil
+		}
+
+		appDirect := scope.Application == "" || table.appCol != ""
+		windowDirect := scope.WindowPattern == "" || table.windowCol != ""
+		switch {
+		case appDirect && windowDirect:
+			if scope.Application != "" {
+				conditions = append(conditions, table.appCol+" = ?")
+				args = append(args, scope.Application)
+			}
+			if scope.WindowPattern != "" {
+				conditions = append(conditions, table.windowCol+` LIKE ? ESCAPE '\'`)
+				args = append(args, globToLike(scope.WindowPattern))
+			}
+		case table.intentLink != "":
+			if len(intents) == 0 {
+				result.Deleted[table.name] = 0
+				continue
+			}
+			if scope.Application != "" && table.appCol != "" {
+				conditions = append(conditions, table.appCol+" = ?")
+				args = append(args, scope.Application)
+			}
+			conditions = append(conditions, fmt.Sprintf(table.intentLink, placeholders(len(intents))))
+			args = append(args, intents...)
+		case table.byTrace:
+			if len(traces) == 0 {
+				result.Deleted[table.name] = 0
+				continue
+			}
+			// The whole trace of a forgotten row goes, whenever its events were recorded
+			conditions = append(conditions, fmt.Sprintf("%s IN (%s)", table.traceCol, placeholders(len(traces))))
+			args = append(args, traces...)
+			match = nil
+		default:
+			result.Unreached = append(result.Unreached, table.name)
+			continue
+		}
+
+		if bySource && table.traceCol != "" && !table.byTrace {
+			found, err := matchingTraces(ctx, tx, table, conditions, args, match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read traces in %s: %w", table.name, err)
+			}
+			for _, traceID := range found {
+				if !seenTraces[traceID] {
+					seenTraces[traceID] = true
+					traces = append(traces, traceID)
+				}
+			}
+		}
+
+		n, err := deleteRows(ctx, tx, table.name, table.capturedCol, conditions, args, match)
+		if err != nil {
+			return nil, fmt.Errorf("failed to forget rows in %s: %w", table.name, err)
+		}
+		result.Deleted[table.name] = n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit forget transaction: %w", err)
+	}
+
+	// Deleted rows otherwise linger in free pages and the WAL until some later VACUUM
+	for _, n := range result.Deleted {
+		if n > 0 {
+			if err := scrubFreedPages(ctx, r.db); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// Vacuum rebuilds the database file so deleted content is physically removed from disk
+func (r *RetentionRepository) Vacuum(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, "VACUUM;"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// forgottenIntents returns the distinct intents run in the scope's applications and windows and time range
+func forgottenIntents(ctx context.Context, tx *sql.Tx, scope ForgetScope) ([]interface{}, error) {
+	exists, err := tableExists(ctx, tx, "intent_history")
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []interface{}
+	if scope.Application != "" {
+		conditions = append(conditions, "application = ?")
+		args = append(args, scope.Application)
+	}
+	if scope.WindowPattern != "" {
+		conditions = append(conditions, `focused_window LIKE ? ESCAPE '\'`)
+		args = append(args, globToLike(scope.WindowPattern))
+	}
+	query := "SELECT intent, executed_at FROM intent_history WHERE " + strings.Join(conditions, " AND ")
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query forgotten intents: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	var intents []interface{}
+	for rows.Next() {
+		var intent string
+		var at sql.NullTime
+		if err := rows.Scan(&intent, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan forgotten intent: %w", err)
+		}
+		if seen[intent] || !at.Valid || !scope.covers(at.Time) {
+			continue
+		}
+		seen[intent] = true
+		intents = append(intents, intent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating forgotten intents: %w", err)
+	}
+	return intents, nil
+}
+
+// matchingTraces returns the trace IDs of the rows deleteRows would delete with the same arguments
+func matchingTraces(ctx context.Context, tx *sql.Tx, table retentionTable, conditions []string, args []interface{}, match func(time.Time) bool) ([]interface{}, error) {
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s IS NOT NULL", table.traceCol, table.capturedCol, table.name, table.traceCol)
+	for _, condition := range conditions {
+		query += " AND " + condition
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var traces []interface{}
+	for rows.Next() {
+		var traceID string
+		var at sql.NullTime
+		if err := rows.Scan(&traceID, &at); err != nil {
+			return nil, err
+		}
+		if traceID != "" && (match == nil || (at.Valid && match(at.Time))) {
+			traces = append(traces, traceID)
+		}
+	}
+	return traces, rows.Err()
+}
+
+// deleteRows deletes the rows matching the SQL conditions whose timestamp column is accepted by match (nil = every row)
+// Timestamps are stored as text with a zone offset, which does not order across zones, so they are
+// compared in Go and the matching rows deleted by rowid.
+func deleteRows(ctx context.Context, tx *sql.Tx, table, timeCol string, conditions []string, args []interface{}, match func(time.Time) bool) (int64, error) {
+	query := fmt.Sprintf("SELECT rowid, %s FROM %s", timeCol, table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query rows: %w", err)
+	}
+	var matched []int64
+	for rows.Next() {
+		var rowID int64
+		var at sql.NullTime
+		if err := rows.Scan(&rowID, &at); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if match == nil || (at.Valid && match(at.Time)) {
+			matched = append(matched, rowID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	deleteSQL := fmt.Sprintf("DELETE FROM %s WHERE rowid = ?", table)
+	for _, rowID := range matched {
+		if _, err := tx.ExecContext(ctx, deleteSQL, rowID); err != nil {
+			return 0, fmt.Errorf("failed to delete row: %w", err)
+		}
+	}
+	return int64(len(matched)), nil
+}
+
+// queryer is satisfied by DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// execer is satisfied by DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// tableExists reports whether a table has been created by its owning repository
+func tableExists(ctx context.Context, q queryer, name string) (bool, error) {
+	var count int
+	err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for table %s: %w", name, err)
+	}
+	return count > 0, nil
+}
+
+// globToLike converts a '*'/'?' glob into a LIKE pattern, escaping LIKE metacharacters
+func globToLike(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteRune('%')
+		case '?':
+			b.WriteRune('_')
+		case '%', '_', '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// placeholders returns a comma-separated list of n SQL placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
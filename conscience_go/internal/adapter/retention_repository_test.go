@@ -0,0 +1,242 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/domain"
+)
+
+func TestForgetScope(t *testing.T) {
+	ctx := context.Background()
+	memoryRepo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "kernel.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	defer memoryRepo.Close()
+	db := memoryRepo.GetDB()
+
+	intentRepo, err := NewIntentHistoryRepository(db)
+	if err != nil {
+		t.Fatalf("NewIntentHistoryRepository() error = %v", err)
+	}
+	actionRepo, err := NewActionRepository(db)
+	if err != nil {
+		t.Fatalf("NewActionRepository() error = %v", err)
+	}
+
+	bank := domain.NewArtifact(domain.ArtifactTypeText, "balance: 1234", domain.BoundingBox{})
+	bank.SourceApp, bank.WindowTitle = "chrome.exe", "My Bank - Chrome"
+	notes := domain.NewArtifact(domain.ArtifactTypeText, "shopping list", domain.BoundingBox{})
+	notes.SourceApp, notes.WindowTitle = "notepad.exe", "list.txt - Notepad"
+	for _, a := range []domain.Artifact{bank, notes} {
+		if err := memoryRepo.Save(ctx, a); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := intentRepo.RecordExecution(ctx, "check balance", "My Bank - Chrome", "chrome.exe", ""); err != nil {
+		t.Fatalf("RecordExecution() error = %v", err)
+	}
+	// Activity that followed from the bank window records the intent and trace, not the window
+	proposal := domain.NewActionProposal("check balance", 10, json.RawMessage(`{}`), "chrome.exe")
+	proposal.TraceID = "trace-bank"
+	other := domain.NewActionProposal("save list", 10, json.RawMessage(`{}`), "notepad.exe")
+	other.TraceID = "trace-notes"
+	for _, p := range []*domain.ActionProposal{proposal, other} {
+		if err := actionRepo.SaveActionProposal(ctx, p); err != nil {
+			t.Fatalf("SaveActionProposal() error = %v", err)
+		}
+	}
+	commandRepo, err := NewCommandRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traceRepo, err := NewTraceRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, traceID := range []string{"trace-bank", "trace-notes"} {
+		cmd := &domain.Command{ID: "cmd-" + traceID, Action: "TYPE", Target: "x", Status: "completed", CreatedAt: time.Now(), TraceID: traceID}
+		if err := commandRepo.SaveCommand(ctx, cmd); err != nil {
+			t.Fatalf("SaveCommand() error = %v", err)
+		}
+		if err := traceRepo.RecordTraceEvent(ctx, traceID, "received", nil); err != nil {
+			t.Fatalf("RecordTraceEvent() error = %v", err)
+		}
+	}
+	goalRepo, err := NewGoalRepository(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goal := domain.NewGoal("pay rent")
+	if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatalf("SaveGoal() error = %v", err)
+	}
+	if _, err := goalRepo.SavePlan(ctx, goal.ID, "", []string{"check balance", "transfer"}); err != nil {
+		t.Fatalf("SavePlan() error = %v", err)
+	}
+
+	repo := NewRetentionRepository(db)
+
+	if _, err := repo.Forget(ctx, ForgetScope{}); err == nil {
+		t.Fatal("Forget() with empty scope should fail")
+	}
+
+	result, err := repo.Forget(ctx, ForgetScope{WindowPattern: "*Bank*"})
+	if err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	want := map[string]int64{"artifacts": 1, "intent_history": 1, "action_proposals": 1, "active_goals": 1, "commands": 1, "trace_events": 1}
+	for table, n := range want {
+		if result.Deleted[table] != n {
+			t.Errorf("Forget() by window deleted %d rows from %s, want %d (all: %v)", result.Deleted[table], table, n, result.Deleted)
+		}
+	}
+	if len(result.Unreached) != 1 || result.Unreached[0] != "memories" {
+		t.Errorf("Forget() by window unreached = %v, want [memories]", result.Unreached)
+	}
+	if left, err := actionRepo.GetActionsByTraceID(ctx, "trace-bank"); err != nil || len(left) != 0 {
+		t.Errorf("proposals left for the bank window = %v, %v; want none", left, err)
+	}
+	if left, err := actionRepo.GetActionsByTraceID(ctx, "trace-notes"); err != nil || len(left) != 1 {
+		t.Errorf("proposals left for notepad = %v, %v; want the one", left, err)
+	}
+	if events, err := traceRepo.Events(ctx, "trace-notes"); err != nil || len(events) != 1 {
+		t.Errorf("notepad trace events = %v, %v; want them kept", events, err)
+	}
+
+	result, err = repo.Forget(ctx, ForgetScope{Application: "notepad.exe"})
+	if err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if result.Deleted["action_proposals"] != 1 || result.Deleted["commands"] != 1 {
+		t.Errorf("Forget() by application deleted = %v, want the notepad proposal and its command", result.Deleted)
+	}
+
+	remaining, err := memoryRepo.GetLastArtifacts(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetLastArtifacts() error = %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("remaining artifacts = %v, want none", remaining)
+	}
+}
+
+func TestCompactRespectsWindows(t *testing.T) {
+	ctx := context.Background()
+	memoryRepo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "kernel.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	defer memoryRepo.Close()
+
+	old := domain.NewArtifact(domain.ArtifactTypeText, "old", domain.BoundingBox{})
+	old.Timestamp = time.Now().Add(-48 * time.Hour)
+	fresh := domain.NewArtifact(domain.ArtifactTypeText, "fresh", domain.BoundingBox{})
+	for _, a := range []domain.Artifact{old, fresh} {
+		if err := memoryRepo.Save(ctx, a); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	repo := NewRetentionRepository(memoryRepo.GetDB())
+	deleted, err := repo.Compact(ctx, RetentionPolicy{Artifacts: 24 * time.Hour}, time.Now())
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if deleted["artifacts"] != 1 {
+		t.Errorf("Compact() deleted %d artifacts, want 1", deleted["artifacts"])
+	}
+	if err := repo.Vacuum(ctx); err != nil {
+		t.Errorf("Vacuum() error = %v", err)
+	}
+}
+
+func TestRetentionComparesTimesAcrossZones(t *testing.T) {
+	ctx := context.Background()
+	memoryRepo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "kernel.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	defer memoryRepo.Close()
+
+	// Stored as text, "+14:00" sorts after the same instant written in UTC or a western zone
+	kiribati := time.FixedZone("LINT", 14*60*60)
+	now := time.Now()
+	recent := domain.NewArtifact(domain.ArtifactTypeText, "balance: 1234", domain.BoundingBox{})
+	recent.Timestamp = now.Add(-time.Hour).In(kiribati)
+	stale := domain.NewArtifact(domain.ArtifactTypeText, "old notes", domain.BoundingBox{})
+	stale.Timestamp = now.Add(-48 * time.Hour).In(time.FixedZone("HST", -10*60*60))
+	for _, a := range []domain.Artifact{recent, stale} {
+		if err := memoryRepo.Save(ctx, a); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	repo := NewRetentionRepository(memoryRepo.GetDB())
+	deleted, err := repo.Compact(ctx, RetentionPolicy{Artifacts: 24 * time.Hour}, now.UTC())
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if deleted["artifacts"] != 1 {
+		t.Errorf("Compact() deleted %d artifacts, want only the 48h-old one", deleted["artifacts"])
+	}
+
+	since, until := now.Add(-2*time.Hour).UTC(), now.UTC()
+	result, err := repo.Forget(ctx, ForgetScope{Since: &since, Until: &until})
+	if err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if result.Deleted["artifacts"] != 1 {
+		t.Errorf("Forget() deleted %d artifacts, want the one captured an hour ago at +14:00", result.Deleted["artifacts"])
+	}
+	if remaining, _ := memoryRepo.GetLastArtifacts(ctx, 10); len(remaining) != 0 {
+		t.Errorf("%d artifacts left, want none", len(remaining))
+	}
+}
+
+func TestForgetLeavesNothingInFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "kernel.db")
+	store, err := OpenStore(DefaultStoreConfig(path))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	memoryRepo, err := NewSQLiteRepositoryWithDB(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := "account number 4929-0000-1111-2222"
+	for i := 0; i < 50; i++ {
+		artifact := domain.NewArtifact(domain.ArtifactTypeText, secret, domain.BoundingBox{})
+		artifact.SourceApp, artifact.WindowTitle = "bank.exe", "Statement - My Bank"
+		if err := memoryRepo.Save(ctx, artifact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Checkpoint(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewRetentionRepository(store).Forget(ctx, ForgetScope{Application: "bank.exe"})
+	if err != nil || result.Deleted["artifacts"] != 50 {
+		t.Fatalf("Forget() = %+v, %v; want 50 artifacts deleted", result, err)
+	}
+	for _, file := range []string{path, path + "-wal"} {
+		data, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("%s still holds forgotten content", filepath.Base(file))
+		}
+	}
+}
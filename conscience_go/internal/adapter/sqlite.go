@@ -8,61 +8,47 @@ import (
 	"fmt"
 	"math"
 
-	_ "modernc.org/sqlite"
-
 	"ghost/kernel/internal/domain"
 )
 
 // SQLiteRepository manages artifact persistence in SQLite
 type SQLiteRepository struct {
-	db *sql.DB
+	db     DB
+	store  *Store // Set when the repository opened (and owns) its own store
+	cipher *FieldCipher
 }
 
-// NewSQLiteRepository creates a new SQLite repository and initializes the database
+// NewSQLiteRepository opens a dedicated store at dbPath and initializes the database
 func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	store, err := OpenStore(DefaultStoreConfig(dbPath))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	// Enable WAL mode for better performance
-	if _, err := db.Exec("PRAGMA journal_mode=WAL;"); err != nil {
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+		return nil, err
 	}
 
-	// Create artifacts table if not exists
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS artifacts (
-		id TEXT PRIMARY KEY,
-		timestamp DATETIME NOT NULL,
-		content TEXT NOT NULL,
-		type TEXT NOT NULL,
-		bounding_box TEXT NOT NULL,
-		classification TEXT,
-		summary TEXT,
-		embedding TEXT
-	);
-	`
-
-	if _, err := db.Exec(createTableSQL); err != nil {
-		return nil, fmt.Errorf("failed to create artifacts table: %w", err)
+	repo, err := NewSQLiteRepositoryWithDB(store)
+	if err != nil {
+		store.Close()
+		return nil, err
 	}
+	repo.store = store
 
-	// Migrate existing tables to add new columns if they don't exist
-	migrateSQL := []string{
-		"ALTER TABLE artifacts ADD COLUMN classification TEXT;",
-		"ALTER TABLE artifacts ADD COLUMN summary TEXT;",
-		"ALTER TABLE artifacts ADD COLUMN embedding TEXT;",
-	}
+	return repo, nil
+}
 
-	for _, stmt := range migrateSQL {
-		// Ignore errors if columns already exist
-		_, _ = db.Exec(stmt)
+// NewSQLiteRepositoryWithDB creates a SQLite repository on a shared database handle
+func NewSQLiteRepositoryWithDB(db DB) (*SQLiteRepository, error) {
+	if err := ensureSchema(db); err != nil {
+		return nil, err
 	}
 
 	return &SQLiteRepository{db: db}, nil
 }
 
+// SetCipher enables at-rest encryption of artifact content and summaries
+func (r *SQLiteRepository) SetCipher(c *FieldCipher) {
+	r.cipher = c
+}
+
 // Save persists an artifact to the database
 func (r *SQLiteRepository) Save(ctx context.Context, artifact domain.Artifact) error {
 	// Serialize bounding box to JSON
@@ -71,9 +57,14 @@ func (r *SQLiteRepository) Save(ctx context.Context, artifact domain.Artifact) e
 		return fmt.Errorf("failed to marshal bounding box: %w", err)
 	}
 
+	content, err := r.cipher.Seal("artifacts", "content", artifact.ID, artifact.Content)
+	if err != nil {
+		return err
+	}
+
 	insertSQL := `
-	INSERT INTO artifacts (id, timestamp, content, type, bounding_box)
-	VALUES (?, ?, ?, ?, ?)
+	INSERT INTO artifacts (id, timestamp, content, type, bounding_box, source_app, window_title)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err = r.db.ExecContext(
@@ -81,9 +72,11 @@ func (r *SQLiteRepository) Save(ctx context.Context, artifact domain.Artifact) e
 		insertSQL,
 		artifact.ID,
 		artifact.Timestamp,
-		artifact.Content,
+		content,
 		string(artifact.Type),
 		string(boundingBoxJSON),
+		artifact.SourceApp,
+		artifact.WindowTitle,
 	)
 
 	if err != nil {
@@ -96,7 +89,7 @@ func (r *SQLiteRepository) Save(ctx context.Context, artifact domain.Artifact) e
 // GetLastArtifacts retrieves the last N artifacts from the database
 func (r *SQLiteRepository) GetLastArtifacts(ctx context.Context, limit int) ([]domain.Artifact, error) {
 	query := `
-	SELECT id, timestamp, content, type, bounding_box, classification, summary
+	SELECT id, timestamp, content, type, bounding_box, classification, summary, source_app, window_title
 	FROM artifacts
 	ORDER BY timestamp DESC
 	LIMIT ?
@@ -115,6 +108,8 @@ func (r *SQLiteRepository) GetLastArtifacts(ctx context.Context, limit int) ([]d
 		var artifactType string
 		var classification sql.NullString
 		var summary sql.NullString
+		var sourceApp sql.NullString
+		var windowTitle sql.NullString
 
 		err := rows.Scan(
 			&artifact.ID,
@@ -124,6 +119,8 @@ func (r *SQLiteRepository) GetLastArtifacts(ctx context.Context, limit int) ([]d
 			&boundingBoxJSON,
 			&classification,
 			&summary,
+			&sourceApp,
+			&windowTitle,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan artifact: %w", err)
@@ -141,6 +138,12 @@ func (r *SQLiteRepository) GetLastArtifacts(ctx context.Context, limit int) ([]d
 		if summary.Valid {
 			artifact.Summary = summary.String
 		}
+		artifact.SourceApp = sourceApp.String
+		artifact.WindowTitle = windowTitle.String
+
+		if err := r.openArtifact(&artifact); err != nil {
+			return nil, err
+		}
 
 		artifacts = append(artifacts, artifact)
 	}
@@ -154,6 +157,11 @@ func (r *SQLiteRepository) GetLastArtifacts(ctx context.Context, limit int) ([]d
 
 // UpdateArtifact enriches an artifact with classification, summary, and embedding from LLM analysis
 func (r *SQLiteRepository) UpdateArtifact(ctx context.Context, id string, classification string, summary string, embedding string) error {
+	summary, err := r.cipher.Seal("artifacts", "summary", id, summary)
+	if err != nil {
+		return err
+	}
+
 	updateSQL := `
 	UPDATE artifacts
 	SET classification = ?, summary = ?, embedding = ?
@@ -181,7 +189,7 @@ func (r *SQLiteRepository) UpdateArtifact(ctx context.Context, id string, classi
 func (r *SQLiteRepository) SearchArtifacts(ctx context.Context, queryEmbedding []float32, limit int) ([]domain.Artifact, error) {
 	// Get all artifacts with embeddings
 	query := `
-	SELECT id, timestamp, content, type, bounding_box, classification, summary, embedding
+	SELECT id, timestamp, content, type, bounding_box, classification, summary, source_app, window_title, embedding
 	FROM artifacts
 	WHERE embedding IS NOT NULL
 	ORDER BY timestamp DESC
@@ -206,6 +214,8 @@ func (r *SQLiteRepository) SearchArtifacts(ctx context.Context, queryEmbedding [
 		var artifactType string
 		var classification sql.NullString
 		var summary sql.NullString
+		var sourceApp sql.NullString
+		var windowTitle sql.NullString
 		var embeddingJSON sql.NullString
 
 		err := rows.Scan(
@@ -216,6 +226,8 @@ func (r *SQLiteRepository) SearchArtifacts(ctx context.Context, queryEmbedding [
 			&boundingBoxJSON,
 			&classification,
 			&summary,
+			&sourceApp,
+			&windowTitle,
 			&embeddingJSON,
 		)
 		if err != nil {
@@ -234,6 +246,12 @@ func (r *SQLiteRepository) SearchArtifacts(ctx context.Context, queryEmbedding [
 		if summary.Valid {
 			artifact.Summary = summary.String
 		}
+		artifact.SourceApp = sourceApp.String
+		artifact.WindowTitle = windowTitle.String
+
+		if err := r.openArtifact(&artifact); err != nil {
+			return nil, err
+		}
 
 		// Parse embedding if available
 		var embedding []float32
@@ -279,6 +297,18 @@ func (r *SQLiteRepository) SearchArtifacts(ctx context.Context, queryEmbedding [
 	return artifacts, nil
 }
 
+// openArtifact decrypts the sensitive fields of a scanned artifact
+func (r *SQLiteRepository) openArtifact(artifact *domain.Artifact) error {
+	var err error
+	if artifact.Content, err = r.cipher.Open("artifacts", "content", artifact.ID, artifact.Content); err != nil {
+		return err
+	}
+	if artifact.Summary, err = r.cipher.Open("artifacts", "summary", artifact.ID, artifact.Summary); err != nil {
+		return err
+	}
+	return nil
+}
+
 // cosineSimilarity calculates the cosine similarity between two vectors
 func cosineSimilarity(a, b []float32) float32 {
 	if len(a) != len(b) || len(a) == 0 {
@@ -300,11 +330,15 @@ func cosineSimilarity(a, b []float32) float32 {
 }
 
 // GetDB returns the underlying database connection
-func (r *SQLiteRepository) GetDB() *sql.DB {
+func (r *SQLiteRepository) GetDB() DB {
 	return r.db
 }
 
-// Close closes the database connection
+// Close closes the database connection if this repository opened it
+// A shared handle is closed by its owner.
 func (r *SQLiteRepository) Close() error {
-	return r.db.Close()
+	if r.store == nil {
+		return nil
+	}
+	return r.store.Close()
 }
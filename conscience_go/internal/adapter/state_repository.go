@@ -4,34 +4,51 @@ package adapter
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"ghost/kernel/internal/domain"
 )
 
+// ErrEmergencyStop is returned for a state change that needs the kill switch re-armed first
+var ErrEmergencyStop = errors.New("emergency stop engaged")
+
+// ErrNotStopped is returned when re-arming a kill switch that is not engaged
+var ErrNotStopped = errors.New("emergency stop is not engaged")
+
+// EmergencyStop is the kill switch latch stored with the application state
+type EmergencyStop struct {
+	Engaged bool       `json:"engaged"`
+	Reason  string     `json:"reason,omitempty"`
+	Actor   string     `json:"actor,omitempty"`
+	At      *time.Time `json:"at,omitempty"`
+}
+
+// HaltResult counts the queued work an emergency stop cancelled
+type HaltResult struct {
+	CancelledActions  int64 `json:"cancelled_actions"`
+	CancelledCommands int64 `json:"cancelled_commands"`
+}
+
 // StateRepository manages global application state
 type StateRepository struct {
-	db    *sql.DB
+	db    DB
 	mu    sync.RWMutex
 	cache domain.AppState // In-memory cache for fast reads
+	estop EmergencyStop
 }
 
 // NewStateRepository creates a new state repository instance
-func NewStateRepository(db *sql.DB) (*StateRepository, error) {
+func NewStateRepository(db DB) (*StateRepository, error) {
 	repo := &StateRepository{
 		db:    db,
 		cache: domain.AppStateShadow, // Default to safe mode
 	}
 
-	// Create table if not exists
-	if err := repo.createTable(); err != nil {
-		return nil, fmt.Errorf("failed to create state table: %w", err)
-	}
-
-	// Initialize with default state if empty
-	if err := repo.initializeState(); err != nil {
-		return nil, fmt.Errorf("failed to initialize state: %w", err)
+	if err := ensureSchema(db); err != nil {
+		return nil, err
 	}
 
 	// Load current state into cache
@@ -42,44 +59,15 @@ func NewStateRepository(db *sql.DB) (*StateRepository, error) {
 	return repo, nil
 }
 
-// createTable creates the app_state table
-func (r *StateRepository) createTable() error {
-	query := `
-	CREATE TABLE IF NOT EXISTS app_state (
-		id INTEGER PRIMARY KEY CHECK (id = 1),
-		state TEXT NOT NULL,
-		updated_at DATETIME NOT NULL
-	);
-	`
-
-	_, err := r.db.Exec(query)
-	return err
-}
-
-// initializeState sets default state if table is empty
-func (r *StateRepository) initializeState() error {
-	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM app_state").Scan(&count)
-	if err != nil {
-		return err
-	}
-
-	if count == 0 {
-		// Insert default SHADOW state
-		query := `INSERT INTO app_state (id, state, updated_at) VALUES (1, ?, datetime('now'))`
-		_, err = r.db.Exec(query, domain.AppStateShadow)
-		return err
-	}
-
-	return nil
-}
-
 // loadCache loads the current state from database into memory
 func (r *StateRepository) loadCache() error {
 	var stateStr string
-	query := "SELECT state FROM app_state WHERE id = 1"
+	var engaged bool
+	var reason, actor sql.NullString
+	var at sql.NullTime
+	query := "SELECT state, estop_engaged, estop_reason, estop_actor, estop_at FROM app_state WHERE id = 1"
 
-	err := r.db.QueryRow(query).Scan(&stateStr)
+	err := r.db.QueryRow(query).Scan(&stateStr, &engaged, &reason, &actor, &at)
 	if err != nil {
 		return err
 	}
@@ -88,6 +76,10 @@ func (r *StateRepository) loadCache() error {
 	defer r.mu.Unlock()
 
 	r.cache = domain.AppState(stateStr)
+	r.estop = EmergencyStop{Engaged: engaged, Reason: reason.String, Actor: actor.String}
+	if at.Valid {
+		r.estop.At = &at.Time
+	}
 	return nil
 }
 
@@ -100,11 +92,15 @@ func (r *StateRepository) GetState(ctx context.Context) (domain.AppState, error)
 }
 
 // SetState updates the application state
+// While the emergency stop is engaged only PAUSED is accepted; Rearm is the way out.
 func (r *StateRepository) SetState(ctx context.Context, state domain.AppState) error {
 	// Validate state
 	if !state.IsValid() {
 		return fmt.Errorf("invalid app state: %s", state)
 	}
+	if r.EmergencyStop().Engaged && state != domain.AppStatePaused {
+		return fmt.Errorf("cannot switch to %s: %w", state, ErrEmergencyStop)
+	}
 
 	// Update database
 	query := `UPDATE app_state SET state = ?, updated_at = datetime('now') WHERE id = 1`
@@ -120,3 +116,97 @@ func (r *StateRepository) SetState(ctx context.Context, state domain.AppState) e
 
 	return nil
 }
+
+// EmergencyStop returns the kill switch latch (fast, cached)
+func (r *StateRepository) EmergencyStop() EmergencyStop {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.estop
+}
+
+// Halt engages the kill switch: PAUSED, latched, and every unfinished proposal and command
+// cancelled in one transaction. The cache is latched before the write, so the kernel stops
+// refusing work even if the database cannot be updated.
+func (r *StateRepository) Halt(ctx context.Context, actor, reason string) (*HaltResult, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	r.cache = domain.AppStatePaused
+	r.estop = EmergencyStop{Engaged: true, Reason: reason, Actor: actor, At: &now}
+	r.mu.Unlock()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin emergency stop: %w", err)
+	}
+	defer tx.Rollback()
+
+	stateSQL := `
+	UPDATE app_state
+	SET state = ?, estop_engaged = 1, estop_reason = ?, estop_actor = ?, estop_at = ?, updated_at = datetime('now')
+	WHERE id = 1
+	`
+	if _, err := tx.ExecContext(ctx, stateSQL, string(domain.AppStatePaused), reason, actor, now); err != nil {
+		return nil, fmt.Errorf("failed to latch emergency stop: %w", err)
+	}
+
+	actionsSQL := `
+	UPDATE action_proposals
+	SET status = ?, updated_at = ?
+	WHERE status IN (?, ?, ?, ?, ?, ?)
+	`
+	actions, err := tx.ExecContext(ctx, actionsSQL,
+		string(domain.ActionProposalStatusCancelled), now,
+		string(domain.ActionProposalStatusBlocked),
+		string(domain.ActionProposalStatusPending),
+		string(domain.ActionProposalStatusWaitingForUser),
+		string(domain.ActionProposalStatusWaitingForContext),
+		string(domain.ActionProposalStatusApproved),
+		string(domain.ActionProposalStatusExecuting))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel action proposals: %w", err)
+	}
+
+	commandsSQL := `UPDATE commands SET status = ? WHERE status IN (?, ?)`
+	commands, err := tx.ExecContext(ctx, commandsSQL,
+		string(domain.CommandStatusCancelled),
+		string(domain.CommandStatusPending),
+		string(domain.CommandStatusExecuting))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel commands: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit emergency stop: %w", err)
+	}
+
+	result := &HaltResult{}
+	result.CancelledActions, _ = actions.RowsAffected()
+	result.CancelledCommands, _ = commands.RowsAffected()
+	return result, nil
+}
+
+// Rearm releases the kill switch and resumes in SHADOW, where nothing acts until a person
+// switches back to ACTIVE
+func (r *StateRepository) Rearm(ctx context.Context) error {
+	if !r.EmergencyStop().Engaged {
+		return ErrNotStopped
+	}
+
+	query := `
+	UPDATE app_state
+	SET state = ?, estop_engaged = 0, estop_reason = NULL, estop_actor = NULL, estop_at = NULL, updated_at = datetime('now')
+	WHERE id = 1
+	`
+	if _, err := r.db.ExecContext(ctx, query, string(domain.AppStateShadow)); err != nil {
+		return fmt.Errorf("failed to re-arm emergency stop: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cache = domain.AppStateShadow
+	r.estop = EmergencyStop{}
+	r.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,228 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"ghost/kernel/internal/metrics"
+
+	_ "modernc.org/sqlite"
+)
+
+// DB is the subset of *sql.DB the repositories use
+// Satisfied by *sql.DB and by *Store, which routes writes and reads to separate pools.
+type DB interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// StoreConfig tunes the SQLite connection pools
+type StoreConfig struct {
+	Path        string
+	BusyTimeout time.Duration // How long a connection waits on a lock before SQLITE_BUSY
+	Synchronous string        // OFF, NORMAL or FULL; NORMAL is durable enough under WAL
+	ForeignKeys bool
+	MaxReaders  int  // Size of the read-only pool
+	ReadOnly    bool // Open an existing file for reading only; every call, including Exec, uses the reader pool
+}
+
+// DefaultStoreConfig returns the recommended settings for the kernel database
+func DefaultStoreConfig(path string) StoreConfig {
+	readers := runtime.NumCPU()
+	if readers < 2 {
+		readers = 2
+	}
+	return StoreConfig{
+		Path:        path,
+		BusyTimeout: 5 * time.Second,
+		Synchronous: "NORMAL",
+		ForeignKeys: true,
+		MaxReaders:  readers,
+	}
+}
+
+// Store owns the kernel's only handles to the database file
+// All writes go through a single connection so writers queue in Go instead of failing with
+// SQLITE_BUSY; reads use a separate query-only pool that WAL lets run alongside the writer.
+// Each call is timed into ghost_db_query_duration_seconds (for queries, up to the first row).
+type Store struct {
+	writer *sql.DB
+	reader *sql.DB
+}
+
+// OpenStore opens the writer and reader pools and applies the connection pragmas
+func OpenStore(cfg StoreConfig) (*Store, error) {
+	if cfg.Path == "" || strings.Contains(cfg.Path, ":memory:") {
+		return nil, fmt.Errorf("store requires a database file path")
+	}
+	switch strings.ToUpper(cfg.Synchronous) {
+	case "OFF", "NORMAL", "FULL", "EXTRA":
+	default:
+		return nil, fmt.Errorf("invalid synchronous mode: %q", cfg.Synchronous)
+	}
+	if cfg.MaxReaders < 1 {
+		cfg.MaxReaders = 1
+	}
+
+	if cfg.ReadOnly {
+		if _, err := os.Stat(cfg.Path); err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		reader, err := sql.Open("sqlite", storeDSN(cfg, true))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database readers: %w", err)
+		}
+		reader.SetMaxOpenConns(cfg.MaxReaders)
+		if err := reader.Ping(); err != nil {
+			reader.Close()
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		return &Store{writer: reader, reader: reader}, nil
+	}
+
+	// The writer is opened (and switched to WAL) before any reader touches the file
+	writer, err := sql.Open("sqlite", storeDSN(cfg, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database writer: %w", err)
+	}
+	writer.SetMaxOpenConns(1)
+	writer.SetMaxIdleConns(1)
+	writer.SetConnMaxLifetime(0)
+
+	if err := writer.Ping(); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	reader, err := sql.Open("sqlite", storeDSN(cfg, true))
+	if err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open database readers: %w", err)
+	}
+	reader.SetMaxOpenConns(cfg.MaxReaders)
+	reader.SetMaxIdleConns(cfg.MaxReaders)
+
+	if err := reader.Ping(); err != nil {
+		reader.Close()
+		writer.Close()
+		return nil, fmt.Errorf("failed to connect database readers: %w", err)
+	}
+
+	return &Store{writer: writer, reader: reader}, nil
+}
+
+// storeDSN builds a modernc.org/sqlite DSN with per-connection pragmas
+func storeDSN(cfg StoreConfig, readOnly bool) string {
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout.Milliseconds()))
+	if cfg.ForeignKeys {
+		params.Add("_pragma", "foreign_keys(1)")
+	}
+	params.Add("_pragma", fmt.Sprintf("synchronous(%s)", strings.ToUpper(cfg.Synchronous)))
+
+	if cfg.ReadOnly {
+		// mode=ro also refuses to create a missing file
+		params.Set("mode", "ro")
+	}
+	if readOnly {
+		params.Add("_pragma", "query_only(1)")
+	} else {
+		params.Add("_pragma", "journal_mode(WAL)")
+		// Take the write lock at BEGIN so transactions never fail upgrading a read lock
+		params.Set("_txlock", "immediate")
+	}
+
+	return "file:" + cfg.Path + "?" + params.Encode()
+}
+
+// Writer returns the single-connection write pool
+func (s *Store) Writer() *sql.DB {
+	return s.writer
+}
+
+// Reader returns the query-only read pool
+func (s *Store) Reader() *sql.DB {
+	return s.reader
+}
+
+// Exec runs a statement on the writer
+func (s *Store) Exec(query string, args ...interface{}) (sql.Result, error) {
+	defer metrics.DBQuery("exec", time.Now())
+	return s.writer.Exec(query, args...)
+}
+
+// ExecContext runs a statement on the writer
+func (s *Store) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer metrics.DBQuery("exec", time.Now())
+	return s.writer.ExecContext(ctx, query, args...)
+}
+
+// Query runs a query on the read pool
+func (s *Store) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	defer metrics.DBQuery("query", time.Now())
+	return s.reader.Query(query, args...)
+}
+
+// QueryContext runs a query on the read pool
+func (s *Store) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer metrics.DBQuery("query", time.Now())
+	return s.reader.QueryContext(ctx, query, args...)
+}
+
+// QueryRow runs a single-row query on the read pool
+func (s *Store) QueryRow(query string, args ...interface{}) *sql.Row {
+	defer metrics.DBQuery("query", time.Now())
+	return s.reader.QueryRow(query, args...)
+}
+
+// QueryRowContext runs a single-row query on the read pool
+func (s *Store) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer metrics.DBQuery("query", time.Now())
+	return s.reader.QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx starts a transaction on the writer; reads inside it see its own writes
+func (s *Store) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	defer metrics.DBQuery("begin", time.Now())
+	return s.writer.BeginTx(ctx, opts)
+}
+
+// Checkpoint copies the WAL back into the main database file and truncates it
+// Run at shutdown so the database file is self-contained when the kernel is not running.
+func (s *Store) Checkpoint(ctx context.Context) error {
+	var busy, logFrames, checkpointed int
+	if err := s.writer.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &logFrames, &checkpointed); err != nil {
+		return fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+	if busy != 0 {
+		return fmt.Errorf("failed to checkpoint WAL: database busy (%d of %d frames copied)", checkpointed, logFrames)
+	}
+	return nil
+}
+
+// Close closes both pools
+func (s *Store) Close() error {
+	readerErr := s.reader.Close()
+	if s.writer == s.reader {
+		return readerErr
+	}
+	if err := s.writer.Close(); err != nil {
+		return fmt.Errorf("failed to close database writer: %w", err)
+	}
+	if readerErr != nil {
+		return fmt.Errorf("failed to close database readers: %w", readerErr)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"ghost/kernel/internal/domain"
+)
+
+func TestStoreConcurrentWriters(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	intentRepo, err := NewIntentHistoryRepository(store)
+	if err != nil {
+		t.Fatalf("NewIntentHistoryRepository() error = %v", err)
+	}
+	artifactRepo, err := NewSQLiteRepositoryWithDB(store)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepositoryWithDB() error = %v", err)
+	}
+
+	const writers = 16
+	const writesPerWriter = 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*writesPerWriter*2)
+
+	// Mirrors the async RecordSuccess goroutines racing artifact ingestion and reads
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writesPerWriter; i++ {
+				if err := intentRepo.RecordExecution(ctx, fmt.Sprintf("intent-%d", i%5), "Editor", "code.exe", ""); err != nil {
+					errs <- err
+				}
+				artifact := domain.NewArtifact(domain.ArtifactTypeText, fmt.Sprintf("w%d-%d", w, i), domain.BoundingBox{})
+				if err := artifactRepo.Save(ctx, artifact); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < writesPerWriter; i++ {
+				if _, err := artifactRepo.GetLastArtifacts(ctx, 10); err != nil {
+					errs <- err
+				}
+				if _, err := intentRepo.GetTrustScore(ctx, "intent-0", "Editor"); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent access error = %v", err)
+	}
+
+	var count int
+	if err := store.QueryRow("SELECT COUNT(*) FROM artifacts").Scan(&count); err != nil {
+		t.Fatalf("count artifacts error = %v", err)
+	}
+	if count != writers*writesPerWriter {
+		t.Errorf("artifact count = %d, want %d", count, writers*writesPerWriter)
+	}
+
+	var executions int
+	if err := store.QueryRow("SELECT SUM(success_count) FROM intent_history").Scan(&executions); err != nil {
+		t.Fatalf("sum success_count error = %v", err)
+	}
+	if executions != writers*writesPerWriter {
+		t.Errorf("recorded executions = %d, want %d (lost updates)", executions, writers*writesPerWriter)
+	}
+}
+
+func TestStoreReadersAreQueryOnly(t *testing.T) {
+	store, err := OpenStore(DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	var mode string
+	if err := store.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("PRAGMA journal_mode error = %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+
+	if _, err := store.Reader().Exec("CREATE TABLE nope (id INTEGER)"); err == nil {
+		t.Error("reader pool accepted a write")
+	}
+}
+
+func TestStoreCheckpointTruncatesWAL(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "kernel.db")
+	store, err := OpenStore(DefaultStoreConfig(path))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	if _, err := Migrate(ctx, store); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if info, err := os.Stat(path + "-wal"); err != nil || info.Size() == 0 {
+		t.Fatalf("expected a non-empty WAL after migrating (err %v)", err)
+	}
+
+	if err := store.Checkpoint(ctx); err != nil {
+		t.Fatalf("Checkpoint() error = %v", err)
+	}
+	if info, err := os.Stat(path + "-wal"); err == nil && info.Size() != 0 {
+		t.Errorf("WAL size after checkpoint = %d, want 0", info.Size())
+	}
+}
@@ -0,0 +1,93 @@
+// Author: Enkae (enkae.dev@pm.me)
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TraceEvent is one recorded stage in the lifecycle of an intent
+type TraceEvent struct {
+	ID        int64             `json:"id"`
+	TraceID   string            `json:"trace_id"`
+	Stage     string            `json:"stage"`
+	Detail    map[string]string `json:"detail,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// TraceRepository stores lifecycle events so an intent can be reconstructed by trace ID
+// Implements tracing.Sink.
+type TraceRepository struct {
+	db DB
+}
+
+// NewTraceRepository creates a new TraceRepository and initializes tables
+func NewTraceRepository(db DB) (*TraceRepository, error) {
+	if err := ensureSchema(db); err != nil {
+		return nil, err
+	}
+
+	return &TraceRepository{db: db}, nil
+}
+
+// RecordTraceEvent appends a lifecycle event for traceID
+func (r *TraceRepository) RecordTraceEvent(ctx context.Context, traceID, stage string, detail map[string]string) error {
+	var detailJSON string
+	if len(detail) > 0 {
+		data, err := json.Marshal(detail)
+		if err != nil {
+			return fmt.Errorf("failed to marshal trace detail: %w", err)
+		}
+		detailJSON = string(data)
+	}
+
+	insertSQL := `
+	INSERT INTO trace_events (trace_id, stage, detail, created_at)
+	VALUES (?, ?, ?, ?)
+	`
+
+	if _, err := r.db.ExecContext(ctx, insertSQL, traceID, stage, detailJSON, time.Now()); err != nil {
+		return fmt.Errorf("failed to insert trace event: %w", err)
+	}
+	return nil
+}
+
+// Events retrieves the lifecycle events recorded for traceID, in the order they happened
+func (r *TraceRepository) Events(ctx context.Context, traceID string) ([]TraceEvent, error) {
+	query := `
+	SELECT id, trace_id, stage, detail, created_at
+	FROM trace_events
+	WHERE trace_id = ?
+	ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, traceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trace events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []TraceEvent
+	for rows.Next() {
+		var event TraceEvent
+		var detail sql.NullString
+		if err := rows.Scan(&event.ID, &event.TraceID, &event.Stage, &detail, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trace event: %w", err)
+		}
+		if detail.Valid && detail.String != "" {
+			if err := json.Unmarshal([]byte(detail.String), &event.Detail); err != nil {
+				return nil, fmt.Errorf("failed to parse trace detail: %w", err)
+			}
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trace rows: %w", err)
+	}
+
+	return events, nil
+}
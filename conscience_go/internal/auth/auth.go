@@ -0,0 +1,187 @@
+// Author: Enkae (enkae.dev@pm.me)
+// Package auth authenticates callers of the kernel's control plane.
+// Each role holds its own bearer token; the dashboard trades the human token for a
+// cookie session protected by a CSRF token. The same identities cover REST and gRPC.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Role identifies which part of the system a caller is
+type Role string
+
+const (
+	// RoleBrain is the Python planner proposing actions
+	RoleBrain Role = "brain"
+	// RoleBody is the Rust Sentinel reporting focus and executing actions
+	RoleBody Role = "body"
+	// RoleHuman is the user, through the dashboard or CLI
+	RoleHuman Role = "human"
+	// RoleAdmin may additionally back up, export and import the database
+	RoleAdmin Role = "admin"
+)
+
+// Roles lists every role in a stable order
+var Roles = []Role{RoleBrain, RoleBody, RoleHuman, RoleAdmin}
+
+// ErrUnauthenticated is returned when no valid credential was presented
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// DefaultSessionTTL is how long a dashboard session lasts without being renewed
+const DefaultSessionTTL = 12 * time.Hour
+
+// session is a logged-in dashboard tab
+type session struct {
+	role      Role
+	csrfToken string
+	expiresAt time.Time
+}
+
+// Authenticator maps credentials to roles
+type Authenticator struct {
+	tokens     map[Role]string
+	proxyToken string // Presented by the kernel's own REST-to-gRPC proxy
+
+	sessionTTL time.Duration
+	mu         sync.Mutex
+	sessions   map[string]*session
+
+	now func() time.Time
+}
+
+// NewAuthenticator creates an authenticator; a role with an empty token cannot authenticate
+func NewAuthenticator(tokens map[Role]string, sessionTTL time.Duration) (*Authenticator, error) {
+	proxyToken, err := NewToken()
+	if err != nil {
+		return nil, err
+	}
+	if sessionTTL <= 0 {
+		sessionTTL = DefaultSessionTTL
+	}
+
+	copied := make(map[Role]string, len(tokens))
+	for role, token := range tokens {
+		copied[role] = token
+	}
+
+	return &Authenticator{
+		tokens:     copied,
+		proxyToken: proxyToken,
+		sessionTTL: sessionTTL,
+		sessions:   make(map[string]*session),
+		now:        time.Now,
+	}, nil
+}
+
+// NewToken returns a random 256-bit hex token
+func NewToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// Authenticate returns the role a bearer token belongs to
+// Every configured token is compared so timing does not reveal which role matched.
+func (a *Authenticator) Authenticate(token string) (Role, error) {
+	var matched Role
+	for _, role := range Roles {
+		expected := a.tokens[role]
+		if expected == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			matched = role
+		}
+	}
+	if matched == "" {
+		return "", ErrUnauthenticated
+	}
+	return matched, nil
+}
+
+// isProxyToken reports whether token is the internal REST-to-gRPC proxy credential
+func (a *Authenticator) isProxyToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.proxyToken)) == 1
+}
+
+// createSession starts a dashboard session and returns its id and CSRF token
+func (a *Authenticator) createSession(role Role) (string, string, error) {
+	id, err := NewToken()
+	if err != nil {
+		return "", "", err
+	}
+	csrf, err := NewToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	// Drop expired sessions so abandoned tabs do not accumulate
+	for key, s := range a.sessions {
+		if now.After(s.expiresAt) {
+			delete(a.sessions, key)
+		}
+	}
+	a.sessions[id] = &session{role: role, csrfToken: csrf, expiresAt: now.Add(a.sessionTTL)}
+	return id, csrf, nil
+}
+
+// lookupSession returns a live session and extends it
+func (a *Authenticator) lookupSession(id string) (*session, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	now := a.now()
+	if now.After(s.expiresAt) {
+		delete(a.sessions, id)
+		return nil, false
+	}
+	s.expiresAt = now.Add(a.sessionTTL)
+	copied := *s
+	return &copied, true
+}
+
+// endSession logs a dashboard session out
+func (a *Authenticator) endSession(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.sessions, id)
+}
+
+type roleKey struct{}
+
+// WithRole returns a context carrying the authenticated caller's role
+func WithRole(ctx context.Context, role Role) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext returns the caller's role, if the request was authenticated
+func RoleFromContext(ctx context.Context) (Role, bool) {
+	role, ok := ctx.Value(roleKey{}).(Role)
+	return role, ok && role != ""
+}
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	for _, role := range Roles {
+		if string(role) == name {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q", name)
+}
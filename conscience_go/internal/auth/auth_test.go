@@ -0,0 +1,241 @@
+// Author: Enkae (enkae.dev@pm.me)
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	brainToken = "brain-token-0123456789"
+	humanToken = "human-token-0123456789"
+	origin     = "http://localhost:8080"
+)
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	a, err := NewAuthenticator(map[Role]string{RoleBrain: brainToken, RoleHuman: humanToken}, 0)
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	return a
+}
+
+// echoRole responds with the caller's role, or "anonymous"
+var echoRole = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	role, ok := RoleFromContext(r.Context())
+	if !ok {
+		role = "anonymous"
+	}
+	w.Write([]byte(role))
+})
+
+func TestAuthenticate(t *testing.T) {
+	a := newTestAuthenticator(t)
+
+	if role, err := a.Authenticate(brainToken); err != nil || role != RoleBrain {
+		t.Errorf("Authenticate(brain) = %q, %v", role, err)
+	}
+	for _, token := range []string{"", "nope", brainToken + "x"} {
+		if _, err := a.Authenticate(token); err != ErrUnauthenticated {
+			t.Errorf("Authenticate(%q) error = %v, want ErrUnauthenticated", token, err)
+		}
+	}
+}
+
+func TestHostGuard(t *testing.T) {
+	handler := HostGuard(LocalHosts(8080), echoRole)
+
+	for host, want := range map[string]int{
+		"localhost:8080":     http.StatusOK,
+		"127.0.0.1:8080":     http.StatusOK,
+		"[::1]:8080":         http.StatusOK,
+		"attacker.test:8080": http.StatusMisdirectedRequest,
+		"127.0.0.1.nip.io":   http.StatusMisdirectedRequest,
+		"localhost:9999":     http.StatusMisdirectedRequest,
+		"LOCALHOST:8080":     http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/state", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Host %q: status %d, want %d", host, rec.Code, want)
+		}
+	}
+}
+
+func TestHTTPMiddlewareBearer(t *testing.T) {
+	a := newTestAuthenticator(t)
+	handler := a.HTTPMiddleware([]string{origin}, MutatingRequest, echoRole)
+
+	tests := []struct {
+		name, method, authorization string
+		wantCode                    int
+		wantBody                    string
+	}{
+		{"read needs no credentials", http.MethodGet, "", http.StatusOK, "anonymous"},
+		{"write needs credentials", http.MethodPost, "", http.StatusUnauthorized, ""},
+		{"valid bearer", http.MethodPost, "Bearer " + brainToken, http.StatusOK, "brain"},
+		{"wrong bearer", http.MethodPost, "Bearer nope", http.StatusUnauthorized, ""},
+		{"wrong bearer on read", http.MethodGet, "Bearer nope", http.StatusUnauthorized, ""},
+		{"wrong scheme", http.MethodPost, "Basic " + brainToken, http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/api/approve/1", nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.wantCode)
+		}
+		if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+			t.Errorf("%s: role %q, want %q", tt.name, rec.Body.String(), tt.wantBody)
+		}
+	}
+}
+
+func TestSessionCSRF(t *testing.T) {
+	a := newTestAuthenticator(t)
+	mux := http.NewServeMux()
+	mux.Handle("/api/session", a.SessionHandler())
+	mux.Handle("/", echoRole)
+	handler := a.HTTPMiddleware([]string{origin}, func(r *http.Request) bool {
+		return r.URL.Path != "/api/session" && MutatingRequest(r)
+	}, mux)
+
+	do := func(method, path, body string, headers map[string]string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Services cannot open browser sessions
+	if rec := do(http.MethodPost, "/api/session", `{"token":"`+brainToken+`"}`, nil, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("brain login status %d, want 403", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/session", `{"token":"nope"}`, nil, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad login status %d, want 401", rec.Code)
+	}
+
+	rec := do(http.MethodPost, "/api/session", `{"token":"`+humanToken+`"}`, nil, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("login status %d, want 201", rec.Code)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteStrictMode {
+		t.Fatalf("session cookie = %+v, want HttpOnly SameSite=Strict", cookies)
+	}
+	cookie := cookies[0]
+	csrf := strings.Split(strings.Split(rec.Body.String(), `"csrf_token":"`)[1], `"`)[0]
+
+	if rec := do(http.MethodGet, "/api/state", "", nil, cookie); rec.Body.String() != "human" {
+		t.Errorf("cookie read role %q, want human", rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/approve/1", "", nil, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("cookie write without CSRF status %d, want 403", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/approve/1", "", map[string]string{CSRFHeader: "forged"}, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("cookie write with wrong CSRF status %d, want 403", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/approve/1", "", map[string]string{CSRFHeader: csrf, "Origin": "http://attacker.test"}, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("cross-origin cookie write status %d, want 403", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/approve/1", "", map[string]string{CSRFHeader: csrf, "Origin": origin}, cookie); rec.Code != http.StatusOK || rec.Body.String() != "human" {
+		t.Errorf("cookie write with CSRF: status %d role %q", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(http.MethodDelete, "/api/session", "", map[string]string{CSRFHeader: csrf}, cookie); rec.Code != http.StatusNoContent {
+		t.Errorf("logout status %d, want 204", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/approve/1", "", map[string]string{CSRFHeader: csrf}, cookie); rec.Code != http.StatusUnauthorized {
+		t.Errorf("write after logout status %d, want 401", rec.Code)
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	a := newTestAuthenticator(t)
+	interceptor := a.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/ghost.NervousSystem/ApproveAction"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		role, _ := RoleFromContext(ctx)
+		return role, nil
+	}
+
+	call := func(pairs ...string) (Role, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+		resp, err := interceptor(ctx, nil, info, handler)
+		if err != nil {
+			return "", err
+		}
+		return resp.(Role), nil
+	}
+
+	if role, err := call("authorization", "Bearer "+brainToken); err != nil || role != RoleBrain {
+		t.Errorf("brain call = %q, %v", role, err)
+	}
+	if _, err := call(); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("anonymous call error = %v, want Unauthenticated", err)
+	}
+
+	// Only the proxy may name a role; anyone else's header is ignored
+	if role, err := call("authorization", "Bearer "+brainToken, proxiedRoleMetadata, "admin"); err != nil || role != RoleBrain {
+		t.Errorf("brain claiming admin = %q, %v; want brain", role, err)
+	}
+	if role, err := call("authorization", "Bearer "+a.proxyToken, proxiedRoleMetadata, "human"); err != nil || role != RoleHuman {
+		t.Errorf("proxied human = %q, %v", role, err)
+	}
+	if _, err := call("authorization", "Bearer "+a.proxyToken); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("proxy without role error = %v, want Unauthenticated", err)
+	}
+}
+
+func TestProxyMetadataForwardsRole(t *testing.T) {
+	a := newTestAuthenticator(t)
+	req := httptest.NewRequest(http.MethodPost, "/v1/system/mode", nil)
+	req = req.WithContext(WithRole(req.Context(), RoleHuman))
+
+	md := a.ProxyMetadata(context.Background(), req)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	if role, err := a.authenticateGRPC(ctx); err != nil || role != RoleHuman {
+		t.Errorf("proxied role = %q, %v; want human", role, err)
+	}
+	if _, ok := ProxyHeaderMatcher("Grpc-Metadata-X-Ghost-Role"); ok {
+		t.Error("ProxyHeaderMatcher forwarded a client-supplied metadata header")
+	}
+}
+
+func TestStripCredentials(t *testing.T) {
+	var got http.Header
+	handler := StripCredentials(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/v1/approvals", nil)
+	req.Header.Set("Authorization", "Bearer "+humanToken)
+	req.AddCookie(&http.Cookie{Name: SessionCookie, Value: "id"})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.Get("Authorization") != "" || got.Get("Cookie") != "" {
+		t.Errorf("proxied headers still carry credentials: %v", got)
+	}
+	if req.Header.Get("Authorization") == "" {
+		t.Error("StripCredentials modified the caller's request")
+	}
+}
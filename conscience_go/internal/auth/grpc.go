@@ -0,0 +1,115 @@
+// Author: Enkae (enkae.dev@pm.me)
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// authorizationMetadata carries "Bearer <token>" on gRPC calls
+	authorizationMetadata = "authorization"
+	// proxiedRoleMetadata names the HTTP caller's role on calls from the kernel's own REST proxy
+	proxiedRoleMetadata = "x-ghost-role"
+)
+
+// authenticateGRPC resolves the caller's role from incoming metadata
+// The REST proxy authenticates with its own credential and forwards the role the HTTP
+// middleware established; that header is ignored from any other caller.
+func (a *Authenticator) authenticateGRPC(ctx context.Context) (Role, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationMetadata)
+	if len(values) != 1 {
+		return "", ErrUnauthenticated
+	}
+	token, ok := bearerToken(values[0])
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	if a.isProxyToken(token) {
+		roles := md.Get(proxiedRoleMetadata)
+		if len(roles) != 1 {
+			return "", ErrUnauthenticated
+		}
+		return ParseRole(roles[0])
+	}
+	return a.Authenticate(token)
+}
+
+// UnaryServerInterceptor rejects unauthenticated unary calls and records the caller's role
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		role, err := a.authenticateGRPC(ctx)
+		if err != nil {
+			slog.Warn("Rejected unauthenticated gRPC call", "method", info.FullMethod, "peer", peerAddr(ctx))
+			return nil, status.Error(codes.Unauthenticated, "valid credentials required")
+		}
+		return handler(WithRole(ctx, role), req)
+	}
+}
+
+// StreamServerInterceptor rejects unauthenticated streams and records the caller's role
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		role, err := a.authenticateGRPC(ss.Context())
+		if err != nil {
+			slog.Warn("Rejected unauthenticated gRPC stream", "method", info.FullMethod, "peer", peerAddr(ss.Context()))
+			return status.Error(codes.Unauthenticated, "valid credentials required")
+		}
+		return handler(srv, &roleStream{ServerStream: ss, ctx: WithRole(ss.Context(), role)})
+	}
+}
+
+// peerAddr describes the connecting process for logs; on the Unix socket it includes its pid and uid
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+// roleStream overrides a stream's context with one carrying the caller's role
+type roleStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *roleStream) Context() context.Context {
+	return s.ctx
+}
+
+// ProxyMetadata is a grpc-gateway runtime.WithMetadata hook that forwards the HTTP caller's role
+func (a *Authenticator) ProxyMetadata(ctx context.Context, r *http.Request) metadata.MD {
+	md := metadata.Pairs(authorizationMetadata, "Bearer "+a.proxyToken)
+	if role, ok := RoleFromContext(r.Context()); ok {
+		md.Set(proxiedRoleMetadata, string(role))
+	}
+	return md
+}
+
+// StripCredentials removes the HTTP caller's credentials before a request reaches the proxy
+// grpc-gateway forwards Authorization unconditionally; once HTTPMiddleware has resolved the
+// role, ProxyMetadata is the only credential the gRPC server should see.
+func StripCredentials(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		r.Header.Del("Authorization")
+		r.Header.Del("Cookie")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ProxyHeaderMatcher is a grpc-gateway incoming header matcher that forwards no client headers
+// Credentials reach the gRPC server only through ProxyMetadata, so a caller cannot smuggle
+// its own authorization or role metadata via Grpc-Metadata-* headers.
+func ProxyHeaderMatcher(key string) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,234 @@
+// Author: Enkae (enkae.dev@pm.me)
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SessionCookie holds the dashboard session id (HttpOnly, SameSite=Strict)
+	SessionCookie = "ghost_session"
+	// CSRFHeader must echo the session's CSRF token on every mutating cookie-authenticated request
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// LocalHosts returns the Host header values a loopback server on port answers to
+func LocalHosts(port int) []string {
+	p := strconv.Itoa(port)
+	return []string{"localhost:" + p, "127.0.0.1:" + p, "[::1]:" + p}
+}
+
+// HostGuard rejects requests whose Host header is not an expected local name
+// A DNS-rebinding page reaches 127.0.0.1 under its own hostname, which this refuses.
+func HostGuard(allowed []string, next http.Handler) http.Handler {
+	hosts := make(map[string]bool, len(allowed))
+	for _, h := range allowed {
+		hosts[strings.ToLower(h)] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hosts[strings.ToLower(r.Host)] {
+			slog.Warn("Rejected request with unexpected Host header", "host", r.Host, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			http.Error(w, "Misdirected request", http.StatusMisdirectedRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isMutating reports whether a method changes state
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// MutatingRequest is the default protection policy: anything but GET, HEAD and OPTIONS
+func MutatingRequest(r *http.Request) bool {
+	return isMutating(r.Method)
+}
+
+// HTTPMiddleware authenticates bearer tokens and session cookies and puts the role on the context
+// Requests for which protected returns true are rejected unless authenticated. Cookie-authenticated
+// mutating requests must also carry the CSRF token and, if present, an allowed Origin.
+func (a *Authenticator) HTTPMiddleware(allowedOrigins []string, protected func(*http.Request) bool, next http.Handler) http.Handler {
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, o := range allowedOrigins {
+		origins[o] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var role Role
+
+		if header := r.Header.Get("Authorization"); header != "" {
+			token, ok := bearerToken(header)
+			if !ok {
+				unauthorized(w, r, "malformed Authorization header")
+				return
+			}
+			authenticated, err := a.Authenticate(token)
+			if err != nil {
+				unauthorized(w, r, "invalid bearer token")
+				return
+			}
+			role = authenticated
+		} else if cookie, err := r.Cookie(SessionCookie); err == nil {
+			if s, ok := a.lookupSession(cookie.Value); ok {
+				if isMutating(r.Method) {
+					if origin := r.Header.Get("Origin"); origin != "" && !origins[origin] {
+						forbidden(w, r, "cross-origin request")
+						return
+					}
+					if subtle.ConstantTimeCompare([]byte(r.Header.Get(CSRFHeader)), []byte(s.csrfToken)) != 1 {
+						forbidden(w, r, "missing or invalid CSRF token")
+						return
+					}
+				}
+				role = s.role
+			}
+		}
+
+		if role == "" && protected(r) {
+			unauthorized(w, r, "no credentials")
+			return
+		}
+		if role != "" {
+			r = r.WithContext(WithRole(r.Context(), role))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SessionResponse is returned by /api/session
+type SessionResponse struct {
+	Role      Role      `json:"role"`
+	CSRFToken string    `json:"csrf_token"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// SessionHandler serves /api/session for the dashboard
+// POST exchanges a human or admin token (bearer or {"token": "..."}) for a session cookie,
+// GET returns the current session's CSRF token, DELETE logs out.
+func (a *Authenticator) SessionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			a.login(w, r)
+		case http.MethodGet:
+			a.currentSession(w, r)
+		case http.MethodDelete:
+			a.logout(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// login handles POST /api/session
+func (a *Authenticator) login(w http.ResponseWriter, r *http.Request) {
+	role, ok := RoleFromContext(r.Context())
+	if !ok || r.Header.Get("Authorization") == "" {
+		var body struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil || body.Token == "" {
+			http.Error(w, "Token required", http.StatusBadRequest)
+			return
+		}
+		authenticated, err := a.Authenticate(body.Token)
+		if err != nil {
+			unauthorized(w, r, "invalid login token")
+			return
+		}
+		role = authenticated
+	}
+
+	// Sessions are for people; services keep using their bearer tokens
+	if role != RoleHuman && role != RoleAdmin {
+		forbidden(w, r, "role cannot open a dashboard session")
+		return
+	}
+
+	id, csrf, err := a.createSession(role)
+	if err != nil {
+		slog.Error("Failed to create session", "error", err)
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(a.sessionTTL / time.Second),
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	slog.Info("Dashboard session created", "role", role, "remote_addr", r.RemoteAddr)
+
+	writeJSON(w, http.StatusCreated, SessionResponse{Role: role, CSRFToken: csrf, ExpiresAt: a.now().Add(a.sessionTTL)})
+}
+
+// currentSession handles GET /api/session so a reloaded dashboard can recover its CSRF token
+func (a *Authenticator) currentSession(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		unauthorized(w, r, "no session")
+		return
+	}
+	s, ok := a.lookupSession(cookie.Value)
+	if !ok {
+		unauthorized(w, r, "session expired")
+		return
+	}
+	writeJSON(w, http.StatusOK, SessionResponse{Role: s.role, CSRFToken: s.csrfToken, ExpiresAt: s.expiresAt})
+}
+
+// logout handles DELETE /api/session; the middleware has already checked the CSRF token
+func (a *Authenticator) logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		a.endSession(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, reason string) {
+	slog.Warn("Rejected unauthenticated request", "method", r.Method, "path", r.URL.Path, "reason", reason, "remote_addr", r.RemoteAddr)
+	w.Header().Set("WWW-Authenticate", `Bearer realm="ghost"`)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+func forbidden(w http.ResponseWriter, r *http.Request, reason string) {
+	slog.Warn("Rejected request", "method", r.Method, "path", r.URL.Path, "reason", reason, "remote_addr", r.RemoteAddr)
+	http.Error(w, "Forbidden", http.StatusForbidden)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,105 @@
+// Author: Enkae (enkae.dev@pm.me)
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	pb "ghost/kernel/internal/protocol"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Policy maps a full gRPC method name to the roles allowed to call it
+// Methods missing from the table are denied to everyone.
+type Policy map[string][]Role
+
+// NervousSystemPolicy separates what the Brain, Body and Human may do
+// The Brain proposes, the Body senses and executes, and only a person approves or changes modes.
+var NervousSystemPolicy = Policy{
+	pb.NervousSystem_ReportFocus_FullMethodName:         {RoleBody},
+	pb.NervousSystem_StreamActions_FullMethodName:       {RoleBody},
+	pb.NervousSystem_ReportOutcome_FullMethodName:       {RoleBody},
+	pb.NervousSystem_RequestPermission_FullMethodName:   {RoleBrain},
+	pb.NervousSystem_GetPendingApprovals_FullMethodName: {RoleHuman, RoleAdmin},
+	pb.NervousSystem_ApproveAction_FullMethodName:       {RoleHuman, RoleAdmin},
+	pb.NervousSystem_SetSystemMode_FullMethodName:       {RoleHuman, RoleAdmin},
+	pb.NervousSystem_GetSystemState_FullMethodName:      {RoleBrain, RoleBody, RoleHuman, RoleAdmin},
+	// Anyone may pull the kill switch; only a person may release it
+	pb.NervousSystem_EmergencyStop_FullMethodName: {RoleBrain, RoleBody, RoleHuman, RoleAdmin},
+	pb.NervousSystem_Rearm_FullMethodName:         {RoleHuman, RoleAdmin},
+	// The planner may abandon its goal; the Body only executes
+	pb.NervousSystem_CancelGoal_FullMethodName: {RoleBrain, RoleHuman, RoleAdmin},
+	// Only a person hands out or takes back standing approvals
+	pb.NervousSystem_CreateGrant_FullMethodName: {RoleHuman, RoleAdmin},
+	pb.NervousSystem_ListGrants_FullMethodName:  {RoleHuman, RoleAdmin},
+	pb.NervousSystem_RevokeGrant_FullMethodName: {RoleHuman, RoleAdmin},
+}
+
+// Allows reports whether role may call method
+func (p Policy) Allows(method string, role Role) bool {
+	for _, allowed := range p[method] {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize checks the role the authentication interceptor put on ctx
+func (p Policy) authorize(ctx context.Context, method string) error {
+	role, ok := RoleFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "valid credentials required")
+	}
+	if !p.Allows(method, role) {
+		slog.Warn("Denied gRPC call", "method", method, "role", role, "peer", peerAddr(ctx))
+		return status.Errorf(codes.PermissionDenied, "role %q may not call %s", role, method)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor enforces the policy; chain it after the Authenticator's interceptor
+func (p Policy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := p.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor enforces the policy; chain it after the Authenticator's interceptor
+func (p Policy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := p.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// RestrictWrites limits mutating requests to the given roles and leaves reads to the caller's policy
+// It guards the REST routes that mirror a restricted RPC, such as approvals and mode changes.
+func RestrictWrites(next http.Handler, roles ...Role) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isMutating(r.Method) {
+			role, _ := RoleFromContext(r.Context())
+			allowed := false
+			for _, candidate := range roles {
+				if role == candidate {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				forbidden(w, r, "role "+string(role)+" may not call this endpoint")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
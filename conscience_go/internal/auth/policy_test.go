@@ -0,0 +1,96 @@
+// Author: Enkae (enkae.dev@pm.me)
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "ghost/kernel/internal/protocol"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNervousSystemPolicy(t *testing.T) {
+	interceptor := NervousSystemPolicy.UnaryServerInterceptor()
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	tests := []struct {
+		method string
+		role   Role
+		want   codes.Code
+	}{
+		// The Brain must not approve its own proposals or switch to AUTO
+		{pb.NervousSystem_ApproveAction_FullMethodName, RoleBrain, codes.PermissionDenied},
+		{pb.NervousSystem_SetSystemMode_FullMethodName, RoleBrain, codes.PermissionDenied},
+		{pb.NervousSystem_ApproveAction_FullMethodName, RoleBody, codes.PermissionDenied},
+		{pb.NervousSystem_ApproveAction_FullMethodName, RoleHuman, codes.OK},
+		{pb.NervousSystem_SetSystemMode_FullMethodName, RoleAdmin, codes.OK},
+		{pb.NervousSystem_RequestPermission_FullMethodName, RoleBrain, codes.OK},
+		{pb.NervousSystem_RequestPermission_FullMethodName, RoleHuman, codes.PermissionDenied},
+		{pb.NervousSystem_GetSystemState_FullMethodName, RoleBody, codes.OK},
+		// Any organ may stop, but the Brain cannot undo a stop
+		{pb.NervousSystem_EmergencyStop_FullMethodName, RoleBrain, codes.OK},
+		{pb.NervousSystem_Rearm_FullMethodName, RoleBrain, codes.PermissionDenied},
+		{pb.NervousSystem_Rearm_FullMethodName, RoleHuman, codes.OK},
+		{pb.NervousSystem_CancelGoal_FullMethodName, RoleBrain, codes.OK},
+		{pb.NervousSystem_CancelGoal_FullMethodName, RoleBody, codes.PermissionDenied},
+		// The Brain cannot grant itself standing approvals
+		{pb.NervousSystem_CreateGrant_FullMethodName, RoleBrain, codes.PermissionDenied},
+		{pb.NervousSystem_CreateGrant_FullMethodName, RoleHuman, codes.OK},
+		{"/ghost.NervousSystem/Unlisted", RoleAdmin, codes.PermissionDenied},
+		{pb.NervousSystem_GetSystemState_FullMethodName, "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.role != "" {
+			ctx = WithRole(ctx, tt.role)
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, ok)
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%s as %q: code %v, want %v", tt.method, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestNervousSystemPolicyStreams(t *testing.T) {
+	interceptor := NervousSystemPolicy.StreamServerInterceptor()
+	ok := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+	info := &grpc.StreamServerInfo{FullMethod: pb.NervousSystem_StreamActions_FullMethodName}
+
+	if err := interceptor(nil, &roleStream{ctx: WithRole(context.Background(), RoleBody)}, info, ok); err != nil {
+		t.Errorf("body StreamActions error = %v", err)
+	}
+	err := interceptor(nil, &roleStream{ctx: WithRole(context.Background(), RoleBrain)}, info, ok)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("brain StreamActions error = %v, want PermissionDenied", err)
+	}
+}
+
+func TestRestrictWrites(t *testing.T) {
+	handler := RestrictWrites(echoRole, RoleHuman, RoleAdmin)
+
+	for _, tt := range []struct {
+		method string
+		role   Role
+		want   int
+	}{
+		{http.MethodPost, RoleHuman, http.StatusOK},
+		{http.MethodPost, RoleBrain, http.StatusForbidden},
+		{http.MethodPost, "", http.StatusForbidden},
+		{http.MethodGet, RoleBrain, http.StatusOK},
+	} {
+		req := httptest.NewRequest(tt.method, "/api/modes", nil)
+		if tt.role != "" {
+			req = req.WithContext(WithRole(req.Context(), tt.role))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s as %q: status %d, want %d", tt.method, tt.role, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+// Author: Enkae (enkae.dev@pm.me)
+// Package config holds the kernel's typed configuration.
+// Values are resolved in increasing precedence: built-in defaults, a JSON config file,
+// GHOST_* environment variables, then command-line flags.
+package config
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Masked replaces secret values in printed configuration
+const Masked = "********"
+
+// approverID is the form of an approval client ID, which also names its token file
+var approverID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Config is the effective kernel configuration
+type Config struct {
+	Database  DatabaseConfig  `json:"database"`
+	HTTP      HTTPConfig      `json:"http"`
+	GRPC      GRPCConfig      `json:"grpc"`
+	Gateway   GatewayConfig   `json:"gateway"`
+	Auth      AuthConfig      `json:"auth"`
+	Approval  ApprovalConfig  `json:"approval"`
+	Safety    SafetyConfig    `json:"safety"`
+	Limits    LimitsConfig    `json:"limits"`
+	Retention RetentionConfig `json:"retention"`
+	Tracing   TracingConfig   `json:"tracing"`
+	Scheduler SchedulerConfig `json:"scheduler"`
+	Lifecycle LifecycleConfig `json:"lifecycle"`
+}
+
+// DatabaseConfig locates kernel.db and its encryption key
+type DatabaseConfig struct {
+	Path       string `json:"path"`
+	KeyFile    string `json:"key_file"`
+	Passphrase string `json:"passphrase"`
+	BackupDir  string `json:"backup_dir"`
+}
+
+// HTTPConfig configures the REST API, gRPC-gateway proxy and dashboard server
+type HTTPConfig struct {
+	Port         int      `json:"port"`
+	StaticDir    string   `json:"static_dir"`
+	CORSOrigins  []string `json:"cors_origins"`  // Empty allows the dashboard's own origin only
+	AllowedHosts []string `json:"allowed_hosts"` // Host headers accepted besides localhost, 127.0.0.1 and [::1]
+	AdminToken   string   `json:"admin_token"`   // Admin role; guards backup/export/import; empty disables them
+}
+
+// GRPCConfig configures the Body/Brain gRPC server
+type GRPCConfig struct {
+	Port   int    `json:"port"`
+	Socket string `json:"socket"`  // Serve on this Unix socket (owner-only) instead of 127.0.0.1:Port
+	TLS    bool   `json:"tls"`     // Require mutual TLS with certificates from the local CA in TLSDir
+	TLSDir string `json:"tls_dir"` // CA, server and client certificates, generated on first start
+}
+
+// GatewayConfig configures the JSON-RPC gateway
+type GatewayConfig struct {
+	Port      int    `json:"port"`
+	Token     string `json:"token"`      // Empty generates one into TokenFile
+	TokenFile string `json:"token_file"` // Where a generated token is kept between runs
+}
+
+// AuthConfig holds the per-role credentials for the REST and gRPC control plane
+// An empty token is generated into TokenDir/<role>.token on first start.
+type AuthConfig struct {
+	BrainToken string   `json:"brain_token"`
+	BodyToken  string   `json:"body_token"`
+	HumanToken string   `json:"human_token"`
+	TokenDir   string   `json:"token_dir"`
+	SessionTTL Duration `json:"session_ttl"` // Dashboard cookie sessions
+}
+
+// ApprovalConfig sets the second factor that lets a high-risk exec.request past the risk rule
+// Without a TOTP secret or approval clients such a request is always refused.
+type ApprovalConfig struct {
+	TOTPSecret string   `json:"totp_secret"` // Base32 secret shared with the user's authenticator app; empty disables codes
+	Clients    []string `json:"clients"`     // Gateway client IDs that confirm as themselves, e.g. "phone"; each connects with auth.token_dir/approver-<id>.token
+	KeyFile    string   `json:"key_file"`    // HMAC key that signs approvals, generated on first start
+	TTL        Duration `json:"ttl"`         // How long a refused request can be confirmed, and its approval used
+}
+
+// SafetyConfig configures the intent and action safety checks and the approval threshold
+// It is the policy "ghost policy replay" evaluates a candidate file's version of.
+type SafetyConfig struct {
+	SafeMode         bool     `json:"safe_mode"`
+	BlockedKeywords  []string `json:"blocked_keywords"`   // Intents containing one are denied
+	AllowedActions   []string `json:"allowed_actions"`    // Action types the Brain and gateway may request
+	AutoApproveBelow int      `json:"auto_approve_below"` // Proposals under this risk score run without asking in AUTO mode
+}
+
+// LimitsConfig rate-limits permission requests and proposals, per minute with a burst allowance
+// A zero rate disables that limit; a zero breaker threshold disables the circuit breaker.
+type LimitsConfig struct {
+	ClientPerMinute  int      `json:"client_per_minute"`
+	ClientBurst      int      `json:"client_burst"`
+	DomainPerMinute  int      `json:"domain_per_minute"`
+	DomainBurst      int      `json:"domain_burst"`
+	IntentPerMinute  int      `json:"intent_per_minute"`
+	IntentBurst      int      `json:"intent_burst"`
+	BreakerThreshold int      `json:"breaker_threshold"` // Denials within breaker_window that switch to MANUAL/SHADOW
+	BreakerWindow    Duration `json:"breaker_window"`
+	BreakerCooldown  Duration `json:"breaker_cooldown"` // How long a tripped source is refused outright
+}
+
+// RetentionConfig sets how long captured data is kept and how often it is compacted
+// A zero window keeps rows forever; a zero interval disables the job.
+type RetentionConfig struct {
+	Artifacts       Duration `json:"artifacts"`
+	IntentHistory   Duration `json:"intent_history"`
+	ActionProposals Duration `json:"action_proposals"`
+	Goals           Duration `json:"goals"`
+	Commands        Duration `json:"commands"`
+	TraceEvents     Duration `json:"trace_events"`
+	CompactInterval Duration `json:"compact_interval"`
+	VacuumInterval  Duration `json:"vacuum_interval"`
+}
+
+// TracingConfig configures OpenTelemetry span export
+type TracingConfig struct {
+	Endpoint string `json:"endpoint"` // OTLP/HTTP collector URL; empty records lifecycles locally only
+}
+
+// SchedulerConfig sets how often scheduled goals are checked; zero disables scheduling
+type SchedulerConfig struct {
+	Interval Duration `json:"interval"`
+}
+
+// LifecycleConfig sets how long the kernel waits for its servers and jobs to stop
+type LifecycleConfig struct {
+	ShutdownTimeout Duration `json:"shutdown_timeout"` // Components still running after this are abandoned
+}
+
+// Duration is a time.Duration written as "720h" in config files
+type Duration time.Duration
+
+// MarshalJSON writes the duration in Go syntax
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON accepts "720h"-style strings
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"720h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the settings the kernel uses when nothing is configured
+func Default() Config {
+	return Config{
+		Database: DatabaseConfig{
+			Path:      "data/kernel.db",
+			BackupDir: "data/backups",
+		},
+		HTTP: HTTPConfig{
+			Port:      8080,
+			StaticDir: "./static",
+		},
+		GRPC: GRPCConfig{
+			Port:   50051,
+			TLSDir: "data/tls",
+		},
+		Gateway: GatewayConfig{
+			Port:      18789,
+			TokenFile: "data/gateway.token",
+		},
+		Auth: AuthConfig{
+			TokenDir:   "data/tokens",
+			SessionTTL: Duration(12 * time.Hour),
+		},
+		Approval: ApprovalConfig{
+			KeyFile: "data/approval.key",
+			TTL:     Duration(5 * time.Minute),
+		},
+		Safety: SafetyConfig{
+			SafeMode:         true,
+			BlockedKeywords:  []string{"delete", "rm ", "format ", "shutdown", "reboot", "sudo"},
+			AllowedActions:   []string{"CLICK", "EDIT", "KEY", "LIST", "MEMORIZE", "READ", "SCAN", "SEARCH", "SPEAK", "TYPE", "WAIT", "WRITE"},
+			AutoApproveBelow: 30,
+		},
+		Limits: LimitsConfig{
+			ClientPerMinute:  120,
+			ClientBurst:      20,
+			DomainPerMinute:  60,
+			DomainBurst:      10,
+			IntentPerMinute:  20,
+			IntentBurst:      5,
+			BreakerThreshold: 20,
+			BreakerWindow:    Duration(time.Minute),
+			BreakerCooldown:  Duration(5 * time.Minute),
+		},
+		Retention: RetentionConfig{
+			Artifacts:       Duration(7 * 24 * time.Hour),
+			IntentHistory:   Duration(180 * 24 * time.Hour),
+			ActionProposals: Duration(30 * 24 * time.Hour),
+			Goals:           Duration(30 * 24 * time.Hour),
+			Commands:        Duration(7 * 24 * time.Hour),
+			TraceEvents:     Duration(7 * 24 * time.Hour),
+			CompactInterval: Duration(time.Hour),
+			VacuumInterval:  Duration(24 * time.Hour),
+		},
+		Scheduler: SchedulerConfig{
+			Interval: Duration(15 * time.Second),
+		},
+		Lifecycle: LifecycleConfig{
+			ShutdownTimeout: Duration(20 * time.Second),
+		},
+	}
+}
+
+// AllowedOrigins returns the CORS origins, defaulting to the dashboard served on HTTP.Port
+func (c *Config) AllowedOrigins() []string {
+	if len(c.HTTP.CORSOrigins) > 0 {
+		return c.HTTP.CORSOrigins
+	}
+	return []string{
+		fmt.Sprintf("http://localhost:%d", c.HTTP.Port),
+		fmt.Sprintf("http://127.0.0.1:%d", c.HTTP.Port),
+	}
+}
+
+// Validate reports every invalid setting at once, naming each by its config key
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if strings.TrimSpace(c.Database.Path) == "" {
+		invalid("database.path", "must not be empty")
+	}
+	if strings.TrimSpace(c.Database.BackupDir) == "" {
+		invalid("database.backup_dir", "must not be empty")
+	}
+	if strings.TrimSpace(c.HTTP.StaticDir) == "" {
+		invalid("http.static_dir", "must not be empty")
+	}
+	if strings.TrimSpace(c.Gateway.TokenFile) == "" && c.Gateway.Token == "" {
+		invalid("gateway.token_file", "must be set when gateway.token is empty")
+	}
+	if c.Gateway.Token != "" && len(c.Gateway.Token) < 16 {
+		invalid("gateway.token", "must be at least 16 characters")
+	}
+	if c.GRPC.TLS && strings.TrimSpace(c.GRPC.TLSDir) == "" {
+		invalid("grpc.tls_dir", "must be set when grpc.tls is enabled")
+	}
+	// sun_path holds 104 bytes on macOS and 108 on Linux, including the terminator
+	if len(c.GRPC.Socket) >= 104 {
+		invalid("grpc.socket", "path is too long for a Unix socket (%d bytes, max 103)", len(c.GRPC.Socket))
+	}
+	if strings.TrimSpace(c.Auth.TokenDir) == "" {
+		invalid("auth.token_dir", "must not be empty")
+	}
+	if c.Auth.SessionTTL <= 0 {
+		invalid("auth.session_ttl", "must be positive (got %s)", time.Duration(c.Auth.SessionTTL))
+	}
+	if c.Lifecycle.ShutdownTimeout <= 0 {
+		invalid("lifecycle.shutdown_timeout", "must be positive (got %s)", time.Duration(c.Lifecycle.ShutdownTimeout))
+	}
+
+	// Role tokens must be long and distinct, or one role could pass as another
+	roleTokens := []struct{ key, token string }{
+		{"auth.brain_token", c.Auth.BrainToken},
+		{"auth.body_token", c.Auth.BodyToken},
+		{"auth.human_token", c.Auth.HumanToken},
+		{"http.admin_token", c.HTTP.AdminToken},
+	}
+	usedBy := make(map[string]string)
+	for _, rt := range roleTokens {
+		if rt.token == "" {
+			continue
+		}
+		if len(rt.token) < 16 {
+			invalid(rt.key, "must be at least 16 characters")
+			continue
+		}
+		if other, ok := usedBy[rt.token]; ok {
+			invalid(rt.key, "must differ from %s", other)
+			continue
+		}
+		usedBy[rt.token] = rt.key
+	}
+
+	if err := checkTOTPSecret(c.Approval.TOTPSecret); err != nil {
+		invalid("approval.totp_secret", "%v", err)
+	}
+	for _, id := range c.Approval.Clients {
+		if !approverID.MatchString(id) {
+			invalid("approval.clients", "%q must be letters, digits, '-' or '_'", id)
+		}
+	}
+	if strings.TrimSpace(c.Approval.KeyFile) == "" {
+		invalid("approval.key_file", "must not be empty")
+	}
+	if c.Approval.TTL <= 0 {
+		invalid("approval.ttl", "must be positive (got %s)", time.Duration(c.Approval.TTL))
+	}
+
+	for _, host := range c.HTTP.AllowedHosts {
+		if strings.TrimSpace(host) == "" || strings.ContainsAny(host, "/ ") {
+			invalid("http.allowed_hosts", "%q is not a host like ghost.local:8080", host)
+		}
+	}
+
+	ports := map[string]int{"http.port": c.HTTP.Port, "grpc.port": c.GRPC.Port, "gateway.port": c.Gateway.Port}
+	seen := make(map[int]string)
+	for _, key := range []string{"http.port", "grpc.port", "gateway.port"} {
+		port := ports[key]
+		if port < 1 || port > 65535 {
+			invalid(key, "must be between 1 and 65535 (got %d)", port)
+			continue
+		}
+		if other, ok := seen[port]; ok {
+			invalid(key, "port %d is already used by %s", port, other)
+			continue
+		}
+		seen[port] = key
+	}
+
+	for _, origin := range c.HTTP.CORSOrigins {
+		if origin == "*" {
+			invalid("http.cors_origins", "wildcard origins are not allowed")
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			invalid("http.cors_origins", "%q is not an origin like http://localhost:5173", origin)
+		}
+	}
+
+	if c.Tracing.Endpoint != "" {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("tracing.endpoint", "%q is not a collector URL like http://localhost:4318", c.Tracing.Endpoint)
+		}
+	}
+
+	if c.Safety.AutoApproveBelow < 0 || c.Safety.AutoApproveBelow > 101 {
+		invalid("safety.auto_approve_below", "must be between 0 (never) and 101 (always) (got %d)", c.Safety.AutoApproveBelow)
+	}
+	for _, keyword := range c.Safety.BlockedKeywords {
+		if strings.TrimSpace(keyword) == "" {
+			invalid("safety.blocked_keywords", "must not contain empty keywords")
+			break
+		}
+	}
+
+	limits := map[string]int{
+		"limits.client_per_minute": c.Limits.ClientPerMinute,
+		"limits.client_burst":      c.Limits.ClientBurst,
+		"limits.domain_per_minute": c.Limits.DomainPerMinute,
+		"limits.domain_burst":      c.Limits.DomainBurst,
+		"limits.intent_per_minute": c.Limits.IntentPerMinute,
+		"limits.intent_burst":      c.Limits.IntentBurst,
+		"limits.breaker_threshold": c.Limits.BreakerThreshold,
+	}
+	for _, s := range settings {
+		if n, ok := limits[s.key]; ok && n < 0 {
+			invalid(s.key, "must not be negative (got %d)", n)
+		}
+	}
+	if c.Limits.BreakerThreshold > 0 {
+		if c.Limits.BreakerWindow <= 0 {
+			invalid("limits.breaker_window", "must be positive when limits.breaker_threshold is set")
+		}
+		if c.Limits.BreakerCooldown < 0 {
+			invalid("limits.breaker_cooldown", "must not be negative (got %s)", time.Duration(c.Limits.BreakerCooldown))
+		}
+	}
+
+	durations := map[string]Duration{
+		"retention.artifacts":        c.Retention.Artifacts,
+		"retention.intent_history":   c.Retention.IntentHistory,
+		"retention.action_proposals": c.Retention.ActionProposals,
+		"retention.goals":            c.Retention.Goals,
+		"retention.commands":         c.Retention.Commands,
+		"retention.trace_events":     c.Retention.TraceEvents,
+		"retention.compact_interval": c.Retention.CompactInterval,
+		"retention.vacuum_interval":  c.Retention.VacuumInterval,
+		"scheduler.interval":         c.Scheduler.Interval,
+	}
+	for _, s := range settings {
+		if d, ok := durations[s.key]; ok && d < 0 {
+			invalid(s.key, "must not be negative (got %s)", time.Duration(d))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkTOTPSecret reports a secret conscience.DecodeTOTPSecret would reject, so the kernel fails at load
+func checkTOTPSecret(secret string) error {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	if secret == "" {
+		return nil
+	}
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return fmt.Errorf("totp secret must be base32: %w", err)
+	}
+	if len(decoded) < 10 {
+		return fmt.Errorf("totp secret must be at least 80 bits")
+	}
+	return nil
+}
+
+// Redacted returns a copy with every secret replaced by Masked
+func (c Config) Redacted() Config {
+	for _, s := range settings {
+		if !s.secret {
+			continue
+		}
+		if p := s.value(&c).(*string); *p != "" {
+			*p = Masked
+		}
+	}
+	// Slices are shared with the original; nothing secret lives in one
+	return c
+}
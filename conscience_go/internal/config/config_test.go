@@ -0,0 +1,160 @@
+// Author: Enkae (enkae.dev@pm.me)
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLoader parses args against a fresh flag set with a fake environment
+func newTestLoader(t *testing.T, env map[string]string, args ...string) *Loader {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	l := NewLoader(fs)
+	l.getenv = func(key string) string { return env[key] }
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse(%v) error = %v", args, err)
+	}
+	return l
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ghost.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"http": {"port": 9000, "static_dir": "/srv/dashboard"},
+		"grpc": {"port": 9001},
+		"gateway": {"port": 9002},
+		"retention": {"artifacts": "48h"}
+	}`)
+	env := map[string]string{
+		"GHOST_CONFIG":             path,
+		"GHOST_GRPC_PORT":          "9101",
+		"GHOST_HTTP_PORT":          "9100",
+		"GHOST_AUTO_APPROVE_BELOW": "15",
+		"GHOST_SHUTDOWN_TIMEOUT":   "45s",
+	}
+
+	cfg, err := newTestLoader(t, env, "-http-port", "9200", "-safe-mode=false").Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"flag beats env and file", cfg.HTTP.Port, 9200},
+		{"env beats file", cfg.GRPC.Port, 9101},
+		{"file beats default", cfg.Gateway.Port, 9002},
+		{"file beats default (string)", cfg.HTTP.StaticDir, "/srv/dashboard"},
+		{"file duration", time.Duration(cfg.Retention.Artifacts), 48 * time.Hour},
+		{"bool flag", cfg.Safety.SafeMode, false},
+		{"env int", cfg.Safety.AutoApproveBelow, 15},
+		{"env duration", time.Duration(cfg.Lifecycle.ShutdownTimeout), 45 * time.Second},
+		{"default allowlist", slices.Contains(cfg.Safety.AllowedActions, "TYPE"), true},
+		{"default kept", cfg.Database.Path, "data/kernel.db"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestLoadValidationErrors(t *testing.T) {
+	env := map[string]string{
+		"GHOST_GATEWAY_TOKEN": "short",
+		"GHOST_CORS_ORIGINS":  "http://localhost:5173, *",
+	}
+	longSocket := "/run/" + strings.Repeat("ghost/", 20) + "kernel.sock"
+	_, err := newTestLoader(t, env, "-http-port", "50051", "-retention-goals", "-1h", "-db", "",
+		"-grpc-tls", "-grpc-tls-dir", "", "-grpc-socket", longSocket, "-shutdown-timeout", "0s").Load()
+	if err == nil {
+		t.Fatal("Load() accepted an invalid configuration")
+	}
+
+	for _, want := range []string{
+		"database.path: must not be empty",
+		"grpc.port: port 50051 is already used by http.port",
+		"gateway.token: must be at least 16 characters",
+		"http.cors_origins: wildcard origins are not allowed",
+		"retention.goals: must not be negative",
+		"grpc.tls_dir: must be set when grpc.tls is enabled",
+		"grpc.socket: path is too long",
+		"lifecycle.shutdown_timeout: must be positive",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "localhost:5173") {
+		t.Errorf("valid origin reported as invalid: %v", err)
+	}
+}
+
+func TestLoadRejectsBadInput(t *testing.T) {
+	unknown := writeConfigFile(t, `{"http": {"prot": 9000}}`)
+	if _, err := newTestLoader(t, map[string]string{"GHOST_CONFIG": unknown}).Load(); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Errorf("Load() with unknown key error = %v", err)
+	}
+
+	if _, err := newTestLoader(t, map[string]string{"GHOST_HTTP_PORT": "eighty"}).Load(); err == nil || !strings.Contains(err.Error(), "GHOST_HTTP_PORT") {
+		t.Errorf("Load() with bad env error = %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	NewLoader(fs)
+	if err := fs.Parse([]string{"-compact-interval", "hourly"}); err == nil {
+		t.Error("Parse() accepted an invalid duration flag")
+	}
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	env := map[string]string{
+		"GHOST_DB_PASSPHRASE": "correct horse battery staple",
+		"GHOST_ADMIN_TOKEN":   "admin-token-0123456789",
+		"GHOST_GATEWAY_TOKEN": "gateway-token-0123456789",
+	}
+	cfg, err := newTestLoader(t, env).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, cfg); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	for _, secret := range env {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Print() leaked %q", secret)
+		}
+	}
+	if !strings.Contains(out.String(), `"passphrase": "`+Masked+`"`) {
+		t.Errorf("Print() did not mask the passphrase:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), `"compact_interval": "1h0m0s"`) {
+		t.Errorf("Print() did not render durations:\n%s", out.String())
+	}
+
+	// Masking works on a copy
+	if cfg.HTTP.AdminToken != env["GHOST_ADMIN_TOKEN"] {
+		t.Error("Print() modified the loaded config")
+	}
+}
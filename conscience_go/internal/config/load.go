@@ -0,0 +1,254 @@
+// Author: Enkae (enkae.dev@pm.me)
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting describes one configurable value and where it can come from
+type setting struct {
+	key    string // Dotted path in the config file, e.g. "http.port"
+	env    string // Environment variable, empty if none
+	flag   string // Command-line flag, empty if none (secrets never go on the command line)
+	usage  string
+	secret bool
+	value  func(c *Config) interface{} // Pointer to the field
+}
+
+// settings lists every value that env vars and flags can override
+var settings = []setting{
+	{"database.path", "GHOST_DB_PATH", "db", "Path to the kernel database", false, func(c *Config) interface{} { return &c.Database.Path }},
+	{"database.key_file", "GHOST_DB_KEY_FILE", "db-key-file", "Key file used to encrypt sensitive database columns", false, func(c *Config) interface{} { return &c.Database.KeyFile }},
+	{"database.passphrase", "GHOST_DB_PASSPHRASE", "", "Passphrase used to encrypt sensitive database columns", true, func(c *Config) interface{} { return &c.Database.Passphrase }},
+	{"database.backup_dir", "GHOST_BACKUP_DIR", "backup-dir", "Directory for snapshots taken through /api/backup", false, func(c *Config) interface{} { return &c.Database.BackupDir }},
+	{"http.port", "GHOST_HTTP_PORT", "http-port", "HTTP gateway port", false, func(c *Config) interface{} { return &c.HTTP.Port }},
+	{"http.static_dir", "GHOST_STATIC_DIR", "static-dir", "Dashboard build output served at /", false, func(c *Config) interface{} { return &c.HTTP.StaticDir }},
+	{"http.cors_origins", "GHOST_CORS_ORIGINS", "cors-origins", "Comma-separated origins allowed to call the API; empty allows the dashboard's own", false, func(c *Config) interface{} { return &c.HTTP.CORSOrigins }},
+	{"http.allowed_hosts", "GHOST_ALLOWED_HOSTS", "allowed-hosts", "Comma-separated extra Host headers to accept (localhost, 127.0.0.1 and [::1] always are)", false, func(c *Config) interface{} { return &c.HTTP.AllowedHosts }},
+	{"http.admin_token", "GHOST_ADMIN_TOKEN", "", "Bearer token for backup/export/import", true, func(c *Config) interface{} { return &c.HTTP.AdminToken }},
+	{"grpc.port", "GHOST_GRPC_PORT", "grpc-port", "gRPC server port", false, func(c *Config) interface{} { return &c.GRPC.Port }},
+	{"grpc.socket", "GHOST_GRPC_SOCKET", "grpc-socket", "Serve gRPC on this Unix socket instead of TCP", false, func(c *Config) interface{} { return &c.GRPC.Socket }},
+	{"grpc.tls", "GHOST_GRPC_TLS", "grpc-tls", "Require mutual TLS on the gRPC server", false, func(c *Config) interface{} { return &c.GRPC.TLS }},
+	{"grpc.tls_dir", "GHOST_GRPC_TLS_DIR", "grpc-tls-dir", "Where the local CA and gRPC certificates are kept", false, func(c *Config) interface{} { return &c.GRPC.TLSDir }},
+	{"gateway.port", "GHOST_GATEWAY_PORT", "gateway-port", "JSON-RPC gateway port", false, func(c *Config) interface{} { return &c.Gateway.Port }},
+	{"gateway.token", "GHOST_GATEWAY_TOKEN", "", "Gateway auth token (generated into gateway.token_file when empty)", true, func(c *Config) interface{} { return &c.Gateway.Token }},
+	{"gateway.token_file", "GHOST_GATEWAY_TOKEN_FILE", "gateway-token-file", "Where a generated gateway token is kept", false, func(c *Config) interface{} { return &c.Gateway.TokenFile }},
+	{"auth.brain_token", "GHOST_BRAIN_TOKEN", "", "Bearer token for the Brain", true, func(c *Config) interface{} { return &c.Auth.BrainToken }},
+	{"auth.body_token", "GHOST_BODY_TOKEN", "", "Bearer token for the Body (Sentinel)", true, func(c *Config) interface{} { return &c.Auth.BodyToken }},
+	{"auth.human_token", "GHOST_HUMAN_TOKEN", "", "Bearer token for the user; also exchanged for dashboard sessions", true, func(c *Config) interface{} { return &c.Auth.HumanToken }},
+	{"auth.token_dir", "GHOST_TOKEN_DIR", "token-dir", "Where generated role tokens are kept", false, func(c *Config) interface{} { return &c.Auth.TokenDir }},
+	{"auth.session_ttl", "GHOST_SESSION_TTL", "session-ttl", "How long an idle dashboard session lasts", false, func(c *Config) interface{} { return &c.Auth.SessionTTL }},
+	{"approval.totp_secret", "GHOST_APPROVAL_TOTP_SECRET", "", "Base32 TOTP secret whose codes confirm high-risk exec requests", true, func(c *Config) interface{} { return &c.Approval.TOTPSecret }},
+	{"approval.clients", "GHOST_APPROVAL_CLIENTS", "approval-clients", "Comma-separated gateway client IDs that may confirm high-risk exec requests", false, func(c *Config) interface{} { return &c.Approval.Clients }},
+	{"approval.key_file", "GHOST_APPROVAL_KEY_FILE", "approval-key-file", "Key that signs second-factor approvals, generated when missing", false, func(c *Config) interface{} { return &c.Approval.KeyFile }},
+	{"approval.ttl", "GHOST_APPROVAL_TTL", "approval-ttl", "How long a high-risk request can be confirmed and its approval used", false, func(c *Config) interface{} { return &c.Approval.TTL }},
+	{"safety.safe_mode", "GHOST_SAFE_MODE", "safe-mode", "Block dangerous intents and unlisted action types", false, func(c *Config) interface{} { return &c.Safety.SafeMode }},
+	{"safety.blocked_keywords", "GHOST_BLOCKED_KEYWORDS", "blocked-keywords", "Comma-separated keywords that deny an intent in safe mode", false, func(c *Config) interface{} { return &c.Safety.BlockedKeywords }},
+	{"safety.allowed_actions", "GHOST_ALLOWED_ACTIONS", "allowed-actions", "Comma-separated action types the Brain and gateway may request", false, func(c *Config) interface{} { return &c.Safety.AllowedActions }},
+	{"safety.auto_approve_below", "GHOST_AUTO_APPROVE_BELOW", "auto-approve-below", "Risk score under which proposals run without asking in AUTO mode", false, func(c *Config) interface{} { return &c.Safety.AutoApproveBelow }},
+	{"limits.client_per_minute", "GHOST_LIMIT_CLIENT_RPM", "limit-client-rpm", "Permission requests per minute per client (0 disables)", false, func(c *Config) interface{} { return &c.Limits.ClientPerMinute }},
+	{"limits.client_burst", "GHOST_LIMIT_CLIENT_BURST", "limit-client-burst", "Requests a client may send at once before its rate applies", false, func(c *Config) interface{} { return &c.Limits.ClientBurst }},
+	{"limits.domain_per_minute", "GHOST_LIMIT_DOMAIN_RPM", "limit-domain-rpm", "Proposals per minute per domain (0 disables)", false, func(c *Config) interface{} { return &c.Limits.DomainPerMinute }},
+	{"limits.domain_burst", "GHOST_LIMIT_DOMAIN_BURST", "limit-domain-burst", "Proposals a domain may receive at once", false, func(c *Config) interface{} { return &c.Limits.DomainBurst }},
+	{"limits.intent_per_minute", "GHOST_LIMIT_INTENT_RPM", "limit-intent-rpm", "Repeats of the same intent per minute (0 disables)", false, func(c *Config) interface{} { return &c.Limits.IntentPerMinute }},
+	{"limits.intent_burst", "GHOST_LIMIT_INTENT_BURST", "limit-intent-burst", "Repeats of the same intent allowed at once", false, func(c *Config) interface{} { return &c.Limits.IntentBurst }},
+	{"limits.breaker_threshold", "GHOST_BREAKER_THRESHOLD", "breaker-threshold", "Denials within the window that switch the domain to MANUAL or the system to SHADOW (0 disables)", false, func(c *Config) interface{} { return &c.Limits.BreakerThreshold }},
+	{"limits.breaker_window", "GHOST_BREAKER_WINDOW", "breaker-window", "Window in which breaker denials are counted", false, func(c *Config) interface{} { return &c.Limits.BreakerWindow }},
+	{"limits.breaker_cooldown", "GHOST_BREAKER_COOLDOWN", "breaker-cooldown", "How long a tripped source is refused outright", false, func(c *Config) interface{} { return &c.Limits.BreakerCooldown }},
+	{"retention.artifacts", "GHOST_RETENTION_ARTIFACTS", "retention-artifacts", "How long captured artifacts are kept", false, func(c *Config) interface{} { return &c.Retention.Artifacts }},
+	{"retention.intent_history", "GHOST_RETENTION_INTENTS", "retention-intents", "How long intent history and reflexes are kept", false, func(c *Config) interface{} { return &c.Retention.IntentHistory }},
+	{"retention.action_proposals", "GHOST_RETENTION_PROPOSALS", "retention-proposals", "How long resolved action proposals are kept", false, func(c *Config) interface{} { return &c.Retention.ActionProposals }},
+	{"retention.goals", "GHOST_RETENTION_GOALS", "retention-goals", "How long finished goals are kept", false, func(c *Config) interface{} { return &c.Retention.Goals }},
+	{"retention.commands", "GHOST_RETENTION_COMMANDS", "retention-commands", "How long finished commands are kept", false, func(c *Config) interface{} { return &c.Retention.Commands }},
+	{"retention.trace_events", "GHOST_RETENTION_TRACES", "retention-traces", "How long intent lifecycle events are kept", false, func(c *Config) interface{} { return &c.Retention.TraceEvents }},
+	{"retention.compact_interval", "GHOST_COMPACT_INTERVAL", "compact-interval", "How often expired rows are deleted (0 disables)", false, func(c *Config) interface{} { return &c.Retention.CompactInterval }},
+	{"retention.vacuum_interval", "GHOST_VACUUM_INTERVAL", "vacuum-interval", "Minimum time between VACUUM runs (0 disables)", false, func(c *Config) interface{} { return &c.Retention.VacuumInterval }},
+	{"scheduler.interval", "GHOST_SCHEDULER_INTERVAL", "scheduler-interval", "How often scheduled and recurring goals are checked (0 disables)", false, func(c *Config) interface{} { return &c.Scheduler.Interval }},
+	{"lifecycle.shutdown_timeout", "GHOST_SHUTDOWN_TIMEOUT", "shutdown-timeout", "How long to wait for servers and jobs to stop on shutdown", false, func(c *Config) interface{} { return &c.Lifecycle.ShutdownTimeout }},
+	{"tracing.endpoint", "GHOST_OTLP_ENDPOINT", "otlp-endpoint", "OTLP/HTTP collector that receives kernel spans, e.g. http://localhost:4318", false, func(c *Config) interface{} { return &c.Tracing.Endpoint }},
+}
+
+// flagValue is a flag seen on the command line, applied after the file and environment
+type flagValue struct {
+	setting setting
+	raw     string
+}
+
+// Loader resolves the configuration from defaults, a file, the environment and flags
+type Loader struct {
+	configPath string
+	flags      []flagValue
+	getenv     func(string) string
+}
+
+// NewLoader registers -config and one flag per setting on fs
+// Call Load after fs.Parse.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{getenv: os.Getenv}
+	defaults := Default()
+
+	fs.StringVar(&l.configPath, "config", "", "JSON config file (or set GHOST_CONFIG)")
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+		s := s
+		usage := fmt.Sprintf("%s (default %s, env %s)", s.usage, formatValue(s.value(&defaults)), s.env)
+		record := func(raw string) error {
+			// Parse now so typos fail at the flag, not after the file is read
+			scratch := Default()
+			if err := parseValue(s.value(&scratch), raw); err != nil {
+				return err
+			}
+			l.flags = append(l.flags, flagValue{setting: s, raw: raw})
+			return nil
+		}
+		if _, ok := s.value(&defaults).(*bool); ok {
+			fs.BoolFunc(s.flag, usage, record)
+		} else {
+			fs.Func(s.flag, usage, record)
+		}
+	}
+	return l
+}
+
+// Load merges defaults, the config file, GHOST_* variables and flags, then validates the result
+func (l *Loader) Load() (*Config, error) {
+	cfg := Default()
+
+	path := l.configPath
+	if path == "" {
+		path = l.getenv("GHOST_CONFIG")
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		raw := l.getenv(s.env)
+		if raw == "" {
+			continue
+		}
+		if err := parseValue(s.value(&cfg), raw); err != nil {
+			return nil, fmt.Errorf("invalid %s for %s: %w", s.env, s.key, err)
+		}
+	}
+
+	for _, f := range l.flags {
+		if err := parseValue(f.setting.value(&cfg), f.raw); err != nil {
+			return nil, fmt.Errorf("invalid -%s for %s: %w", f.setting.flag, f.setting.key, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return &cfg, nil
+}
+
+// LoadFile reads a config file over the defaults, ignoring the environment and flags
+// It is used to evaluate candidate files, such as a policy to replay, without applying them.
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	if err := loadFile(&cfg, path); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return &cfg, nil
+}
+
+// loadFile overlays a JSON config file; keys it omits keep their defaults
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// parseValue sets the field ptr points to from its string form
+func parseValue(ptr interface{}, raw string) error {
+	switch p := ptr.(type) {
+	case *string:
+		*p = raw
+	case *int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		*p = n
+	case *bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		*p = b
+	case *Duration:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 720h", raw)
+		}
+		*p = Duration(d)
+	case *[]string:
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		*p = values
+	default:
+		return fmt.Errorf("unsupported setting type %T", ptr)
+	}
+	return nil
+}
+
+// formatValue renders a field for flag usage text
+func formatValue(ptr interface{}) string {
+	switch p := ptr.(type) {
+	case *string:
+		return strconv.Quote(*p)
+	case *int:
+		return strconv.Itoa(*p)
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *Duration:
+		return time.Duration(*p).String()
+	case *[]string:
+		return strconv.Quote(strings.Join(*p, ","))
+	}
+	return fmt.Sprint(ptr)
+}
+
+// Print writes the effective configuration as JSON with secrets masked
+func Print(w io.Writer, cfg *Config) error {
+	effective := cfg.Redacted()
+	effective.HTTP.CORSOrigins = cfg.AllowedOrigins()
+	out, err := json.MarshalIndent(effective, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
@@ -0,0 +1,373 @@
+// Author: Enkae (enkae.dev@pm.me)
+package conscience
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ghost/kernel/internal/protocol"
+
+	"github.com/google/uuid"
+)
+
+// Second-factor factors
+const (
+	FactorTOTP   = "totp"
+	FactorClient = "client"
+)
+
+// totpStep is the RFC 6238 time step; codes from the step before and after are accepted for clock drift
+const totpStep = 30 * time.Second
+
+// maxCodeFailures is how many wrong codes a challenge takes before it is withdrawn
+const maxCodeFailures = 3
+
+// Second-factor approval errors
+var (
+	ErrNoChallenge     = errors.New("no high-risk request is waiting for confirmation under this ID")
+	ErrInvalidCode     = errors.New("one-time code is not valid")
+	ErrNotApprover     = errors.New("only a registered approval client may confirm without a one-time code")
+	ErrInvalidApproval = errors.New("approval is not valid for this request")
+	ErrChallengeTaken  = errors.New("request ID is already awaiting confirmation of different actions")
+	ErrDigestMismatch  = errors.New("request awaiting confirmation is not the one shown to the approver")
+	ErrTooManyCodes    = errors.New("too many wrong one-time codes; the request must be made again")
+	ErrNeedsApproval   = errors.New("approving this needs a one-time code or confirmation from an approval client")
+)
+
+// Approvals issues and verifies the signed approvals that let a high-risk request past the risk rule
+// A request refused for its risk leaves a challenge; a one-time code or a registered client confirms
+// it, and the approval the kernel signs in return is good once, for that exact request, until it expires.
+// The kernel raises challenges of its own through Redeem, for the high-risk proposals and batches a
+// person approves; a registered client's confirmation of those is kept for Redeem to use.
+type Approvals struct {
+	mu         sync.Mutex
+	key        []byte
+	totpSecret []byte // nil disables one-time codes
+	clients    map[string]bool
+	ttl        time.Duration
+	challenges map[string]challenge                // Request ID -> the request awaiting confirmation
+	redeemed   map[string]time.Time                // Approval ID -> when it expires; kept until then so it cannot be replayed
+	confirmed  map[string]*protocol.SignedApproval // Request ID -> a client's confirmation of a challenge Redeem raised
+	lastCode   uint64                              // Time step of the last code accepted; a code is never accepted twice
+	now        func() time.Time
+}
+
+// challenge is a refused request a second factor may confirm
+type challenge struct {
+	digest    string
+	expiresAt time.Time
+	failures  int  // Wrong codes given so far
+	held      bool // Raised by Redeem; a confirmation is kept rather than handed to a requester
+}
+
+// NewApprovals signs approvals with key
+// totpSecret is the base32 secret shared with the user's authenticator; empty disables codes.
+// clients are the gateway client IDs that may confirm as themselves.
+func NewApprovals(key []byte, totpSecret string, clients []string, ttl time.Duration) (*Approvals, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("approval signing key is empty")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("approval ttl must be positive")
+	}
+	secret, err := DecodeTOTPSecret(totpSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]bool, len(clients))
+	for _, id := range clients {
+		registered[id] = true
+	}
+	return &Approvals{
+		key:        key,
+		totpSecret: secret,
+		clients:    registered,
+		ttl:        ttl,
+		challenges: make(map[string]challenge),
+		redeemed:   make(map[string]time.Time),
+		confirmed:  make(map[string]*protocol.SignedApproval),
+		now:        time.Now,
+	}, nil
+}
+
+// DecodeTOTPSecret decodes a base32 TOTP secret as authenticator apps show it; empty decodes to nil
+func DecodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	if secret == "" {
+		return nil, nil
+	}
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("totp secret must be base32: %w", err)
+	}
+	if len(decoded) < 10 {
+		return nil, fmt.Errorf("totp secret must be at least 80 bits")
+	}
+	return decoded, nil
+}
+
+// RequestDigest fingerprints what a request would do, so an approval cannot be moved to another request
+func RequestDigest(req *protocol.ActionValidationRequest) string {
+	return Digest(struct {
+		Intent         string                  `json:"intent"`
+		Actions        []protocol.LegacyAction `json:"actions"`
+		ExpectedWindow string                  `json:"expected_window"`
+	}{req.Intent, req.Actions, req.ExpectedWindow})
+}
+
+// Digest fingerprints the JSON encoding of what an approval approves
+func Digest(subject interface{}) string {
+	data, _ := json.Marshal(subject)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Challenge lets a second factor confirm a request the risk rule refused and returns the digest it will approve
+// A live challenge is only renewed by the same request; one with the same ID but other actions is refused,
+// so the actions cannot be swapped after the approver was shown them.
+func (a *Approvals) Challenge(req *protocol.ActionValidationRequest) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	a.expire(now)
+
+	digest := RequestDigest(req)
+	pending, ok := a.challenges[req.RequestID]
+	if ok && (pending.digest != digest || pending.held) {
+		return "", ErrChallengeTaken
+	}
+	// Renewing keeps the wrong codes counted against it
+	a.challenges[req.RequestID] = challenge{digest: digest, expiresAt: now.Add(a.ttl), failures: pending.failures}
+	return digest, nil
+}
+
+// Confirm signs an approval for a challenged request
+// With a code it checks the code; without one, approver must be a registered client.
+// A digest, when given, must be the one the challenge was made for; one Redeem raised needs it,
+// as what it approves can change while it waits. The challenge is withdrawn
+// after maxCodeFailures wrong codes, so a code cannot be guessed within one challenge's lifetime.
+func (a *Approvals) Confirm(requestID, digest, code, approver string) (*protocol.SignedApproval, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	a.expire(now)
+
+	pending, ok := a.challenges[requestID]
+	if !ok {
+		return nil, ErrNoChallenge
+	}
+	if (digest != "" || pending.held) && digest != pending.digest {
+		return nil, ErrDigestMismatch
+	}
+
+	approval := &protocol.SignedApproval{
+		ID:        uuid.New().String(),
+		RequestID: requestID,
+		Digest:    pending.digest,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(a.ttl),
+	}
+	switch {
+	case code != "":
+		step, ok := a.checkCode(code, now)
+		if !ok {
+			return nil, a.codeFailed(requestID)
+		}
+		a.lastCode = step
+		approval.Factor, approval.Approver = FactorTOTP, FactorTOTP
+	case approver != "" && a.clients[approver]:
+		approval.Factor, approval.Approver = FactorClient, approver
+	default:
+		return nil, ErrNotApprover
+	}
+
+	delete(a.challenges, requestID)
+	approval.Signature = a.sign(approval)
+	if pending.held {
+		a.confirmed[requestID] = approval
+	}
+	return approval, nil
+}
+
+// Redeem uses up a second factor for approving what digest describes, under requestID
+// A code is checked there and then; without one, the approval a registered client gave through
+// Confirm is used. Failing both, the approval is challenged for a client to confirm and
+// ErrNeedsApproval is returned. The returned approval is already redeemed.
+func (a *Approvals) Redeem(requestID, digest, code string) (*protocol.SignedApproval, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	a.expire(now)
+
+	if approval, ok := a.confirmed[requestID]; ok && approval.Digest == digest {
+		delete(a.confirmed, requestID)
+		a.redeemed[approval.ID] = approval.ExpiresAt
+		return approval, nil
+	}
+
+	// The person approving may change what they approve, such as by editing a payload; the challenge
+	// then moves to the new digest, which a client confirming the old one does not match
+	pending, ok := a.challenges[requestID]
+	if ok && !pending.held {
+		return nil, ErrChallengeTaken
+	}
+	// Renewing keeps the wrong codes counted against it
+	a.challenges[requestID] = challenge{digest: digest, expiresAt: now.Add(a.ttl), failures: pending.failures, held: true}
+	if code == "" {
+		return nil, ErrNeedsApproval
+	}
+
+	step, valid := a.checkCode(code, now)
+	if !valid {
+		return nil, a.codeFailed(requestID)
+	}
+	a.lastCode = step
+	delete(a.challenges, requestID)
+	approval := &protocol.SignedApproval{
+		ID:        uuid.New().String(),
+		RequestID: requestID,
+		Digest:    digest,
+		Factor:    FactorTOTP,
+		Approver:  FactorTOTP,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(a.ttl),
+	}
+	approval.Signature = a.sign(approval)
+	a.redeemed[approval.ID] = approval.ExpiresAt
+	return approval, nil
+}
+
+// Verify checks that req carries a genuine, unexpired and unused approval for exactly this request
+// It leaves the approval unused, so only dry runs should rely on it.
+func (a *Approvals) Verify(req *protocol.ActionValidationRequest) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.verify(req)
+}
+
+// Consume verifies the approval req carries and uses it up in the same step
+// Of concurrent requests carrying one approval only the first gets it.
+func (a *Approvals) Consume(req *protocol.ActionValidationRequest) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.verify(req); err != nil {
+		return err
+	}
+	a.redeemed[req.Approval.ID] = req.Approval.ExpiresAt
+	return nil
+}
+
+// Release returns a consumed approval the other rules then blocked, so it can be used again until it expires
+func (a *Approvals) Release(approval *protocol.SignedApproval) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.redeemed, approval.ID)
+}
+
+// verify is Verify with a.mu held
+func (a *Approvals) verify(req *protocol.ActionValidationRequest) error {
+	approval := req.Approval
+	if approval == nil {
+		return fmt.Errorf("%w: none given", ErrInvalidApproval)
+	}
+
+	now := a.now()
+	a.expire(now)
+
+	switch {
+	case !hmac.Equal([]byte(approval.Signature), []byte(a.sign(approval))):
+		return fmt.Errorf("%w: bad signature", ErrInvalidApproval)
+	case !now.Before(approval.ExpiresAt):
+		return fmt.Errorf("%w: expired", ErrInvalidApproval)
+	case approval.RequestID != req.RequestID || approval.Digest != RequestDigest(req):
+		return fmt.Errorf("%w: it approves a different request", ErrInvalidApproval)
+	}
+	if _, used := a.redeemed[approval.ID]; used {
+		return fmt.Errorf("%w: already used", ErrInvalidApproval)
+	}
+	return nil
+}
+
+// sign returns the hex HMAC-SHA256 of every field of approval but its signature
+func (a *Approvals) sign(approval *protocol.SignedApproval) string {
+	mac := hmac.New(sha256.New, a.key)
+	for _, field := range []string{
+		approval.ID, approval.RequestID, approval.Digest, approval.Factor, approval.Approver,
+		approval.IssuedAt.UTC().Format(time.RFC3339Nano), approval.ExpiresAt.UTC().Format(time.RFC3339Nano),
+	} {
+		// Length-prefixed so fields cannot run into each other
+		binary.Write(mac, binary.BigEndian, uint32(len(field)))
+		mac.Write([]byte(field))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// codeFailed counts a wrong code against a challenge, withdrawing it at maxCodeFailures
+func (a *Approvals) codeFailed(requestID string) error {
+	pending := a.challenges[requestID]
+	pending.failures++
+	if pending.failures >= maxCodeFailures {
+		delete(a.challenges, requestID)
+		return ErrTooManyCodes
+	}
+	a.challenges[requestID] = pending
+	return ErrInvalidCode
+}
+
+// checkCode returns the time step a code is valid for, allowing one step of drift either way
+func (a *Approvals) checkCode(code string, now time.Time) (uint64, bool) {
+	if a.totpSecret == nil {
+		return 0, false
+	}
+	current := uint64(now.Unix()) / uint64(totpStep/time.Second)
+	for _, step := range []uint64{current - 1, current, current + 1} {
+		if step > a.lastCode && subtle.ConstantTimeCompare([]byte(TOTPCode(a.totpSecret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// expire forgets challenges, confirmations and redeemed approvals that have expired
+func (a *Approvals) expire(now time.Time) {
+	for id, approval := range a.confirmed {
+		if !now.Before(approval.ExpiresAt) {
+			delete(a.confirmed, id)
+		}
+	}
+	for id, pending := range a.challenges {
+		if !now.Before(pending.expiresAt) {
+			delete(a.challenges, id)
+		}
+	}
+	for id, expiresAt := range a.redeemed {
+		if !now.Before(expiresAt) {
+			delete(a.redeemed, id)
+		}
+	}
+}
+
+// TOTPCode is the six-digit RFC 6238 code (HMAC-SHA1) for a time step
+func TOTPCode(secret []byte, step uint64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], step)
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
@@ -0,0 +1,360 @@
+// Author: Enkae (enkae.dev@pm.me)
+package conscience
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/protocol"
+)
+
+// testSecret is the RFC 6238 SHA-1 test key ("12345678901234567890") in base32
+const testSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// newTestApprovals returns approvals on a clock the test moves, with "phone" as the approval client
+func newTestApprovals(t *testing.T) (*Approvals, *time.Time) {
+	t.Helper()
+	approvals, err := NewApprovals([]byte("signing-key"), testSecret, []string{"phone"}, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("NewApprovals() error = %v", err)
+	}
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	approvals.now = func() time.Time { return now }
+	return approvals, &now
+}
+
+// codeAt is the one-time code an authenticator shows at t
+func codeAt(t time.Time) string {
+	secret, _ := DecodeTOTPSecret(testSecret)
+	return TOTPCode(secret, uint64(t.Unix())/30)
+}
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	secret, err := DecodeTOTPSecret(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RFC 6238 appendix B, truncated to six digits
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 2000000000: "279037"} {
+		if got := TOTPCode(secret, uint64(unix)/30); got != want {
+			t.Errorf("TOTPCode(T=%d) = %s, want %s", unix, got, want)
+		}
+	}
+	if _, err := DecodeTOTPSecret("GEZDGNBV"); err == nil {
+		t.Error("DecodeTOTPSecret() accepted a 40-bit secret")
+	}
+}
+
+func TestApprovalIsBoundToOneRequest(t *testing.T) {
+	approvals, now := newTestApprovals(t)
+	req := &protocol.ActionValidationRequest{
+		RequestID: "req-1",
+		Intent:    "delete old logs",
+		Actions:   []protocol.LegacyAction{{Type: "DELETE", Target: "logs/old.txt"}},
+	}
+
+	if _, err := approvals.Confirm("req-1", "", codeAt(*now), ""); !errors.Is(err, ErrNoChallenge) {
+		t.Fatalf("Confirm() before a challenge error = %v, want ErrNoChallenge", err)
+	}
+	approvals.Challenge(req)
+	if _, err := approvals.Confirm("req-1", "", "000000", ""); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Confirm() with a wrong code error = %v, want ErrInvalidCode", err)
+	}
+	if _, err := approvals.Confirm("req-1", "", "", "laptop"); !errors.Is(err, ErrNotApprover) {
+		t.Fatalf("Confirm() by an unregistered client error = %v, want ErrNotApprover", err)
+	}
+
+	approval, err := approvals.Confirm("req-1", "", codeAt(*now), "")
+	if err != nil || approval.Factor != FactorTOTP {
+		t.Fatalf("Confirm() = %+v, %v; want a TOTP approval", approval, err)
+	}
+	req.Approval = approval
+	if err := approvals.Verify(req); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// Moved to a different request, or tampered with, it no longer verifies
+	other := *req
+	other.Actions = []protocol.LegacyAction{{Type: "DELETE", Target: "C:/Windows"}}
+	if err := approvals.Verify(&other); !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("Verify() of other actions error = %v, want ErrInvalidApproval", err)
+	}
+	forged := *approval
+	forged.ExpiresAt = forged.ExpiresAt.Add(time.Hour)
+	other = *req
+	other.Approval = &forged
+	if err := approvals.Verify(&other); !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("Verify() of an extended approval error = %v, want ErrInvalidApproval", err)
+	}
+
+	// It is good once, unless given back
+	if err := approvals.Consume(req); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if err := approvals.Consume(req); !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("second Consume() error = %v, want ErrInvalidApproval", err)
+	}
+	approvals.Release(approval)
+	if err := approvals.Consume(req); err != nil {
+		t.Errorf("Consume() after Release() error = %v", err)
+	}
+
+	// The same code cannot confirm another request
+	approvals.Challenge(&protocol.ActionValidationRequest{RequestID: "req-2", Intent: "delete old logs"})
+	if _, err := approvals.Confirm("req-2", "", codeAt(*now), ""); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Confirm() with a used code error = %v, want ErrInvalidCode", err)
+	}
+	if approval, err := approvals.Confirm("req-2", "", "", "phone"); err != nil || approval.Approver != "phone" {
+		t.Errorf("Confirm() by phone = %+v, %v; want a client approval", approval, err)
+	}
+}
+
+func TestApprovalExpires(t *testing.T) {
+	approvals, now := newTestApprovals(t)
+	req := &protocol.ActionValidationRequest{RequestID: "req-1", Intent: "delete old logs"}
+
+	approvals.Challenge(req)
+	*now = now.Add(6 * time.Minute)
+	if _, err := approvals.Confirm("req-1", "", "", "phone"); !errors.Is(err, ErrNoChallenge) {
+		t.Fatalf("Confirm() after the ttl error = %v, want ErrNoChallenge", err)
+	}
+
+	approvals.Challenge(req)
+	approval, err := approvals.Confirm("req-1", "", "", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Approval = approval
+	*now = now.Add(6 * time.Minute)
+	if err := approvals.Verify(req); !errors.Is(err, ErrInvalidApproval) {
+		t.Errorf("Verify() after the ttl error = %v, want ErrInvalidApproval", err)
+	}
+}
+
+func TestHighRiskRequestNeedsSecondFactor(t *testing.T) {
+	ctx := context.Background()
+	v := NewValidator()
+	v.SetFocusedWindow("Notepad")
+	approvals, now := newTestApprovals(t)
+	v.SetApprovals(approvals)
+
+	req := &protocol.ExecApprovalRequestParams{
+		RequestID:      "write-1",
+		Intent:         "save notes",
+		Actions:        json.RawMessage(`[{"type":"WRITE","payload":{"path":"notes.txt"}}]`),
+		ExpectedWindow: "Notepad",
+	}
+	result, err := v.RequestApproval(ctx, req)
+	if err != nil || result.Approved || result.ErrorCode != protocol.ErrorCodeApprovalRequired || result.Digest == "" {
+		t.Fatalf("RequestApproval() = %+v, %v; want approval_required with a digest", result, err)
+	}
+
+	approval, err := v.ConfirmApproval(ctx, &protocol.ExecConfirmParams{RequestID: "write-1", Digest: result.Digest, Code: codeAt(*now)}, "")
+	if err != nil {
+		t.Fatalf("ConfirmApproval() error = %v", err)
+	}
+	req.Approval = approval
+	if result, _ := v.RequestApproval(ctx, req); !result.Approved {
+		t.Fatalf("RequestApproval() with the approval = %+v, want approved", result)
+	}
+	if entry := v.GetAuditLog(1)[0]; entry.Approval == nil || entry.Approval.ID != approval.ID {
+		t.Errorf("audit entry approval = %+v, want %s", entry.Approval, approval.ID)
+	}
+
+	// Replayed, the approval is refused and the request is challenged again
+	result, _ = v.RequestApproval(ctx, req)
+	if result.Approved || result.ErrorCode != protocol.ErrorCodeApprovalRequired {
+		t.Errorf("RequestApproval() replaying the approval = %+v, want approval_required", result)
+	}
+}
+
+func TestConcurrentReplayApprovesOnce(t *testing.T) {
+	ctx := context.Background()
+	v := NewValidator()
+	v.SetFocusedWindow("Notepad")
+	approvals, _ := newTestApprovals(t)
+	v.SetApprovals(approvals)
+
+	req := &protocol.ExecApprovalRequestParams{
+		RequestID:      "write-1",
+		Intent:         "save notes",
+		Actions:        json.RawMessage(`[{"type":"WRITE","payload":{"path":"notes.txt"}}]`),
+		ExpectedWindow: "Notepad",
+	}
+	if _, err := v.RequestApproval(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	approval, err := v.ConfirmApproval(ctx, &protocol.ExecConfirmParams{RequestID: "write-1"}, "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Approval = approval
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	approved := 0
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := v.RequestApproval(ctx, req); err == nil && result.Approved {
+				mu.Lock()
+				approved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if approved != 1 {
+		t.Errorf("%d concurrent requests were approved with one approval, want 1", approved)
+	}
+}
+
+func TestBlockedRequestKeepsItsApproval(t *testing.T) {
+	ctx := context.Background()
+	v := NewValidator()
+	v.SetFocusedWindow("Chrome")
+	approvals, _ := newTestApprovals(t)
+	v.SetApprovals(approvals)
+
+	req := &protocol.ExecApprovalRequestParams{
+		RequestID:      "write-1",
+		Intent:         "save notes",
+		Actions:        json.RawMessage(`[{"type":"WRITE","payload":{"path":"notes.txt"}}]`),
+		ExpectedWindow: "Notepad",
+	}
+	if _, err := v.RequestApproval(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	approval, err := v.ConfirmApproval(ctx, &protocol.ExecConfirmParams{RequestID: "write-1"}, "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Approval = approval
+
+	// The focus rule blocks it, so the approval is still good once focus is right
+	if result, _ := v.RequestApproval(ctx, req); result.Approved {
+		t.Fatalf("RequestApproval() in the wrong window = %+v, want a denial", result)
+	}
+	v.SetFocusedWindow("notes.txt - Notepad")
+	if result, _ := v.RequestApproval(ctx, req); !result.Approved {
+		t.Errorf("RequestApproval() after focusing Notepad = %+v, want approved", result)
+	}
+}
+
+func TestChallengeCannotBeSwapped(t *testing.T) {
+	approvals, _ := newTestApprovals(t)
+	shown := &protocol.ActionValidationRequest{
+		RequestID: "req-1",
+		Intent:    "delete old logs",
+		Actions:   []protocol.LegacyAction{{Type: "DELETE", Target: "logs/old.txt"}},
+	}
+	digest, err := approvals.Challenge(shown)
+	if err != nil || digest != RequestDigest(shown) {
+		t.Fatalf("Challenge() = %q, %v; want the request digest", digest, err)
+	}
+
+	// Another request under the same ID cannot take over the prompt
+	swapped := *shown
+	swapped.Actions = []protocol.LegacyAction{{Type: "DELETE", Target: "documents"}}
+	if _, err := approvals.Challenge(&swapped); !errors.Is(err, ErrChallengeTaken) {
+		t.Fatalf("Challenge() with other actions error = %v, want ErrChallengeTaken", err)
+	}
+	if again, err := approvals.Challenge(shown); err != nil || again != digest {
+		t.Fatalf("Challenge() repeated = %q, %v; want it renewed", again, err)
+	}
+
+	// The approver confirms the digest they were shown
+	if _, err := approvals.Confirm("req-1", RequestDigest(&swapped), "", "phone"); !errors.Is(err, ErrDigestMismatch) {
+		t.Fatalf("Confirm() of another digest error = %v, want ErrDigestMismatch", err)
+	}
+	approval, err := approvals.Confirm("req-1", digest, "", "phone")
+	if err != nil || approval.Digest != digest {
+		t.Fatalf("Confirm() = %+v, %v; want an approval of the digest shown", approval, err)
+	}
+}
+
+func TestWrongCodesWithdrawChallenge(t *testing.T) {
+	approvals, now := newTestApprovals(t)
+	req := &protocol.ActionValidationRequest{
+		RequestID: "req-1",
+		Intent:    "delete old logs",
+		Actions:   []protocol.LegacyAction{{Type: "DELETE", Target: "logs/old.txt"}},
+	}
+	if _, err := approvals.Challenge(req); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong := "000000"
+	if wrong == codeAt(*now) {
+		wrong = "111111"
+	}
+	if _, err := approvals.Confirm("req-1", "", wrong, ""); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Confirm() with a wrong code error = %v, want ErrInvalidCode", err)
+	}
+	// Renewing the challenge does not clear the wrong codes given against it
+	if _, err := approvals.Challenge(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := approvals.Confirm("req-1", "", wrong, ""); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("second wrong code error = %v, want ErrInvalidCode", err)
+	}
+	if _, err := approvals.Confirm("req-1", "", wrong, ""); !errors.Is(err, ErrTooManyCodes) {
+		t.Fatalf("third wrong code error = %v, want ErrTooManyCodes", err)
+	}
+
+	// The right code is too late; the request has to be made, and shown, again
+	if _, err := approvals.Confirm("req-1", "", codeAt(*now), ""); !errors.Is(err, ErrNoChallenge) {
+		t.Fatalf("Confirm() after lockout error = %v, want ErrNoChallenge", err)
+	}
+	if _, err := approvals.Challenge(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := approvals.Confirm("req-1", "", codeAt(*now), ""); err != nil {
+		t.Errorf("Confirm() on a new challenge error = %v", err)
+	}
+}
+
+func TestRedeemHeldApproval(t *testing.T) {
+	approvals, now := newTestApprovals(t)
+
+	if _, err := approvals.Redeem("proposal:1", "d1", ""); !errors.Is(err, ErrNeedsApproval) {
+		t.Fatalf("Redeem() without a factor error = %v, want ErrNeedsApproval", err)
+	}
+	// An exec request cannot take over the kernel's challenge, nor a client confirm it blind
+	if _, err := approvals.Challenge(&protocol.ActionValidationRequest{RequestID: "proposal:1", Intent: "pay"}); !errors.Is(err, ErrChallengeTaken) {
+		t.Fatalf("Challenge() of a held ID error = %v, want ErrChallengeTaken", err)
+	}
+	if _, err := approvals.Confirm("proposal:1", "", "", "phone"); !errors.Is(err, ErrDigestMismatch) {
+		t.Fatalf("Confirm() without the digest error = %v, want ErrDigestMismatch", err)
+	}
+
+	// The person edits what they approve; the phone's confirmation of the old digest no longer fits
+	if _, err := approvals.Redeem("proposal:1", "d2", ""); !errors.Is(err, ErrNeedsApproval) {
+		t.Fatalf("Redeem() of an edit error = %v, want ErrNeedsApproval", err)
+	}
+	if _, err := approvals.Confirm("proposal:1", "d1", "", "phone"); !errors.Is(err, ErrDigestMismatch) {
+		t.Fatalf("Confirm() of the old digest error = %v, want ErrDigestMismatch", err)
+	}
+	confirmed, err := approvals.Confirm("proposal:1", "d2", "", "phone")
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	redeemed, err := approvals.Redeem("proposal:1", "d2", "")
+	if err != nil || redeemed.ID != confirmed.ID {
+		t.Fatalf("Redeem() = %+v, %v; want the phone's approval", redeemed, err)
+	}
+	if _, err := approvals.Redeem("proposal:1", "d2", ""); !errors.Is(err, ErrNeedsApproval) {
+		t.Fatalf("second Redeem() error = %v, want the approval used up", err)
+	}
+
+	// A code redeems on the spot
+	approval, err := approvals.Redeem("batch:1", "d3", codeAt(*now))
+	if err != nil || approval.Factor != FactorTOTP || approval.Digest != "d3" {
+		t.Fatalf("Redeem() with a code = %+v, %v", approval, err)
+	}
+}
@@ -6,7 +6,7 @@
 //
 // Rules:
 // 1. ALL Action requests must pass through ValidateAction() before routing to Body
-// 2. If RiskLevel > High (7+), reject automatically unless Override key is present
+// 2. If RiskLevel > High (7+), reject automatically unless a second factor signed an approval for it
 // 3. Actions are logged for audit and trust score calculation
 package conscience
 
@@ -20,9 +20,13 @@ import (
 	"sync"
 	"time"
 
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
 	"ghost/kernel/internal/protocol"
+	"ghost/kernel/internal/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -79,6 +83,16 @@ type Validator struct {
 	focusedWindow   string
 	trustScores     map[string]int // intent -> trust score
 	auditLog        []AuditEntry
+	allowedActions  map[string]bool
+	approvals       *Approvals // nil leaves high-risk requests no way past the risk rule
+	onDecision      []func(ctx context.Context, record *domain.DecisionRecord)
+}
+
+// DecisionInput is everything Evaluate looks at, recorded with each decision so it can be replayed
+type DecisionInput struct {
+	Request       *protocol.ActionValidationRequest `json:"request"`
+	FocusedWindow string                            `json:"focused_window,omitempty"`
+	TrustScore    int                               `json:"trust_score"`
 }
 
 // PendingRequest tracks an action awaiting approval
@@ -93,13 +107,13 @@ type PendingRequest struct {
 
 // AuditEntry logs action validations
 type AuditEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	RequestID string    `json:"request_id"`
-	Intent    string    `json:"intent"`
-	RiskLevel int       `json:"risk_level"`
-	Blocked   bool      `json:"blocked"`
-	Reason    string    `json:"reason,omitempty"`
-	Override  bool      `json:"override"`
+	Timestamp time.Time                `json:"timestamp"`
+	RequestID string                   `json:"request_id"`
+	Intent    string                   `json:"intent"`
+	RiskLevel int                      `json:"risk_level"`
+	Blocked   bool                     `json:"blocked"`
+	Reason    string                   `json:"reason,omitempty"`
+	Approval  *protocol.SignedApproval `json:"approval,omitempty"` // The second factor that let it past the risk rule
 }
 
 // NewValidator creates a new Conscience Kernel validator
@@ -108,9 +122,34 @@ func NewValidator() *Validator {
 		pendingRequests: make(map[string]*PendingRequest),
 		trustScores:     make(map[string]int),
 		auditLog:        make([]AuditEntry, 0, 1000),
+		allowedActions:  AllowedActionTypes,
 	}
 }
 
+// SetAllowedActions replaces the allowlist of action types
+// Call it before the validator starts serving requests.
+func (v *Validator) SetAllowedActions(allowed map[string]bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.allowedActions = allowed
+}
+
+// SetApprovals lets high-risk requests through once a second factor confirms them
+// Call it before the validator starts serving requests.
+func (v *Validator) SetApprovals(approvals *Approvals) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.approvals = approvals
+}
+
+// OnDecision registers a listener called with each request the rules decided
+// Dry runs are not reported.
+func (v *Validator) OnDecision(fn func(ctx context.Context, record *domain.DecisionRecord)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.onDecision = append(v.onDecision, fn)
+}
+
 // SetFocusedWindow updates the current focus state
 func (v *Validator) SetFocusedWindow(window string) {
 	v.mu.Lock()
@@ -127,116 +166,171 @@ func (v *Validator) ValidateAction(ctx context.Context, req *protocol.ActionVali
 			Reason:  "Nil validation request",
 		}
 	}
-	// 1. Calculate maximum risk level and check blocked keywords (No lock needed)
+	req, approvalErr := v.admitApproval(req, false)
+	result, _ := v.validate(ctx, req, approvalErr)
+	return result
+}
+
+// admitApproval returns req without its approval unless the approval verifies, and why it did not
+// Evaluate trusts any approval on a request, so every request from outside passes through here first.
+// Except in a dry run the approval is used up as it is admitted; validate releases it if the request is blocked.
+func (v *Validator) admitApproval(req *protocol.ActionValidationRequest, dryRun bool) (*protocol.ActionValidationRequest, error) {
+	if req.Approval == nil {
+		return req, nil
+	}
+	v.mu.RLock()
+	approvals := v.approvals
+	v.mu.RUnlock()
+
+	err := fmt.Errorf("%w: second-factor approvals are not configured", ErrInvalidApproval)
+	switch {
+	case approvals != nil && dryRun:
+		err = approvals.Verify(req)
+	case approvals != nil:
+		err = approvals.Consume(req)
+	}
+	if err == nil {
+		return req, nil
+	}
+	stripped := *req
+	stripped.Approval = nil
+	return &stripped, err
+}
+
+// explainApproval puts why an approval was refused on a request the risk rule then blocked
+func explainApproval(result *protocol.ActionValidationResult, trace *domain.DecisionTrace, approvalErr error) {
+	if approvalErr == nil || trace.Rule != metrics.RuleRiskOverride {
+		return
+	}
+	reason := "Approval refused: " + approvalErr.Error()
+	result.Reason, trace.Reason = reason, reason
+}
+
+// validate evaluates an admitted request against the current focus and trust, then logs and remembers it
+// A request the risk rule blocks can be confirmed by a second factor; a blocked request gives back its approval.
+func (v *Validator) validate(ctx context.Context, req *protocol.ActionValidationRequest, approvalErr error) (*protocol.ActionValidationResult, *domain.DecisionTrace) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	result, trace := v.Evaluate(req, v.focusedWindow, v.getTrustScore(req.Intent))
+	explainApproval(result, trace, approvalErr)
+	if result.Blocked {
+		if trace.Rule == metrics.RuleRiskOverride {
+			slog.Warn("Action blocked by Conscience Kernel",
+				"request_id", req.RequestID,
+				"intent", req.Intent,
+				"risk_level", result.RiskLevel,
+			)
+			if v.approvals != nil {
+				digest, err := v.approvals.Challenge(req)
+				if err != nil {
+					result.Reason = fmt.Sprintf("%s: %v", result.Reason, err)
+					trace.Reason = result.Reason
+				}
+				result.Digest = digest
+			}
+		}
+		if req.Approval != nil {
+			v.approvals.Release(req.Approval)
+		}
+		v.logAudit(ctx, req, result, trace.Rule)
+		return result, trace
+	}
+
+	// Store as pending request (for UI approval if needed)
+	pending := &PendingRequest{
+		ID:        req.RequestID,
+		Request:   req,
+		CreatedAt: time.Now(),
+	}
+	v.pendingRequests[req.RequestID] = pending
+
+	slog.Info("Action validated by Conscience Kernel",
+		"request_id", req.RequestID,
+		"intent", req.Intent,
+		"risk_level", result.RiskLevel,
+		"approval", approvalID(req.Approval),
+	)
+
+	v.logAudit(ctx, req, result, metrics.RulePassed)
+	return result, trace
+}
+
+// Evaluate applies every rule to a request given the focused window and the intent's trust score
+// It has no side effects, so dry runs and policy replays can call it directly.
+func (v *Validator) Evaluate(req *protocol.ActionValidationRequest, focusedWindow string, trustScore int) (*protocol.ActionValidationResult, *domain.DecisionTrace) {
+	trace := &domain.DecisionTrace{Path: metrics.PathGateway, TrustScore: trustScore}
+	denied := metrics.DecisionDenied
+
 	maxRisk := protocol.RiskLevelNone
 	for i := range req.Actions {
 		action := &req.Actions[i]
+		label := fmt.Sprintf("action %d (%s)", i, action.Type)
 
 		// Enforce Allowlist
-		actionType := strings.ToUpper(action.Type)
-		if !AllowedActionTypes[actionType] {
-			v.mu.Lock()
-			defer v.mu.Unlock()
-			result := &protocol.ActionValidationResult{
-				Valid:      false,
-				Blocked:    true,
-				Reason:     fmt.Sprintf("Action type '%s' is not allowed", action.Type),
-				RiskLevel:  protocol.RiskLevelCritical,
-			}
-			v.logAudit(req, result)
-			return result
-		}
+		allowed := v.allowedActions[strings.ToUpper(action.Type)]
+		trace.Check(metrics.RuleAllowlist, allowed, denied, reasonIf(!allowed, label,
+			fmt.Sprintf("Action type '%s' is not allowed", action.Type)))
 
 		// Validate File System Paths
-		if err := v.validateActionPath(action); err != nil {
-			v.mu.Lock()
-			defer v.mu.Unlock()
-			result := &protocol.ActionValidationResult{
-				Valid:      false,
-				Blocked:    true,
-				Reason:     fmt.Sprintf("Path validation failed for action %d: %v", i, err),
-				RiskLevel:  protocol.RiskLevelCritical,
-			}
-			v.logAudit(req, result)
-			return result
-		}
+		err := v.validateActionPath(action)
+		trace.Check(metrics.RulePath, err == nil, denied, reasonIf(err != nil, label,
+			fmt.Sprintf("Path validation failed for action %d: %v", i, err)))
 
 		actionRisk := v.evaluateActionRisk(action)
 		if actionRisk > maxRisk {
 			maxRisk = actionRisk
 		}
+		trace.Actions = append(trace.Actions, domain.ActionRisk{Index: i, Type: action.Type, Risk: int(actionRisk)})
 
 		// Check for blocked keywords in action payload
-		if v.containsBlockedKeyword(action) {
-			v.mu.Lock()
-			defer v.mu.Unlock()
-			result := &protocol.ActionValidationResult{
-				Valid:      false,
-				Blocked:    true,
-				Override:   req.Override,
-				TrustScore: v.getTrustScore(req.Intent),
-				Reason:     fmt.Sprintf("Action %d contains blocked keyword pattern", i),
-				RiskLevel:  protocol.RiskLevelCritical,
-			}
-			v.logAudit(req, result)
-			return result
-		}
-	}
-
-	// 2. State-dependent validation (Lock needed)
-	v.mu.Lock()
-	defer v.mu.Unlock()
-
-	result := &protocol.ActionValidationResult{
-		Valid:      true,
-		Blocked:    false,
-		Override:   req.Override,
-		TrustScore: v.getTrustScore(req.Intent),
-		RiskLevel:  maxRisk,
+		blocked := v.containsBlockedKeyword(action)
+		trace.Check(metrics.RuleBlockedKeyword, !blocked, denied, reasonIf(blocked, label,
+			fmt.Sprintf("Action %d contains blocked keyword pattern", i)))
 	}
 
-	// Rule: RiskLevel > High (7+) requires Override
-	if maxRisk >= protocol.RiskLevelHigh && !req.Override {
-		result.Valid = false
-		result.Blocked = true
-		result.Reason = fmt.Sprintf("High risk action (level %d) requires explicit override", maxRisk)
-		slog.Warn("Action blocked by Conscience Kernel",
-			"request_id", req.RequestID,
-			"intent", req.Intent,
-			"risk_level", maxRisk,
-		)
-		v.logAudit(req, result)
-		return result
+	// Rule: RiskLevel > High (7+) requires a signed approval
+	risky := maxRisk >= protocol.RiskLevelHigh && req.Approval == nil
+	checked := fmt.Sprintf("max risk level %d", maxRisk)
+	if req.Approval != nil {
+		checked += fmt.Sprintf(", approved by %s %s", req.Approval.Factor, req.Approval.Approver)
 	}
+	trace.Check(metrics.RuleRiskOverride, !risky, denied, reasonIf(risky, checked,
+		fmt.Sprintf("High risk action (level %d) requires a second-factor approval", maxRisk)))
 
 	// Check focus window if required
-	if req.ExpectedWindow != "" && v.focusedWindow != "" {
-		if !strings.Contains(strings.ToLower(v.focusedWindow), strings.ToLower(req.ExpectedWindow)) {
-			result.Valid = false
-			result.Blocked = true
-			result.Reason = fmt.Sprintf("Focus mismatch: expected '%s', got '%s'", req.ExpectedWindow, v.focusedWindow)
-			v.logAudit(req, result)
-			return result
+	focus := &domain.FocusCheck{Expected: req.ExpectedWindow, Actual: focusedWindow, Matched: true}
+	trace.Focus = focus
+	if req.ExpectedWindow != "" {
+		if focusedWindow != "" {
+			focus.Matched = strings.Contains(strings.ToLower(focusedWindow), strings.ToLower(req.ExpectedWindow))
 		}
+		trace.Check(metrics.RuleFocusMismatch, focus.Matched, denied, reasonIf(!focus.Matched, "focused '"+focusedWindow+"'",
+			fmt.Sprintf("Focus mismatch: expected '%s', got '%s'", req.ExpectedWindow, focusedWindow)))
 	}
+	trace.Settle(metrics.DecisionApproved, metrics.RulePassed)
 
-	// Store as pending request (for UI approval if needed)
-	pending := &PendingRequest{
-		ID:        req.RequestID,
-		Request:   req,
-		CreatedAt: time.Now(),
+	result := &protocol.ActionValidationResult{
+		Valid:      trace.Decision == metrics.DecisionApproved,
+		Override:   req.Approval != nil && maxRisk >= protocol.RiskLevelHigh,
+		TrustScore: trustScore,
+		Reason:     trace.Reason,
+		RiskLevel:  maxRisk,
 	}
-	v.pendingRequests[req.RequestID] = pending
-
-	slog.Info("Action validated by Conscience Kernel",
-		"request_id", req.RequestID,
-		"intent", req.Intent,
-		"risk_level", maxRisk,
-		"override", req.Override,
-	)
+	result.Blocked = !result.Valid
+	switch trace.Rule {
+	case metrics.RuleAllowlist, metrics.RulePath, metrics.RuleBlockedKeyword:
+		result.RiskLevel = protocol.RiskLevelCritical
+	}
+	return result, trace
+}
 
-	v.logAudit(req, result)
-	return result
+// reasonIf picks a check's detail: the refusal reason when it failed, what was checked otherwise
+func reasonIf(failed bool, checked, reason string) string {
+	if failed {
+		return reason
+	}
+	return checked
 }
 
 // validateActionPath checks for safe file system paths
@@ -331,12 +425,15 @@ func (v *Validator) evaluateActionRisk(action *protocol.LegacyAction) protocol.R
 	}
 
 	// Fall back to type-based risk assessment
-	actionType := strings.ToUpper(action.Type)
-	if risk, exists := DangerousActionTypes[actionType]; exists {
+	return ActionTypeRisk(action.Type)
+}
+
+// ActionTypeRisk returns the risk level of an action type, low for unknown types
+func ActionTypeRisk(actionType string) protocol.RiskLevel {
+	if risk, exists := DangerousActionTypes[strings.ToUpper(actionType)]; exists {
 		return risk
 	}
-
-	return protocol.RiskLevelLow // Default to low for unknown types
+	return protocol.RiskLevelLow
 }
 
 // containsBlockedKeyword checks if an action contains dangerous patterns
@@ -399,6 +496,9 @@ func (v *Validator) ResolveRequest(requestID string, approved bool, reason strin
 	}
 
 	now := time.Now()
+	if pending.ResolvedAt == nil {
+		metrics.ApprovalLatency(metrics.PathGateway, approved, now.Sub(pending.CreatedAt))
+	}
 	pending.ResolvedAt = &now
 	pending.Approved = approved
 	pending.Reason = reason
@@ -413,7 +513,19 @@ func (v *Validator) ResolveRequest(requestID string, approved bool, reason strin
 }
 
 // logAudit records an action validation for audit trail
-func (v *Validator) logAudit(req *protocol.ActionValidationRequest, result *protocol.ActionValidationResult) {
+// rule names the check that decided it, for the permission metrics and the intent's trace
+func (v *Validator) logAudit(ctx context.Context, req *protocol.ActionValidationRequest, result *protocol.ActionValidationResult, rule string) {
+	decision := metrics.DecisionApproved
+	if result.Blocked || !result.Valid {
+		decision = metrics.DecisionDenied
+	}
+	metrics.Permission(metrics.PathGateway, decision, rule)
+	metrics.ExecRiskLevel(int(result.RiskLevel))
+	tracing.Decision(tracing.WithTraceID(ctx, req.TraceID), metrics.PathGateway, decision, rule,
+		attribute.String("request_id", req.RequestID),
+		attribute.Int("risk_level", int(result.RiskLevel)),
+	)
+
 	entry := AuditEntry{
 		Timestamp: time.Now(),
 		RequestID: req.RequestID,
@@ -421,7 +533,9 @@ func (v *Validator) logAudit(req *protocol.ActionValidationRequest, result *prot
 		RiskLevel: int(result.RiskLevel),
 		Blocked:   result.Blocked,
 		Reason:    result.Reason,
-		Override:  result.Override,
+	}
+	if result.Override {
+		entry.Approval = req.Approval
 	}
 	v.auditLog = append(v.auditLog, entry)
 
@@ -465,7 +579,7 @@ func (v *Validator) RequestApproval(ctx context.Context, req *protocol.ExecAppro
 		Intent:         req.Intent,
 		Actions:        actions,
 		ExpectedWindow: req.ExpectedWindow,
-		Override:       false, // No override by default
+		Approval:       req.Approval,
 		TraceID:        req.TraceID,
 	}
 
@@ -473,18 +587,109 @@ func (v *Validator) RequestApproval(ctx context.Context, req *protocol.ExecAppro
 	if validationReq.RequestID == "" {
 		validationReq.RequestID = uuid.New().String()
 	}
+	// Only a verified approval is evaluated, and recorded for replay
+	validationReq, approvalErr := v.admitApproval(validationReq, req.DryRun)
+
+	if req.DryRun {
+		v.mu.RLock()
+		result, trace := v.Evaluate(validationReq, v.focusedWindow, v.getTrustScore(req.Intent))
+		confirmable := v.approvals != nil
+		v.mu.RUnlock()
+		explainApproval(result, trace, approvalErr)
+		if confirmable && trace.Rule == metrics.RuleRiskOverride {
+			result.Digest = RequestDigest(validationReq)
+		}
+		trace.DryRun = true
+		approvalResult := v.approvalResult(validationReq.RequestID, result)
+		approvalResult.Trace = trace
+		return approvalResult, nil
+	}
 
-	result := v.ValidateAction(ctx, validationReq)
+	result, trace := v.validate(ctx, validationReq, approvalErr)
+	v.notifyDecision(ctx, trace, &DecisionInput{
+		Request:       validationReq,
+		FocusedWindow: trace.Focus.Actual,
+		TrustScore:    trace.TrustScore,
+	})
 
-	return &protocol.ExecApprovalResult{
-		RequestID:  validationReq.RequestID,
+	return v.approvalResult(validationReq.RequestID, result), nil
+}
+
+// approvalResult answers exec.request, saying when a second factor could let a refused request through
+func (v *Validator) approvalResult(requestID string, result *protocol.ActionValidationResult) *protocol.ExecApprovalResult {
+	approvalResult := &protocol.ExecApprovalResult{
+		RequestID:  requestID,
 		Approved:   result.Valid && !result.Blocked,
 		Reason:     result.Reason,
 		TrustScore: result.TrustScore,
-	}, nil
+		RiskLevel:  int(result.RiskLevel),
+		Digest:     result.Digest,
+	}
+	if result.Digest != "" {
+		approvalResult.ErrorCode = protocol.ErrorCodeApprovalRequired
+	}
+	return approvalResult
+}
+
+// ConfirmApproval signs an approval for a request the risk rule refused; approver is the registered
+// client confirming it, or empty for a client that must give a one-time code
+func (v *Validator) ConfirmApproval(ctx context.Context, req *protocol.ExecConfirmParams, approver string) (*protocol.SignedApproval, error) {
+	v.mu.RLock()
+	approvals := v.approvals
+	v.mu.RUnlock()
+	if approvals == nil {
+		return nil, fmt.Errorf("second-factor approvals are not configured")
+	}
+
+	approval, err := approvals.Confirm(req.RequestID, req.Digest, req.Code, approver)
+	if err != nil {
+		slog.Warn("Approval confirmation refused", "request_id", req.RequestID, "approver", approver, "error", err)
+		return nil, err
+	}
+	slog.Info("High-risk request confirmed", "request_id", req.RequestID, "approval_id", approval.ID,
+		"factor", approval.Factor, "approver", approval.Approver)
+	return approval, nil
+}
+
+// approvalID names an approval in logs, or nothing when there is none
+func approvalID(approval *protocol.SignedApproval) string {
+	if approval == nil {
+		return ""
+	}
+	return approval.ID
+}
+
+// notifyDecision reports a decided request to the OnDecision listeners
+func (v *Validator) notifyDecision(ctx context.Context, trace *domain.DecisionTrace, input *DecisionInput) {
+	v.mu.RLock()
+	listeners := v.onDecision
+	v.mu.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+
+	record, err := domain.NewDecisionRecord(trace, input.Request.Intent, "", input.Request.TraceID, input)
+	if err != nil {
+		slog.Error("Failed to record decision", "request_id", input.Request.RequestID, "error", err)
+		return
+	}
+	for _, fn := range listeners {
+		fn(ctx, record)
+	}
 }
 
 // ResolveApproval handles exec.resolve from the gateway
 func (v *Validator) ResolveApproval(ctx context.Context, req *protocol.ExecApprovalResolveParams) error {
-	return v.ResolveRequest(req.RequestID, req.Approved, req.Reason)
+	if err := v.ResolveRequest(req.RequestID, req.Approved, req.Reason); err != nil {
+		return err
+	}
+
+	v.mu.RLock()
+	traceID := v.pendingRequests[req.RequestID].Request.TraceID
+	v.mu.RUnlock()
+	tracing.Event(tracing.WithTraceID(ctx, traceID), tracing.StageApproval,
+		attribute.String("request_id", req.RequestID),
+		attribute.Bool("approved", req.Approved),
+	)
+	return nil
 }
@@ -2,8 +2,11 @@
 package conscience
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
 	"ghost/kernel/internal/protocol"
 )
 
@@ -92,3 +95,46 @@ func TestValidateActionPath(t *testing.T) {
 		}
 	}
 }
+
+func TestRequestApprovalDryRun(t *testing.T) {
+	v := NewValidator()
+	v.SetFocusedWindow("Notepad")
+	var recorded []*domain.DecisionRecord
+	v.OnDecision(func(ctx context.Context, record *domain.DecisionRecord) {
+		recorded = append(recorded, record)
+	})
+
+	req := &protocol.ExecApprovalRequestParams{
+		RequestID:      "dry-1",
+		Intent:         "save notes",
+		Actions:        json.RawMessage(`[{"type":"CLICK","target":"Save"},{"type":"WRITE","payload":{"path":"notes.txt"}}]`),
+		ExpectedWindow: "Word",
+		DryRun:         true,
+	}
+	result, err := v.RequestApproval(context.Background(), req)
+	if err != nil {
+		t.Fatalf("RequestApproval() error = %v", err)
+	}
+	trace := result.Trace
+	if result.Approved || trace == nil || !trace.DryRun {
+		t.Fatalf("dry run = %+v, want a denial with its trace", result)
+	}
+	// The WRITE needs an override and decides first, but the focus rule is still evaluated
+	if trace.Rule != metrics.RuleRiskOverride || trace.Focus == nil || trace.Focus.Matched {
+		t.Errorf("trace decided by %s with focus %+v, want %s and a focus mismatch", trace.Rule, trace.Focus, metrics.RuleRiskOverride)
+	}
+	if len(trace.Actions) != 2 || trace.Actions[1].Risk != int(protocol.RiskLevelHigh) {
+		t.Errorf("action risks = %+v, want WRITE at high risk", trace.Actions)
+	}
+	if len(v.pendingRequests) != 0 || len(v.GetAuditLog(0)) != 0 || len(recorded) != 0 {
+		t.Errorf("dry run left %d pending, %d audited, %d recorded", len(v.pendingRequests), len(v.GetAuditLog(0)), len(recorded))
+	}
+
+	req.DryRun = false
+	if result, _ := v.RequestApproval(context.Background(), req); result.Approved || result.Trace != nil {
+		t.Errorf("RequestApproval() = %+v, want a denial without a trace", result)
+	}
+	if len(recorded) != 1 || recorded[0].Rule != metrics.RuleRiskOverride {
+		t.Fatalf("recorded %v, want the denial", recorded)
+	}
+}
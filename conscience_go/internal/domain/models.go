@@ -3,9 +3,16 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
 )
 
 // Artifact represents a UI Element captured from the Accessibility Tree
@@ -17,6 +24,8 @@ type Artifact struct {
 	Timestamp      time.Time    `json:"timestamp"`
 	Classification string       `json:"classification,omitempty"`
 	Summary        string       `json:"summary,omitempty"`
+	SourceApp      string       `json:"source_app,omitempty"`   // Process that owned the element
+	WindowTitle    string       `json:"window_title,omitempty"` // Top-level window the element was captured from
 }
 
 // ArtifactType defines the type of UI element
@@ -107,13 +116,14 @@ func NewArtifact(artifactType ArtifactType, content string, boundingBox Bounding
 
 // Command represents an action to be executed by the Sentinel
 type Command struct {
-	ID        string        `json:"id"`
-	Action    CommandAction `json:"action"`
-	Target    string        `json:"target"`
-	Payload   string        `json:"payload"`
-	Status    CommandStatus `json:"status"`
-	CreatedAt time.Time     `json:"created_at"`
-	ExecutedAt *time.Time   `json:"executed_at,omitempty"`
+	ID         string        `json:"id"`
+	Action     CommandAction `json:"action"`
+	Target     string        `json:"target"`
+	Payload    string        `json:"payload"`
+	Status     CommandStatus `json:"status"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ExecutedAt *time.Time    `json:"executed_at,omitempty"`
+	TraceID    string        `json:"trace_id,omitempty"`
 }
 
 // CommandAction defines the type of action to execute
@@ -134,6 +144,7 @@ const (
 	CommandStatusExecuting CommandStatus = "executing"
 	CommandStatusCompleted CommandStatus = "completed"
 	CommandStatusFailed    CommandStatus = "failed"
+	CommandStatusCancelled CommandStatus = "cancelled" // Dropped by an emergency stop
 )
 
 // NewCommand creates a new command with a generated UUID and current timestamp
@@ -165,20 +176,26 @@ type ActionProposal struct {
 	InteractionType InteractionType `json:"interaction_type"`
 	AgentMessage    string          `json:"agent_message,omitempty"`
 	UserResponse    string          `json:"user_response,omitempty"`
+
+	// TraceID ties the proposal to the intent that produced it
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // ActionProposalStatus represents the approval state of an action
 type ActionProposalStatus string
 
 const (
-	ActionProposalStatusPending          ActionProposalStatus = "PENDING"
-	ActionProposalStatusWaitingForUser   ActionProposalStatus = "WAITING_FOR_USER"
+	ActionProposalStatusPending           ActionProposalStatus = "PENDING"
+	ActionProposalStatusWaitingForUser    ActionProposalStatus = "WAITING_FOR_USER"
 	ActionProposalStatusWaitingForContext ActionProposalStatus = "WAITING_FOR_CONTEXT"
-	ActionProposalStatusApproved         ActionProposalStatus = "APPROVED"
-	ActionProposalStatusRejected         ActionProposalStatus = "REJECTED"
-	ActionProposalStatusExecuting        ActionProposalStatus = "EXECUTING"
-	ActionProposalStatusCompleted        ActionProposalStatus = "COMPLETED"
-	ActionProposalStatusFailed           ActionProposalStatus = "FAILED"
+	ActionProposalStatusApproved          ActionProposalStatus = "APPROVED"
+	ActionProposalStatusRejected          ActionProposalStatus = "REJECTED"
+	ActionProposalStatusExecuting         ActionProposalStatus = "EXECUTING"
+	ActionProposalStatusCompleted         ActionProposalStatus = "COMPLETED"
+	ActionProposalStatusFailed            ActionProposalStatus = "FAILED"
+	ActionProposalStatusCancelled         ActionProposalStatus = "CANCELLED" // Dropped by an emergency stop
+	ActionProposalStatusBlocked           ActionProposalStatus = "BLOCKED"   // Held by its batch until the steps it depends on complete
+	ActionProposalStatusSkipped           ActionProposalStatus = "SKIPPED"   // Never ran because its batch stopped or needed no compensation
 )
 
 // InteractionType defines the type of user interaction required
@@ -242,6 +259,337 @@ func NewClarificationRequest(intent string, agentMessage string, payload json.Ra
 	}
 }
 
+// MessageRole names who wrote a message in a proposal's clarification thread
+type MessageRole string
+
+const (
+	MessageRoleAgent MessageRole = "AGENT"
+	MessageRoleUser  MessageRole = "USER"
+)
+
+// QuestionType tells the UI how to render the answer to an agent's question
+type QuestionType string
+
+const (
+	QuestionTypeFreeText     QuestionType = "FREE_TEXT"
+	QuestionTypeSingleChoice QuestionType = "SINGLE_CHOICE" // Answer with exactly one of the options
+	QuestionTypeConfirm      QuestionType = "CONFIRM"       // Answer "yes" or "no"
+)
+
+// MaxMessageLength caps the text of a single thread message
+const MaxMessageLength = 4000
+
+// Question is a structured question an agent message waits on
+type Question struct {
+	Type    QuestionType `json:"type"`
+	Options []string     `json:"options,omitempty"` // Only for SINGLE_CHOICE
+}
+
+// Validate checks that the question can be rendered and answered
+func (q *Question) Validate() error {
+	switch q.Type {
+	case QuestionTypeFreeText, QuestionTypeConfirm:
+		if len(q.Options) > 0 {
+			return fmt.Errorf("%s questions take no options", q.Type)
+		}
+	case QuestionTypeSingleChoice:
+		if len(q.Options) < 2 {
+			return fmt.Errorf("%s questions need at least two options", q.Type)
+		}
+		seen := make(map[string]bool, len(q.Options))
+		for _, option := range q.Options {
+			if strings.TrimSpace(option) == "" {
+				return fmt.Errorf("%s options cannot be empty", q.Type)
+			}
+			if seen[option] {
+				return fmt.Errorf("duplicate option %q", option)
+			}
+			seen[option] = true
+		}
+	default:
+		return fmt.Errorf("unknown question type %q", q.Type)
+	}
+	return nil
+}
+
+// CheckAnswer reports whether text is a valid answer to the question
+func (q *Question) CheckAnswer(text string) error {
+	switch q.Type {
+	case QuestionTypeSingleChoice:
+		for _, option := range q.Options {
+			if text == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("answer must be one of %q", q.Options)
+	case QuestionTypeConfirm:
+		if answer := strings.ToLower(strings.TrimSpace(text)); answer != "yes" && answer != "no" {
+			return fmt.Errorf("answer must be yes or no")
+		}
+	}
+	return nil
+}
+
+// ProposalMessage is one turn of the clarification thread on an action proposal
+type ProposalMessage struct {
+	ID        string      `json:"id"`
+	ActionID  string      `json:"action_id"`
+	Seq       int         `json:"seq"` // Position in the thread, from 1
+	Role      MessageRole `json:"role"`
+	Text      string      `json:"text"`
+	Question  *Question   `json:"question,omitempty"` // Set on agent messages that wait for an answer
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// NewProposalMessage validates a message for the thread of actionID
+// Only agent messages may ask a question.
+func NewProposalMessage(actionID string, role MessageRole, text string, question *Question) (*ProposalMessage, error) {
+	if role != MessageRoleAgent && role != MessageRoleUser {
+		return nil, fmt.Errorf("unknown message role %q", role)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("message text is required")
+	}
+	if len(text) > MaxMessageLength {
+		return nil, fmt.Errorf("message is longer than %d bytes", MaxMessageLength)
+	}
+	if question != nil {
+		if role != MessageRoleAgent {
+			return nil, fmt.Errorf("only the agent can ask a question")
+		}
+		if err := question.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ProposalMessage{
+		ID:        uuid.New().String(),
+		ActionID:  actionID,
+		Role:      role,
+		Text:      text,
+		Question:  question,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NextStatus returns the status the message moves its proposal to, and false once the thread is closed
+// A question puts the proposal back on the user's side as WAITING_FOR_CONTEXT, a plain agent message
+// returns it to the approval inbox, and a user message hands it back to the agent as PENDING.
+// Decided, running and batch-held proposals take no more messages.
+func (m *ProposalMessage) NextStatus(current ActionProposalStatus) (ActionProposalStatus, bool) {
+	switch current {
+	case ActionProposalStatusPending, ActionProposalStatusWaitingForUser, ActionProposalStatusWaitingForContext:
+	default:
+		return current, false
+	}
+
+	if m.Role == MessageRoleUser {
+		return ActionProposalStatusPending, true
+	}
+	if m.Question != nil {
+		return ActionProposalStatusWaitingForContext, true
+	}
+	return ActionProposalStatusWaitingForUser, true
+}
+
+// PayloadChange is one field an approver changed, addressed by JSON Pointer
+// Before is absent for an added field and After for a removed one.
+type PayloadChange struct {
+	Path   string          `json:"path"` // e.g. "/actions/0/payload/path"
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// ProposalRevision is a payload an approver edited before approving the proposal
+type ProposalRevision struct {
+	ID        string          `json:"id"`
+	ActionID  string          `json:"action_id"`
+	Original  json.RawMessage `json:"original"`
+	Edited    json.RawMessage `json:"edited"`
+	Changes   []PayloadChange `json:"changes"`
+	Actor     string          `json:"actor,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewProposalRevision diffs an edited payload against the proposal's own
+// A revision without changes means the approver sent the payload back untouched.
+func NewProposalRevision(action *ActionProposal, edited json.RawMessage, actor string) (*ProposalRevision, error) {
+	changes, err := DiffPayload(action.Payload, edited)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProposalRevision{
+		ID:        uuid.New().String(),
+		ActionID:  action.ID,
+		Original:  action.Payload,
+		Edited:    edited,
+		Changes:   changes,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// DiffPayload lists the fields that differ between two JSON payloads
+// Objects are compared key by key in sorted order and arrays index by index.
+func DiffPayload(before, after json.RawMessage) ([]PayloadChange, error) {
+	var from, to interface{}
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &from); err != nil {
+			return nil, fmt.Errorf("failed to decode original payload: %w", err)
+		}
+	}
+	if err := json.Unmarshal(after, &to); err != nil {
+		return nil, fmt.Errorf("failed to decode edited payload: %w", err)
+	}
+
+	changes := []PayloadChange{}
+	diffValue("", from, to, &changes)
+	return changes, nil
+}
+
+// diffValue appends the differences between before and after at path
+func diffValue(path string, before, after interface{}, changes *[]PayloadChange) {
+	switch from := before.(type) {
+	case map[string]interface{}:
+		if to, ok := after.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(from)+len(to))
+			for key := range from {
+				keys = append(keys, key)
+			}
+			for key := range to {
+				if _, ok := from[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				child := path + "/" + pointerEscaper.Replace(key)
+				old, hadOld := from[key]
+				updated, hasNew := to[key]
+				switch {
+				case !hasNew:
+					*changes = append(*changes, PayloadChange{Path: child, Before: rawJSON(old)})
+				case !hadOld:
+					*changes = append(*changes, PayloadChange{Path: child, After: rawJSON(updated)})
+				default:
+					diffValue(child, old, updated, changes)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if to, ok := after.([]interface{}); ok {
+			for i := 0; i < len(from) || i < len(to); i++ {
+				child := fmt.Sprintf("%s/%d", path, i)
+				switch {
+				case i >= len(to):
+					*changes = append(*changes, PayloadChange{Path: child, Before: rawJSON(from[i])})
+				case i >= len(from):
+					*changes = append(*changes, PayloadChange{Path: child, After: rawJSON(to[i])})
+				default:
+					diffValue(child, from[i], to[i], changes)
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, PayloadChange{Path: path, Before: rawJSON(before), After: rawJSON(after)})
+	}
+}
+
+// pointerEscaper escapes an object key for a JSON Pointer (RFC 6901)
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// rawJSON re-encodes a decoded JSON value
+func rawJSON(v interface{}) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// Grant is a standing approval: a proposal whose every action a grant covers runs without asking
+// Its scope is an action type, optionally narrowed to a process and a path prefix; a grant
+// without ExpiresAt lasts until revoked.
+type Grant struct {
+	ID         string     `json:"id"`
+	ActionType string     `json:"action_type"`
+	Process    string     `json:"process,omitempty"`     // e.g. "code.exe", matched case-insensitively
+	PathPrefix string     `json:"path_prefix,omitempty"` // e.g. "docs", matched on whole path segments
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewGrant validates the scope of a standing approval
+func NewGrant(actionType, process, pathPrefix string, expiresAt *time.Time, createdBy string) (*Grant, error) {
+	now := time.Now()
+	actionType = strings.ToUpper(strings.TrimSpace(actionType))
+	if actionType == "" {
+		return nil, fmt.Errorf("action_type is required")
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("expiry is in the past")
+	}
+	if pathPrefix != "" {
+		var ok bool
+		if pathPrefix, ok = relativePath(pathPrefix); !ok || pathPrefix == "." {
+			return nil, fmt.Errorf("path_prefix must be a relative directory without '..'")
+		}
+	}
+
+	return &Grant{
+		ID:         uuid.New().String(),
+		ActionType: actionType,
+		Process:    strings.TrimSpace(process),
+		PathPrefix: pathPrefix,
+		ExpiresAt:  expiresAt,
+		CreatedBy:  createdBy,
+		CreatedAt:  now,
+	}, nil
+}
+
+// Active reports whether the grant is neither revoked nor expired at now
+func (g *Grant) Active(now time.Time) bool {
+	return g.RevokedAt == nil && (g.ExpiresAt == nil || now.Before(*g.ExpiresAt))
+}
+
+// Covers reports whether the grant allows an action of actionType in process touching actionPath
+// A path-scoped grant never covers an action without a path or one that leaves its directory.
+func (g *Grant) Covers(actionType, process, actionPath string, now time.Time) bool {
+	if !g.Active(now) || !strings.EqualFold(g.ActionType, actionType) {
+		return false
+	}
+	if g.Process != "" && !strings.EqualFold(g.Process, process) {
+		return false
+	}
+	if g.PathPrefix != "" {
+		cleaned, ok := relativePath(actionPath)
+		if !ok || (cleaned != g.PathPrefix && !strings.HasPrefix(cleaned, g.PathPrefix+"/")) {
+			return false
+		}
+	}
+	return true
+}
+
+// relativePath cleans p with forward slashes, and fails for empty, absolute or escaping paths
+func relativePath(p string) (string, bool) {
+	p = strings.ReplaceAll(strings.TrimSpace(p), "\\", "/")
+	if p == "" || strings.HasPrefix(p, "/") || (len(p) > 1 && p[1] == ':') {
+		return "", false
+	}
+	cleaned := path.Clean(p)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// DefaultAutoApproveBelow is the risk score below which AUTO mode approves proposals unless configured otherwise
+const DefaultAutoApproveBelow = 30
+
 // ShouldAutoApprove determines if an action should be auto-approved
 // Based on risk score and user mode settings
 func (ap *ActionProposal) ShouldAutoApprove(userMode *UserMode) bool {
@@ -251,7 +599,117 @@ func (ap *ActionProposal) ShouldAutoApprove(userMode *UserMode) bool {
 	}
 
 	// AUTO mode with low risk (< 30) auto-approves
-	return ap.RiskScore < 30
+	return ap.RiskScore < DefaultAutoApproveBelow
+}
+
+// DecisionTrace explains a permission decision rule by rule
+// Dry runs return it instead of acting; the first rule that fails decides the request.
+type DecisionTrace struct {
+	Path       string       `json:"path"`     // grpc, rest or gateway
+	Decision   string       `json:"decision"` // approved, denied, pending, halted or throttled
+	Rule       string       `json:"rule"`     // The rule that decided it
+	Reason     string       `json:"reason,omitempty"`
+	Checks     []RuleCheck  `json:"checks"` // Every rule evaluated, in order
+	Actions    []ActionRisk `json:"actions,omitempty"`
+	Mode       string       `json:"mode,omitempty"` // Automation mode of the proposal's domain
+	TrustScore int          `json:"trust_score"`
+	Focus      *FocusCheck  `json:"focus,omitempty"`
+	Grants     []string     `json:"grants,omitempty"` // Standing approvals that let it through
+	DryRun     bool         `json:"dry_run"`
+}
+
+// RuleCheck is one rule a request was evaluated against
+type RuleCheck struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ActionRisk is the risk the kernel assigned to one action of a request
+type ActionRisk struct {
+	Index int    `json:"index"`
+	Type  string `json:"type,omitempty"`
+	Risk  int    `json:"risk"`
+}
+
+// FocusCheck compares the window a request expects with the one in focus
+type FocusCheck struct {
+	Expected string `json:"expected,omitempty"` // Empty when the request names no window
+	Actual   string `json:"actual,omitempty"`
+	Matched  bool   `json:"matched"`
+}
+
+// Check records one evaluated rule; if it failed and nothing has decided yet, it decides
+func (t *DecisionTrace) Check(rule string, passed bool, decision, detail string) {
+	t.Checks = append(t.Checks, RuleCheck{Rule: rule, Passed: passed, Detail: detail})
+	if !passed && !t.Decided() {
+		t.Decision, t.Rule, t.Reason = decision, rule, detail
+	}
+}
+
+// Decided reports whether a rule has decided the request
+func (t *DecisionTrace) Decided() bool {
+	return t.Decision != ""
+}
+
+// Settle gives a request no rule refused its decision
+func (t *DecisionTrace) Settle(decision, rule string) {
+	if !t.Decided() {
+		t.Decision, t.Rule = decision, rule
+	}
+}
+
+// Include appends the rules another evaluator checked after this one
+// Its decision applies only if nothing here has decided.
+func (t *DecisionTrace) Include(next *DecisionTrace) {
+	if next == nil {
+		return
+	}
+	t.Checks = append(t.Checks, next.Checks...)
+	t.Actions = append(t.Actions, next.Actions...)
+	t.TrustScore = next.TrustScore
+	if next.Mode != "" {
+		t.Mode = next.Mode
+	}
+	if next.Focus != nil {
+		t.Focus = next.Focus
+	}
+	if !t.Decided() {
+		t.Decision, t.Rule, t.Reason = next.Decision, next.Rule, next.Reason
+	}
+}
+
+// DecisionRecord is a permission request as the policy decided it
+// Request holds everything the rules looked at, so the decision can be replayed under another policy.
+type DecisionRecord struct {
+	ID        string          `json:"id"`
+	Path      string          `json:"path"`
+	Intent    string          `json:"intent"`
+	Domain    string          `json:"domain,omitempty"`
+	Request   json.RawMessage `json:"request"`
+	Decision  string          `json:"decision"`
+	Rule      string          `json:"rule"`
+	TraceID   string          `json:"trace_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewDecisionRecord records the outcome of trace for the request it evaluated
+func NewDecisionRecord(trace *DecisionTrace, intent, domain, traceID string, request interface{}) (*DecisionRecord, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal decision request: %w", err)
+	}
+	return &DecisionRecord{
+		ID:        uuid.New().String(),
+		Path:      trace.Path,
+		Intent:    intent,
+		Domain:    domain,
+		Request:   data,
+		Decision:  trace.Decision,
+		Rule:      trace.Rule,
+		TraceID:   traceID,
+		CreatedAt: time.Now(),
+	}, nil
 }
 
 // Goal represents a natural language goal injected by the user
@@ -262,19 +720,56 @@ type Goal struct {
 	Status    GoalStatus `json:"status"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Planners take the highest priority first, then the earliest deadline, then the oldest goal.
+	// A goal still queued when its deadline passes fails.
+	Priority int        `json:"priority"`
+	Deadline *time.Time `json:"deadline,omitempty"`
+
+	// A SCHEDULED goal becomes ACTIVE at RunAt. With a cron Recurrence it stays SCHEDULED and
+	// each firing queues a new goal whose ParentID points back at it.
+	RunAt      *time.Time `json:"run_at,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+	ParentID   string     `json:"parent_id,omitempty"`
+
+	// Set while a planner holds the goal; an expired lease returns an unstarted goal to ACTIVE
+	LeaseOwner     string     `json:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
 }
 
 // GoalStatus represents the planning state of a goal
 type GoalStatus string
 
 const (
+	GoalStatusScheduled GoalStatus = "SCHEDULED" // Waiting for its run time
 	GoalStatusActive    GoalStatus = "ACTIVE"    // Ready for planner to process
 	GoalStatusPlanning  GoalStatus = "PLANNING"  // Planner is generating steps
 	GoalStatusExecuting GoalStatus = "EXECUTING" // Actions are being executed
+	GoalStatusPaused    GoalStatus = "PAUSED"    // Held by the user; neither fired nor planned
 	GoalStatusCompleted GoalStatus = "COMPLETED" // Goal fully achieved
 	GoalStatusFailed    GoalStatus = "FAILED"    // Goal could not be completed
+	GoalStatusCancelled GoalStatus = "CANCELLED" // Withdrawn by the user
 )
 
+// IsFinal reports whether a goal in this status can no longer change
+func (s GoalStatus) IsFinal() bool {
+	return s == GoalStatusCompleted || s == GoalStatusFailed || s == GoalStatusCancelled
+}
+
+// NextRun returns the first time after after that a cron recurrence fires
+// Accepts standard five-field expressions, descriptors like @daily, and a CRON_TZ= prefix.
+func NextRun(recurrence string, after time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(recurrence)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid recurrence %q: %w", recurrence, err)
+	}
+	next := schedule.Next(after)
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("recurrence %q never fires", recurrence)
+	}
+	return next, nil
+}
+
 // NewGoal creates a new goal with a generated UUID
 func NewGoal(goalText string) *Goal {
 	now := time.Now()
@@ -287,6 +782,378 @@ func NewGoal(goalText string) *Goal {
 	}
 }
 
+// PlanStep is one ordered step of a goal's plan
+// ActionID links the step to the proposal generated for it once the planner submits it.
+type PlanStep struct {
+	ID           string               `json:"id"`
+	GoalID       string               `json:"goal_id"`
+	Position     int                  `json:"position"`
+	Intent       string               `json:"intent"`
+	ActionID     string               `json:"action_id,omitempty"`
+	ActionStatus ActionProposalStatus `json:"action_status,omitempty"`
+	Status       PlanStepStatus       `json:"status"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+// PlanStepStatus represents how far a plan step has progressed
+type PlanStepStatus string
+
+const (
+	PlanStepStatusPending   PlanStepStatus = "PENDING"   // Not yet proposed
+	PlanStepStatusProposed  PlanStepStatus = "PROPOSED"  // Proposal awaiting approval or execution
+	PlanStepStatusCompleted PlanStepStatus = "COMPLETED" // Proposal executed successfully
+	PlanStepStatusFailed    PlanStepStatus = "FAILED"    // Proposal failed, was rejected or was cancelled
+)
+
+// StepStatusFor maps the status of a step's proposal onto the step
+func StepStatusFor(status ActionProposalStatus) PlanStepStatus {
+	switch status {
+	case ActionProposalStatusCompleted:
+		return PlanStepStatusCompleted
+	case ActionProposalStatusFailed, ActionProposalStatusRejected, ActionProposalStatusCancelled:
+		return PlanStepStatusFailed
+	default:
+		return PlanStepStatusProposed
+	}
+}
+
+// DeriveGoalStatus computes a goal's status from its plan
+// Final goals stay final, a goal without steps keeps its current status,
+// and one failed step fails the goal. A queued or paused goal keeps waiting for a planner
+// until its plan completes or fails.
+func DeriveGoalStatus(current GoalStatus, steps []PlanStep) GoalStatus {
+	if current.IsFinal() || len(steps) == 0 {
+		return current
+	}
+
+	completed, started := 0, false
+	for _, step := range steps {
+		switch step.Status {
+		case PlanStepStatusFailed:
+			return GoalStatusFailed
+		case PlanStepStatusCompleted:
+			completed++
+			started = true
+		case PlanStepStatusProposed:
+			started = true
+		}
+	}
+
+	switch {
+	case completed == len(steps):
+		return GoalStatusCompleted
+	case current == GoalStatusActive || current == GoalStatusPaused:
+		// Re-queued after its planner's lease lapsed: it waits for the next planner to claim it
+		return current
+	case started:
+		return GoalStatusExecuting
+	default:
+		return GoalStatusPlanning
+	}
+}
+
+// GoalPlan is a goal with its ordered steps, progress and, if it failed, the step that failed
+type GoalPlan struct {
+	Goal       *Goal      `json:"goal"`
+	Steps      []PlanStep `json:"steps"`
+	Completed  int        `json:"completed"`
+	Total      int        `json:"total"`
+	Progress   float64    `json:"progress"` // Completed fraction of the plan, 0 to 1
+	FailedStep *PlanStep  `json:"failed_step,omitempty"`
+}
+
+// NewGoalPlan summarizes the progress of steps toward goal
+func NewGoalPlan(goal *Goal, steps []PlanStep) *GoalPlan {
+	if steps == nil {
+		steps = []PlanStep{}
+	}
+	plan := &GoalPlan{Goal: goal, Steps: steps, Total: len(steps)}
+	for i := range steps {
+		switch steps[i].Status {
+		case PlanStepStatusCompleted:
+			plan.Completed++
+		case PlanStepStatusFailed:
+			if plan.FailedStep == nil {
+				plan.FailedStep = &steps[i]
+			}
+		}
+	}
+	if plan.Total > 0 {
+		plan.Progress = float64(plan.Completed) / float64(plan.Total)
+	}
+	return plan
+}
+
+// ActionBatch is a multi-step plan the user approves once
+// Steps run in dependency order and the batch stops at the first failure; the
+// compensations of completed steps then run in reverse order to undo them.
+type ActionBatch struct {
+	ID          string            `json:"id"`
+	Intent      string            `json:"intent"`
+	Domain      string            `json:"domain"`
+	Status      ActionBatchStatus `json:"status"`
+	RiskScore   int               `json:"risk_score"`    // Aggregate risk of all steps and compensations, shown when approving
+	MaxStepRisk int               `json:"max_step_risk"` // Risk of the riskiest single step or compensation
+	TraceID     string            `json:"trace_id,omitempty"`
+	FailedStep  *int              `json:"failed_step,omitempty"` // Position of the step that stopped the batch
+	Steps       []BatchStep       `json:"steps"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// BatchStep is one step of a batch with the proposal that runs it
+// Compensation, if set, is proposed with the batch and only runs if a later step fails.
+type BatchStep struct {
+	Position     int             `json:"position"`
+	DependsOn    []int           `json:"depends_on"`
+	Action       *ActionProposal `json:"action"`
+	Compensation *ActionProposal `json:"compensation,omitempty"`
+}
+
+// ActionBatchStatus represents how far a batch has progressed
+type ActionBatchStatus string
+
+const (
+	ActionBatchStatusWaitingForUser ActionBatchStatus = "WAITING_FOR_USER" // Awaiting one approval for the whole batch
+	ActionBatchStatusRunning        ActionBatchStatus = "RUNNING"          // Steps are being released as their dependencies complete
+	ActionBatchStatusCompensating   ActionBatchStatus = "COMPENSATING"     // A step failed; completed steps are being undone
+	ActionBatchStatusCompleted      ActionBatchStatus = "COMPLETED"        // Every step completed
+	ActionBatchStatusFailed         ActionBatchStatus = "FAILED"           // A step failed and compensation has finished
+	ActionBatchStatusRejected       ActionBatchStatus = "REJECTED"         // The user rejected the batch
+	ActionBatchStatusCancelled      ActionBatchStatus = "CANCELLED"        // Withdrawn by an emergency stop before it was approved
+)
+
+// IsFinal reports whether a batch in this status can no longer change
+func (s ActionBatchStatus) IsFinal() bool {
+	switch s {
+	case ActionBatchStatusCompleted, ActionBatchStatusFailed, ActionBatchStatusRejected, ActionBatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// AggregateRisk combines step risks as independent chances of harm
+// Two steps of risk 20 give 36: never less than the riskiest step, never more than 100.
+func AggregateRisk(scores []int) int {
+	safe := 1.0
+	for _, score := range scores {
+		score = min(max(score, 0), 100)
+		safe *= 1 - float64(score)/100
+	}
+	return int(math.Round(100 * (1 - safe)))
+}
+
+// NewActionBatch creates a batch awaiting approval from its steps
+// Steps are numbered in order; a step without DependsOn depends on the step before it.
+// Compensations run unattended on failure, so their risk counts like a step's.
+func NewActionBatch(intent string, domain string, steps []BatchStep) (*ActionBatch, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("a batch needs at least one step")
+	}
+
+	now := time.Now()
+	batch := &ActionBatch{
+		ID:        uuid.New().String(),
+		Intent:    intent,
+		Domain:    domain,
+		Status:    ActionBatchStatusWaitingForUser,
+		Steps:     steps,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	scores := make([]int, 0, 2*len(steps))
+	for i := range steps {
+		step := &steps[i]
+		if step.Action == nil {
+			return nil, fmt.Errorf("step %d has no action", i)
+		}
+		step.Position = i
+		if step.DependsOn == nil {
+			step.DependsOn = []int{}
+			if i > 0 {
+				step.DependsOn = []int{i - 1}
+			}
+		}
+		for _, dep := range step.DependsOn {
+			if dep < 0 || dep >= i {
+				return nil, fmt.Errorf("step %d can only depend on earlier steps, not %d", i, dep)
+			}
+		}
+
+		step.Action.Status = ActionProposalStatusBlocked
+		scores = append(scores, step.Action.RiskScore)
+		batch.MaxStepRisk = max(batch.MaxStepRisk, step.Action.RiskScore)
+		if step.Compensation != nil {
+			step.Compensation.Status = ActionProposalStatusBlocked
+			scores = append(scores, step.Compensation.RiskScore)
+			batch.MaxStepRisk = max(batch.MaxStepRisk, step.Compensation.RiskScore)
+		}
+	}
+	batch.RiskScore = AggregateRisk(scores)
+	return batch, nil
+}
+
+// Decide approves or rejects a batch awaiting the user and returns the proposals it changed
+// Approval releases the steps with no dependencies; rejection rejects every step.
+func (b *ActionBatch) Decide(approved bool) []*ActionProposal {
+	if b.Status != ActionBatchStatusWaitingForUser {
+		return nil
+	}
+	if approved {
+		b.Status = ActionBatchStatusRunning
+		return b.Advance()
+	}
+
+	b.Status = ActionBatchStatusRejected
+	var changed []*ActionProposal
+	for _, step := range b.Steps {
+		changed = setStatus(changed, step.Action, ActionProposalStatusBlocked, ActionProposalStatusRejected)
+		changed = setStatus(changed, step.Compensation, ActionProposalStatusBlocked, ActionProposalStatusSkipped)
+	}
+	return changed
+}
+
+// Advance moves a running or compensating batch forward after its proposals change
+// and returns the proposals whose status it changed. A failed, rejected or cancelled
+// step skips the steps that have not run and starts compensation, which undoes the
+// completed steps one at a time, latest first, once nothing is still executing.
+func (b *ActionBatch) Advance() []*ActionProposal {
+	var changed []*ActionProposal
+
+	if b.Status == ActionBatchStatusRunning || b.Status == ActionBatchStatusWaitingForUser {
+		for _, step := range b.Steps {
+			if stopsBatch(statusOf(step.Action)) {
+				position := step.Position
+				b.FailedStep = &position
+				if b.Status == ActionBatchStatusWaitingForUser {
+					b.Status = ActionBatchStatusCancelled
+				} else {
+					b.Status = ActionBatchStatusCompensating
+				}
+				break
+			}
+		}
+	}
+
+	switch b.Status {
+	case ActionBatchStatusRunning:
+		completed := make(map[int]bool, len(b.Steps))
+		for _, step := range b.Steps {
+			if statusOf(step.Action) == ActionProposalStatusCompleted {
+				completed[step.Position] = true
+			}
+		}
+		if len(completed) == len(b.Steps) {
+			b.Status = ActionBatchStatusCompleted
+			for _, step := range b.Steps {
+				changed = setStatus(changed, step.Compensation, ActionProposalStatusBlocked, ActionProposalStatusSkipped)
+			}
+			return changed
+		}
+		for _, step := range b.Steps {
+			if statusOf(step.Action) != ActionProposalStatusBlocked {
+				continue
+			}
+			ready := true
+			for _, dep := range step.DependsOn {
+				ready = ready && completed[dep]
+			}
+			if ready {
+				changed = setStatus(changed, step.Action, ActionProposalStatusBlocked, ActionProposalStatusApproved)
+			}
+		}
+
+	case ActionBatchStatusCancelled:
+		for _, step := range b.Steps {
+			changed = setStatus(changed, step.Action, ActionProposalStatusBlocked, ActionProposalStatusSkipped)
+			changed = setStatus(changed, step.Compensation, ActionProposalStatusBlocked, ActionProposalStatusSkipped)
+		}
+
+	case ActionBatchStatusCompensating:
+		for _, step := range b.Steps {
+			changed = setStatus(changed, step.Action, ActionProposalStatusBlocked, ActionProposalStatusSkipped)
+			changed = setStatus(changed, step.Action, ActionProposalStatusApproved, ActionProposalStatusSkipped)
+		}
+		for _, step := range b.Steps {
+			if statusOf(step.Action) == ActionProposalStatusExecuting {
+				return changed // Undo only once the Body has finished what it started
+			}
+			switch statusOf(step.Compensation) {
+			case ActionProposalStatusApproved, ActionProposalStatusExecuting:
+				return changed
+			}
+		}
+		for i := len(b.Steps) - 1; i >= 0; i-- {
+			step := b.Steps[i]
+			if statusOf(step.Action) == ActionProposalStatusCompleted && statusOf(step.Compensation) == ActionProposalStatusBlocked {
+				return setStatus(changed, step.Compensation, ActionProposalStatusBlocked, ActionProposalStatusApproved)
+			}
+		}
+		b.Status = ActionBatchStatusFailed
+		for _, step := range b.Steps {
+			changed = setStatus(changed, step.Compensation, ActionProposalStatusBlocked, ActionProposalStatusSkipped)
+		}
+	}
+	return changed
+}
+
+// stopsBatch reports whether a step in this status stops its batch
+func stopsBatch(status ActionProposalStatus) bool {
+	switch status {
+	case ActionProposalStatusFailed, ActionProposalStatusRejected, ActionProposalStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// statusOf returns the status of a proposal that may be missing
+func statusOf(action *ActionProposal) ActionProposalStatus {
+	if action == nil {
+		return ""
+	}
+	return action.Status
+}
+
+// setStatus moves action from one status to another and records it in changed
+func setStatus(changed []*ActionProposal, action *ActionProposal, from, to ActionProposalStatus) []*ActionProposal {
+	if action == nil || action.Status != from {
+		return changed
+	}
+	action.Status = to
+	return append(changed, action)
+}
+
+// Memory is a long-term fact stored by the Brain via memory.store
+type Memory struct {
+	ID        string     `json:"id"`
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	Context   string     `json:"context,omitempty"`
+	Embedding []float32  `json:"embedding,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewMemory creates a new memory with a generated UUID
+// A ttl of zero keeps the memory until it is explicitly forgotten
+func NewMemory(key string, value string, context string, ttl time.Duration) *Memory {
+	now := time.Now()
+	memory := &Memory{
+		ID:        uuid.New().String(),
+		Key:       key,
+		Value:     value,
+		Context:   context,
+		CreatedAt: now,
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		memory.ExpiresAt = &expiresAt
+	}
+	return memory
+}
+
 // AppState represents the global consciousness state of Engram
 type AppState string
 
@@ -10,17 +10,24 @@ package gateway
 import (
 	"bufio"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
 	"ghost/kernel/internal/protocol"
+	"ghost/kernel/internal/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Server is the Ghost Gateway server
@@ -29,6 +36,7 @@ type Server struct {
 	port           int
 	authToken      string
 	clients        map[string]*Client
+	requesters     map[string]string // Request ID awaiting a second factor -> the client that asked; guarded by clientsMu
 	clientsMu      sync.RWMutex
 	startTime      time.Time
 	handlers       map[string]MethodHandler
@@ -37,6 +45,17 @@ type Server struct {
 	// Dependencies
 	approvalHandler ApprovalHandler
 	memoryHandler   MemoryHandler
+	limiter         Limiter
+	stopper         EmergencyStopper
+	confirmer       Confirmer
+	approvers       map[string]string // Registered approval client ID -> its own token
+
+	// Shutdown state: open connections, whether they are draining, and the running Serve
+	connsMu  sync.Mutex
+	conns    map[net.Conn]struct{}
+	draining bool
+	stop     context.CancelFunc
+	done     chan struct{}
 }
 
 // Client represents a connected client
@@ -48,6 +67,8 @@ type Client struct {
 	Authenticated bool
 	ConnectedAt   time.Time
 	Capabilities  []string
+	Approver      string // Registered approval client ID when it connected with that client's token
+	Identity      string // Credential it authenticated with; throttling is keyed by it, not by the connection
 }
 
 // MethodHandler processes a JSON-RPC method call
@@ -59,6 +80,26 @@ type ApprovalHandler interface {
 	ResolveApproval(ctx context.Context, req *protocol.ExecApprovalResolveParams) error
 }
 
+// Limiter admits or refuses exec.request calls before they reach the ApprovalHandler
+// A refusal that implements json.Marshaler is sent as the error's data.
+type Limiter interface {
+	Allow(ctx context.Context, client, domain, intent string) error
+	Peek(client, domain, intent string) error // The refusal Allow would return, without consuming anything
+	RecordDenial(ctx context.Context, client, domain string)
+}
+
+// EmergencyStopper pulls the kill switch for system.estop and refuses exec.request while it is engaged
+type EmergencyStopper interface {
+	Halt(ctx context.Context, actor, reason string) (*protocol.StopResult, error)
+	Engaged() bool
+}
+
+// Confirmer signs the approvals that let a high-risk exec.request through on its second try
+// approver is the registered approval client confirming, or empty when the caller must give a code.
+type Confirmer interface {
+	ConfirmApproval(ctx context.Context, req *protocol.ExecConfirmParams, approver string) (*protocol.SignedApproval, error)
+}
+
 // MemoryHandler interface for memory operations
 type MemoryHandler interface {
 	Store(ctx context.Context, req *protocol.MemoryStoreParams) (*protocol.MemoryStoreResult, error)
@@ -72,9 +113,11 @@ func NewServer(host string, port int, authToken string) *Server {
 		port:           port,
 		authToken:      authToken,
 		clients:        make(map[string]*Client),
+		requesters:     make(map[string]string),
 		startTime:      time.Now(),
 		handlers:       make(map[string]MethodHandler),
 		eventBroadcast: make(chan protocol.EventFrame, 100),
+		conns:          make(map[net.Conn]struct{}),
 	}
 
 	// Register method handlers
@@ -88,6 +131,27 @@ func (s *Server) SetApprovalHandler(h ApprovalHandler) {
 	s.approvalHandler = h
 }
 
+// SetLimiter rate-limits exec.request per client and intent
+func (s *Server) SetLimiter(l Limiter) {
+	s.limiter = l
+}
+
+// SetEmergencyStopper enables system.estop for every client
+func (s *Server) SetEmergencyStopper(st EmergencyStopper) {
+	s.stopper = st
+}
+
+// SetConfirmer enables exec.confirm
+func (s *Server) SetConfirmer(c Confirmer) {
+	s.confirmer = c
+}
+
+// SetApprovers registers the approval clients, such as the user's phone, by client ID and token
+// A client that connects with its client_id and that token may confirm without a one-time code.
+func (s *Server) SetApprovers(tokens map[string]string) {
+	s.approvers = tokens
+}
+
 // SetMemoryHandler sets the memory operations handler
 func (s *Server) SetMemoryHandler(h MemoryHandler) {
 	s.memoryHandler = h
@@ -100,16 +164,27 @@ func (s *Server) registerHandlers() {
 	s.handlers["talk_mode"] = s.handleTalkMode
 	s.handlers["exec.request"] = s.handleExecRequest
 	s.handlers["exec.resolve"] = s.handleExecResolve
+	s.handlers["exec.confirm"] = s.handleExecConfirm
 	s.handlers["memory.store"] = s.handleMemoryStore
 	s.handlers["memory.search"] = s.handleMemorySearch
 	s.handlers["focus.update"] = s.handleFocusUpdate
 	s.handlers["session.snapshot"] = s.handleSessionSnapshot
 	s.handlers["session.update"] = s.handleSessionUpdate
 	s.handlers["registry.snapshot"] = s.handleRegistrySnapshot
+	s.handlers["system.estop"] = s.handleEmergencyStop
 }
 
-// Start begins listening for connections
+// Start begins listening for connections and serves until ctx is cancelled
 func (s *Server) Start(ctx context.Context) error {
+	listener, err := s.Listen()
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Listen binds the gateway address so bind errors surface before serving
+func (s *Server) Listen() (net.Listener, error) {
 	// Security: Enforce localhost binding if host is empty or 0.0.0.0
 	if s.host == "" || s.host == "0.0.0.0" {
 		s.host = "127.0.0.1"
@@ -118,36 +193,154 @@ func (s *Server) Start(ctx context.Context) error {
 	listenAddr := fmt.Sprintf("%s:%d", s.host, s.port)
 	listener, err := net.Listen("tcp", listenAddr)
 	if err != nil {
-		return fmt.Errorf("failed to bind to %s: %w", listenAddr, err)
+		return nil, fmt.Errorf("failed to bind to %s: %w", listenAddr, err)
 	}
-	defer listener.Close()
+	return listener, nil
+}
+
+// Serve accepts connections on listener until ctx is cancelled or Shutdown is called
+// On shutdown it stops accepting, lets in-flight requests finish and returns nil once every
+// connection has closed.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	defer close(done)
 
-	slog.Info("Ghost Gateway listening", "address", listenAddr, "protocol", protocol.ProtocolVersion)
-	fmt.Printf("[GATEWAY] 🌐 WebSocket Gateway listening on %s (Protocol v%s)\n", listenAddr, protocol.ProtocolVersion)
+	s.connsMu.Lock()
+	s.stop = cancel
+	s.done = done
+	s.draining = false
+	s.connsMu.Unlock()
+
+	slog.Info("Ghost Gateway listening", "address", listener.Addr().String(), "protocol", protocol.ProtocolVersion)
+	fmt.Printf("[GATEWAY] 🌐 WebSocket Gateway listening on %s (Protocol v%s)\n", listener.Addr().String(), protocol.ProtocolVersion)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
 
 	// Start event broadcaster
-	go s.broadcastLoop(ctx)
+	go func() {
+		defer wg.Done()
+		s.broadcastLoop(ctx)
+	}()
 
 	// Start heartbeat ticker
-	go s.heartbeatLoop(ctx)
-
+	go func() {
+		defer wg.Done()
+		s.heartbeatLoop(ctx)
+	}()
+
+	// Accept blocks, so closing the listener is what unblocks it on cancellation
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		listener.Close()
+		s.drainConnections()
+	}()
+
+	var serveErr error
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			conn, err := listener.Accept()
-			if err != nil {
-				slog.Error("Connection accept error", "error", err)
-				continue
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
 			}
-			go s.handleConnection(ctx, conn)
+			if errors.Is(err, net.ErrClosed) {
+				serveErr = fmt.Errorf("gateway listener closed: %w", err)
+				break
+			}
+			slog.Error("Connection accept error", "error", err)
+			continue
+		}
+
+		if !s.trackConn(conn) {
+			conn.Close()
+			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleConnection(ctx, conn)
+		}()
+	}
+
+	cancel()
+	wg.Wait()
+	return serveErr
+}
+
+// Shutdown stops Serve and waits for in-flight requests to finish
+// If ctx expires first the remaining connections are closed and ctx's error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.connsMu.Lock()
+	stop, done := s.stop, s.done
+	s.connsMu.Unlock()
+	if stop == nil {
+		return nil
+	}
+
+	stop()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.closeConnections()
+		return ctx.Err()
+	}
+}
+
+// trackConn registers a new connection, refusing it once the server is draining
+func (s *Server) trackConn(conn net.Conn) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	if s.draining {
+		return false
 	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+// untrackConn forgets a closed connection
+func (s *Server) untrackConn(conn net.Conn) {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	delete(s.conns, conn)
+}
+
+// drainConnections wakes every connection blocked on a read so it exits after its current request
+func (s *Server) drainConnections() {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	s.draining = true
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+}
+
+// closeConnections force-closes every open connection
+func (s *Server) closeConnections() {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// armReadDeadline extends a connection's read deadline unless the server is draining
+func (s *Server) armReadDeadline(conn net.Conn) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	if s.draining {
+		return false
+	}
+	// Set read deadline to prevent hanging connections (heartbeat is 30s)
+	conn.SetReadDeadline(time.Now().Add(65 * time.Second))
+	return true
 }
 
 // handleConnection processes a single client connection
 func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
+	defer s.untrackConn(conn)
 	defer conn.Close()
 
 	client := &Client{
@@ -160,8 +353,9 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	scanner := bufio.NewScanner(conn)
 
 	for {
-		// Set read deadline to prevent hanging connections (heartbeat is 30s)
-		conn.SetReadDeadline(time.Now().Add(65 * time.Second))
+		if !s.armReadDeadline(conn) {
+			break
+		}
 
 		if !scanner.Scan() {
 			break
@@ -192,9 +386,15 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 	// Cleanup on disconnect
 	s.clientsMu.Lock()
 	delete(s.clients, client.ID)
+	for requestID, requester := range s.requesters {
+		if requester == client.ID {
+			delete(s.requesters, requestID)
+		}
+	}
 	s.clientsMu.Unlock()
 
 	if client.Authenticated {
+		metrics.GatewayClientDisconnected(client.Type)
 		slog.Info("Client disconnected", "client_id", client.ID, "type", client.Type)
 	}
 }
@@ -225,7 +425,15 @@ func (s *Server) dispatchMethod(ctx context.Context, client *Client, frame *prot
 		return
 	}
 
-	// Execute handler
+	// Only the methods granted to the client type declared at connect
+	if !slices.Contains(client.Capabilities, frame.Method) {
+		s.sendError(client, frame.ID, protocol.ErrCodePermissionDenied, fmt.Sprintf("Method not permitted for %q clients: %s", client.Type, frame.Method), nil)
+		return
+	}
+
+	// Execute handler in its own span; gateway frames carry no trace headers
+	ctx, span := tracing.Start(ctx, "gateway/"+frame.Method, attribute.String("client.type", client.Type))
+	defer span.End()
 	result, errShape := handler(ctx, client, frame.Params)
 	if errShape != nil {
 		s.sendError(client, frame.ID, errShape.Code, errShape.Message, errShape.Data)
@@ -265,6 +473,7 @@ func (s *Server) sendError(client *Client, id string, code int, message string,
 // broadcastEvent sends an event to all authenticated clients
 func (s *Server) broadcastEvent(event protocol.EventFrame) {
 	s.eventBroadcast <- event
+	metrics.EventPublished("gateway", event.Method)
 }
 
 // broadcastLoop processes the event broadcast channel
@@ -279,6 +488,9 @@ func (s *Server) broadcastLoop(ctx context.Context) {
 				if client.Authenticated {
 					if err := client.Encoder.Encode(event); err != nil {
 						slog.Warn("Failed to broadcast event", "client_id", client.ID, "error", err)
+						metrics.EventDelivered("gateway", "failed")
+					} else {
+						metrics.EventDelivered("gateway", "sent")
 					}
 				}
 			}
@@ -287,6 +499,45 @@ func (s *Server) broadcastLoop(ctx context.Context) {
 	}
 }
 
+// BroadcastStop tells every client to abort in-flight input; the kill switch calls it on every stop
+// It never blocks: a stop must not wait on a full event queue.
+func (s *Server) BroadcastStop(reason string) {
+	event := protocol.EventFrame{
+		JSONRPC: "2.0",
+		Method:  "system.stop",
+	}
+	data, err := json.Marshal(protocol.EmergencyStopEvent{Reason: reason, Timestamp: time.Now()})
+	if err != nil {
+		return
+	}
+	event.Params = data
+
+	select {
+	case s.eventBroadcast <- event:
+		metrics.EventPublished("gateway", event.Method)
+	default:
+		slog.Error("Event queue full, emergency stop not broadcast to gateway clients")
+		metrics.EventDelivered("gateway", "dropped")
+	}
+}
+
+// PublishPending asks approval clients to confirm an approval the kernel holds itself, such as a person
+// approving a high-risk proposal over REST; their confirmation stays with the kernel, not a requester.
+// Like BroadcastStop it never blocks, so an approval request does not wait on a full event queue.
+func (s *Server) PublishPending(pending protocol.ApprovalPendingEvent) {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return
+	}
+	select {
+	case s.eventBroadcast <- protocol.EventFrame{JSONRPC: "2.0", Method: "exec.pending", Params: data}:
+		metrics.EventPublished("gateway", "exec.pending")
+	default:
+		slog.Warn("Event queue full, pending approval not sent to approval clients", "request_id", pending.RequestID)
+		metrics.EventDelivered("gateway", "dropped")
+	}
+}
+
 // heartbeatLoop sends periodic tick events
 func (s *Server) heartbeatLoop(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
@@ -321,23 +572,41 @@ func (s *Server) handleConnect(ctx context.Context, client *Client, params json.
 		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInvalidParams, Message: "Invalid connect params"}
 	}
 
-	// Validate token
-	if req.Token != s.authToken {
+	// Validate token; an approval client has its own
+	approver := ""
+	if token, ok := s.approvers[req.ClientID]; ok && req.ClientID != "" && subtle.ConstantTimeCompare([]byte(req.Token), []byte(token)) == 1 {
+		approver = req.ClientID
+	} else if req.Token != s.authToken {
 		slog.Warn("Authentication failed", "client_id", client.ID, "remote_addr", client.Conn.RemoteAddr())
 		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeAuthFailed, Message: "Invalid authentication token"}
 	}
 
-	// Set client info
+	// Set client info; a repeated connect re-registers the client under its new type
+	if client.Authenticated {
+		metrics.GatewayClientDisconnected(client.Type)
+	}
 	client.Authenticated = true
 	client.Type = req.ClientType
 	client.Capabilities = s.getCapabilitiesForType(req.ClientType)
+	client.Approver = approver
+	// Confirming is tied to the approver's own token, not to the client type a caller declares
+	if approver != "" {
+		client.Capabilities = append(client.Capabilities, "exec.confirm")
+	}
+	// Like the REST and gRPC paths, which throttle per role, clients sharing the gateway token share
+	// its limits; reconnecting does not buy a fresh bucket or breaker
+	client.Identity = "token"
+	if approver != "" {
+		client.Identity = "approver:" + approver
+	}
 
 	// Register client
 	s.clientsMu.Lock()
 	s.clients[client.ID] = client
 	s.clientsMu.Unlock()
+	metrics.GatewayClientConnected(client.Type)
 
-	slog.Info("Client authenticated", "client_id", client.ID, "type", client.Type)
+	slog.Info("Client authenticated", "client_id", client.ID, "type", client.Type, "approver", client.Approver)
 	fmt.Printf("[GATEWAY] ✓ Client authenticated: %s (%s)\n", client.ID[:8], client.Type)
 
 	result := protocol.ConnectResult{
@@ -394,21 +663,195 @@ func (s *Server) handleExecRequest(ctx context.Context, client *Client, params j
 		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInvalidParams, Message: "Invalid exec.request params"}
 	}
 
-	slog.Info("Execution approval requested", "request_id", req.RequestID, "intent", req.Intent, "risk_level", req.RiskLevel)
+	slog.Info("Execution approval requested", "request_id", req.RequestID, "intent", req.Intent, "risk_level", req.RiskLevel, "trace_id", req.TraceID)
+	ctx = tracing.WithTraceID(ctx, req.TraceID)
 
 	if s.approvalHandler == nil {
 		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInternalError, Message: "No approval handler configured"}
 	}
 
+	if req.DryRun {
+		return s.dryRunExecRequest(ctx, client, &req)
+	}
+
+	if s.stopper != nil && s.stopper.Engaged() {
+		recordDecision(ctx, metrics.DecisionHalted, metrics.RuleEmergencyStop)
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodePermissionDenied, Message: "Emergency stop engaged"}
+	}
+
+	// Back-pressure: tell a looping planner to slow down instead of queueing its requests
+	limiterKey := "gateway:" + client.Identity
+	if s.limiter != nil {
+		if err := s.limiter.Allow(ctx, limiterKey, "", req.Intent); err != nil {
+			slog.Warn("Execution request throttled", "client_id", client.ID, "identity", client.Identity, "error", err)
+			recordDecision(ctx, metrics.DecisionThrottled, metrics.RuleThrottle)
+			shape := &protocol.ErrorShape{Code: protocol.ErrCodeRiskBlocked, Message: err.Error()}
+			if m, ok := err.(json.Marshaler); ok {
+				shape.Data, _ = m.MarshalJSON()
+			}
+			return nil, shape
+		}
+	}
+
 	result, err := s.approvalHandler.RequestApproval(ctx, &req)
 	if err != nil {
+		if s.limiter != nil {
+			s.limiter.RecordDenial(ctx, limiterKey, "")
+		}
 		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInternalError, Message: err.Error()}
 	}
+	if !result.Approved && s.limiter != nil {
+		s.limiter.RecordDenial(ctx, limiterKey, "")
+	}
+	// Approval clients prompt the user for the second factor, showing exactly what it approves
+	if result.ErrorCode == protocol.ErrorCodeApprovalRequired {
+		s.clientsMu.Lock()
+		s.requesters[result.RequestID] = client.ID
+		s.clientsMu.Unlock()
+		s.publish("exec.pending", protocol.ApprovalPendingEvent{
+			RequestID:      result.RequestID,
+			Intent:         req.Intent,
+			RiskLevel:      result.RiskLevel,
+			Actions:        req.Actions,
+			ExpectedWindow: req.ExpectedWindow,
+			Digest:         result.Digest,
+			Timestamp:      time.Now(),
+		})
+	}
 
 	data, _ := json.Marshal(result)
 	return data, nil
 }
 
+// handleExecConfirm confirms a high-risk exec.request as a second factor
+// The client that made the request cannot confirm it. Only that client receives the signed approval,
+// as an exec.confirmed event, and re-sends the request with it to have it approved; the confirming
+// client gets the approval without its signature, so it cannot present it first. Confirmations draw
+// from the same limiter as exec.request and a refused one counts as a denial, so codes cannot be
+// guessed at speed.
+func (s *Server) handleExecConfirm(ctx context.Context, client *Client, params json.RawMessage) (json.RawMessage, *protocol.ErrorShape) {
+	var req protocol.ExecConfirmParams
+	if err := json.Unmarshal(params, &req); err != nil || req.RequestID == "" {
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInvalidParams, Message: "Invalid exec.confirm params"}
+	}
+	if s.confirmer == nil {
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInternalError, Message: "No second-factor approval configured"}
+	}
+
+	s.clientsMu.RLock()
+	own := s.requesters[req.RequestID] == client.ID
+	s.clientsMu.RUnlock()
+	if own {
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodePermissionDenied, Message: "A request must be confirmed by a client other than the one that made it"}
+	}
+
+	limiterKey := "gateway:" + client.Identity
+	if s.limiter != nil {
+		if err := s.limiter.Allow(ctx, limiterKey, "", ""); err != nil {
+			slog.Warn("Execution confirmation throttled", "client_id", client.ID, "identity", client.Identity, "error", err)
+			shape := &protocol.ErrorShape{Code: protocol.ErrCodeRiskBlocked, Message: err.Error()}
+			if m, ok := err.(json.Marshaler); ok {
+				shape.Data, _ = m.MarshalJSON()
+			}
+			return nil, shape
+		}
+	}
+
+	approval, err := s.confirmer.ConfirmApproval(ctx, &req, client.Approver)
+	if err != nil {
+		if s.limiter != nil {
+			s.limiter.RecordDenial(ctx, limiterKey, "")
+		}
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodePermissionDenied, Message: err.Error()}
+	}
+
+	s.clientsMu.Lock()
+	requesterID := s.requesters[req.RequestID]
+	delete(s.requesters, req.RequestID)
+	if requester := s.clients[requesterID]; requester != nil && requester != client {
+		s.sendEvent(requester, "exec.confirmed", approval)
+	}
+	s.clientsMu.Unlock()
+
+	unsigned := *approval
+	unsigned.Signature = ""
+	data, _ := json.Marshal(unsigned)
+	return data, nil
+}
+
+// sendEvent sends an event to one client; the caller holds clientsMu
+func (s *Server) sendEvent(client *Client, method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		slog.Error("Failed to encode event", "method", method, "error", err)
+		return
+	}
+	if err := client.Encoder.Encode(protocol.EventFrame{JSONRPC: "2.0", Method: method, Params: data}); err != nil {
+		slog.Warn("Failed to send event", "client_id", client.ID, "method", method, "error", err)
+		metrics.EventDelivered("gateway", "failed")
+		return
+	}
+	metrics.EventPublished("gateway", method)
+	metrics.EventDelivered("gateway", "sent")
+}
+
+// publish broadcasts an event with params to every authenticated client
+func (s *Server) publish(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		slog.Error("Failed to encode event", "method", method, "error", err)
+		return
+	}
+	s.broadcastEvent(protocol.EventFrame{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// dryRunExecRequest returns how exec.request would be decided, without throttling, validating or recording it
+func (s *Server) dryRunExecRequest(ctx context.Context, client *Client, req *protocol.ExecApprovalRequestParams) (json.RawMessage, *protocol.ErrorShape) {
+	trace := &domain.DecisionTrace{Path: metrics.PathGateway, DryRun: true}
+	if s.stopper != nil {
+		engaged := s.stopper.Engaged()
+		trace.Check(metrics.RuleEmergencyStop, !engaged, metrics.DecisionHalted, reasonIf(engaged, "Emergency stop engaged"))
+	}
+	if s.limiter != nil {
+		err := s.limiter.Peek("gateway:"+client.Identity, "", req.Intent)
+		trace.Check(metrics.RuleThrottle, err == nil, metrics.DecisionThrottled, errorDetail(err))
+	}
+
+	result, err := s.approvalHandler.RequestApproval(ctx, req)
+	if err != nil {
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInternalError, Message: err.Error()}
+	}
+	trace.Include(result.Trace)
+	result.Trace = trace
+	result.Approved = trace.Decision == metrics.DecisionApproved
+	result.Reason = trace.Reason
+
+	data, _ := json.Marshal(result)
+	return data, nil
+}
+
+// reasonIf returns reason when a check failed
+func reasonIf(failed bool, reason string) string {
+	if failed {
+		return reason
+	}
+	return ""
+}
+
+// errorDetail returns an error's message, or nothing for a nil error
+func errorDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// recordDecision counts an exec.request refused before validation and records it on the intent's trace
+func recordDecision(ctx context.Context, decision, rule string) {
+	metrics.Permission(metrics.PathGateway, decision, rule)
+	tracing.Decision(ctx, metrics.PathGateway, decision, rule)
+}
+
 func (s *Server) handleExecResolve(ctx context.Context, client *Client, params json.RawMessage) (json.RawMessage, *protocol.ErrorShape) {
 	var req protocol.ExecApprovalResolveParams
 	if err := json.Unmarshal(params, &req); err != nil {
@@ -499,6 +942,27 @@ func (s *Server) handleFocusUpdate(ctx context.Context, client *Client, params j
 	return data, nil
 }
 
+// handleEmergencyStop pulls the kill switch; any authenticated client may, none may re-arm it
+func (s *Server) handleEmergencyStop(ctx context.Context, client *Client, params json.RawMessage) (json.RawMessage, *protocol.ErrorShape) {
+	if s.stopper == nil {
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInternalError, Message: "No emergency stop configured"}
+	}
+
+	// A stop is never refused for malformed params
+	var req protocol.EmergencyStopParams
+	_ = json.Unmarshal(params, &req)
+
+	slog.Warn("Emergency stop requested via gateway", "client_id", client.ID, "client_type", client.Type)
+
+	result, err := s.stopper.Halt(ctx, "gateway:"+client.ID, req.Reason)
+	if err != nil {
+		return nil, &protocol.ErrorShape{Code: protocol.ErrCodeInternalError, Message: err.Error()}
+	}
+
+	data, _ := json.Marshal(result)
+	return data, nil
+}
+
 func (s *Server) handleSessionSnapshot(ctx context.Context, client *Client, params json.RawMessage) (json.RawMessage, *protocol.ErrorShape) {
 	var req protocol.SessionSnapshotParams
 	if err := json.Unmarshal(params, &req); err != nil {
@@ -583,13 +1047,13 @@ func (s *Server) handleRegistrySnapshot(ctx context.Context, client *Client, par
 func (s *Server) getCapabilitiesForType(clientType string) []string {
 	switch clientType {
 	case "brain":
-		return []string{"exec.request", "memory.store", "memory.search", "session.snapshot", "session.update", "registry.snapshot"}
+		return []string{"exec.request", "memory.store", "memory.search", "session.snapshot", "session.update", "registry.snapshot", "system.estop"}
 	case "sentinel":
-		return []string{"focus.update"}
+		return []string{"focus.update", "system.estop"}
 	case "ears":
-		return []string{"wake", "talk_mode"}
+		return []string{"wake", "talk_mode", "system.estop"}
 	case "external":
-		return []string{"wake", "talk_mode", "session.snapshot"} // Limited for mobile/external clients
+		return []string{"wake", "talk_mode", "session.snapshot", "exec.resolve", "system.estop"} // Limited for mobile/external clients
 	default:
 		return []string{}
 	}
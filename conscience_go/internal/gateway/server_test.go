@@ -0,0 +1,618 @@
+// Author: Enkae (enkae.dev@pm.me)
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/protocol"
+)
+
+const testToken = "test-token"
+
+// waitForGoroutines fails the test if the goroutine count does not return to baseline
+func waitForGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutine leak: %d running, want <= %d\n%s", runtime.NumGoroutine(), baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// slowMemory blocks Store until release is closed
+type slowMemory struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (m *slowMemory) Store(ctx context.Context, req *protocol.MemoryStoreParams) (*protocol.MemoryStoreResult, error) {
+	close(m.entered)
+	<-m.release
+	return &protocol.MemoryStoreResult{Success: true, ArtifactID: "mem-1"}, nil
+}
+
+func (m *slowMemory) Search(ctx context.Context, req *protocol.MemorySearchParams) (*protocol.MemorySearchResult, error) {
+	return &protocol.MemorySearchResult{}, nil
+}
+
+// startTestServer serves on an ephemeral port and returns the address and Serve's result
+func startTestServer(t *testing.T, s *Server) (string, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(context.Background(), listener)
+	}()
+	return listener.Addr().String(), served
+}
+
+// dialAndConnect opens a client connection and authenticates it
+func dialAndConnect(t *testing.T, addr string) (net.Conn, *json.Decoder) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	params, _ := json.Marshal(protocol.ConnectParams{Token: testToken, ClientType: "brain"})
+	send(t, conn, "1", "connect", params)
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	var resp protocol.ResponseFrame
+	if err := dec.Decode(&resp); err != nil || resp.Error != nil {
+		t.Fatalf("connect failed: %v %v", err, resp.Error)
+	}
+	return conn, dec
+}
+
+func send(t *testing.T, conn net.Conn, id, method string, params json.RawMessage) {
+	t.Helper()
+	frame := protocol.RequestFrame{JSONRPC: "2.0", ID: id, Method: method, Params: params}
+	if err := json.NewEncoder(conn).Encode(frame); err != nil {
+		t.Fatalf("send %s: %v", method, err)
+	}
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	memory := &slowMemory{entered: make(chan struct{}), release: make(chan struct{})}
+	s := NewServer("127.0.0.1", 0, testToken)
+	s.SetMemoryHandler(memory)
+	addr, served := startTestServer(t, s)
+
+	conn, dec := dialAndConnect(t, addr)
+	defer conn.Close()
+
+	params, _ := json.Marshal(protocol.MemoryStoreParams{Key: "editor", Value: "vim"})
+	send(t, conn, "2", "memory.store", params)
+	<-memory.entered
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		shutdownErr <- s.Shutdown(ctx)
+	}()
+
+	// The request in flight when shutdown began still gets its answer
+	time.Sleep(50 * time.Millisecond)
+	close(memory.release)
+
+	var resp protocol.ResponseFrame
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatalf("in-flight response lost: %v", err)
+	}
+	if resp.ID != "2" || resp.Error != nil {
+		t.Errorf("response = %+v, want result for request 2", resp)
+	}
+
+	// Then the server closes the connection instead of waiting for the next request
+	if err := dec.Decode(&resp); err == nil {
+		t.Errorf("connection still open after shutdown, got %+v", resp)
+	}
+
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+
+	conn.Close()
+	waitForGoroutines(t, baseline)
+}
+
+func TestShutdownIdleClientsAndAccept(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	s := NewServer("127.0.0.1", 0, testToken)
+	addr, served := startTestServer(t, s)
+
+	// An idle authenticated client would otherwise hold the read loop for 65s
+	conn, _ := dialAndConnect(t, addr)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+
+	if _, err := net.DialTimeout("tcp", addr, 200*time.Millisecond); err == nil {
+		t.Error("listener still accepting after shutdown")
+	}
+
+	conn.Close()
+	waitForGoroutines(t, baseline)
+}
+
+func TestShutdownDeadlineClosesStuckConnections(t *testing.T) {
+	memory := &slowMemory{entered: make(chan struct{}), release: make(chan struct{})}
+	defer close(memory.release)
+
+	s := NewServer("127.0.0.1", 0, testToken)
+	s.SetMemoryHandler(memory)
+	addr, _ := startTestServer(t, s)
+
+	conn, dec := dialAndConnect(t, addr)
+	defer conn.Close()
+
+	params, _ := json.Marshal(protocol.MemoryStoreParams{Key: "editor", Value: "vim"})
+	send(t, conn, "2", "memory.store", params)
+	<-memory.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+
+	var resp protocol.ResponseFrame
+	if err := dec.Decode(&resp); err == nil {
+		t.Errorf("stuck connection was not closed, got %+v", resp)
+	}
+}
+
+// refusingLimiter admits a fixed number of requests and counts denials
+type refusingLimiter struct {
+	admit   int
+	denials int
+	clients []string // Client keys Allow was asked about
+}
+
+type backoff struct{}
+
+func (backoff) Error() string                { return "intent rate limit exceeded" }
+func (backoff) MarshalJSON() ([]byte, error) { return []byte(`{"retry_after_ms":1000}`), nil }
+
+func (l *refusingLimiter) Allow(ctx context.Context, client, domain, intent string) error {
+	l.clients = append(l.clients, client)
+	if l.admit == 0 {
+		return backoff{}
+	}
+	l.admit--
+	return nil
+}
+
+func (l *refusingLimiter) Peek(client, domain, intent string) error {
+	if l.admit == 0 {
+		return backoff{}
+	}
+	return nil
+}
+
+func (l *refusingLimiter) RecordDenial(ctx context.Context, client, domain string) {
+	l.denials++
+}
+
+// denyingApprovals blocks every request
+type denyingApprovals struct{}
+
+func (denyingApprovals) RequestApproval(ctx context.Context, req *protocol.ExecApprovalRequestParams) (*protocol.ExecApprovalResult, error) {
+	return &protocol.ExecApprovalResult{RequestID: req.RequestID, Approved: false, Reason: "blocked"}, nil
+}
+
+func (denyingApprovals) ResolveApproval(ctx context.Context, req *protocol.ExecApprovalResolveParams) error {
+	return nil
+}
+
+func TestExecRequestThrottled(t *testing.T) {
+	limiter := &refusingLimiter{admit: 1}
+	s := NewServer("127.0.0.1", 0, testToken)
+	s.SetApprovalHandler(denyingApprovals{})
+	s.SetLimiter(limiter)
+	addr, _ := startTestServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	conn, dec := dialAndConnect(t, addr)
+	defer conn.Close()
+
+	params, _ := json.Marshal(protocol.ExecApprovalRequestParams{RequestID: "r1", Intent: "delete logs", Actions: json.RawMessage(`[]`)})
+	send(t, conn, "2", "exec.request", params)
+	var resp protocol.ResponseFrame
+	if err := dec.Decode(&resp); err != nil || resp.Error != nil {
+		t.Fatalf("first request: %v %+v", err, resp.Error)
+	}
+	if limiter.denials != 1 {
+		t.Errorf("denials = %d, want the blocked request counted", limiter.denials)
+	}
+
+	send(t, conn, "3", "exec.request", params)
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == nil || resp.Error.Code != protocol.ErrCodeRiskBlocked {
+		t.Fatalf("throttled response error = %+v, want ErrCodeRiskBlocked", resp.Error)
+	}
+	if string(resp.Error.Data) != `{"retry_after_ms":1000}` {
+		t.Errorf("error data = %s, want the limiter's backoff", resp.Error.Data)
+	}
+
+	// Reconnecting does not make it a new client to the limiter
+	conn.Close()
+	again, againDec := dialAndConnect(t, addr)
+	defer again.Close()
+	send(t, again, "4", "exec.request", params)
+	if err := againDec.Decode(&resp); err != nil || resp.Error == nil || resp.Error.Code != protocol.ErrCodeRiskBlocked {
+		t.Fatalf("request after reconnecting: %v %+v, want ErrCodeRiskBlocked", err, resp.Error)
+	}
+	if len(limiter.clients) != 3 || limiter.clients[0] != limiter.clients[2] {
+		t.Errorf("limiter keys = %v, want the same key across connections", limiter.clients)
+	}
+}
+
+// latchingStopper engages on the first Halt and broadcasts like the kernel's kill switch
+type latchingStopper struct {
+	server  *Server
+	engaged atomic.Bool
+	actor   atomic.Value
+}
+
+func (st *latchingStopper) Halt(ctx context.Context, actor, reason string) (*protocol.StopResult, error) {
+	st.engaged.Store(true)
+	st.actor.Store(actor)
+	st.server.BroadcastStop(reason)
+	return &protocol.StopResult{Engaged: true, State: "PAUSED", Reason: reason}, nil
+}
+
+func (st *latchingStopper) Engaged() bool { return st.engaged.Load() }
+
+// frame decodes either a response or a server-pushed event
+type frame struct {
+	ID     string               `json:"id"`
+	Method string               `json:"method"`
+	Result json.RawMessage      `json:"result"`
+	Params json.RawMessage      `json:"params"`
+	Error  *protocol.ErrorShape `json:"error"`
+}
+
+func TestEmergencyStopBroadcastsAndBlocksExec(t *testing.T) {
+	s := NewServer("127.0.0.1", 0, testToken)
+	stopper := &latchingStopper{server: s}
+	s.SetApprovalHandler(denyingApprovals{})
+	s.SetEmergencyStopper(stopper)
+	addr, _ := startTestServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	caller, callerDec := dialAndConnect(t, addr)
+	defer caller.Close()
+	other, otherDec := dialAndConnect(t, addr)
+	defer other.Close()
+
+	params, _ := json.Marshal(protocol.EmergencyStopParams{Reason: "wrong window"})
+	send(t, caller, "2", "system.estop", params)
+
+	// The caller gets its result and the event, in either order
+	var gotResult, gotEvent bool
+	for !gotResult || !gotEvent {
+		var f frame
+		if err := callerDec.Decode(&f); err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case f.ID == "2":
+			var result protocol.StopResult
+			if f.Error != nil || json.Unmarshal(f.Result, &result) != nil || !result.Engaged {
+				t.Fatalf("system.estop response = %+v", f)
+			}
+			gotResult = true
+		case f.Method == "system.stop":
+			gotEvent = true
+		}
+	}
+	if actor, _ := stopper.actor.Load().(string); actor == "" || actor[:8] != "gateway:" {
+		t.Errorf("stop actor = %q, want the gateway client", actor)
+	}
+
+	// Every other client is told to abort
+	var event frame
+	if err := otherDec.Decode(&event); err != nil || event.Method != "system.stop" {
+		t.Fatalf("other client got %+v, %v; want system.stop", event, err)
+	}
+	var stop protocol.EmergencyStopEvent
+	if err := json.Unmarshal(event.Params, &stop); err != nil || stop.Reason != "wrong window" {
+		t.Errorf("stop event = %s, want the reason", event.Params)
+	}
+
+	exec, _ := json.Marshal(protocol.ExecApprovalRequestParams{RequestID: "r1", Intent: "type text", Actions: json.RawMessage(`[]`)})
+	send(t, other, "3", "exec.request", exec)
+	var resp frame
+	if err := otherDec.Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error == nil || resp.Error.Code != protocol.ErrCodePermissionDenied {
+		t.Errorf("exec.request while stopped error = %+v, want ErrCodePermissionDenied", resp.Error)
+	}
+}
+
+// challengingApprovals refuses every request until a second factor confirms it
+type challengingApprovals struct{ denyingApprovals }
+
+func (challengingApprovals) RequestApproval(ctx context.Context, req *protocol.ExecApprovalRequestParams) (*protocol.ExecApprovalResult, error) {
+	return &protocol.ExecApprovalResult{RequestID: req.RequestID, ErrorCode: protocol.ErrorCodeApprovalRequired, Digest: "d1"}, nil
+}
+
+// clientConfirmer approves only for a registered approval client, as the Conscience does without a code
+type clientConfirmer struct{}
+
+func (clientConfirmer) ConfirmApproval(ctx context.Context, req *protocol.ExecConfirmParams, approver string) (*protocol.SignedApproval, error) {
+	if approver == "" {
+		return nil, errors.New("not an approver")
+	}
+	return &protocol.SignedApproval{ID: "a1", RequestID: req.RequestID, Digest: req.Digest, Approver: approver, Signature: "sig"}, nil
+}
+
+// nextFrame decodes frames until one matches, failing on a read error
+func nextFrame(t *testing.T, dec *json.Decoder, match func(frame) bool) frame {
+	t.Helper()
+	for {
+		var f frame
+		if err := dec.Decode(&f); err != nil {
+			t.Fatal(err)
+		}
+		if match(f) {
+			return f
+		}
+	}
+}
+
+func TestExecConfirmByApprovalClient(t *testing.T) {
+	s := NewServer("127.0.0.1", 0, testToken)
+	s.SetApprovalHandler(challengingApprovals{})
+	s.SetConfirmer(clientConfirmer{})
+	s.SetApprovers(map[string]string{"phone": "phone-token"})
+	addr, _ := startTestServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	connectAs := func(clientID, token string) (net.Conn, *json.Decoder, *protocol.ErrorShape) {
+		t.Helper()
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		params, _ := json.Marshal(protocol.ConnectParams{ClientID: clientID, Token: token, ClientType: "external"})
+		send(t, conn, "1", "connect", params)
+		dec := json.NewDecoder(bufio.NewReader(conn))
+		var resp frame
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return conn, dec, resp.Error
+	}
+
+	// An approver token only works for its own client ID
+	impostor, _, connectErr := connectAs("laptop", "phone-token")
+	impostor.Close()
+	if connectErr == nil || connectErr.Code != protocol.ErrCodeAuthFailed {
+		t.Fatalf("connect as laptop with phone's token error = %+v, want auth failed", connectErr)
+	}
+	phone, phoneDec, connectErr := connectAs("phone", "phone-token")
+	defer phone.Close()
+	if connectErr != nil {
+		t.Fatalf("connect as phone error = %+v", connectErr)
+	}
+
+	agent, agentDec := dialAndConnect(t, addr)
+	defer agent.Close()
+	exec, _ := json.Marshal(protocol.ExecApprovalRequestParams{RequestID: "r1", Intent: "delete logs", Actions: json.RawMessage(`[{"type":"DELETE","target":"logs"}]`)})
+	send(t, agent, "2", "exec.request", exec)
+
+	// The phone is shown exactly what it approves
+	pending := nextFrame(t, phoneDec, func(f frame) bool { return f.Method == "exec.pending" })
+	var event protocol.ApprovalPendingEvent
+	if err := json.Unmarshal(pending.Params, &event); err != nil || event.Digest != "d1" || string(event.Actions) != `[{"type":"DELETE","target":"logs"}]` {
+		t.Fatalf("exec.pending = %s, want the actions and digest", pending.Params)
+	}
+
+	// The agent that asked cannot confirm its own request
+	confirm, _ := json.Marshal(protocol.ExecConfirmParams{RequestID: "r1", Digest: event.Digest})
+	send(t, agent, "3", "exec.confirm", confirm)
+	if f := nextFrame(t, agentDec, func(f frame) bool { return f.ID == "3" }); f.Error == nil || f.Error.Code != protocol.ErrCodePermissionDenied {
+		t.Fatalf("exec.confirm by the agent = %+v; want permission denied", f)
+	}
+
+	// The phone's confirmation carries no signature anyone could present
+	send(t, phone, "2", "exec.confirm", confirm)
+	f := nextFrame(t, phoneDec, func(f frame) bool { return f.ID == "2" })
+	var approval protocol.SignedApproval
+	if f.Error != nil || json.Unmarshal(f.Result, &approval) != nil || approval.Approver != "phone" || approval.Signature != "" {
+		t.Fatalf("exec.confirm by phone = %+v, want an unsigned approval by phone", f)
+	}
+
+	// Only the agent receives the signed approval to resend its request with
+	confirmed := nextFrame(t, agentDec, func(f frame) bool { return f.Method == "exec.confirmed" })
+	if err := json.Unmarshal(confirmed.Params, &approval); err != nil || approval.Signature != "sig" {
+		t.Fatalf("exec.confirmed = %s, want the signed approval", confirmed.Params)
+	}
+}
+
+func TestMethodsGatedByClientType(t *testing.T) {
+	s := NewServer("127.0.0.1", 0, testToken)
+	s.SetApprovalHandler(challengingApprovals{})
+	s.SetConfirmer(clientConfirmer{})
+	s.SetEmergencyStopper(&latchingStopper{server: s})
+	addr, _ := startTestServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	params, _ := json.Marshal(protocol.ConnectParams{Token: testToken, ClientType: "sentinel"})
+	send(t, conn, "1", "connect", params)
+	if f := nextFrame(t, dec, func(f frame) bool { return f.ID == "1" }); f.Error != nil {
+		t.Fatalf("connect error = %+v", f.Error)
+	}
+
+	// The shared token does not open methods the declared type was not granted
+	calls := map[string]json.RawMessage{
+		"exec.confirm": json.RawMessage(`{"request_id": "r1", "digest": "d1"}`),
+		"exec.resolve": json.RawMessage(`{"request_id": "r1", "approved": true}`),
+		"exec.request": json.RawMessage(`{"request_id": "r1", "intent": "type text", "actions": []}`),
+	}
+	for method, params := range calls {
+		send(t, conn, method, method, params)
+		if f := nextFrame(t, dec, func(f frame) bool { return f.ID == method }); f.Error == nil || f.Error.Code != protocol.ErrCodePermissionDenied {
+			t.Errorf("%s by a sentinel = %+v; want permission denied", method, f)
+		}
+	}
+
+	// A granted method still goes through
+	send(t, conn, "2", "system.estop", json.RawMessage(`{"reason": "test"}`))
+	if f := nextFrame(t, dec, func(f frame) bool { return f.ID == "2" }); f.Error != nil {
+		t.Errorf("system.estop by a sentinel error = %+v", f.Error)
+	}
+}
+
+func TestExecConfirmRefusesRequester(t *testing.T) {
+	s := NewServer("127.0.0.1", 0, testToken)
+	s.SetApprovalHandler(challengingApprovals{})
+	s.SetConfirmer(clientConfirmer{})
+	s.SetApprovers(map[string]string{"phone": "phone-token"})
+	addr, _ := startTestServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	connect := func(id, clientType string) {
+		t.Helper()
+		params, _ := json.Marshal(protocol.ConnectParams{ClientID: "phone", Token: "phone-token", ClientType: clientType})
+		send(t, conn, id, "connect", params)
+		if f := nextFrame(t, dec, func(f frame) bool { return f.ID == id }); f.Error != nil {
+			t.Fatalf("connect as %s error = %+v", clientType, f.Error)
+		}
+	}
+
+	// An agent holding the approver's token asks, then re-connects as an external client to confirm itself
+	connect("1", "brain")
+	exec, _ := json.Marshal(protocol.ExecApprovalRequestParams{RequestID: "r1", Intent: "delete logs", Actions: json.RawMessage(`[]`)})
+	send(t, conn, "2", "exec.request", exec)
+	nextFrame(t, dec, func(f frame) bool { return f.ID == "2" })
+	connect("3", "external")
+
+	confirm, _ := json.Marshal(protocol.ExecConfirmParams{RequestID: "r1", Digest: "d1"})
+	send(t, conn, "4", "exec.confirm", confirm)
+	if f := nextFrame(t, dec, func(f frame) bool { return f.ID == "4" }); f.Error == nil || f.Error.Code != protocol.ErrCodePermissionDenied {
+		t.Fatalf("exec.confirm by the requester = %+v; want permission denied", f)
+	}
+}
+
+// codeConfirmer approves only the code "123456", as the Conscience does for a code
+type codeConfirmer struct{}
+
+func (codeConfirmer) ConfirmApproval(ctx context.Context, req *protocol.ExecConfirmParams, approver string) (*protocol.SignedApproval, error) {
+	if req.Code != "123456" {
+		return nil, errors.New("one-time code is not valid")
+	}
+	return &protocol.SignedApproval{ID: "a1", RequestID: req.RequestID, Digest: req.Digest, Approver: "totp", Signature: "sig"}, nil
+}
+
+func TestExecConfirmGuessingThrottled(t *testing.T) {
+	limiter := &refusingLimiter{admit: 2}
+	s := NewServer("127.0.0.1", 0, testToken)
+	s.SetApprovalHandler(challengingApprovals{})
+	s.SetConfirmer(codeConfirmer{})
+	s.SetApprovers(map[string]string{"phone": "phone-token"})
+	s.SetLimiter(limiter)
+	addr, _ := startTestServer(t, s)
+	defer s.Shutdown(context.Background())
+
+	connectAs := func(clientID, token string) (net.Conn, *json.Decoder) {
+		t.Helper()
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		params, _ := json.Marshal(protocol.ConnectParams{ClientID: clientID, Token: token, ClientType: "external"})
+		send(t, conn, "1", "connect", params)
+		dec := json.NewDecoder(bufio.NewReader(conn))
+		if f := nextFrame(t, dec, func(f frame) bool { return f.ID == "1" }); f.Error != nil {
+			t.Fatalf("connect as %q error = %+v", clientID, f.Error)
+		}
+		return conn, dec
+	}
+	confirm := func(conn net.Conn, dec *json.Decoder, id, code string) *protocol.ErrorShape {
+		t.Helper()
+		params, _ := json.Marshal(protocol.ExecConfirmParams{RequestID: "r1", Code: code})
+		send(t, conn, id, "exec.confirm", params)
+		return nextFrame(t, dec, func(f frame) bool { return f.ID == id }).Error
+	}
+
+	// A second connection on the shared token cannot guess, whatever type it declares
+	other, otherDec := connectAs("", testToken)
+	defer other.Close()
+	if err := confirm(other, otherDec, "2", "000000"); err == nil || err.Code != protocol.ErrCodePermissionDenied {
+		t.Fatalf("exec.confirm on the shared token = %+v; want permission denied", err)
+	}
+	if len(limiter.clients) != 0 {
+		t.Errorf("limiter asked about %v, want the refusal before it", limiter.clients)
+	}
+
+	// The approver's wrong codes count as denials until the limiter refuses it
+	phone, phoneDec := connectAs("phone", "phone-token")
+	for i, id := range []string{"2", "3"} {
+		if err := confirm(phone, phoneDec, id, "000000"); err == nil || err.Code != protocol.ErrCodePermissionDenied {
+			t.Fatalf("wrong code %d = %+v; want permission denied", i+1, err)
+		}
+	}
+	if limiter.denials != 2 {
+		t.Errorf("denials = %d, want each wrong code counted", limiter.denials)
+	}
+	if err := confirm(phone, phoneDec, "4", "123456"); err == nil || err.Code != protocol.ErrCodeRiskBlocked {
+		t.Fatalf("confirm after the limit = %+v; want ErrCodeRiskBlocked", err)
+	}
+
+	// Reconnecting does not buy more guesses
+	phone.Close()
+	again, againDec := connectAs("phone", "phone-token")
+	defer again.Close()
+	if err := confirm(again, againDec, "2", "123456"); err == nil || err.Code != protocol.ErrCodeRiskBlocked {
+		t.Fatalf("confirm after reconnecting = %+v; want ErrCodeRiskBlocked", err)
+	}
+	if limiter.clients[0] != "gateway:approver:phone" || limiter.clients[0] != limiter.clients[len(limiter.clients)-1] {
+		t.Errorf("limiter keys = %v, want the approver's key across connections", limiter.clients)
+	}
+}
@@ -0,0 +1,178 @@
+// Author: Enkae (enkae.dev@pm.me)
+// Package lifecycle starts the kernel's subsystems in dependency order and stops them in reverse,
+// bounding every shutdown step with a deadline so one stuck component cannot hang the process.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultStopTimeout bounds a component's Stop when it does not set its own Timeout
+const DefaultStopTimeout = 5 * time.Second
+
+// Component is one subsystem managed by the Manager
+type Component struct {
+	Name string
+
+	// Start prepares the component (binds listeners, opens files); it must not block
+	Start func(ctx context.Context) error
+
+	// Run, if set, is started in its own goroutine after Start and blocks until the component stops
+	// A non-nil error before Stop is called is reported on Manager.Failed
+	Run func() error
+
+	// Stop shuts the component down and should return once ctx is done
+	Stop func(ctx context.Context) error
+
+	// Timeout bounds Stop; zero uses the manager's StopTimeout
+	Timeout time.Duration
+}
+
+// Manager owns an ordered list of components
+type Manager struct {
+	// StopTimeout is the default per-component shutdown deadline
+	StopTimeout time.Duration
+
+	mu         sync.Mutex
+	components []Component
+	started    []Component
+	stopping   bool
+
+	runners sync.WaitGroup
+	failed  chan error
+}
+
+// NewManager creates an empty lifecycle manager
+func NewManager() *Manager {
+	return &Manager{
+		StopTimeout: DefaultStopTimeout,
+		failed:      make(chan error, 1),
+	}
+}
+
+// Add appends a component; components start in the order they were added
+func (m *Manager) Add(c Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, c)
+}
+
+// Start brings every component up in order
+// If one fails, the components already started are stopped in reverse and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	components := m.components
+	m.mu.Unlock()
+
+	for _, c := range components {
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				stopErr := m.Stop(context.Background())
+				return errors.Join(fmt.Errorf("failed to start %s: %w", c.Name, err), stopErr)
+			}
+		}
+
+		m.mu.Lock()
+		m.started = append(m.started, c)
+		m.mu.Unlock()
+
+		if c.Run != nil {
+			m.runners.Add(1)
+			go m.run(c)
+		}
+		slog.Info("Component started", "component", c.Name)
+	}
+	return nil
+}
+
+// run executes a component's Run and reports an unexpected exit
+func (m *Manager) run(c Component) {
+	defer m.runners.Done()
+
+	err := c.Run()
+
+	m.mu.Lock()
+	stopping := m.stopping
+	m.mu.Unlock()
+	if err == nil || stopping {
+		return
+	}
+
+	select {
+	case m.failed <- fmt.Errorf("%s exited: %w", c.Name, err):
+	default:
+		// A failure is already pending; the first one triggers shutdown
+	}
+}
+
+// Failed delivers the first unexpected Run error so the caller can shut down
+func (m *Manager) Failed() <-chan error {
+	return m.failed
+}
+
+// Stop shuts started components down in reverse order, then waits for their Run goroutines
+// Every component gets its own deadline (capped by ctx); a component that misses it is
+// reported and skipped so the rest still stop.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopping = true
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		if c.Stop == nil {
+			continue
+		}
+
+		timeout := c.Timeout
+		if timeout <= 0 {
+			timeout = m.StopTimeout
+		}
+		if err := stopWithDeadline(ctx, c, timeout); err != nil {
+			slog.Error("Component did not stop cleanly", "component", c.Name, "error", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.Name, err))
+			continue
+		}
+		slog.Info("Component stopped", "component", c.Name)
+	}
+
+	// Runners return once their component has stopped
+	done := make(chan struct{})
+	go func() {
+		m.runners.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("components still running after shutdown: %w", ctx.Err()))
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopWithDeadline calls c.Stop and gives up once the deadline passes
+func stopWithDeadline(parent context.Context, c Component, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,180 @@
+// Author: Enkae (enkae.dev@pm.me)
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForGoroutines fails the test if the goroutine count does not return to baseline
+func waitForGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutine leak: %d running, want <= %d\n%s", runtime.NumGoroutine(), baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// recorder collects lifecycle events in the order they happen
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// blockingComponent runs until stopped, recording start and stop
+func blockingComponent(name string, rec *recorder) Component {
+	quit := make(chan struct{})
+	return Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			rec.add("start " + name)
+			return nil
+		},
+		Run: func() error {
+			<-quit
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			rec.add("stop " + name)
+			close(quit)
+			return nil
+		},
+	}
+}
+
+func TestManagerStartsInOrderAndStopsInReverse(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	rec := &recorder{}
+
+	m := NewManager()
+	m.Add(blockingComponent("db", rec))
+	m.Add(blockingComponent("grpc", rec))
+	m.Add(blockingComponent("http", rec))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	want := []string{"start db", "start grpc", "start http", "stop http", "stop grpc", "stop db"}
+	if got := rec.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	waitForGoroutines(t, baseline)
+}
+
+func TestManagerRollsBackOnStartFailure(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	rec := &recorder{}
+	bindErr := errors.New("address in use")
+
+	m := NewManager()
+	m.Add(blockingComponent("db", rec))
+	m.Add(blockingComponent("grpc", rec))
+	m.Add(Component{
+		Name:  "http",
+		Start: func(ctx context.Context) error { return bindErr },
+		Stop: func(ctx context.Context) error {
+			rec.add("stop http")
+			return nil
+		},
+	})
+
+	err := m.Start(context.Background())
+	if !errors.Is(err, bindErr) {
+		t.Fatalf("Start() error = %v, want %v", err, bindErr)
+	}
+
+	want := []string{"start db", "start grpc", "stop grpc", "stop db"}
+	if got := rec.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	waitForGoroutines(t, baseline)
+}
+
+func TestManagerStopDeadline(t *testing.T) {
+	rec := &recorder{}
+	release := make(chan struct{})
+	defer close(release)
+
+	m := NewManager()
+	m.Add(blockingComponent("db", rec))
+	m.Add(Component{
+		Name:    "stuck",
+		Timeout: 50 * time.Millisecond,
+		Stop: func(ctx context.Context) error {
+			<-release // Ignores ctx, like a server that never drains
+			return nil
+		},
+	})
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	start := time.Now()
+	err := m.Stop(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Stop() took %v; a stuck component should not hold up shutdown", elapsed)
+	}
+
+	// The stuck component must not prevent the rest from stopping
+	if got := rec.list(); got[len(got)-1] != "stop db" {
+		t.Errorf("events = %v, want db stopped", got)
+	}
+}
+
+func TestManagerReportsRunFailure(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	serveErr := errors.New("listener closed")
+
+	m := NewManager()
+	m.Add(Component{
+		Name: "gateway",
+		Run:  func() error { return serveErr },
+	})
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	select {
+	case err := <-m.Failed():
+		if !errors.Is(err, serveErr) {
+			t.Errorf("Failed() = %v, want %v", err, serveErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Failed() did not report the exited component")
+	}
+
+	if err := m.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+	waitForGoroutines(t, baseline)
+}
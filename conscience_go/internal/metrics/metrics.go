@@ -0,0 +1,248 @@
+// Author: Enkae (enkae.dev@pm.me)
+// Package metrics holds the kernel's Prometheus counters and histograms.
+// Every label takes its value from a fixed set: intents, IDs, window titles and client names
+// never become label values, so the number of series stays bounded however the kernel is used.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Permission paths: which front door a request came through
+const (
+	PathGRPC    = "grpc"
+	PathREST    = "rest"
+	PathGateway = "gateway"
+)
+
+// Permission decisions
+const (
+	DecisionApproved  = "approved"
+	DecisionDenied    = "denied"
+	DecisionPending   = "pending" // Held for a person
+	DecisionThrottled = "throttled"
+	DecisionHalted    = "halted" // Refused by the emergency stop
+)
+
+// Rules name the check that produced a decision
+const (
+	RulePassed           = "passed"
+	RuleAutoApprove      = "auto_approve"
+	RuleManualMode       = "manual_mode"
+	RuleRiskThreshold    = "risk_threshold"
+	RuleBlockedKeyword   = "blocked_keyword"
+	RuleActionValidation = "action_validation"
+	RuleAllowlist        = "allowlist"
+	RulePath             = "path"
+	RuleRiskOverride     = "risk_override"
+	RuleFocusMismatch    = "focus_mismatch"
+	RuleThrottle         = "throttle"
+	RuleEmergencyStop    = "emergency_stop"
+	RuleGrant            = "grant" // A standing approval covered a proposal held for the user
+)
+
+// Queue drop reasons
+const (
+	DropQueueFull     = "queue_full"
+	DropEmergencyStop = "emergency_stop"
+	DropGoalCancelled = "goal_cancelled"
+)
+
+// other replaces any label value outside its allowed set
+const other = "other"
+
+var (
+	paths     = set(PathGRPC, PathREST, PathGateway)
+	decisions = set(DecisionApproved, DecisionDenied, DecisionPending, DecisionThrottled, DecisionHalted)
+	rules     = set(RulePassed, RuleAutoApprove, RuleManualMode, RuleRiskThreshold, RuleBlockedKeyword,
+		RuleActionValidation, RuleAllowlist, RulePath, RuleRiskOverride, RuleFocusMismatch, RuleThrottle, RuleEmergencyStop, RuleGrant)
+	dropReasons = set(DropQueueFull, DropEmergencyStop, DropGoalCancelled)
+	clientTypes = set("brain", "sentinel", "ears", "external")
+	workKinds   = set("action", "command", "stream")
+	outcomes    = set("executing", "completed", "failed")
+	dbOps       = set("exec", "query", "begin")
+	transports  = set("gateway", "sse")
+	events      = set("tick", "focus.changed", "session.update", "system.stop", "exec.pending", "exec.confirmed")
+	deliveries  = set("sent", "failed", "dropped")
+)
+
+// Registry holds every kernel collector plus the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	permissionRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_permission_requests_total",
+		Help: "Permission requests by entry path, decision and the rule that decided them.",
+	}, []string{"path", "decision", "rule"})
+
+	proposalRisk = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "ghost_proposal_risk_score",
+		Help:    "Risk score (0-100) of proposals submitted over REST.",
+		Buckets: prometheus.LinearBuckets(10, 10, 10),
+	})
+
+	execRiskLevels = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_exec_risk_level_total",
+		Help: "Highest risk level of each exec.request the Conscience validated.",
+	}, []string{"level"})
+
+	approvalLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ghost_approval_latency_seconds",
+		Help:    "Time from a request being held for a person to their decision.",
+		Buckets: []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600, 14400},
+	}, []string{"path", "decision"})
+
+	actionQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ghost_action_queue_depth",
+		Help: "Commands waiting in the in-memory queue for the Body's action stream.",
+	})
+
+	actionQueueDrops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_action_queue_dropped_total",
+		Help: "Commands dropped before reaching the Body.",
+	}, []string{"reason"})
+
+	bodyExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_body_executions_total",
+		Help: "Execution status reports from the Body and Sentinel.",
+	}, []string{"kind", "outcome"})
+
+	gatewayClients = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ghost_gateway_clients",
+		Help: "Authenticated gateway connections by client type.",
+	}, []string{"type"})
+
+	dbLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ghost_db_query_duration_seconds",
+		Help:    "Time to run a statement or open a transaction on the kernel database.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+	}, []string{"op"})
+
+	eventsPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_events_published_total",
+		Help: "Events published for fan-out to connected clients.",
+	}, []string{"transport", "event"})
+
+	eventDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_event_deliveries_total",
+		Help: "Per-client event deliveries by result.",
+	}, []string{"transport", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		permissionRequests, proposalRisk, execRiskLevels, approvalLatency,
+		actionQueueDepth, actionQueueDrops, bodyExecutions, gatewayClients,
+		dbLatency, eventsPublished, eventDeliveries,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBPool exports the connection pool statistics of db under db_name=name
+func RegisterDBPool(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Permission counts one permission decision
+func Permission(path, decision, rule string) {
+	permissionRequests.WithLabelValues(bounded(paths, path), bounded(decisions, decision), bounded(rules, rule)).Inc()
+}
+
+// ProposalRisk records the risk score of a REST proposal
+func ProposalRisk(score int) {
+	proposalRisk.Observe(float64(score))
+}
+
+// ExecRiskLevel counts an exec.request by its 0-10 risk level, named after protocol.RiskLevel's steps
+func ExecRiskLevel(level int) {
+	name := "critical"
+	switch {
+	case level <= 0:
+		name = "none"
+	case level < 3:
+		name = "low"
+	case level < 7:
+		name = "medium"
+	case level < 10:
+		name = "high"
+	}
+	execRiskLevels.WithLabelValues(name).Inc()
+}
+
+// ApprovalLatency records how long a person took to decide on a held request
+func ApprovalLatency(path string, approved bool, held time.Duration) {
+	decision := "rejected"
+	if approved {
+		decision = "approved"
+	}
+	approvalLatency.WithLabelValues(bounded(paths, path), decision).Observe(held.Seconds())
+}
+
+// ActionQueueDepth sets the number of commands waiting for the Body
+func ActionQueueDepth(n int) {
+	actionQueueDepth.Set(float64(n))
+}
+
+// ActionDropped counts commands dropped before reaching the Body
+func ActionDropped(reason string, n int) {
+	actionQueueDrops.WithLabelValues(bounded(dropReasons, reason)).Add(float64(n))
+}
+
+// BodyExecution counts an execution status report for an action proposal, a command or a streamed action
+func BodyExecution(kind, outcome string) {
+	bodyExecutions.WithLabelValues(bounded(workKinds, kind), bounded(outcomes, outcome)).Inc()
+}
+
+// GatewayClientConnected adds an authenticated gateway client of clientType
+func GatewayClientConnected(clientType string) {
+	gatewayClients.WithLabelValues(bounded(clientTypes, clientType)).Inc()
+}
+
+// GatewayClientDisconnected removes an authenticated gateway client of clientType
+func GatewayClientDisconnected(clientType string) {
+	gatewayClients.WithLabelValues(bounded(clientTypes, clientType)).Dec()
+}
+
+// DBQuery records the latency of a database call; op is exec, query or begin
+func DBQuery(op string, start time.Time) {
+	dbLatency.WithLabelValues(bounded(dbOps, op)).Observe(time.Since(start).Seconds())
+}
+
+// EventPublished counts an event queued for fan-out over transport
+func EventPublished(transport, event string) {
+	eventsPublished.WithLabelValues(bounded(transports, transport), bounded(events, event)).Inc()
+}
+
+// EventDelivered counts one per-client delivery of an event; result is sent, failed or dropped
+func EventDelivered(transport, result string) {
+	eventDeliveries.WithLabelValues(bounded(transports, transport), bounded(deliveries, result)).Inc()
+}
+
+// set builds a lookup of allowed label values
+func set(values ...string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// bounded returns value if it is allowed, else "other"
+func bounded(allowed map[string]bool, value string) string {
+	if allowed[value] {
+		return value
+	}
+	return other
+}
@@ -0,0 +1,69 @@
+// Author: Enkae (enkae.dev@pm.me)
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLabelsAreBounded(t *testing.T) {
+	// Caller-controlled strings must never become label values
+	Permission(PathREST, DecisionDenied, "open ~/secrets.txt in vim")
+	GatewayClientConnected("my-phone-7f3a")
+	EventPublished("gateway", "custom.event."+time.Now().String())
+	BodyExecution("action", "COMPLETED")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	text := string(body)
+
+	for _, leaked := range []string{"secrets", "my-phone", "custom.event", "COMPLETED"} {
+		if strings.Contains(text, leaked) {
+			t.Errorf("scrape contains caller-supplied value %q", leaked)
+		}
+	}
+	for _, want := range []string{
+		`ghost_permission_requests_total{decision="denied",path="rest",rule="other"} 1`,
+		`ghost_gateway_clients{type="other"} 1`,
+		`ghost_events_published_total{event="other",transport="gateway"} 1`,
+		`ghost_body_executions_total{kind="action",outcome="other"} 1`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+}
+
+func TestExecRiskLevelNames(t *testing.T) {
+	for level, want := range map[int]string{0: "none", 1: "low", 3: "medium", 6: "medium", 7: "high", 10: "critical"} {
+		before := riskCount(t, want)
+		ExecRiskLevel(level)
+		if got := riskCount(t, want); got != before+1 {
+			t.Errorf("ExecRiskLevel(%d) did not count as %s", level, want)
+		}
+	}
+}
+
+// riskCount returns the ghost_exec_risk_level_total sample for level
+func riskCount(t *testing.T, level string) float64 {
+	t.Helper()
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != "ghost_exec_risk_level_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			if m.GetLabel()[0].GetValue() == level {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -90,6 +90,7 @@ type PermissionRequest struct {
 	Intent        string                 `protobuf:"bytes,1,opt,name=intent,proto3" json:"intent,omitempty"`
 	Actions       []*Action              `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
 	TraceId       string                 `protobuf:"bytes,3,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Return the decision trace without throttling, enqueueing or recording anything
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -145,11 +146,19 @@ func (x *PermissionRequest) GetTraceId() string {
 	return ""
 }
 
+func (x *PermissionRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
 type PermissionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Approved      bool                   `protobuf:"varint,1,opt,name=approved,proto3" json:"approved,omitempty"`
 	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
 	TrustScore    int32                  `protobuf:"varint,3,opt,name=trust_score,json=trustScore,proto3" json:"trust_score,omitempty"`
+	Trace         *DecisionTrace         `protobuf:"bytes,4,opt,name=trace,proto3" json:"trace,omitempty"` // Set for dry runs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -205,28 +214,44 @@ func (x *PermissionResponse) GetTrustScore() int32 {
 	return 0
 }
 
-type Action struct {
+func (x *PermissionResponse) GetTrace() *DecisionTrace {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
+// DecisionTrace explains a permission decision rule by rule
+type DecisionTrace struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "CLICK", "TYPE", "EXEC", "SPEAK"
-	Payload       map[string]string      `protobuf:"bytes,2,rep,name=payload,proto3" json:"payload,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`         // "grpc", "rest" or "gateway"
+	Decision      string                 `protobuf:"bytes,2,opt,name=decision,proto3" json:"decision,omitempty"` // "approved", "denied", "pending", "halted" or "throttled"
+	Rule          string                 `protobuf:"bytes,3,opt,name=rule,proto3" json:"rule,omitempty"`         // The rule that decided it
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Checks        []*RuleCheck           `protobuf:"bytes,5,rep,name=checks,proto3" json:"checks,omitempty"` // Every rule evaluated, in order
+	Actions       []*ActionRisk          `protobuf:"bytes,6,rep,name=actions,proto3" json:"actions,omitempty"`
+	Mode          string                 `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	TrustScore    int32                  `protobuf:"varint,8,opt,name=trust_score,json=trustScore,proto3" json:"trust_score,omitempty"`
+	Focus         *FocusCheck            `protobuf:"bytes,9,opt,name=focus,proto3" json:"focus,omitempty"`
+	DryRun        bool                   `protobuf:"varint,10,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Action) Reset() {
-	*x = Action{}
+func (x *DecisionTrace) Reset() {
+	*x = DecisionTrace{}
 	mi := &file_ghost_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Action) String() string {
+func (x *DecisionTrace) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Action) ProtoMessage() {}
+func (*DecisionTrace) ProtoMessage() {}
 
-func (x *Action) ProtoReflect() protoreflect.Message {
+func (x *DecisionTrace) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -238,47 +263,104 @@ func (x *Action) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Action.ProtoReflect.Descriptor instead.
-func (*Action) Descriptor() ([]byte, []int) {
+// Deprecated: Use DecisionTrace.ProtoReflect.Descriptor instead.
+func (*DecisionTrace) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *Action) GetType() string {
+func (x *DecisionTrace) GetPath() string {
 	if x != nil {
-		return x.Type
+		return x.Path
 	}
 	return ""
 }
 
-func (x *Action) GetPayload() map[string]string {
+func (x *DecisionTrace) GetDecision() string {
 	if x != nil {
-		return x.Payload
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *DecisionTrace) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *DecisionTrace) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DecisionTrace) GetChecks() []*RuleCheck {
+	if x != nil {
+		return x.Checks
 	}
 	return nil
 }
 
-type ActionCommand struct {
+func (x *DecisionTrace) GetActions() []*ActionRisk {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *DecisionTrace) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *DecisionTrace) GetTrustScore() int32 {
+	if x != nil {
+		return x.TrustScore
+	}
+	return 0
+}
+
+func (x *DecisionTrace) GetFocus() *FocusCheck {
+	if x != nil {
+		return x.Focus
+	}
+	return nil
+}
+
+func (x *DecisionTrace) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type RuleCheck struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CommandId     string                 `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
-	Action        *Action                `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Passed        bool                   `protobuf:"varint,2,opt,name=passed,proto3" json:"passed,omitempty"`
+	Detail        string                 `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ActionCommand) Reset() {
-	*x = ActionCommand{}
+func (x *RuleCheck) Reset() {
+	*x = RuleCheck{}
 	mi := &file_ghost_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ActionCommand) String() string {
+func (x *RuleCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ActionCommand) ProtoMessage() {}
+func (*RuleCheck) ProtoMessage() {}
 
-func (x *ActionCommand) ProtoReflect() protoreflect.Message {
+func (x *RuleCheck) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -290,46 +372,55 @@ func (x *ActionCommand) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ActionCommand.ProtoReflect.Descriptor instead.
-func (*ActionCommand) Descriptor() ([]byte, []int) {
+// Deprecated: Use RuleCheck.ProtoReflect.Descriptor instead.
+func (*RuleCheck) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ActionCommand) GetCommandId() string {
+func (x *RuleCheck) GetRule() string {
 	if x != nil {
-		return x.CommandId
+		return x.Rule
 	}
 	return ""
 }
 
-func (x *ActionCommand) GetAction() *Action {
+func (x *RuleCheck) GetPassed() bool {
 	if x != nil {
-		return x.Action
+		return x.Passed
 	}
-	return nil
+	return false
 }
 
-type PendingList struct {
+func (x *RuleCheck) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ActionRisk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*PendingItem         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Risk          int32                  `protobuf:"varint,3,opt,name=risk,proto3" json:"risk,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PendingList) Reset() {
-	*x = PendingList{}
+func (x *ActionRisk) Reset() {
+	*x = ActionRisk{}
 	mi := &file_ghost_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PendingList) String() string {
+func (x *ActionRisk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PendingList) ProtoMessage() {}
+func (*ActionRisk) ProtoMessage() {}
 
-func (x *PendingList) ProtoReflect() protoreflect.Message {
+func (x *ActionRisk) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -341,41 +432,55 @@ func (x *PendingList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PendingList.ProtoReflect.Descriptor instead.
-func (*PendingList) Descriptor() ([]byte, []int) {
+// Deprecated: Use ActionRisk.ProtoReflect.Descriptor instead.
+func (*ActionRisk) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *PendingList) GetItems() []*PendingItem {
+func (x *ActionRisk) GetIndex() int32 {
 	if x != nil {
-		return x.Items
+		return x.Index
 	}
-	return nil
+	return 0
 }
 
-type PendingItem struct {
+func (x *ActionRisk) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ActionRisk) GetRisk() int32 {
+	if x != nil {
+		return x.Risk
+	}
+	return 0
+}
+
+type FocusCheck struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ActionId      string                 `protobuf:"bytes,1,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
-	Intent        string                 `protobuf:"bytes,2,opt,name=intent,proto3" json:"intent,omitempty"`
-	RiskScore     int32                  `protobuf:"varint,3,opt,name=risk_score,json=riskScore,proto3" json:"risk_score,omitempty"`
+	Expected      string                 `protobuf:"bytes,1,opt,name=expected,proto3" json:"expected,omitempty"` // Empty when the request names no window
+	Actual        string                 `protobuf:"bytes,2,opt,name=actual,proto3" json:"actual,omitempty"`
+	Matched       bool                   `protobuf:"varint,3,opt,name=matched,proto3" json:"matched,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PendingItem) Reset() {
-	*x = PendingItem{}
+func (x *FocusCheck) Reset() {
+	*x = FocusCheck{}
 	mi := &file_ghost_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PendingItem) String() string {
+func (x *FocusCheck) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PendingItem) ProtoMessage() {}
+func (*FocusCheck) ProtoMessage() {}
 
-func (x *PendingItem) ProtoReflect() protoreflect.Message {
+func (x *FocusCheck) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -387,54 +492,54 @@ func (x *PendingItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PendingItem.ProtoReflect.Descriptor instead.
-func (*PendingItem) Descriptor() ([]byte, []int) {
+// Deprecated: Use FocusCheck.ProtoReflect.Descriptor instead.
+func (*FocusCheck) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *PendingItem) GetActionId() string {
+func (x *FocusCheck) GetExpected() string {
 	if x != nil {
-		return x.ActionId
+		return x.Expected
 	}
 	return ""
 }
 
-func (x *PendingItem) GetIntent() string {
+func (x *FocusCheck) GetActual() string {
 	if x != nil {
-		return x.Intent
+		return x.Actual
 	}
 	return ""
 }
 
-func (x *PendingItem) GetRiskScore() int32 {
+func (x *FocusCheck) GetMatched() bool {
 	if x != nil {
-		return x.RiskScore
+		return x.Matched
 	}
-	return 0
+	return false
 }
 
-type ApprovalDecision struct {
+type Action struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ActionId      string                 `protobuf:"bytes,1,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
-	Approved      bool                   `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "CLICK", "TYPE", "EXEC", "SPEAK"; the kernel sends "STOP" on emergency stop and "ABORT" on goal cancellation
+	Payload       map[string]string      `protobuf:"bytes,2,rep,name=payload,proto3" json:"payload,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ApprovalDecision) Reset() {
-	*x = ApprovalDecision{}
+func (x *Action) Reset() {
+	*x = Action{}
 	mi := &file_ghost_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ApprovalDecision) String() string {
+func (x *Action) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApprovalDecision) ProtoMessage() {}
+func (*Action) ProtoMessage() {}
 
-func (x *ApprovalDecision) ProtoReflect() protoreflect.Message {
+func (x *Action) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -446,47 +551,48 @@ func (x *ApprovalDecision) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApprovalDecision.ProtoReflect.Descriptor instead.
-func (*ApprovalDecision) Descriptor() ([]byte, []int) {
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ApprovalDecision) GetActionId() string {
+func (x *Action) GetType() string {
 	if x != nil {
-		return x.ActionId
+		return x.Type
 	}
 	return ""
 }
 
-func (x *ApprovalDecision) GetApproved() bool {
+func (x *Action) GetPayload() map[string]string {
 	if x != nil {
-		return x.Approved
+		return x.Payload
 	}
-	return false
+	return nil
 }
 
-type ModeRequest struct {
+type ActionCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"` // "*" or "browser"
-	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`     // "AUTO", "MANUAL"
+	CommandId     string                 `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Action        *Action                `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	TraceId       string                 `protobuf:"bytes,3,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"` // The intent this command belongs to; echo it in ActionOutcome
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ModeRequest) Reset() {
-	*x = ModeRequest{}
+func (x *ActionCommand) Reset() {
+	*x = ActionCommand{}
 	mi := &file_ghost_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ModeRequest) String() string {
+func (x *ActionCommand) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ModeRequest) ProtoMessage() {}
+func (*ActionCommand) ProtoMessage() {}
 
-func (x *ModeRequest) ProtoReflect() protoreflect.Message {
+func (x *ActionCommand) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -498,47 +604,56 @@ func (x *ModeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ModeRequest.ProtoReflect.Descriptor instead.
-func (*ModeRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ActionCommand.ProtoReflect.Descriptor instead.
+func (*ActionCommand) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ModeRequest) GetDomain() string {
+func (x *ActionCommand) GetCommandId() string {
 	if x != nil {
-		return x.Domain
+		return x.CommandId
 	}
 	return ""
 }
 
-func (x *ModeRequest) GetMode() string {
+func (x *ActionCommand) GetAction() *Action {
 	if x != nil {
-		return x.Mode
+		return x.Action
+	}
+	return nil
+}
+
+func (x *ActionCommand) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
 	}
 	return ""
 }
 
-type SystemState struct {
+type ActionOutcome struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // "ACTIVE", "SHADOW", "PAUSED"
-	ActiveFocus   string                 `protobuf:"bytes,2,opt,name=active_focus,json=activeFocus,proto3" json:"active_focus,omitempty"`
+	CommandId     string                 `protobuf:"bytes,1,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	TraceId       string                 `protobuf:"bytes,2,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SystemState) Reset() {
-	*x = SystemState{}
+func (x *ActionOutcome) Reset() {
+	*x = ActionOutcome{}
 	mi := &file_ghost_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SystemState) String() string {
+func (x *ActionOutcome) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SystemState) ProtoMessage() {}
+func (*ActionOutcome) ProtoMessage() {}
 
-func (x *SystemState) ProtoReflect() protoreflect.Message {
+func (x *ActionOutcome) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -550,46 +665,60 @@ func (x *SystemState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SystemState.ProtoReflect.Descriptor instead.
-func (*SystemState) Descriptor() ([]byte, []int) {
+// Deprecated: Use ActionOutcome.ProtoReflect.Descriptor instead.
+func (*ActionOutcome) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *SystemState) GetState() string {
+func (x *ActionOutcome) GetCommandId() string {
 	if x != nil {
-		return x.State
+		return x.CommandId
 	}
 	return ""
 }
 
-func (x *SystemState) GetActiveFocus() string {
+func (x *ActionOutcome) GetTraceId() string {
 	if x != nil {
-		return x.ActiveFocus
+		return x.TraceId
 	}
 	return ""
 }
 
-type Ack struct {
+func (x *ActionOutcome) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ActionOutcome) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PendingList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Items         []*PendingItem         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Ack) Reset() {
-	*x = Ack{}
+func (x *PendingList) Reset() {
+	*x = PendingList{}
 	mi := &file_ghost_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Ack) String() string {
+func (x *PendingList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Ack) ProtoMessage() {}
+func (*PendingList) ProtoMessage() {}
 
-func (x *Ack) ProtoReflect() protoreflect.Message {
+func (x *PendingList) ProtoReflect() protoreflect.Message {
 	mi := &file_ghost_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -601,75 +730,1060 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
-func (*Ack) Descriptor() ([]byte, []int) {
+// Deprecated: Use PendingList.ProtoReflect.Descriptor instead.
+func (*PendingList) Descriptor() ([]byte, []int) {
 	return file_ghost_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Ack) GetSuccess() bool {
+func (x *PendingList) GetItems() []*PendingItem {
 	if x != nil {
-		return x.Success
+		return x.Items
 	}
-	return false
+	return nil
 }
 
-var File_ghost_proto protoreflect.FileDescriptor
+type PendingItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActionId      string                 `protobuf:"bytes,1,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
+	Intent        string                 `protobuf:"bytes,2,opt,name=intent,proto3" json:"intent,omitempty"`
+	RiskScore     int32                  `protobuf:"varint,3,opt,name=risk_score,json=riskScore,proto3" json:"risk_score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_ghost_proto_rawDesc = "" +
+func (x *PendingItem) Reset() {
+	*x = PendingItem{}
+	mi := &file_ghost_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PendingItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingItem) ProtoMessage() {}
+
+func (x *PendingItem) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingItem.ProtoReflect.Descriptor instead.
+func (*PendingItem) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PendingItem) GetActionId() string {
+	if x != nil {
+		return x.ActionId
+	}
+	return ""
+}
+
+func (x *PendingItem) GetIntent() string {
+	if x != nil {
+		return x.Intent
+	}
+	return ""
+}
+
+func (x *PendingItem) GetRiskScore() int32 {
+	if x != nil {
+		return x.RiskScore
+	}
+	return 0
+}
+
+type ApprovalDecision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActionId      string                 `protobuf:"bytes,1,opt,name=action_id,json=actionId,proto3" json:"action_id,omitempty"`
+	Approved      bool                   `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
+	Code          string                 `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"` // One-time code from the user's authenticator; approving a high-risk action needs it or an approval client's confirmation
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApprovalDecision) Reset() {
+	*x = ApprovalDecision{}
+	mi := &file_ghost_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalDecision) ProtoMessage() {}
+
+func (x *ApprovalDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalDecision.ProtoReflect.Descriptor instead.
+func (*ApprovalDecision) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ApprovalDecision) GetActionId() string {
+	if x != nil {
+		return x.ActionId
+	}
+	return ""
+}
+
+func (x *ApprovalDecision) GetApproved() bool {
+	if x != nil {
+		return x.Approved
+	}
+	return false
+}
+
+func (x *ApprovalDecision) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type ModeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"` // "*" or "browser"
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`     // "AUTO", "MANUAL"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModeRequest) Reset() {
+	*x = ModeRequest{}
+	mi := &file_ghost_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModeRequest) ProtoMessage() {}
+
+func (x *ModeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModeRequest.ProtoReflect.Descriptor instead.
+func (*ModeRequest) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ModeRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ModeRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type SystemState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	State         string                 `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // "ACTIVE", "SHADOW", "PAUSED"
+	ActiveFocus   string                 `protobuf:"bytes,2,opt,name=active_focus,json=activeFocus,proto3" json:"active_focus,omitempty"`
+	EmergencyStop bool                   `protobuf:"varint,3,opt,name=emergency_stop,json=emergencyStop,proto3" json:"emergency_stop,omitempty"` // PAUSED until re-armed
+	StopReason    string                 `protobuf:"bytes,4,opt,name=stop_reason,json=stopReason,proto3" json:"stop_reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SystemState) Reset() {
+	*x = SystemState{}
+	mi := &file_ghost_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SystemState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemState) ProtoMessage() {}
+
+func (x *SystemState) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemState.ProtoReflect.Descriptor instead.
+func (*SystemState) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SystemState) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SystemState) GetActiveFocus() string {
+	if x != nil {
+		return x.ActiveFocus
+	}
+	return ""
+}
+
+func (x *SystemState) GetEmergencyStop() bool {
+	if x != nil {
+		return x.EmergencyStop
+	}
+	return false
+}
+
+func (x *SystemState) GetStopReason() string {
+	if x != nil {
+		return x.StopReason
+	}
+	return ""
+}
+
+type StopRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRequest) Reset() {
+	*x = StopRequest{}
+	mi := &file_ghost_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRequest) ProtoMessage() {}
+
+func (x *StopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRequest.ProtoReflect.Descriptor instead.
+func (*StopRequest) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StopRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RearmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"` // Required
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RearmRequest) Reset() {
+	*x = RearmRequest{}
+	mi := &file_ghost_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RearmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RearmRequest) ProtoMessage() {}
+
+func (x *RearmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RearmRequest.ProtoReflect.Descriptor instead.
+func (*RearmRequest) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RearmRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type StopResult struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Engaged           bool                   `protobuf:"varint,1,opt,name=engaged,proto3" json:"engaged,omitempty"`
+	State             string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Reason            string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	CancelledActions  int32                  `protobuf:"varint,4,opt,name=cancelled_actions,json=cancelledActions,proto3" json:"cancelled_actions,omitempty"`    // Proposals waiting, approved or executing
+	CancelledCommands int32                  `protobuf:"varint,5,opt,name=cancelled_commands,json=cancelledCommands,proto3" json:"cancelled_commands,omitempty"` // Sentinel commands not yet finished
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *StopResult) Reset() {
+	*x = StopResult{}
+	mi := &file_ghost_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopResult) ProtoMessage() {}
+
+func (x *StopResult) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopResult.ProtoReflect.Descriptor instead.
+func (*StopResult) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StopResult) GetEngaged() bool {
+	if x != nil {
+		return x.Engaged
+	}
+	return false
+}
+
+func (x *StopResult) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *StopResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StopResult) GetCancelledActions() int32 {
+	if x != nil {
+		return x.CancelledActions
+	}
+	return 0
+}
+
+func (x *StopResult) GetCancelledCommands() int32 {
+	if x != nil {
+		return x.CancelledCommands
+	}
+	return 0
+}
+
+type Ack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	mi := &file_ghost_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Ack) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type CancelGoalRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GoalId        string                 `protobuf:"bytes,1,opt,name=goal_id,json=goalId,proto3" json:"goal_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelGoalRequest) Reset() {
+	*x = CancelGoalRequest{}
+	mi := &file_ghost_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelGoalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelGoalRequest) ProtoMessage() {}
+
+func (x *CancelGoalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelGoalRequest.ProtoReflect.Descriptor instead.
+func (*CancelGoalRequest) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CancelGoalRequest) GetGoalId() string {
+	if x != nil {
+		return x.GoalId
+	}
+	return ""
+}
+
+func (x *CancelGoalRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelGoalResult struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	GoalId            string                 `protobuf:"bytes,1,opt,name=goal_id,json=goalId,proto3" json:"goal_id,omitempty"`
+	Status            string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                                                 // "CANCELLED"
+	RejectedActions   int32                  `protobuf:"varint,3,opt,name=rejected_actions,json=rejectedActions,proto3" json:"rejected_actions,omitempty"`       // Proposals that were waiting on the user or on context
+	CancelledActions  int32                  `protobuf:"varint,4,opt,name=cancelled_actions,json=cancelledActions,proto3" json:"cancelled_actions,omitempty"`    // Approved or executing proposals
+	CancelledCommands int32                  `protobuf:"varint,5,opt,name=cancelled_commands,json=cancelledCommands,proto3" json:"cancelled_commands,omitempty"` // Sentinel commands not yet finished
+	Aborted           bool                   `protobuf:"varint,6,opt,name=aborted,proto3" json:"aborted,omitempty"`                                              // The Body was told to abort the goal's commands
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CancelGoalResult) Reset() {
+	*x = CancelGoalResult{}
+	mi := &file_ghost_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelGoalResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelGoalResult) ProtoMessage() {}
+
+func (x *CancelGoalResult) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelGoalResult.ProtoReflect.Descriptor instead.
+func (*CancelGoalResult) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CancelGoalResult) GetGoalId() string {
+	if x != nil {
+		return x.GoalId
+	}
+	return ""
+}
+
+func (x *CancelGoalResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CancelGoalResult) GetRejectedActions() int32 {
+	if x != nil {
+		return x.RejectedActions
+	}
+	return 0
+}
+
+func (x *CancelGoalResult) GetCancelledActions() int32 {
+	if x != nil {
+		return x.CancelledActions
+	}
+	return 0
+}
+
+func (x *CancelGoalResult) GetCancelledCommands() int32 {
+	if x != nil {
+		return x.CancelledCommands
+	}
+	return 0
+}
+
+func (x *CancelGoalResult) GetAborted() bool {
+	if x != nil {
+		return x.Aborted
+	}
+	return false
+}
+
+type Grant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GrantId       string                 `protobuf:"bytes,1,opt,name=grant_id,json=grantId,proto3" json:"grant_id,omitempty"`
+	ActionType    string                 `protobuf:"bytes,2,opt,name=action_type,json=actionType,proto3" json:"action_type,omitempty"` // "TYPE", "READ", ...
+	Process       string                 `protobuf:"bytes,3,opt,name=process,proto3" json:"process,omitempty"`                         // Empty for any process
+	PathPrefix    string                 `protobuf:"bytes,4,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"` // Relative directory; empty for any path
+	ExpiresAt     int64                  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`   // Unix seconds; 0 until revoked
+	CreatedBy     string                 `protobuf:"bytes,6,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	RevokedAt     int64                  `protobuf:"varint,8,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"` // 0 while not revoked
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Grant) Reset() {
+	*x = Grant{}
+	mi := &file_ghost_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Grant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Grant) ProtoMessage() {}
+
+func (x *Grant) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Grant.ProtoReflect.Descriptor instead.
+func (*Grant) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Grant) GetGrantId() string {
+	if x != nil {
+		return x.GrantId
+	}
+	return ""
+}
+
+func (x *Grant) GetActionType() string {
+	if x != nil {
+		return x.ActionType
+	}
+	return ""
+}
+
+func (x *Grant) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+func (x *Grant) GetPathPrefix() string {
+	if x != nil {
+		return x.PathPrefix
+	}
+	return ""
+}
+
+func (x *Grant) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *Grant) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Grant) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Grant) GetRevokedAt() int64 {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return 0
+}
+
+type CreateGrantRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ActionType      string                 `protobuf:"bytes,1,opt,name=action_type,json=actionType,proto3" json:"action_type,omitempty"`
+	Process         string                 `protobuf:"bytes,2,opt,name=process,proto3" json:"process,omitempty"`
+	PathPrefix      string                 `protobuf:"bytes,3,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	DurationSeconds int64                  `protobuf:"varint,4,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"` // 0 until revoked
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateGrantRequest) Reset() {
+	*x = CreateGrantRequest{}
+	mi := &file_ghost_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGrantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGrantRequest) ProtoMessage() {}
+
+func (x *CreateGrantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGrantRequest.ProtoReflect.Descriptor instead.
+func (*CreateGrantRequest) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateGrantRequest) GetActionType() string {
+	if x != nil {
+		return x.ActionType
+	}
+	return ""
+}
+
+func (x *CreateGrantRequest) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+func (x *CreateGrantRequest) GetPathPrefix() string {
+	if x != nil {
+		return x.PathPrefix
+	}
+	return ""
+}
+
+func (x *CreateGrantRequest) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+type ListGrantsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IncludeInactive bool                   `protobuf:"varint,1,opt,name=include_inactive,json=includeInactive,proto3" json:"include_inactive,omitempty"` // Also list expired and revoked grants
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListGrantsRequest) Reset() {
+	*x = ListGrantsRequest{}
+	mi := &file_ghost_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGrantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGrantsRequest) ProtoMessage() {}
+
+func (x *ListGrantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGrantsRequest.ProtoReflect.Descriptor instead.
+func (*ListGrantsRequest) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListGrantsRequest) GetIncludeInactive() bool {
+	if x != nil {
+		return x.IncludeInactive
+	}
+	return false
+}
+
+type GrantList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Grants        []*Grant               `protobuf:"bytes,1,rep,name=grants,proto3" json:"grants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantList) Reset() {
+	*x = GrantList{}
+	mi := &file_ghost_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantList) ProtoMessage() {}
+
+func (x *GrantList) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantList.ProtoReflect.Descriptor instead.
+func (*GrantList) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GrantList) GetGrants() []*Grant {
+	if x != nil {
+		return x.Grants
+	}
+	return nil
+}
+
+type RevokeGrantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GrantId       string                 `protobuf:"bytes,1,opt,name=grant_id,json=grantId,proto3" json:"grant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeGrantRequest) Reset() {
+	*x = RevokeGrantRequest{}
+	mi := &file_ghost_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeGrantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeGrantRequest) ProtoMessage() {}
+
+func (x *RevokeGrantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ghost_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeGrantRequest.ProtoReflect.Descriptor instead.
+func (*RevokeGrantRequest) Descriptor() ([]byte, []int) {
+	return file_ghost_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RevokeGrantRequest) GetGrantId() string {
+	if x != nil {
+		return x.GrantId
+	}
+	return ""
+}
+
+var File_ghost_proto protoreflect.FileDescriptor
+
+const file_ghost_proto_rawDesc = "" +
 	"\n" +
 	"\vghost.proto\x12\x05ghost\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\"|\n" +
 	"\n" +
 	"FocusState\x12!\n" +
 	"\fwindow_title\x18\x01 \x01(\tR\vwindowTitle\x12!\n" +
 	"\fprocess_name\x18\x02 \x01(\tR\vprocessName\x12(\n" +
-	"\x10ui_tree_snapshot\x18\x03 \x01(\tR\x0euiTreeSnapshot\"o\n" +
+	"\x10ui_tree_snapshot\x18\x03 \x01(\tR\x0euiTreeSnapshot\"\x88\x01\n" +
 	"\x11PermissionRequest\x12\x16\n" +
 	"\x06intent\x18\x01 \x01(\tR\x06intent\x12'\n" +
 	"\aactions\x18\x02 \x03(\v2\r.ghost.ActionR\aactions\x12\x19\n" +
-	"\btrace_id\x18\x03 \x01(\tR\atraceId\"i\n" +
+	"\btrace_id\x18\x03 \x01(\tR\atraceId\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"\x95\x01\n" +
 	"\x12PermissionResponse\x12\x1a\n" +
 	"\bapproved\x18\x01 \x01(\bR\bapproved\x12\x16\n" +
 	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x1f\n" +
 	"\vtrust_score\x18\x03 \x01(\x05R\n" +
-	"trustScore\"\x8e\x01\n" +
+	"trustScore\x12*\n" +
+	"\x05trace\x18\x04 \x01(\v2\x14.ghost.DecisionTraceR\x05trace\"\xb9\x02\n" +
+	"\rDecisionTrace\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1a\n" +
+	"\bdecision\x18\x02 \x01(\tR\bdecision\x12\x12\n" +
+	"\x04rule\x18\x03 \x01(\tR\x04rule\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12(\n" +
+	"\x06checks\x18\x05 \x03(\v2\x10.ghost.RuleCheckR\x06checks\x12+\n" +
+	"\aactions\x18\x06 \x03(\v2\x11.ghost.ActionRiskR\aactions\x12\x12\n" +
+	"\x04mode\x18\a \x01(\tR\x04mode\x12\x1f\n" +
+	"\vtrust_score\x18\b \x01(\x05R\n" +
+	"trustScore\x12'\n" +
+	"\x05focus\x18\t \x01(\v2\x11.ghost.FocusCheckR\x05focus\x12\x17\n" +
+	"\adry_run\x18\n" +
+	" \x01(\bR\x06dryRun\"O\n" +
+	"\tRuleCheck\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12\x16\n" +
+	"\x06passed\x18\x02 \x01(\bR\x06passed\x12\x16\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detail\"J\n" +
+	"\n" +
+	"ActionRisk\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x12\n" +
+	"\x04risk\x18\x03 \x01(\x05R\x04risk\"Z\n" +
+	"\n" +
+	"FocusCheck\x12\x1a\n" +
+	"\bexpected\x18\x01 \x01(\tR\bexpected\x12\x16\n" +
+	"\x06actual\x18\x02 \x01(\tR\x06actual\x12\x18\n" +
+	"\amatched\x18\x03 \x01(\bR\amatched\"\x8e\x01\n" +
 	"\x06Action\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x124\n" +
 	"\apayload\x18\x02 \x03(\v2\x1a.ghost.Action.PayloadEntryR\apayload\x1a:\n" +
 	"\fPayloadEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"U\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"p\n" +
 	"\rActionCommand\x12\x1d\n" +
 	"\n" +
 	"command_id\x18\x01 \x01(\tR\tcommandId\x12%\n" +
-	"\x06action\x18\x02 \x01(\v2\r.ghost.ActionR\x06action\"7\n" +
+	"\x06action\x18\x02 \x01(\v2\r.ghost.ActionR\x06action\x12\x19\n" +
+	"\btrace_id\x18\x03 \x01(\tR\atraceId\"y\n" +
+	"\rActionOutcome\x12\x1d\n" +
+	"\n" +
+	"command_id\x18\x01 \x01(\tR\tcommandId\x12\x19\n" +
+	"\btrace_id\x18\x02 \x01(\tR\atraceId\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"7\n" +
 	"\vPendingList\x12(\n" +
 	"\x05items\x18\x01 \x03(\v2\x12.ghost.PendingItemR\x05items\"a\n" +
 	"\vPendingItem\x12\x1b\n" +
 	"\taction_id\x18\x01 \x01(\tR\bactionId\x12\x16\n" +
 	"\x06intent\x18\x02 \x01(\tR\x06intent\x12\x1d\n" +
 	"\n" +
-	"risk_score\x18\x03 \x01(\x05R\triskScore\"K\n" +
+	"risk_score\x18\x03 \x01(\x05R\triskScore\"_\n" +
 	"\x10ApprovalDecision\x12\x1b\n" +
 	"\taction_id\x18\x01 \x01(\tR\bactionId\x12\x1a\n" +
-	"\bapproved\x18\x02 \x01(\bR\bapproved\"9\n" +
+	"\bapproved\x18\x02 \x01(\bR\bapproved\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\"9\n" +
 	"\vModeRequest\x12\x16\n" +
 	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x12\n" +
-	"\x04mode\x18\x02 \x01(\tR\x04mode\"F\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\"\x8e\x01\n" +
 	"\vSystemState\x12\x14\n" +
 	"\x05state\x18\x01 \x01(\tR\x05state\x12!\n" +
-	"\factive_focus\x18\x02 \x01(\tR\vactiveFocus\"\x1f\n" +
+	"\factive_focus\x18\x02 \x01(\tR\vactiveFocus\x12%\n" +
+	"\x0eemergency_stop\x18\x03 \x01(\bR\remergencyStop\x12\x1f\n" +
+	"\vstop_reason\x18\x04 \x01(\tR\n" +
+	"stopReason\"%\n" +
+	"\vStopRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"&\n" +
+	"\fRearmRequest\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"\xb0\x01\n" +
+	"\n" +
+	"StopResult\x12\x18\n" +
+	"\aengaged\x18\x01 \x01(\bR\aengaged\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12+\n" +
+	"\x11cancelled_actions\x18\x04 \x01(\x05R\x10cancelledActions\x12-\n" +
+	"\x12cancelled_commands\x18\x05 \x01(\x05R\x11cancelledCommands\"\x1f\n" +
 	"\x03Ack\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xa9\x04\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"D\n" +
+	"\x11CancelGoalRequest\x12\x17\n" +
+	"\agoal_id\x18\x01 \x01(\tR\x06goalId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xe4\x01\n" +
+	"\x10CancelGoalResult\x12\x17\n" +
+	"\agoal_id\x18\x01 \x01(\tR\x06goalId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12)\n" +
+	"\x10rejected_actions\x18\x03 \x01(\x05R\x0frejectedActions\x12+\n" +
+	"\x11cancelled_actions\x18\x04 \x01(\x05R\x10cancelledActions\x12-\n" +
+	"\x12cancelled_commands\x18\x05 \x01(\x05R\x11cancelledCommands\x12\x18\n" +
+	"\aaborted\x18\x06 \x01(\bR\aaborted\"\xfa\x01\n" +
+	"\x05Grant\x12\x19\n" +
+	"\bgrant_id\x18\x01 \x01(\tR\agrantId\x12\x1f\n" +
+	"\vaction_type\x18\x02 \x01(\tR\n" +
+	"actionType\x12\x18\n" +
+	"\aprocess\x18\x03 \x01(\tR\aprocess\x12\x1f\n" +
+	"\vpath_prefix\x18\x04 \x01(\tR\n" +
+	"pathPrefix\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x06 \x01(\tR\tcreatedBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\b \x01(\x03R\trevokedAt\"\x9b\x01\n" +
+	"\x12CreateGrantRequest\x12\x1f\n" +
+	"\vaction_type\x18\x01 \x01(\tR\n" +
+	"actionType\x12\x18\n" +
+	"\aprocess\x18\x02 \x01(\tR\aprocess\x12\x1f\n" +
+	"\vpath_prefix\x18\x03 \x01(\tR\n" +
+	"pathPrefix\x12)\n" +
+	"\x10duration_seconds\x18\x04 \x01(\x03R\x0fdurationSeconds\">\n" +
+	"\x11ListGrantsRequest\x12)\n" +
+	"\x10include_inactive\x18\x01 \x01(\bR\x0fincludeInactive\"1\n" +
+	"\tGrantList\x12$\n" +
+	"\x06grants\x18\x01 \x03(\v2\f.ghost.GrantR\x06grants\"/\n" +
+	"\x12RevokeGrantRequest\x12\x19\n" +
+	"\bgrant_id\x18\x01 \x01(\tR\agrantId2\xd1\b\n" +
 	"\rNervousSystem\x12:\n" +
 	"\vReportFocus\x12\x11.ghost.FocusState\x1a\x16.google.protobuf.Empty(\x01\x12H\n" +
 	"\x11RequestPermission\x12\x18.ghost.PermissionRequest\x1a\x19.ghost.PermissionResponse\x12?\n" +
-	"\rStreamActions\x12\x16.google.protobuf.Empty\x1a\x14.ghost.ActionCommand0\x01\x12X\n" +
+	"\rStreamActions\x12\x16.google.protobuf.Empty\x1a\x14.ghost.ActionCommand0\x01\x121\n" +
+	"\rReportOutcome\x12\x14.ghost.ActionOutcome\x1a\n" +
+	".ghost.Ack\x12X\n" +
 	"\x13GetPendingApprovals\x12\x16.google.protobuf.Empty\x1a\x12.ghost.PendingList\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/approvals\x12U\n" +
 	"\rApproveAction\x12\x17.ghost.ApprovalDecision\x1a\n" +
 	".ghost.Ack\"\x1f\x82\xd3\xe4\x93\x02\x19\"\x17/v1/approve/{action_id}\x12H\n" +
 	"\rSetSystemMode\x12\x12.ghost.ModeRequest\x1a\n" +
 	".ghost.Ack\"\x17\x82\xd3\xe4\x93\x02\x11\"\x0f/v1/system/mode\x12V\n" +
-	"\x0eGetSystemState\x12\x16.google.protobuf.Empty\x1a\x12.ghost.SystemState\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/system/stateB Z\x1eghost/kernel/internal/protocolb\x06proto3"
+	"\x0eGetSystemState\x12\x16.google.protobuf.Empty\x1a\x12.ghost.SystemState\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/system/state\x12S\n" +
+	"\rEmergencyStop\x12\x12.ghost.StopRequest\x1a\x11.ghost.StopResult\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/system/estop\x12L\n" +
+	"\x05Rearm\x12\x13.ghost.RearmRequest\x1a\x11.ghost.StopResult\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/system/rearm\x12\\\n" +
+	"\n" +
+	"CancelGoal\x12\x18.ghost.CancelGoalRequest\x1a\x17.ghost.CancelGoalResult\"\x1b\x82\xd3\xe4\x93\x02\x15*\x13/v1/goals/{goal_id}\x12M\n" +
+	"\vCreateGrant\x12\x19.ghost.CreateGrantRequest\x1a\f.ghost.Grant\"\x15\x82\xd3\xe4\x93\x02\x0f:\x01*\"\n" +
+	"/v1/grants\x12L\n" +
+	"\n" +
+	"ListGrants\x12\x18.ghost.ListGrantsRequest\x1a\x10.ghost.GrantList\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/grants\x12U\n" +
+	"\vRevokeGrant\x12\x19.ghost.RevokeGrantRequest\x1a\f.ghost.Grant\"\x1d\x82\xd3\xe4\x93\x02\x17*\x15/v1/grants/{grant_id}B Z\x1eghost/kernel/internal/protocolb\x06proto3"
 
 var (
 	file_ghost_proto_rawDescOnce sync.Once
@@ -683,46 +1797,80 @@ func file_ghost_proto_rawDescGZIP() []byte {
 	return file_ghost_proto_rawDescData
 }
 
-var file_ghost_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_ghost_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
 var file_ghost_proto_goTypes = []any{
 	(*FocusState)(nil),         // 0: ghost.FocusState
 	(*PermissionRequest)(nil),  // 1: ghost.PermissionRequest
 	(*PermissionResponse)(nil), // 2: ghost.PermissionResponse
-	(*Action)(nil),             // 3: ghost.Action
-	(*ActionCommand)(nil),      // 4: ghost.ActionCommand
-	(*PendingList)(nil),        // 5: ghost.PendingList
-	(*PendingItem)(nil),        // 6: ghost.PendingItem
-	(*ApprovalDecision)(nil),   // 7: ghost.ApprovalDecision
-	(*ModeRequest)(nil),        // 8: ghost.ModeRequest
-	(*SystemState)(nil),        // 9: ghost.SystemState
-	(*Ack)(nil),                // 10: ghost.Ack
-	nil,                        // 11: ghost.Action.PayloadEntry
-	(*emptypb.Empty)(nil),      // 12: google.protobuf.Empty
+	(*DecisionTrace)(nil),      // 3: ghost.DecisionTrace
+	(*RuleCheck)(nil),          // 4: ghost.RuleCheck
+	(*ActionRisk)(nil),         // 5: ghost.ActionRisk
+	(*FocusCheck)(nil),         // 6: ghost.FocusCheck
+	(*Action)(nil),             // 7: ghost.Action
+	(*ActionCommand)(nil),      // 8: ghost.ActionCommand
+	(*ActionOutcome)(nil),      // 9: ghost.ActionOutcome
+	(*PendingList)(nil),        // 10: ghost.PendingList
+	(*PendingItem)(nil),        // 11: ghost.PendingItem
+	(*ApprovalDecision)(nil),   // 12: ghost.ApprovalDecision
+	(*ModeRequest)(nil),        // 13: ghost.ModeRequest
+	(*SystemState)(nil),        // 14: ghost.SystemState
+	(*StopRequest)(nil),        // 15: ghost.StopRequest
+	(*RearmRequest)(nil),       // 16: ghost.RearmRequest
+	(*StopResult)(nil),         // 17: ghost.StopResult
+	(*Ack)(nil),                // 18: ghost.Ack
+	(*CancelGoalRequest)(nil),  // 19: ghost.CancelGoalRequest
+	(*CancelGoalResult)(nil),   // 20: ghost.CancelGoalResult
+	(*Grant)(nil),              // 21: ghost.Grant
+	(*CreateGrantRequest)(nil), // 22: ghost.CreateGrantRequest
+	(*ListGrantsRequest)(nil),  // 23: ghost.ListGrantsRequest
+	(*GrantList)(nil),          // 24: ghost.GrantList
+	(*RevokeGrantRequest)(nil), // 25: ghost.RevokeGrantRequest
+	nil,                        // 26: ghost.Action.PayloadEntry
+	(*emptypb.Empty)(nil),      // 27: google.protobuf.Empty
 }
 var file_ghost_proto_depIdxs = []int32{
-	3,  // 0: ghost.PermissionRequest.actions:type_name -> ghost.Action
-	11, // 1: ghost.Action.payload:type_name -> ghost.Action.PayloadEntry
-	3,  // 2: ghost.ActionCommand.action:type_name -> ghost.Action
-	6,  // 3: ghost.PendingList.items:type_name -> ghost.PendingItem
-	0,  // 4: ghost.NervousSystem.ReportFocus:input_type -> ghost.FocusState
-	1,  // 5: ghost.NervousSystem.RequestPermission:input_type -> ghost.PermissionRequest
-	12, // 6: ghost.NervousSystem.StreamActions:input_type -> google.protobuf.Empty
-	12, // 7: ghost.NervousSystem.GetPendingApprovals:input_type -> google.protobuf.Empty
-	7,  // 8: ghost.NervousSystem.ApproveAction:input_type -> ghost.ApprovalDecision
-	8,  // 9: ghost.NervousSystem.SetSystemMode:input_type -> ghost.ModeRequest
-	12, // 10: ghost.NervousSystem.GetSystemState:input_type -> google.protobuf.Empty
-	12, // 11: ghost.NervousSystem.ReportFocus:output_type -> google.protobuf.Empty
-	2,  // 12: ghost.NervousSystem.RequestPermission:output_type -> ghost.PermissionResponse
-	4,  // 13: ghost.NervousSystem.StreamActions:output_type -> ghost.ActionCommand
-	5,  // 14: ghost.NervousSystem.GetPendingApprovals:output_type -> ghost.PendingList
-	10, // 15: ghost.NervousSystem.ApproveAction:output_type -> ghost.Ack
-	10, // 16: ghost.NervousSystem.SetSystemMode:output_type -> ghost.Ack
-	9,  // 17: ghost.NervousSystem.GetSystemState:output_type -> ghost.SystemState
-	11, // [11:18] is the sub-list for method output_type
-	4,  // [4:11] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	7,  // 0: ghost.PermissionRequest.actions:type_name -> ghost.Action
+	3,  // 1: ghost.PermissionResponse.trace:type_name -> ghost.DecisionTrace
+	4,  // 2: ghost.DecisionTrace.checks:type_name -> ghost.RuleCheck
+	5,  // 3: ghost.DecisionTrace.actions:type_name -> ghost.ActionRisk
+	6,  // 4: ghost.DecisionTrace.focus:type_name -> ghost.FocusCheck
+	26, // 5: ghost.Action.payload:type_name -> ghost.Action.PayloadEntry
+	7,  // 6: ghost.ActionCommand.action:type_name -> ghost.Action
+	11, // 7: ghost.PendingList.items:type_name -> ghost.PendingItem
+	21, // 8: ghost.GrantList.grants:type_name -> ghost.Grant
+	0,  // 9: ghost.NervousSystem.ReportFocus:input_type -> ghost.FocusState
+	1,  // 10: ghost.NervousSystem.RequestPermission:input_type -> ghost.PermissionRequest
+	27, // 11: ghost.NervousSystem.StreamActions:input_type -> google.protobuf.Empty
+	9,  // 12: ghost.NervousSystem.ReportOutcome:input_type -> ghost.ActionOutcome
+	27, // 13: ghost.NervousSystem.GetPendingApprovals:input_type -> google.protobuf.Empty
+	12, // 14: ghost.NervousSystem.ApproveAction:input_type -> ghost.ApprovalDecision
+	13, // 15: ghost.NervousSystem.SetSystemMode:input_type -> ghost.ModeRequest
+	27, // 16: ghost.NervousSystem.GetSystemState:input_type -> google.protobuf.Empty
+	15, // 17: ghost.NervousSystem.EmergencyStop:input_type -> ghost.StopRequest
+	16, // 18: ghost.NervousSystem.Rearm:input_type -> ghost.RearmRequest
+	19, // 19: ghost.NervousSystem.CancelGoal:input_type -> ghost.CancelGoalRequest
+	22, // 20: ghost.NervousSystem.CreateGrant:input_type -> ghost.CreateGrantRequest
+	23, // 21: ghost.NervousSystem.ListGrants:input_type -> ghost.ListGrantsRequest
+	25, // 22: ghost.NervousSystem.RevokeGrant:input_type -> ghost.RevokeGrantRequest
+	27, // 23: ghost.NervousSystem.ReportFocus:output_type -> google.protobuf.Empty
+	2,  // 24: ghost.NervousSystem.RequestPermission:output_type -> ghost.PermissionResponse
+	8,  // 25: ghost.NervousSystem.StreamActions:output_type -> ghost.ActionCommand
+	18, // 26: ghost.NervousSystem.ReportOutcome:output_type -> ghost.Ack
+	10, // 27: ghost.NervousSystem.GetPendingApprovals:output_type -> ghost.PendingList
+	18, // 28: ghost.NervousSystem.ApproveAction:output_type -> ghost.Ack
+	18, // 29: ghost.NervousSystem.SetSystemMode:output_type -> ghost.Ack
+	14, // 30: ghost.NervousSystem.GetSystemState:output_type -> ghost.SystemState
+	17, // 31: ghost.NervousSystem.EmergencyStop:output_type -> ghost.StopResult
+	17, // 32: ghost.NervousSystem.Rearm:output_type -> ghost.StopResult
+	20, // 33: ghost.NervousSystem.CancelGoal:output_type -> ghost.CancelGoalResult
+	21, // 34: ghost.NervousSystem.CreateGrant:output_type -> ghost.Grant
+	24, // 35: ghost.NervousSystem.ListGrants:output_type -> ghost.GrantList
+	21, // 36: ghost.NervousSystem.RevokeGrant:output_type -> ghost.Grant
+	23, // [23:37] is the sub-list for method output_type
+	9,  // [9:23] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_ghost_proto_init() }
@@ -736,7 +1884,7 @@ func file_ghost_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ghost_proto_rawDesc), len(file_ghost_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
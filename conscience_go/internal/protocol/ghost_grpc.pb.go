@@ -25,10 +25,17 @@ const (
 	NervousSystem_ReportFocus_FullMethodName         = "/ghost.NervousSystem/ReportFocus"
 	NervousSystem_RequestPermission_FullMethodName   = "/ghost.NervousSystem/RequestPermission"
 	NervousSystem_StreamActions_FullMethodName       = "/ghost.NervousSystem/StreamActions"
+	NervousSystem_ReportOutcome_FullMethodName       = "/ghost.NervousSystem/ReportOutcome"
 	NervousSystem_GetPendingApprovals_FullMethodName = "/ghost.NervousSystem/GetPendingApprovals"
 	NervousSystem_ApproveAction_FullMethodName       = "/ghost.NervousSystem/ApproveAction"
 	NervousSystem_SetSystemMode_FullMethodName       = "/ghost.NervousSystem/SetSystemMode"
 	NervousSystem_GetSystemState_FullMethodName      = "/ghost.NervousSystem/GetSystemState"
+	NervousSystem_EmergencyStop_FullMethodName       = "/ghost.NervousSystem/EmergencyStop"
+	NervousSystem_Rearm_FullMethodName               = "/ghost.NervousSystem/Rearm"
+	NervousSystem_CancelGoal_FullMethodName          = "/ghost.NervousSystem/CancelGoal"
+	NervousSystem_CreateGrant_FullMethodName         = "/ghost.NervousSystem/CreateGrant"
+	NervousSystem_ListGrants_FullMethodName          = "/ghost.NervousSystem/ListGrants"
+	NervousSystem_RevokeGrant_FullMethodName         = "/ghost.NervousSystem/RevokeGrant"
 )
 
 // NervousSystemClient is the client API for NervousSystem service.
@@ -48,6 +55,8 @@ type NervousSystemClient interface {
 	// --- MOTOR CONTROL (Kernel -> Body) ---
 	// Sentinel subscribes to a stream of approved actions.
 	StreamActions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ActionCommand], error)
+	// Sentinel reports how each command it received turned out.
+	ReportOutcome(ctx context.Context, in *ActionOutcome, opts ...grpc.CallOption) (*Ack, error)
 	// UI asks: "Is there anything waiting for approval?"
 	GetPendingApprovals(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PendingList, error)
 	// User says: "Yes, do it."
@@ -56,6 +65,19 @@ type NervousSystemClient interface {
 	SetSystemMode(ctx context.Context, in *ModeRequest, opts ...grpc.CallOption) (*Ack, error)
 	// Dashboard polling for status
 	GetSystemState(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SystemState, error)
+	// Anyone says: "Stop everything now." Pauses, cancels queued work and tells the Body to abort.
+	EmergencyStop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopResult, error)
+	// User says: "Safe to continue." Clears the stop and resumes in SHADOW.
+	Rearm(ctx context.Context, in *RearmRequest, opts ...grpc.CallOption) (*StopResult, error)
+	// User or planner says: "Drop this goal." Rejects its waiting proposals, revokes its queued
+	// commands and tells the Body to abort a step in progress.
+	CancelGoal(ctx context.Context, in *CancelGoalRequest, opts ...grpc.CallOption) (*CancelGoalResult, error)
+	// User says: "Allow TYPE in code.exe for 30 minutes." Matching proposals run without asking.
+	CreateGrant(ctx context.Context, in *CreateGrantRequest, opts ...grpc.CallOption) (*Grant, error)
+	// Grants in force, or every grant ever made
+	ListGrants(ctx context.Context, in *ListGrantsRequest, opts ...grpc.CallOption) (*GrantList, error)
+	// User says: "Ask me again." Ends a grant at once.
+	RevokeGrant(ctx context.Context, in *RevokeGrantRequest, opts ...grpc.CallOption) (*Grant, error)
 }
 
 type nervousSystemClient struct {
@@ -108,6 +130,16 @@ func (c *nervousSystemClient) StreamActions(ctx context.Context, in *emptypb.Emp
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type NervousSystem_StreamActionsClient = grpc.ServerStreamingClient[ActionCommand]
 
+func (c *nervousSystemClient) ReportOutcome(ctx context.Context, in *ActionOutcome, opts ...grpc.CallOption) (*Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ack)
+	err := c.cc.Invoke(ctx, NervousSystem_ReportOutcome_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *nervousSystemClient) GetPendingApprovals(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PendingList, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PendingList)
@@ -148,6 +180,66 @@ func (c *nervousSystemClient) GetSystemState(ctx context.Context, in *emptypb.Em
 	return out, nil
 }
 
+func (c *nervousSystemClient) EmergencyStop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopResult)
+	err := c.cc.Invoke(ctx, NervousSystem_EmergencyStop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nervousSystemClient) Rearm(ctx context.Context, in *RearmRequest, opts ...grpc.CallOption) (*StopResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopResult)
+	err := c.cc.Invoke(ctx, NervousSystem_Rearm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nervousSystemClient) CancelGoal(ctx context.Context, in *CancelGoalRequest, opts ...grpc.CallOption) (*CancelGoalResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelGoalResult)
+	err := c.cc.Invoke(ctx, NervousSystem_CancelGoal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nervousSystemClient) CreateGrant(ctx context.Context, in *CreateGrantRequest, opts ...grpc.CallOption) (*Grant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Grant)
+	err := c.cc.Invoke(ctx, NervousSystem_CreateGrant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nervousSystemClient) ListGrants(ctx context.Context, in *ListGrantsRequest, opts ...grpc.CallOption) (*GrantList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GrantList)
+	err := c.cc.Invoke(ctx, NervousSystem_ListGrants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nervousSystemClient) RevokeGrant(ctx context.Context, in *RevokeGrantRequest, opts ...grpc.CallOption) (*Grant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Grant)
+	err := c.cc.Invoke(ctx, NervousSystem_RevokeGrant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NervousSystemServer is the server API for NervousSystem service.
 // All implementations must embed UnimplementedNervousSystemServer
 // for forward compatibility.
@@ -165,6 +257,8 @@ type NervousSystemServer interface {
 	// --- MOTOR CONTROL (Kernel -> Body) ---
 	// Sentinel subscribes to a stream of approved actions.
 	StreamActions(*emptypb.Empty, grpc.ServerStreamingServer[ActionCommand]) error
+	// Sentinel reports how each command it received turned out.
+	ReportOutcome(context.Context, *ActionOutcome) (*Ack, error)
 	// UI asks: "Is there anything waiting for approval?"
 	GetPendingApprovals(context.Context, *emptypb.Empty) (*PendingList, error)
 	// User says: "Yes, do it."
@@ -173,6 +267,19 @@ type NervousSystemServer interface {
 	SetSystemMode(context.Context, *ModeRequest) (*Ack, error)
 	// Dashboard polling for status
 	GetSystemState(context.Context, *emptypb.Empty) (*SystemState, error)
+	// Anyone says: "Stop everything now." Pauses, cancels queued work and tells the Body to abort.
+	EmergencyStop(context.Context, *StopRequest) (*StopResult, error)
+	// User says: "Safe to continue." Clears the stop and resumes in SHADOW.
+	Rearm(context.Context, *RearmRequest) (*StopResult, error)
+	// User or planner says: "Drop this goal." Rejects its waiting proposals, revokes its queued
+	// commands and tells the Body to abort a step in progress.
+	CancelGoal(context.Context, *CancelGoalRequest) (*CancelGoalResult, error)
+	// User says: "Allow TYPE in code.exe for 30 minutes." Matching proposals run without asking.
+	CreateGrant(context.Context, *CreateGrantRequest) (*Grant, error)
+	// Grants in force, or every grant ever made
+	ListGrants(context.Context, *ListGrantsRequest) (*GrantList, error)
+	// User says: "Ask me again." Ends a grant at once.
+	RevokeGrant(context.Context, *RevokeGrantRequest) (*Grant, error)
 	mustEmbedUnimplementedNervousSystemServer()
 }
 
@@ -192,6 +299,9 @@ func (UnimplementedNervousSystemServer) RequestPermission(context.Context, *Perm
 func (UnimplementedNervousSystemServer) StreamActions(*emptypb.Empty, grpc.ServerStreamingServer[ActionCommand]) error {
 	return status.Error(codes.Unimplemented, "method StreamActions not implemented")
 }
+func (UnimplementedNervousSystemServer) ReportOutcome(context.Context, *ActionOutcome) (*Ack, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportOutcome not implemented")
+}
 func (UnimplementedNervousSystemServer) GetPendingApprovals(context.Context, *emptypb.Empty) (*PendingList, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetPendingApprovals not implemented")
 }
@@ -204,6 +314,24 @@ func (UnimplementedNervousSystemServer) SetSystemMode(context.Context, *ModeRequ
 func (UnimplementedNervousSystemServer) GetSystemState(context.Context, *emptypb.Empty) (*SystemState, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetSystemState not implemented")
 }
+func (UnimplementedNervousSystemServer) EmergencyStop(context.Context, *StopRequest) (*StopResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method EmergencyStop not implemented")
+}
+func (UnimplementedNervousSystemServer) Rearm(context.Context, *RearmRequest) (*StopResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method Rearm not implemented")
+}
+func (UnimplementedNervousSystemServer) CancelGoal(context.Context, *CancelGoalRequest) (*CancelGoalResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelGoal not implemented")
+}
+func (UnimplementedNervousSystemServer) CreateGrant(context.Context, *CreateGrantRequest) (*Grant, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateGrant not implemented")
+}
+func (UnimplementedNervousSystemServer) ListGrants(context.Context, *ListGrantsRequest) (*GrantList, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListGrants not implemented")
+}
+func (UnimplementedNervousSystemServer) RevokeGrant(context.Context, *RevokeGrantRequest) (*Grant, error) {
+	return nil, status.Error(codes.Unimplemented, "method RevokeGrant not implemented")
+}
 func (UnimplementedNervousSystemServer) mustEmbedUnimplementedNervousSystemServer() {}
 func (UnimplementedNervousSystemServer) testEmbeddedByValue()                       {}
 
@@ -261,6 +389,24 @@ func _NervousSystem_StreamActions_Handler(srv interface{}, stream grpc.ServerStr
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type NervousSystem_StreamActionsServer = grpc.ServerStreamingServer[ActionCommand]
 
+func _NervousSystem_ReportOutcome_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActionOutcome)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NervousSystemServer).ReportOutcome(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NervousSystem_ReportOutcome_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NervousSystemServer).ReportOutcome(ctx, req.(*ActionOutcome))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _NervousSystem_GetPendingApprovals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -333,6 +479,114 @@ func _NervousSystem_GetSystemState_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NervousSystem_EmergencyStop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NervousSystemServer).EmergencyStop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NervousSystem_EmergencyStop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NervousSystemServer).EmergencyStop(ctx, req.(*StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NervousSystem_Rearm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RearmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NervousSystemServer).Rearm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NervousSystem_Rearm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NervousSystemServer).Rearm(ctx, req.(*RearmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NervousSystem_CancelGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelGoalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NervousSystemServer).CancelGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NervousSystem_CancelGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NervousSystemServer).CancelGoal(ctx, req.(*CancelGoalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NervousSystem_CreateGrant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NervousSystemServer).CreateGrant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NervousSystem_CreateGrant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NervousSystemServer).CreateGrant(ctx, req.(*CreateGrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NervousSystem_ListGrants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGrantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NervousSystemServer).ListGrants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NervousSystem_ListGrants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NervousSystemServer).ListGrants(ctx, req.(*ListGrantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NervousSystem_RevokeGrant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeGrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NervousSystemServer).RevokeGrant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NervousSystem_RevokeGrant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NervousSystemServer).RevokeGrant(ctx, req.(*RevokeGrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // NervousSystem_ServiceDesc is the grpc.ServiceDesc for NervousSystem service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -344,6 +598,10 @@ var NervousSystem_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RequestPermission",
 			Handler:    _NervousSystem_RequestPermission_Handler,
 		},
+		{
+			MethodName: "ReportOutcome",
+			Handler:    _NervousSystem_ReportOutcome_Handler,
+		},
 		{
 			MethodName: "GetPendingApprovals",
 			Handler:    _NervousSystem_GetPendingApprovals_Handler,
@@ -360,6 +618,30 @@ var NervousSystem_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetSystemState",
 			Handler:    _NervousSystem_GetSystemState_Handler,
 		},
+		{
+			MethodName: "EmergencyStop",
+			Handler:    _NervousSystem_EmergencyStop_Handler,
+		},
+		{
+			MethodName: "Rearm",
+			Handler:    _NervousSystem_Rearm_Handler,
+		},
+		{
+			MethodName: "CancelGoal",
+			Handler:    _NervousSystem_CancelGoal_Handler,
+		},
+		{
+			MethodName: "CreateGrant",
+			Handler:    _NervousSystem_CreateGrant_Handler,
+		},
+		{
+			MethodName: "ListGrants",
+			Handler:    _NervousSystem_ListGrants_Handler,
+		},
+		{
+			MethodName: "RevokeGrant",
+			Handler:    _NervousSystem_RevokeGrant_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
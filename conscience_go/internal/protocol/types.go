@@ -6,6 +6,8 @@ package protocol
 import (
 	"encoding/json"
 	"time"
+
+	"ghost/kernel/internal/domain"
 )
 
 // ProtocolVersion is the current gateway protocol version
@@ -125,6 +127,8 @@ type ExecApprovalRequestParams struct {
 	ExpectedWindow string          `json:"expected_window,omitempty"`
 	RiskLevel      int             `json:"risk_level"` // 1-10 scale for VA Conscience Kernel
 	TraceID        string          `json:"trace_id,omitempty"`
+	DryRun         bool            `json:"dry_run,omitempty"`  // Return the decision trace without acting on it
+	Approval       *SignedApproval `json:"approval,omitempty"` // From exec.confirm; lets a high-risk request past the risk rule once
 }
 
 // ExecApprovalResolveParams resolves a pending approval
@@ -141,7 +145,35 @@ type ExecApprovalResult struct {
 	Approved   bool   `json:"approved"`
 	Reason     string `json:"reason,omitempty"`
 	TrustScore int    `json:"trust_score"`
-	ErrorCode  string `json:"error_code,omitempty"`
+	ErrorCode  string `json:"error_code,omitempty"` // "approval_required" when a second factor must confirm it
+	RiskLevel  int    `json:"risk_level,omitempty"`
+	Digest     string `json:"digest,omitempty"` // With "approval_required", the digest a confirmation will approve
+
+	Trace *domain.DecisionTrace `json:"trace,omitempty"` // Set for dry runs
+}
+
+// ErrorCodeApprovalRequired marks a high-risk request that can be re-sent with a signed approval
+const ErrorCodeApprovalRequired = "approval_required"
+
+// ExecConfirmParams confirms a high-risk request refused with "approval_required"
+// A registered approval client confirms as itself; any other client must give a one-time code.
+type ExecConfirmParams struct {
+	RequestID string `json:"request_id"`
+	Digest    string `json:"digest,omitempty"` // Digest of the actions the approver was shown; refused if the request differs
+	Code      string `json:"code,omitempty"`   // Time-based one-time code from the user's authenticator
+}
+
+// SignedApproval is the kernel's signature over a second-factor confirmation of one request
+// It is good once, for the request with the same ID and digest, until it expires.
+type SignedApproval struct {
+	ID        string    `json:"id"`
+	RequestID string    `json:"request_id"`
+	Digest    string    `json:"digest"`   // SHA-256 of the request's intent, actions and expected window
+	Factor    string    `json:"factor"`   // "totp" or "client"
+	Approver  string    `json:"approver"` // The registered client that confirmed, or "totp"
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Signature string    `json:"signature"` // HMAC-SHA256 over the fields above
 }
 
 // Memory Operations
@@ -214,6 +246,14 @@ type SessionSnapshot struct {
 	LastActiveAt   time.Time       `json:"last_active_at"`
 }
 
+// Emergency Stop (Kill Switch)
+// ----------------------------
+
+// EmergencyStopParams pulls the kill switch; the result is a StopResult
+type EmergencyStopParams struct {
+	Reason string `json:"reason,omitempty"`
+}
+
 // Event Types (Server-pushed)
 // ---------------------------
 
@@ -234,9 +274,19 @@ type FocusChangedEvent struct {
 
 // ApprovalPendingEvent is pushed when action needs approval
 type ApprovalPendingEvent struct {
-	RequestID string    `json:"request_id"`
-	Intent    string    `json:"intent"`
-	RiskLevel int       `json:"risk_level"`
+	RequestID      string          `json:"request_id"`
+	Intent         string          `json:"intent"`
+	RiskLevel      int             `json:"risk_level"`
+	Actions        json.RawMessage `json:"actions,omitempty"`         // Exactly what a confirmation approves
+	ExpectedWindow string          `json:"expected_window,omitempty"` // Window the actions are meant for
+	Digest         string          `json:"digest,omitempty"`          // Pass back in exec.confirm to approve only these actions
+	Timestamp      time.Time       `json:"timestamp"`
+}
+
+// EmergencyStopEvent is pushed to every client when the kill switch is pulled
+// Clients abort in-flight input and drop anything they have queued.
+type EmergencyStopEvent struct {
+	Reason    string    `json:"reason"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -299,18 +349,19 @@ type ActionValidationResult struct {
 	Blocked    bool      `json:"blocked"`
 	Reason     string    `json:"reason,omitempty"`
 	RiskLevel  RiskLevel `json:"risk_level"`
-	Override   bool      `json:"override"`    // True if Override key was provided
-	TrustScore int       `json:"trust_score"` // Historical trust from intent history
+	Override   bool      `json:"override"`         // True if a signed approval let it past the risk rule
+	TrustScore int       `json:"trust_score"`      // Historical trust from intent history
+	Digest     string    `json:"digest,omitempty"` // Set when a second factor may confirm the blocked request
 }
 
 // ActionValidationRequest is sent to the Conscience Kernel
 type ActionValidationRequest struct {
-	RequestID      string         `json:"request_id"`
-	Intent         string         `json:"intent"`
-	Actions        []LegacyAction `json:"actions"`
-	ExpectedWindow string         `json:"expected_window,omitempty"`
-	Override       bool           `json:"override"` // If true, bypass RiskLevel checks
-	TraceID        string         `json:"trace_id,omitempty"`
+	RequestID      string          `json:"request_id"`
+	Intent         string          `json:"intent"`
+	Actions        []LegacyAction  `json:"actions"`
+	ExpectedWindow string          `json:"expected_window,omitempty"`
+	Approval       *SignedApproval `json:"approval,omitempty"` // Second-factor approval that lets it past the RiskLevel check
+	TraceID        string          `json:"trace_id,omitempty"`
 }
 
 // Client Registry Types
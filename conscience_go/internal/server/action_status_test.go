@@ -0,0 +1,145 @@
+// Author: Enkae (enkae.dev@pm.me)
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/service"
+)
+
+// newTestServer serves the REST API over a fresh store
+func newTestServer(t *testing.T) (*Server, *adapter.Store) {
+	t.Helper()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	memoryRepo, err := adapter.NewSQLiteRepositoryWithDB(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actionRepo, err := adapter.NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commandRepo, err := adapter.NewCommandRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goalRepo, err := adapter.NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateRepo, err := adapter.NewStateRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewServer(memoryRepo, commandRepo, actionRepo, goalRepo, stateRepo), store
+}
+
+// serve sends a request through the server's handler
+func serve(s *Server, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestStatusWritesNeedAnApprovedAction(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestServer(t)
+	action := domain.NewActionProposal("send payment", 40, json.RawMessage(`{"type":"CLICK"}`), "banking")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := s.actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+
+	// Held for the user: it cannot be pushed to the effector
+	if rec := serve(s, http.MethodPost, "/api/actions/"+action.ID+"/executing", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("executing a held proposal: status %d, want 409", rec.Code)
+	}
+	if held, _ := s.actionRepo.GetActionByID(ctx, action.ID); held.Status != domain.ActionProposalStatusWaitingForUser {
+		t.Fatalf("held proposal is %s, want it still WAITING_FOR_USER", held.Status)
+	}
+	if rec := serve(s, http.MethodPost, "/api/actions/missing-action/executing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("executing an unknown proposal: status %d, want 404", rec.Code)
+	}
+
+	// Approved, it runs to completion, after which it is final
+	if err := s.actionRepo.UpdateActionStatus(ctx, action.ID, domain.ActionProposalStatusApproved); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []string{"executing", "complete"} {
+		if rec := serve(s, http.MethodPost, "/api/actions/"+action.ID+"/"+step, ""); rec.Code != http.StatusOK {
+			t.Fatalf("%s an approved proposal: status %d: %s", step, rec.Code, rec.Body)
+		}
+	}
+	if rec := serve(s, http.MethodPost, "/api/actions/"+action.ID+"/executing", ""); rec.Code != http.StatusConflict {
+		t.Errorf("executing a completed proposal: status %d, want 409", rec.Code)
+	}
+}
+
+func TestApprovalAfterGoalCancelled(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestServer(t)
+	goal := domain.NewGoal("pay rent")
+	if err := s.goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.goalRepo.SavePlan(ctx, goal.ID, "", []string{"transfer rent"}); err != nil {
+		t.Fatal(err)
+	}
+	action := domain.NewActionProposal("transfer rent", 40, json.RawMessage(`{"type":"CLICK"}`), "banking")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := s.actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.goalRepo.AttachAction(ctx, goal.ID, 0, action); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.goalRepo.CancelGoal(ctx, goal.ID); err != nil {
+		t.Fatalf("CancelGoal() error = %v", err)
+	}
+	if rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, `{"approved": true}`); rec.Code != http.StatusConflict {
+		t.Fatalf("approving a cancelled goal's proposal: status %d, want 409", rec.Code)
+	}
+	if held, _ := s.actionRepo.GetActionByID(ctx, action.ID); held.Status != domain.ActionProposalStatusRejected {
+		t.Errorf("proposal is %s after the refused approval, want REJECTED", held.Status)
+	}
+}
+
+func TestApprovalAfterEmergencyStop(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestServer(t)
+	s.SetKillSwitch(service.NewKillSwitch(s.stateRepo, nil))
+	waiting := domain.NewActionProposal("send payment", 40, json.RawMessage(`{"type":"CLICK"}`), "banking")
+	waiting.Status = domain.ActionProposalStatusWaitingForUser
+	if err := s.actionRepo.SaveActionProposal(ctx, waiting); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := serve(s, http.MethodPost, "/api/estop", `{"reason": "wrong window"}`); rec.Code != http.StatusOK {
+		t.Fatalf("estop status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := serve(s, http.MethodPost, "/api/estop/rearm", `{"reason": "checked the window"}`); rec.Code != http.StatusOK {
+		t.Fatalf("rearm status %d: %s", rec.Code, rec.Body)
+	}
+
+	// Re-armed, the work the stop cancelled stays cancelled rather than paused
+	if rec := serve(s, http.MethodPost, "/api/approve/"+waiting.ID, `{"approved": true}`); rec.Code != http.StatusConflict {
+		t.Fatalf("approving a proposal the stop cancelled: status %d, want 409", rec.Code)
+	}
+	if queue, _ := s.actionRepo.GetApprovedActions(ctx); len(queue) != 0 {
+		t.Errorf("effector queue has %d actions after re-arming", len(queue))
+	}
+}
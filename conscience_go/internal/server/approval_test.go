@@ -0,0 +1,182 @@
+// Author: Enkae (enkae.dev@pm.me)
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/protocol"
+	"ghost/kernel/internal/service"
+)
+
+// testTOTPSecret is the RFC 6238 SHA-1 test key in base32
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// newSecondFactorServer serves approvals held to a one-time code or the "phone" approval client
+func newSecondFactorServer(t *testing.T) (*Server, *adapter.Store, *conscience.Approvals, *[]protocol.ApprovalPendingEvent) {
+	t.Helper()
+	s, store := newTestServer(t)
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetDataGovernance(adapter.NewRetentionRepository(store), auditRepo)
+	approvals, err := conscience.NewApprovals([]byte("signing-key"), testTOTPSecret, []string{"phone"}, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pending []protocol.ApprovalPendingEvent
+	secondFactor := service.NewSecondFactor(approvals)
+	secondFactor.OnPending(func(event protocol.ApprovalPendingEvent) { pending = append(pending, event) })
+	s.SetSecondFactor(secondFactor)
+	return s, store, approvals, &pending
+}
+
+// currentCode is the one-time code the user's authenticator shows now
+func currentCode() string {
+	secret, _ := conscience.DecodeTOTPSecret(testTOTPSecret)
+	return conscience.TOTPCode(secret, uint64(time.Now().Unix())/30)
+}
+
+// lastAudit returns the detail of the newest audit entry
+func lastAudit(t *testing.T, s *Server) map[string]interface{} {
+	t.Helper()
+	records, err := s.auditRepo.GetRecent(context.Background(), 1)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("GetRecent() = %v, %v", records, err)
+	}
+	var detail map[string]interface{}
+	if err := json.Unmarshal(records[0].Detail, &detail); err != nil {
+		t.Fatal(err)
+	}
+	return detail
+}
+
+func TestHighRiskApprovalNeedsSecondFactor(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, pending := newSecondFactorServer(t)
+	payload := json.RawMessage(`{"actions":[{"type":"CLICK","payload":{"target":"pay"}}]}`)
+	action := domain.NewActionProposal("send payment", 80, payload, "banking")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := s.actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a factor the approval waits, and approval clients are shown what it approves
+	rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, `{"approved": true}`)
+	var refused map[string]string
+	if rec.Code != http.StatusForbidden || json.Unmarshal(rec.Body.Bytes(), &refused) != nil || refused["request_id"] != "proposal:"+action.ID {
+		t.Fatalf("approve without a code: status %d: %s; want 403 naming the request", rec.Code, rec.Body)
+	}
+	if len(*pending) != 1 || (*pending)[0].Digest != refused["digest"] || string((*pending)[0].Actions) != string(payload) {
+		t.Fatalf("pending events = %+v, want one with the digest and payload", *pending)
+	}
+
+	// An edited payload is a different approval
+	edited := `{"approved": true, "payload": {"actions":[{"type":"CLICK","payload":{"target":"cancel"}}]}}`
+	rec = serve(s, http.MethodPost, "/api/approve/"+action.ID, edited)
+	var refusedEdit map[string]string
+	if rec.Code != http.StatusForbidden || json.Unmarshal(rec.Body.Bytes(), &refusedEdit) != nil || refusedEdit["digest"] == refused["digest"] {
+		t.Fatalf("approve edited without a code: status %d: %s; want 403 for another digest", rec.Code, rec.Body)
+	}
+
+	wrong := "000000"
+	if wrong == currentCode() {
+		wrong = "111111"
+	}
+	if rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, `{"approved": true, "code": "`+wrong+`"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("approve with a wrong code: status %d, want 403", rec.Code)
+	}
+	if held, _ := s.actionRepo.GetActionByID(ctx, action.ID); held.Status != domain.ActionProposalStatusWaitingForUser {
+		t.Fatalf("proposal is %s after refused approvals, want it still WAITING_FOR_USER", held.Status)
+	}
+
+	if rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, `{"approved": true, "code": "`+currentCode()+`"}`); rec.Code != http.StatusOK {
+		t.Fatalf("approve with the code: status %d: %s", rec.Code, rec.Body)
+	}
+	detail := lastAudit(t, s)
+	if detail["approval_id"] == nil || detail["factor"] != conscience.FactorTOTP || detail["approved"] != nil {
+		t.Errorf("audit detail = %v, want the signed approval in place of the approved flag", detail)
+	}
+
+	// Rejecting needs no factor
+	rejected := domain.NewActionProposal("send payment", 80, payload, "banking")
+	rejected.Status = domain.ActionProposalStatusWaitingForUser
+	if err := s.actionRepo.SaveActionProposal(ctx, rejected); err != nil {
+		t.Fatal(err)
+	}
+	if rec := serve(s, http.MethodPost, "/api/approve/"+rejected.ID, `{"approved": false}`); rec.Code != http.StatusOK {
+		t.Fatalf("reject: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHighRiskBatchConfirmedByApprovalClient(t *testing.T) {
+	s, store, approvals, _ := newSecondFactorServer(t)
+	batchRepo, err := adapter.NewBatchRepository(store, s.actionRepo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetBatchRepository(batchRepo)
+
+	rec := serve(s, http.MethodPost, "/api/batch", `{"intent": "pay rent", "domain": "banking", "steps": [{"intent": "transfer rent", "risk_score": 80}]}`)
+	var batch domain.ActionBatch
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &batch) != nil || batch.Status != domain.ActionBatchStatusWaitingForUser {
+		t.Fatalf("propose batch: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(s, http.MethodPost, "/api/batch/"+batch.ID+"/approve", `{"approved": true}`)
+	var refused map[string]string
+	if rec.Code != http.StatusForbidden || json.Unmarshal(rec.Body.Bytes(), &refused) != nil {
+		t.Fatalf("approve batch without a factor: status %d: %s; want 403", rec.Code, rec.Body)
+	}
+
+	// The phone confirms what it was shown; the kernel keeps the approval for the person's retry
+	if _, err := approvals.Confirm(refused["request_id"], refused["digest"], "", "phone"); err != nil {
+		t.Fatalf("Confirm() by phone error = %v", err)
+	}
+	if rec := serve(s, http.MethodPost, "/api/batch/"+batch.ID+"/approve", `{"approved": true}`); rec.Code != http.StatusOK {
+		t.Fatalf("approve confirmed batch: status %d: %s", rec.Code, rec.Body)
+	}
+	if detail := lastAudit(t, s); detail["approver"] != "phone" || detail["factor"] != conscience.FactorClient {
+		t.Errorf("audit detail = %v, want the phone's approval", detail)
+	}
+}
+
+func TestEditRaisingRiskNeedsConfirmation(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, _ := newSecondFactorServer(t)
+	action := domain.NewActionProposal("save rent note", 20, json.RawMessage(`{"actions":[{"type":"CLICK","payload":{"target":"save"}}]}`), "editor")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := s.actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+	// Writing a file sits six levels above a click, raising the score from 20 to 80
+	edited := `"payload": {"actions":[{"type":"WRITE","path":"notes/rent.md"}]}`
+
+	rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, `{"approved": true, `+edited+`}`)
+	var held struct {
+		Status    string `json:"status"`
+		RiskScore int    `json:"risk_score"`
+	}
+	if rec.Code != http.StatusConflict || json.Unmarshal(rec.Body.Bytes(), &held) != nil || held.Status != "confirm_risk" || held.RiskScore != 80 {
+		t.Fatalf("approve raised edit: status %d: %s; want 409 asking to confirm risk 80", rec.Code, rec.Body)
+	}
+
+	// Confirmed at High, it still waits on the second factor
+	if rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, `{"approved": true, "confirm_risk": true, `+edited+`}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("approve confirmed edit without a code: status %d: %s; want 403", rec.Code, rec.Body)
+	}
+	body := `{"approved": true, "confirm_risk": true, "code": "` + currentCode() + `", ` + edited + `}`
+	if rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, body); rec.Code != http.StatusOK {
+		t.Fatalf("approve confirmed edit with the code: status %d: %s", rec.Code, rec.Body)
+	}
+	if approved, _ := s.actionRepo.GetActionByID(ctx, action.ID); approved.Status != domain.ActionProposalStatusApproved {
+		t.Errorf("proposal is %s, want APPROVED", approved.Status)
+	}
+}
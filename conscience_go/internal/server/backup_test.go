@@ -0,0 +1,40 @@
+// Author: Enkae (enkae.dev@pm.me)
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ghost/kernel/internal/adapter"
+)
+
+func TestBackupsInTheSameSecondDoNotCollide(t *testing.T) {
+	s, store := newTestServer(t)
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetDataGovernance(adapter.NewRetentionRepository(store), auditRepo)
+	s.SetPortability(adapter.NewBackupRepository(store), t.TempDir(), "admin-token")
+
+	paths := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/backup", nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("backup %d status %d: %s", i, rec.Code, rec.Body)
+		}
+		var result map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		paths[result["path"]] = true
+	}
+	if len(paths) != 3 {
+		t.Errorf("three backups wrote %d distinct files, want 3", len(paths))
+	}
+}
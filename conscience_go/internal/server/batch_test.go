@@ -0,0 +1,47 @@
+// Author: Enkae (enkae.dev@pm.me)
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+)
+
+func TestBatchHeldByAManualStepDomain(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestServer(t)
+	batchRepo, err := adapter.NewBatchRepository(store, s.actionRepo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetBatchRepository(batchRepo)
+	if err := s.actionRepo.SetUserMode(ctx, "banking", domain.ModeTypeManual); err != nil {
+		t.Fatal(err)
+	}
+
+	propose := func(body string) *domain.ActionBatch {
+		t.Helper()
+		rec := serve(s, http.MethodPost, "/api/batch", body)
+		var batch domain.ActionBatch
+		if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &batch) != nil {
+			t.Fatalf("propose batch: status %d: %s", rec.Code, rec.Body)
+		}
+		return &batch
+	}
+
+	if batch := propose(`{"intent": "take notes", "domain": "editor", "steps": [{"intent": "open editor", "risk_score": 5}]}`); batch.Status != domain.ActionBatchStatusRunning {
+		t.Fatalf("low-risk editor batch = %s, want it auto-approved", batch.Status)
+	}
+	for _, body := range []string{
+		`{"intent": "pay and note", "domain": "editor", "steps": [{"intent": "open editor", "risk_score": 5}, {"intent": "pay rent", "risk_score": 5, "domain": "banking"}]}`,
+		`{"intent": "note", "domain": "editor", "steps": [{"intent": "open editor", "risk_score": 5, "compensation": {"intent": "refund", "risk_score": 5, "domain": "banking"}}]}`,
+	} {
+		if batch := propose(body); batch.Status != domain.ActionBatchStatusWaitingForUser {
+			t.Errorf("batch with a banking action = %s, want WAITING_FOR_USER", batch.Status)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Author: Enkae (enkae.dev@pm.me)
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ghost/kernel/internal/adapter"
+)
+
+// newCaptureServer serves the artifact and forget endpoints over a fresh store
+func newCaptureServer(t *testing.T) (*Server, *adapter.SQLiteRepository) {
+	t.Helper()
+	s, store := newTestServer(t)
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetDataGovernance(adapter.NewRetentionRepository(store), auditRepo)
+	return s, s.repo
+}
+
+func TestForgetReachesCapturedArtifacts(t *testing.T) {
+	s, memoryRepo := newCaptureServer(t)
+	focused := [2]string{"bank.exe", "Statement - My Bank"}
+	s.SetFocus(func() (string, string) { return focused[0], focused[1] })
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post("/api/artifacts", `{"type": "TEXT", "content": "Balance 1,024.00"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("capture status %d: %s", rec.Code, rec.Body)
+	}
+	focused = [2]string{"notepad.exe", "notes.txt - Notepad"}
+	if rec := post("/api/artifacts", `{"type": "edit", "content": "shopping list"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("capture status %d: %s", rec.Code, rec.Body)
+	}
+
+	artifacts, err := memoryRepo.GetLastArtifacts(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 2 {
+		t.Fatalf("stored %d artifacts, want 2", len(artifacts))
+	}
+	for _, a := range artifacts {
+		if a.SourceApp == "" || a.WindowTitle == "" {
+			t.Errorf("artifact %q stored without its focus: app %q, window %q", a.Content, a.SourceApp, a.WindowTitle)
+		}
+	}
+
+	for _, scope := range []string{`{"application": "bank.exe"}`, `{"window_pattern": "*Notepad*"}`} {
+		rec := post("/api/forget", scope)
+		var result adapter.ForgetResult
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &result) != nil || result.Deleted["artifacts"] != 1 {
+			t.Errorf("forget %s = %d %s, want one artifact deleted", scope, rec.Code, rec.Body)
+		}
+	}
+	if artifacts, _ := memoryRepo.GetLastArtifacts(context.Background(), 10); len(artifacts) != 0 {
+		t.Errorf("%d artifacts left after forgetting both scopes", len(artifacts))
+	}
+}
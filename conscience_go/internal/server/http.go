@@ -3,15 +3,29 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/auth"
 	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
+	"ghost/kernel/internal/protocol"
+	"ghost/kernel/internal/service"
+	"ghost/kernel/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Server represents the HTTP API server
@@ -22,8 +36,59 @@ type Server struct {
 	goalRepo   *adapter.GoalRepository
 	stateRepo  *adapter.StateRepository
 	mux        *http.ServeMux
+
+	// Data governance (optional)
+	retentionRepo *adapter.RetentionRepository
+	auditRepo     *adapter.AuditRepository
+
+	// Backup and portability (optional, admin token required)
+	backupRepo *adapter.BackupRepository
+	backupDir  string
+	adminToken string
+
+	// Proposal rate limiting (optional)
+	throttle *service.Throttle
+
+	// Approval policy; defaults to service.DefaultSafetyConfig
+	safety *service.SafetyChecker
+	// Records policy decisions for replay (optional)
+	decisionLog *adapter.DecisionLogRepository
+
+	// Emergency stop (optional)
+	killSwitch *service.KillSwitch
+	// Confirms high-risk approvals; without it they are refused
+	secondFactor *service.SecondFactor
+	// Cancels goals with the proposals and commands they spawned (optional)
+	goalCanceller *service.GoalCanceller
+	// Multi-step plans approved once and run in order (optional)
+	batchRepo *adapter.BatchRepository
+	// Clarification threads on proposals (optional)
+	messageRepo *adapter.MessageRepository
+	// Learns plans corrected at approval (optional)
+	intentRepo *adapter.IntentHistoryRepository
+	// Standing approvals checked before a proposal waits for the user (optional)
+	grantRepo      *adapter.GrantRepository
+	focusedProcess func() string
+	// Stamps captured artifacts with the process and window in focus (optional)
+	focus func() (process, window string)
+
+	// Intent lifecycle lookup (optional)
+	traceRepo *adapter.TraceRepository
+
+	// Wakes long-polling planners when a goal is injected
+	goalMu    sync.Mutex
+	goalReady chan struct{}
 }
 
+// Goal lease bounds; the long-poll wait stays under the HTTP write timeout
+const (
+	defaultGoalLease    = time.Minute
+	minGoalLease        = 5 * time.Second
+	maxGoalLease        = 10 * time.Minute
+	maxGoalWait         = 10 * time.Second
+	goalRecheckInterval = time.Second // Leases expire without a wake-up, so waiters also re-check
+)
+
 // NewServer creates a new HTTP server instance
 func NewServer(repo *adapter.SQLiteRepository, cmdRepo *adapter.CommandRepository, actionRepo *adapter.ActionRepository, goalRepo *adapter.GoalRepository, stateRepo *adapter.StateRepository) *Server {
 	s := &Server{
@@ -33,38 +98,133 @@ func NewServer(repo *adapter.SQLiteRepository, cmdRepo *adapter.CommandRepositor
 		goalRepo:   goalRepo,
 		stateRepo:  stateRepo,
 		mux:        http.NewServeMux(),
+		safety:     service.NewSafetyChecker(service.DefaultSafetyConfig()),
 	}
 
 	s.registerRoutes()
 	return s
 }
 
+// SetDataGovernance enables the right-to-forget endpoint
+func (s *Server) SetDataGovernance(retentionRepo *adapter.RetentionRepository, auditRepo *adapter.AuditRepository) {
+	s.retentionRepo = retentionRepo
+	s.auditRepo = auditRepo
+}
+
+// SetPortability enables the backup, export and import endpoints
+// Every request must carry "Authorization: Bearer <adminToken>"; an empty token keeps them disabled.
+func (s *Server) SetPortability(backupRepo *adapter.BackupRepository, backupDir string, adminToken string) {
+	s.backupRepo = backupRepo
+	s.backupDir = backupDir
+	s.adminToken = adminToken
+}
+
+// SetThrottle rate-limits /api/propose per caller, domain and intent
+func (s *Server) SetThrottle(throttle *service.Throttle) {
+	s.throttle = throttle
+}
+
+// SetSafety replaces the policy that decides which proposals are auto-approved
+func (s *Server) SetSafety(safety *service.SafetyChecker) {
+	s.safety = safety
+}
+
+// SetDecisionLog records proposal decisions so candidate policies can be replayed against them
+func (s *Server) SetDecisionLog(decisionLog *adapter.DecisionLogRepository) {
+	s.decisionLog = decisionLog
+}
+
+// SetKillSwitch enables /api/estop and refuses new work while the stop is engaged
+func (s *Server) SetKillSwitch(killSwitch *service.KillSwitch) {
+	s.killSwitch = killSwitch
+}
+
+// SetSecondFactor lets a one-time code or an approval client confirm approving high-risk proposals and batches
+func (s *Server) SetSecondFactor(secondFactor *service.SecondFactor) {
+	s.secondFactor = secondFactor
+}
+
+// SetGoalCanceller enables DELETE /api/goal/{id}
+func (s *Server) SetGoalCanceller(canceller *service.GoalCanceller) {
+	s.goalCanceller = canceller
+}
+
+// SetBatchRepository enables /api/batch and /api/batches
+func (s *Server) SetBatchRepository(batchRepo *adapter.BatchRepository) {
+	s.batchRepo = batchRepo
+}
+
+// SetMessageRepository enables /api/actions/{id}/messages
+func (s *Server) SetMessageRepository(messageRepo *adapter.MessageRepository) {
+	s.messageRepo = messageRepo
+}
+
+// SetIntentHistory feeds payloads edited at approval back into the reflex cache
+func (s *Server) SetIntentHistory(intentRepo *adapter.IntentHistoryRepository) {
+	s.intentRepo = intentRepo
+}
+
+// SetGrants lets proposals covered by a standing approval run without asking
+// focusedProcess names the process Sentinel reports in focus, the only one a grant's process scope is matched against.
+func (s *Server) SetGrants(grantRepo *adapter.GrantRepository, focusedProcess func() string) {
+	s.grantRepo = grantRepo
+	s.focusedProcess = focusedProcess
+}
+
+// SetFocus stamps artifacts posted to /api/artifacts with the process and window Sentinel reports in focus,
+// so forgetting an application or window title reaches them
+func (s *Server) SetFocus(focus func() (process, window string)) {
+	s.focus = focus
+}
+
+// SetTraceRepository enables /api/trace/{id}
+func (s *Server) SetTraceRepository(traceRepo *adapter.TraceRepository) {
+	s.traceRepo = traceRepo
+}
+
 // registerRoutes sets up all HTTP endpoints
 func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("/health", s.handleHealth)
-	s.mux.HandleFunc("/api/artifacts/", s.handleArtifactByID) // Handle both GET /api/artifacts and POST /api/artifacts/{id}/enrich
-	s.mux.HandleFunc("/api/search", s.handleSearch) // Semantic search endpoint
+	s.mux.HandleFunc("/api/artifacts", s.handleArtifactByID)           // GET to list, POST to capture one; without this the mux redirects to /api/artifacts/
+	s.mux.HandleFunc("/api/artifacts/", s.handleArtifactByID)          // POST /api/artifacts/{id}/enrich
+	s.mux.HandleFunc("/api/search", s.handleSearch)                    // Semantic search endpoint
 	s.mux.HandleFunc("/api/commands/pending", s.handlePendingCommands) // Command queue for Sentinel
-	s.mux.HandleFunc("/api/commands", s.handleCommands) // Create new commands
+	s.mux.HandleFunc("/api/commands", s.handleCommands)                // Create new commands
 	s.mux.HandleFunc("/api/stream", s.handleStream)
 
 	// Permission Kernel endpoints
-	s.mux.HandleFunc("/api/propose", s.handlePropose) // Cortex proposes actions
-	s.mux.HandleFunc("/api/approvals", s.handleApprovals) // UI polls for pending approvals
-	s.mux.HandleFunc("/api/approve/", s.handleApprove) // User approves/rejects actions
-	s.mux.HandleFunc("/api/reply/", s.handleReply) // User replies to clarification requests
-	s.mux.HandleFunc("/api/modes", s.handleUserModes) // Get/Set automation modes
+	s.mux.HandleFunc("/api/propose", s.handlePropose)                  // Cortex proposes actions
+	s.mux.HandleFunc("/api/approvals", s.handleApprovals)              // UI polls for pending approvals
+	s.mux.HandleFunc("/api/approve/", s.handleApprove)                 // User approves/rejects actions, optionally with an edited payload
+	s.mux.HandleFunc("/api/reply/", s.handleReply)                     // User replies to clarification requests; same as a user message on the thread
+	s.mux.HandleFunc("/api/modes", s.handleUserModes)                  // Get/Set automation modes
 	s.mux.HandleFunc("/api/actions/approved", s.handleApprovedActions) // Effector queue
-	s.mux.HandleFunc("/api/actions/", s.handleActionStatus) // Update action status
+	s.mux.HandleFunc("/api/actions/", s.handleActionStatus)            // Update action status, GET/POST .../messages for its clarification thread or GET .../revisions
+	s.mux.HandleFunc("/api/batch", s.handleProposeBatch)               // Cortex proposes a multi-step plan
+	s.mux.HandleFunc("/api/batch/", s.handleBatchByID)                 // GET a batch with its steps, POST .../approve to decide it
+	s.mux.HandleFunc("/api/batches", s.handleListBatches)              // GET batches, newest first, optionally ?status=WAITING_FOR_USER
 
 	// Agentic Planner endpoints
-	s.mux.HandleFunc("/api/goal", s.handleGoal) // POST to inject goal, GET to poll for active goal
+	s.mux.HandleFunc("/api/goal", s.handleGoal)       // POST to inject goal, GET to poll for active goal
+	s.mux.HandleFunc("/api/goal/", s.handleGoalByID)  // GET plan and progress, PUT .../plan, POST .../fail, .../heartbeat, .../pause, .../resume or .../cancel
+	s.mux.HandleFunc("/api/goals", s.handleListGoals) // GET goals, newest first, optionally ?status=SCHEDULED
 
 	// RAG endpoints (Omniscient Operator)
 	s.mux.HandleFunc("/api/search/vector", s.handleVectorSearch) // POST with vector, returns similar artifacts
 
 	// Consciousness Switch endpoints (Global State Manager)
-	s.mux.HandleFunc("/api/state", s.handleState) // GET current state, POST to update state
+	s.mux.HandleFunc("/api/state", s.handleState)         // GET current state, POST to update state
+	s.mux.HandleFunc("/api/estop", s.handleEmergencyStop) // GET latch, POST to stop everything now
+	s.mux.HandleFunc("/api/estop/rearm", s.handleRearm)   // POST with a reason to resume in SHADOW
+	s.mux.HandleFunc("/api/trace/", s.handleTrace)        // GET the lifecycle of one intent by trace ID
+
+	// Data governance endpoints
+	s.mux.HandleFunc("/api/forget", s.handleForget) // POST to delete everything captured within a scope
+
+	// Backup and portability endpoints (admin token)
+	s.mux.HandleFunc("/api/backup", s.handleBackup) // POST to snapshot the database into the backup directory
+	s.mux.HandleFunc("/api/export", s.handleExport) // GET JSON-lines export (?redact=true)
+	s.mux.HandleFunc("/api/import", s.handleImport) // POST JSON-lines export to merge
 }
 
 // handleHealth returns a simple health check response
@@ -80,13 +240,16 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleArtifactByID(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	
-	// GET /api/artifacts - list all artifacts
+	// GET /api/artifacts - list all artifacts; POST /api/artifacts - capture one
 	if path == "/api/artifacts" || path == "/api/artifacts/" {
-		if r.Method != http.MethodGet {
+		switch r.Method {
+		case http.MethodGet:
+			s.handleArtifactsList(w, r)
+		case http.MethodPost:
+			s.handleArtifactCapture(w, r)
+		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
 		}
-		s.handleArtifactsList(w, r)
 		return
 	}
 	
@@ -116,6 +279,46 @@ func (s *Server) handleArtifactsList(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CaptureRequest is an element or file captured by the Body
+type CaptureRequest struct {
+	Type        string             `json:"type"`
+	Content     string             `json:"content"`
+	BoundingBox domain.BoundingBox `json:"bounding_rectangle"`
+}
+
+// handleArtifactCapture handles POST /api/artifacts
+// The artifact is stamped with the process and window in focus as it is stored, never with what the
+// client claims, so a right-to-forget scope over an application or window title finds it.
+func (s *Server) handleArtifactCapture(w http.ResponseWriter, r *http.Request) {
+	var req CaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Content == "" {
+		http.Error(w, "content is required", http.StatusBadRequest)
+		return
+	}
+	artifactType := domain.ArtifactType(strings.ToLower(req.Type))
+	if artifactType == "" {
+		artifactType = domain.ArtifactTypeUnknown
+	}
+
+	artifact := domain.NewArtifact(artifactType, req.Content, req.BoundingBox)
+	if s.focus != nil {
+		artifact.SourceApp, artifact.WindowTitle = s.focus()
+	}
+	if err := s.repo.Save(r.Context(), artifact); err != nil {
+		log.Printf("[ERROR] Failed to save artifact: %v", err)
+		http.Error(w, "Failed to save artifact", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(artifact)
+}
+
 // EnrichmentRequest represents the payload for enriching an artifact
 type EnrichmentRequest struct {
 	Classification string   `json:"classification"`
@@ -252,6 +455,9 @@ func (s *Server) handlePendingCommands(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if s.halted() {
+		commands = []domain.Command{} // Nothing runs while the emergency stop is engaged
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -279,6 +485,7 @@ func (s *Server) createCommand(w http.ResponseWriter, r *http.Request) {
 		Action  string `json:"action"`
 		Target  string `json:"target"`
 		Payload string `json:"payload"`
+		TraceID string `json:"trace_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -303,13 +510,21 @@ func (s *Server) createCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.halted() {
+		s.writeHalted(w)
+		return
+	}
+
+	ctx := tracing.WithTraceID(r.Context(), req.TraceID)
 	cmd := domain.NewCommand(action, req.Target, req.Payload)
+	cmd.TraceID = tracing.TraceID(ctx)
 
 	if err := s.cmdRepo.SaveCommand(context.Background(), cmd); err != nil {
 		log.Printf("[ERROR] Failed to save command: %v", err)
 		http.Error(w, "Failed to create command", http.StatusInternalServerError)
 		return
 	}
+	tracing.Event(ctx, tracing.StageCommandEnqueued, attribute.String("command_id", cmd.ID), attribute.String("type", string(cmd.Action)))
 
 	log.Printf("[COMMAND] Created: %s | Action: %s | Payload: %s", cmd.ID[:8], cmd.Action, cmd.Payload)
 
@@ -351,6 +566,11 @@ func (s *Server) updateCommandStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("[COMMAND] Updated %s to %s", req.ID[:8], status)
+	metrics.BodyExecution("command", req.Status)
+	if cmd, err := s.cmdRepo.GetCommandByID(context.Background(), req.ID); err == nil {
+		ctx := tracing.WithTraceID(r.Context(), cmd.TraceID)
+		tracing.Event(ctx, tracing.StageCommandOutcome, attribute.String("command_id", req.ID), attribute.String("status", req.Status))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -371,6 +591,12 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
 	if f, ok := w.(http.Flusher); ok {
 		f.Flush()
 	}
+	metrics.EventDelivered("sse", "sent")
+}
+
+// Handler returns the router for all /api/* endpoints so it can be mounted on a shared server
+func (s *Server) Handler() http.Handler {
+	return s.mux
 }
 
 // Start launches the HTTP server on the specified address
@@ -399,6 +625,10 @@ type ProposeRequest struct {
 	RiskScore int             `json:"risk_score"`
 	Payload   json.RawMessage `json:"payload"`
 	Domain    string          `json:"domain"`
+	TraceID   string          `json:"trace_id"`  // Optional; defaults to the request's OpenTelemetry trace
+	GoalID    string          `json:"goal_id"`   // Optional; links the proposal to a step of this goal's plan
+	GoalStep  *int            `json:"goal_step"` // Zero-based position of that step, required with goal_id
+	DryRun    bool            `json:"dry_run"`   // Return the decision trace without saving or throttling anything
 }
 
 // handlePropose handles POST /api/propose - Cortex submits action proposals
@@ -426,8 +656,18 @@ func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create action proposal
-	action := domain.NewActionProposal(req.Intent, req.RiskScore, req.Payload, req.Domain)
+	if req.GoalID != "" && req.GoalStep == nil {
+		http.Error(w, "goal_step is required with goal_id", http.StatusBadRequest)
+		return
+	}
+
+	ctx := tracing.WithTraceID(r.Context(), req.TraceID)
+
+	// Back-pressure before the proposal reaches the approval inbox
+	client := r.RemoteAddr
+	if role, ok := auth.RoleFromContext(r.Context()); ok {
+		client = string(role)
+	}
 
 	// Get user mode for this domain
 	userMode, err := s.actionRepo.GetUserMode(context.Background(), req.Domain)
@@ -438,22 +678,71 @@ func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Apply Permission Kernel logic
-	if action.ShouldAutoApprove(userMode) {
+	trace, throttled := s.evaluateProposal(r.Context(), client, req.Domain, req.Intent, req.RiskScore, userMode, []json.RawMessage{req.Payload}, req.DryRun)
+	if req.DryRun {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(trace)
+		return
+	}
+
+	metrics.ProposalRisk(req.RiskScore)
+	switch trace.Decision {
+	case metrics.DecisionHalted:
+		recordDecision(ctx, metrics.DecisionHalted, metrics.RuleEmergencyStop)
+		s.writeHalted(w)
+		return
+	case metrics.DecisionThrottled:
+		log.Printf("[KERNEL] Proposal throttled: %v", throttled)
+		recordDecision(ctx, metrics.DecisionThrottled, metrics.RuleThrottle)
+		writeThrottled(w, throttled)
+		return
+	}
+
+	// A plan step is proposed once
+	if req.GoalID != "" {
+		available, err := s.goalRepo.StepAvailable(context.Background(), req.GoalID, *req.GoalStep)
+		if err != nil {
+			log.Printf("[PLANNER] Failed to look up plan step: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !available {
+			http.Error(w, "Plan step does not exist or was already proposed", http.StatusConflict)
+			return
+		}
+	}
+
+	// Create action proposal
+	action := domain.NewActionProposal(req.Intent, req.RiskScore, req.Payload, req.Domain)
+	action.TraceID = tracing.TraceID(ctx)
+
+	if trace.Decision == metrics.DecisionApproved {
 		// Auto-approve low-risk actions in AUTO mode
 		action.Status = domain.ActionProposalStatusExecuting
-		log.Printf("[KERNEL] ✓ AUTO-APPROVED: %s | Risk: %d | Domain: %s", action.Intent, action.RiskScore, action.Domain)
+		log.Printf("[KERNEL] ✓ AUTO-APPROVED: %s | Risk: %d | Domain: %s | Rule: %s", action.Intent, action.RiskScore, action.Domain, trace.Rule)
 	} else {
 		// Hold for user approval
 		action.Status = domain.ActionProposalStatusWaitingForUser
-		log.Printf("[KERNEL] ⏸ WAITING FOR USER: %s | Risk: %d | Mode: %s", action.Intent, action.RiskScore, userMode.Mode)
+		log.Printf("[KERNEL] ⏸ WAITING FOR USER: %s | Risk: %d | Mode: %s", action.Intent, action.RiskScore, trace.Mode)
 	}
 
 	// Save to database
 	if err := s.actionRepo.SaveActionProposal(context.Background(), action); err != nil {
 		log.Printf("[KERNEL] Failed to save action proposal: %v", err)
+		if s.throttle != nil {
+			s.throttle.RecordDenial(r.Context(), client, req.Domain)
+		}
 		http.Error(w, "Failed to save action", http.StatusInternalServerError)
 		return
 	}
+	recordDecision(ctx, trace.Decision, trace.Rule, attribute.String("action_id", action.ID), attribute.Int("risk_score", action.RiskScore))
+	s.logDecision(ctx, trace, req.Intent, req.Domain, req.RiskScore)
+	s.auditAutoApproval(ctx, trace, "action_id", action.ID, action.RiskScore)
+	if req.GoalID != "" {
+		if _, err := s.goalRepo.AttachAction(context.Background(), req.GoalID, *req.GoalStep, action); err != nil {
+			log.Printf("[PLANNER] Failed to link action %s to goal %s: %v", action.ID[:8], req.GoalID, err)
+		}
+	}
 
 	// Return response
 	w.Header().Set("Content-Type", "application/json")
@@ -461,6 +750,109 @@ func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(action)
 }
 
+// recordDecision counts a proposal decision and records it on the intent's trace
+func recordDecision(ctx context.Context, decision, rule string, attrs ...attribute.KeyValue) {
+	metrics.Permission(metrics.PathREST, decision, rule)
+	tracing.Decision(ctx, metrics.PathREST, decision, rule, attrs...)
+}
+
+// evaluateProposal applies the emergency stop, throttle, approval rules and standing grants to a proposal
+// A dry run only peeks at the throttle; otherwise a token is taken unless the stop already refused it.
+// payloads are every action the proposal would run, for the grants to cover.
+func (s *Server) evaluateProposal(ctx context.Context, client, domainName, intent string, riskScore int, userMode *domain.UserMode, payloads []json.RawMessage, dryRun bool) (*domain.DecisionTrace, error) {
+	trace := &domain.DecisionTrace{Path: metrics.PathREST, DryRun: dryRun}
+
+	halted := s.halted()
+	trace.Check(metrics.RuleEmergencyStop, !halted, metrics.DecisionHalted, reasonIf(halted, "Emergency stop engaged"))
+
+	var throttled error
+	if s.throttle != nil {
+		if dryRun {
+			throttled = s.throttle.Peek(client, domainName, intent)
+		} else if !trace.Decided() {
+			throttled = s.throttle.Allow(ctx, client, domainName, intent)
+		}
+		trace.Check(metrics.RuleThrottle, throttled == nil, metrics.DecisionThrottled, errorDetail(throttled))
+	}
+
+	s.safety.EvaluateProposal(trace, riskScore, userMode)
+	if trace.Decision == metrics.DecisionPending && s.grantRepo != nil {
+		grants, err := s.grantRepo.ListGrants(ctx, false, time.Now())
+		if err != nil {
+			// Without its grants the proposal still waits for the user
+			log.Printf("[KERNEL] Failed to load grants: %v", err)
+		}
+		process := ""
+		if s.focusedProcess != nil {
+			process = s.focusedProcess()
+		}
+		s.safety.EvaluateGrants(trace, grants, payloads, process, time.Now())
+	}
+	return trace, throttled
+}
+
+// logDecision records a proposal the approval rules decided, for replay against candidate policies
+func (s *Server) logDecision(ctx context.Context, trace *domain.DecisionTrace, intent, domainName string, riskScore int) {
+	if s.decisionLog == nil {
+		return
+	}
+	input := &service.ProposalInput{RiskScore: riskScore, Mode: domain.ModeType(trace.Mode), Grants: trace.Grants}
+	record, err := domain.NewDecisionRecord(trace, intent, domainName, tracing.TraceID(ctx), input)
+	if err == nil {
+		err = s.decisionLog.Record(ctx, record)
+	}
+	if err != nil {
+		log.Printf("[KERNEL] Failed to record proposal decision: %v", err)
+	}
+}
+
+// auditAutoApproval records that the kernel approved a proposal without asking, citing the rule and any grants
+func (s *Server) auditAutoApproval(ctx context.Context, trace *domain.DecisionTrace, idKey, id string, riskScore int) {
+	if s.auditRepo == nil || trace.Decision != metrics.DecisionApproved {
+		return
+	}
+	detail := map[string]interface{}{idKey: id, "rule": trace.Rule, "risk_score": riskScore}
+	if len(trace.Grants) > 0 {
+		detail["grants"] = trace.Grants
+	}
+	if _, err := s.auditRepo.Record(ctx, adapter.AuditEventAutoApproval, "kernel", detail); err != nil {
+		log.Printf("[KERNEL] Failed to audit %s: %v", adapter.AuditEventAutoApproval, err)
+	}
+}
+
+// reasonIf returns reason when a check failed
+func reasonIf(failed bool, reason string) string {
+	if failed {
+		return reason
+	}
+	return ""
+}
+
+// errorDetail returns an error's message, or nothing for a nil error
+func errorDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// writeThrottled answers 429 with a Retry-After header and the limit that was hit
+func writeThrottled(w http.ResponseWriter, err error) {
+	var throttled *service.ThrottleError
+	if !errors.As(err, &throttled) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	seconds := int(throttled.RetryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(throttled)
+}
+
 // handleApprovals handles GET /api/approvals - UI polls for pending approvals
 func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -481,8 +873,14 @@ func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
 }
 
 // ApprovalRequest represents user's approval/rejection decision
+// Payload, when set, approves the proposal with that payload instead of the proposed one.
+// Approving at service.HighRiskScore or above needs Code, or an approval client's confirmation.
 type ApprovalRequest struct {
-	Approved bool `json:"approved"`
+	Approved bool            `json:"approved"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+	Code     string          `json:"code,omitempty"` // One-time code from the user's authenticator
+	// ConfirmRisk accepts an edit that raises the proposal's risk to the auto-approve threshold or above
+	ConfirmRisk bool `json:"confirm_risk,omitempty"`
 }
 
 // handleApprove handles POST /api/approve/{id} - User approves or rejects
@@ -513,6 +911,11 @@ func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Approved && s.halted() {
+		s.writeHalted(w)
+		return
+	}
+
 	// Update status based on user decision
 	var newStatus domain.ActionProposalStatus
 	if req.Approved {
@@ -523,20 +926,172 @@ func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[KERNEL] ✗ USER REJECTED: %s", actionID[:8])
 	}
 
-	if err := s.actionRepo.UpdateActionStatus(context.Background(), actionID, newStatus); err != nil {
-		log.Printf("[KERNEL] Failed to update action status: %v", err)
-		http.Error(w, "Failed to update action", http.StatusInternalServerError)
+	// Looked up first so the approval latency covers only proposals that were waiting on a person
+	held, _ := s.actionRepo.GetActionByID(context.Background(), actionID)
+	if held != nil {
+		r = r.WithContext(tracing.WithTraceID(r.Context(), held.TraceID))
+	}
+	if held != nil && held.Status == domain.ActionProposalStatusBlocked {
+		http.Error(w, "Action is a step of a batch; decide the batch instead", http.StatusConflict)
 		return
 	}
 
+	// An edited payload is approved only if it passes the policy on its own
+	var rev *domain.ProposalRevision
+	if len(req.Payload) > 0 {
+		if !req.Approved {
+			http.Error(w, "An edited payload can only be approved", http.StatusBadRequest)
+			return
+		}
+		if held == nil {
+			http.Error(w, "Action not found", http.StatusNotFound)
+			return
+		}
+		var ok bool
+		if rev, ok = s.reviseAction(w, r, held, req.Payload, req.ConfirmRisk); !ok {
+			return
+		}
+	}
+
+	// A high-risk approval needs a second factor for exactly the payload approved
+	var approval *protocol.SignedApproval
+	if req.Approved {
+		if held == nil {
+			http.Error(w, "Action not found", http.StatusNotFound)
+			return
+		}
+		payload, riskScore := held.Payload, held.RiskScore
+		if rev != nil {
+			payload, riskScore = rev.Edited, service.RevisedRiskScore(held, rev.Edited)
+		}
+		var err error
+		if approval, err = s.secondFactor.ApproveProposal(held, payload, riskScore, req.Code); err != nil {
+			writeSecondFactorError(w, err)
+			return
+		}
+	}
+
+	detail := map[string]interface{}{"action_id": actionID}
+	if rev != nil {
+		if err := s.actionRepo.ApproveRevision(r.Context(), rev); err != nil {
+			s.secondFactor.Release(approval)
+			if errors.Is(err, adapter.ErrActionState) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			log.Printf("[KERNEL] Failed to approve edited action: %v", err)
+			http.Error(w, "Failed to update action", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("[KERNEL] ✎ USER APPROVED WITH %d CHANGES: %s", len(rev.Changes), actionID)
+
+		// The audit entry carries the whole revision, so compacting revisions does not lose what was
+		// approved; audit details are encrypted like the revision itself
+		detail["revision_id"], detail["original"], detail["edited"], detail["changes"] = rev.ID, rev.Original, rev.Edited, rev.Changes
+		s.learnCorrection(r.Context(), held, rev.Edited)
+	} else {
+		var err error
+		if req.Approved {
+			err = s.actionRepo.ApproveAction(r.Context(), actionID)
+		} else {
+			err = s.actionRepo.UpdateActionStatus(r.Context(), actionID, newStatus)
+		}
+		if err != nil {
+			s.secondFactor.Release(approval)
+		}
+		switch {
+		case errors.Is(err, adapter.ErrActionNotFound):
+			http.Error(w, "Action not found", http.StatusNotFound)
+			return
+		case errors.Is(err, adapter.ErrActionState):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			log.Printf("[KERNEL] Failed to update action status: %v", err)
+			http.Error(w, "Failed to update action", http.StatusInternalServerError)
+			return
+		}
+	}
+	if held != nil && held.Status == domain.ActionProposalStatusWaitingForUser {
+		metrics.ApprovalLatency(metrics.PathREST, req.Approved, time.Since(held.CreatedAt))
+	}
+	s.auditDecision(r, adapter.AuditEventApproval, service.ApprovalDetail(detail, req.Approved, approval))
+	tracing.Event(r.Context(), tracing.StageApproval, attribute.String("action_id", actionID), attribute.Bool("approved", req.Approved))
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "success",
-		"message": "Action decision recorded",
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"message":  "Action decision recorded",
+		"revision": rev,
 	})
 }
 
+// reviseAction diffs an edited payload against the proposal and holds it to the full policy
+// A refused edit is answered with 422 and the decision trace, and an edit raising the risk the user has
+// not confirmed with 409 and the raised score; an unchanged payload returns no revision.
+func (s *Server) reviseAction(w http.ResponseWriter, r *http.Request, action *domain.ActionProposal, edited json.RawMessage, riskConfirmed bool) (*domain.ProposalRevision, bool) {
+	rev, err := domain.NewProposalRevision(action, edited, callerActor(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if len(rev.Changes) == 0 {
+		return nil, true // Sent back untouched: a plain approval
+	}
+
+	userMode, err := s.actionRepo.GetUserMode(r.Context(), action.Domain)
+	if err != nil {
+		log.Printf("[KERNEL] Failed to get user mode: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	trace := &domain.DecisionTrace{Path: metrics.PathREST}
+	if err := s.safety.EvaluateEdit(trace, action, edited, userMode, riskConfirmed); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	recordDecision(r.Context(), trace.Decision, trace.Rule, attribute.String("action_id", action.ID))
+	if trace.Decision == metrics.DecisionPending {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     "confirm_risk",
+			"message":    trace.Reason,
+			"risk_score": service.RevisedRiskScore(action, edited),
+			"trace":      trace,
+		})
+		return nil, false
+	}
+	if trace.Decision != metrics.DecisionApproved {
+		log.Printf("[KERNEL] Edited payload for %s refused by %s: %s", action.ID, trace.Rule, trace.Reason)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "denied",
+			"message": trace.Reason,
+			"trace":   trace,
+		})
+		return nil, false
+	}
+	return rev, true
+}
+
+// learnCorrection replaces the reflex cached for the proposal's intent with the plan the user corrected
+func (s *Server) learnCorrection(ctx context.Context, action *domain.ActionProposal, edited json.RawMessage) {
+	if s.intentRepo == nil {
+		return
+	}
+	plan, ok := service.CorrectedPlan(edited)
+	if !ok {
+		return
+	}
+	if err := s.intentRepo.RecordCorrection(ctx, action.Intent, action.Domain, plan); err != nil {
+		log.Printf("[KERNEL] Failed to learn corrected plan: %v", err)
+	}
+}
+
 // ReplyRequest represents user's response to a clarification request
 type ReplyRequest struct {
 	Message string `json:"message"`
@@ -575,22 +1130,11 @@ func (s *Server) handleReply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Store user response
-	if err := s.actionRepo.UpdateUserResponse(context.Background(), actionID, req.Message); err != nil {
-		log.Printf("[GHOST_CHAT] Failed to update user response: %v", err)
-		http.Error(w, "Failed to save response", http.StatusInternalServerError)
-		return
-	}
-
-	// Update status to PENDING so agent can resume
-	if err := s.actionRepo.UpdateActionStatus(context.Background(), actionID, domain.ActionProposalStatusPending); err != nil {
-		log.Printf("[GHOST_CHAT] Failed to update status: %v", err)
-		http.Error(w, "Failed to update status", http.StatusInternalServerError)
+	// The reply joins the thread and hands the proposal back to the agent
+	if _, ok := s.appendMessage(w, r, actionID, domain.MessageRoleUser, MessageRequest{Text: req.Message}); !ok {
 		return
 	}
 
-	log.Printf("[GHOST_CHAT] 💬 User replied to %s: \"%s\"", actionID[:8], req.Message)
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -599,6 +1143,131 @@ func (s *Server) handleReply(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// MessageRequest is a turn in a proposal's clarification thread
+// Whether it is an agent or a user message follows from the caller's role; only the agent may ask a question.
+type MessageRequest struct {
+	Text     string           `json:"text"`
+	Question *domain.Question `json:"question,omitempty"`
+}
+
+// MessageResponse returns the stored message with the status it moved the proposal to
+type MessageResponse struct {
+	Message *domain.ProposalMessage     `json:"message"`
+	Status  domain.ActionProposalStatus `json:"status"`
+}
+
+// handleMessages handles GET/POST /api/actions/{id}/messages - The clarification thread of a proposal
+// The planner posts as the agent; a question sends the proposal to WAITING_FOR_CONTEXT and a plain
+// message back to WAITING_FOR_USER. People post as the user, which hands the proposal back as PENDING.
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request, actionID string) {
+	if s.messageRepo == nil {
+		http.Error(w, "Clarification threads are not configured", http.StatusNotFound)
+		return
+	}
+	if actionID == "" || strings.Contains(actionID, "/") {
+		http.Error(w, "Action ID is required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		messages, err := s.messageRepo.ListMessages(r.Context(), actionID)
+		if err != nil {
+			writeMessageError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(messages)
+	case http.MethodPost:
+		var role domain.MessageRole
+		switch caller, _ := auth.RoleFromContext(r.Context()); caller {
+		case auth.RoleBrain:
+			role = domain.MessageRoleAgent
+		case auth.RoleHuman, auth.RoleAdmin:
+			role = domain.MessageRoleUser
+		default:
+			http.Error(w, "Only the planner or the user can post to a thread", http.StatusForbidden)
+			return
+		}
+
+		var req MessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Printf("[GHOST_CHAT] Failed to decode message: %v", err)
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		resp, ok := s.appendMessage(w, r, actionID, role, req)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// appendMessage adds a message to the thread of actionID, writing the error response if that fails
+func (s *Server) appendMessage(w http.ResponseWriter, r *http.Request, actionID string, role domain.MessageRole, req MessageRequest) (*MessageResponse, bool) {
+	if s.messageRepo == nil {
+		http.Error(w, "Clarification threads are not configured", http.StatusNotFound)
+		return nil, false
+	}
+	msg, err := domain.NewProposalMessage(actionID, role, req.Text, req.Question)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	status, err := s.messageRepo.AppendMessage(r.Context(), msg)
+	if err != nil {
+		writeMessageError(w, err)
+		return nil, false
+	}
+
+	log.Printf("[GHOST_CHAT] 💬 %s message #%d on %s, now %s", role, msg.Seq, actionID, status)
+	return &MessageResponse{Message: msg, Status: status}, true
+}
+
+// handleRevisions handles GET /api/actions/{id}/revisions - Payloads edited at approval, with the original and a diff
+func (s *Server) handleRevisions(w http.ResponseWriter, r *http.Request, actionID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if actionID == "" || strings.Contains(actionID, "/") {
+		http.Error(w, "Action ID is required", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := s.actionRepo.ListRevisions(r.Context(), actionID)
+	if err != nil {
+		log.Printf("[KERNEL] Failed to list revisions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// writeMessageError maps thread repository errors onto HTTP statuses
+func writeMessageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, adapter.ErrActionNotFound):
+		http.Error(w, "Action not found", http.StatusNotFound)
+	case errors.Is(err, adapter.ErrConversationClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, adapter.ErrInvalidAnswer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[GHOST_CHAT] Thread request failed: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
 // handleUserModes handles GET/POST /api/modes - Manage automation modes
 func (s *Server) handleUserModes(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -667,6 +1336,7 @@ func (s *Server) setUserMode(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("[KERNEL] Mode changed: %s -> %s", req.Domain, mode)
+	s.auditDecision(r, adapter.AuditEventModeChange, map[string]string{"domain": req.Domain, "mode": string(mode)})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -676,6 +1346,25 @@ func (s *Server) setUserMode(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// auditDecision records an approval or mode change under the caller's role
+func (s *Server) auditDecision(r *http.Request, event string, detail interface{}) {
+	if s.auditRepo == nil {
+		return
+	}
+	if _, err := s.auditRepo.Record(r.Context(), event, callerActor(r), detail); err != nil {
+		log.Printf("[KERNEL] Failed to audit %s: %v", event, err)
+	}
+}
+
+// callerActor names the caller in the audit log, like "http:human"
+func callerActor(r *http.Request) string {
+	role, ok := auth.RoleFromContext(r.Context())
+	if !ok {
+		role = "unknown"
+	}
+	return "http:" + string(role)
+}
+
 // handleApprovedActions handles GET /api/actions/approved - Effector Queue
 // Returns all approved actions ready for execution by the Sentinel
 func (s *Server) handleApprovedActions(w http.ResponseWriter, r *http.Request) {
@@ -690,6 +1379,9 @@ func (s *Server) handleApprovedActions(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if s.halted() {
+		actions = []*domain.ActionProposal{} // Nothing runs while the emergency stop is engaged
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -702,12 +1394,23 @@ type ActionStatusRequest struct {
 }
 
 // handleActionStatus handles POST /api/actions/{id}/complete, /fail, or GET /api/actions/{id}
+// Status writes come from the Sentinel and apply only to APPROVED or EXECUTING proposals; anything else is 409.
 func (s *Server) handleActionStatus(w http.ResponseWriter, r *http.Request) {
 	// Skip if this is the /api/actions/approved route
 	if r.URL.Path == "/api/actions/approved" {
 		return
 	}
 
+	// /api/actions/{id}/messages is the proposal's clarification thread
+	if actionID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/actions/"), "/messages"); ok {
+		s.handleMessages(w, r, actionID)
+		return
+	}
+	if actionID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/actions/"), "/revisions"); ok {
+		s.handleRevisions(w, r, actionID)
+		return
+	}
+
 	// GET /api/actions/{id} - Return action status (for polling)
 	if r.Method == http.MethodGet {
 		s.handleActionLookup(w, r)
@@ -753,6 +1456,10 @@ func (s *Server) handleActionStatus(w http.ResponseWriter, r *http.Request) {
 		newStatus = domain.ActionProposalStatusFailed
 		log.Printf("[EFFECTOR] ✗ Action %s marked as FAILED", actionID[:8])
 	case "executing":
+		if s.halted() {
+			s.writeHalted(w)
+			return
+		}
 		newStatus = domain.ActionProposalStatusExecuting
 		log.Printf("[EFFECTOR] ⚡ Action %s marked as EXECUTING", actionID[:8])
 	default:
@@ -760,11 +1467,26 @@ func (s *Server) handleActionStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.actionRepo.UpdateActionStatus(context.Background(), actionID, newStatus); err != nil {
+	// Only work a person (or the policy) approved reaches the effector; a held proposal or a
+	// batch step its batch has not released cannot be moved along from here
+	err := s.actionRepo.UpdateActionStatusFrom(r.Context(), actionID, newStatus, domain.ActionProposalStatusApproved, domain.ActionProposalStatusExecuting)
+	switch {
+	case errors.Is(err, adapter.ErrActionNotFound):
+		http.Error(w, "Action not found", http.StatusNotFound)
+		return
+	case errors.Is(err, adapter.ErrActionState):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
 		log.Printf("[ERROR] Failed to update action status: %v", err)
 		http.Error(w, "Failed to update action status", http.StatusInternalServerError)
 		return
 	}
+	metrics.BodyExecution("action", strings.ToLower(string(newStatus)))
+	if action, err := s.actionRepo.GetActionByID(context.Background(), actionID); err == nil {
+		ctx := tracing.WithTraceID(r.Context(), action.TraceID)
+		tracing.Event(ctx, tracing.StageProposalStatus, attribute.String("action_id", actionID), attribute.String("status", string(newStatus)))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -775,10 +1497,24 @@ func (s *Server) handleActionStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 // GoalRequest represents a natural language goal from the user
+// Delay or run_at hold the goal until then; recurrence is a cron expression ("0 9 * * 1-5", "@daily")
+// that queues a fresh copy of the goal on every firing. Higher priorities are planned first,
+// and a goal not yet planned by its deadline fails.
 type GoalRequest struct {
-	Goal string `json:"goal"`
+	Goal       string     `json:"goal"`
+	Priority   int        `json:"priority,omitempty"`
+	Deadline   *time.Time `json:"deadline,omitempty"`
+	Delay      string     `json:"delay,omitempty"` // e.g. "20m"
+	RunAt      *time.Time `json:"run_at,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
 }
 
+// maxGoalList caps GET /api/goals
+const maxGoalList = 500
+
+// defaultGoalList is how many goals GET /api/goals returns without ?limit
+const defaultGoalList = 50
+
 // handleGoal handles POST /api/goal (inject goal) and GET /api/goal (poll for active goal)
 func (s *Server) handleGoal(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -807,29 +1543,167 @@ func (s *Server) handleInjectGoal(w http.ResponseWriter, r *http.Request) {
 
 	// Create new goal
 	goal := domain.NewGoal(req.Goal)
-
-	// Save to database
+	goal.Priority = req.Priority
+	if req.Deadline != nil {
+		// Stored in local time like every other timestamp so SQLite compares them correctly
+		deadline := req.Deadline.Local()
+		goal.Deadline = &deadline
+	}
+	if err := scheduleGoal(goal, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Save to database
 	if err := s.goalRepo.SaveGoal(context.Background(), goal); err != nil {
 		log.Printf("[PLANNER] Failed to save goal: %v", err)
 		http.Error(w, "Failed to save goal", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("[PLANNER] 🎯 Goal injected: %s | ID: %s", goal.GoalText, goal.ID[:8])
+	if goal.Status == domain.GoalStatusScheduled {
+		log.Printf("[PLANNER] ⏰ Goal scheduled for %s: %s | ID: %s", goal.RunAt.Format(time.RFC3339), goal.GoalText, goal.ID[:8])
+	} else {
+		log.Printf("[PLANNER] 🎯 Goal injected: %s | ID: %s", goal.GoalText, goal.ID[:8])
+		s.notifyGoal()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(goal)
 }
 
+// scheduleGoal applies the request's delay, run time or recurrence, leaving the goal SCHEDULED
+// when it should not be planned yet
+func scheduleGoal(goal *domain.Goal, req GoalRequest) error {
+	now := time.Now()
+	if req.Delay != "" && req.RunAt != nil {
+		return errors.New("delay and run_at cannot both be set")
+	}
+	if req.Recurrence != "" && req.Deadline != nil {
+		return errors.New("a recurring goal cannot have a deadline")
+	}
+
+	runAt := req.RunAt
+	if req.Delay != "" {
+		delay, err := time.ParseDuration(req.Delay)
+		if err != nil || delay < 0 {
+			return fmt.Errorf("invalid delay %q", req.Delay)
+		}
+		at := now.Add(delay)
+		runAt = &at
+	}
+
+	if req.Recurrence != "" {
+		// run_at or delay, if given, is when the recurrence starts counting from
+		from := now
+		if runAt != nil {
+			from = *runAt
+		}
+		next, err := domain.NextRun(req.Recurrence, from.Add(-time.Second))
+		if err != nil {
+			return err
+		}
+		goal.Recurrence = req.Recurrence
+		runAt = &next
+	}
+
+	if runAt == nil {
+		return nil
+	}
+	if goal.Deadline != nil && goal.Deadline.Before(*runAt) {
+		return errors.New("deadline is before the goal's run time")
+	}
+	local := runAt.Local()
+	goal.RunAt = &local
+	if goal.Recurrence != "" || local.After(now) {
+		goal.Status = domain.GoalStatusScheduled
+	}
+	return nil
+}
+
+// handleListGoals handles GET /api/goals?status=&limit= - newest goals first
+func (s *Server) handleListGoals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := domain.GoalStatus(strings.ToUpper(r.URL.Query().Get("status")))
+	limit := defaultGoalList
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxGoalList)
+	}
+
+	goals, err := s.goalRepo.ListGoals(r.Context(), status, limit)
+	if err != nil {
+		writeGoalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(goals)
+}
+
 // handlePollGoal handles GET /api/goal - Python polls for active goals
+// ?worker=ID claims the goal under a lease (?lease=60s) instead of only peeking at it;
+// ?wait=10s long-polls until a goal is available.
 func (s *Server) handlePollGoal(w http.ResponseWriter, r *http.Request) {
-	goal, err := s.goalRepo.GetActiveGoal(context.Background())
+	query := r.URL.Query()
+	worker := query.Get("worker")
+	lease, err := goalLease(query.Get("lease"))
+	if err != nil {
+		http.Error(w, "Invalid lease duration", http.StatusBadRequest)
+		return
+	}
+	var wait time.Duration
+	if raw := query.Get("wait"); raw != "" {
+		if wait, err = time.ParseDuration(raw); err != nil || wait < 0 {
+			http.Error(w, "Invalid wait duration", http.StatusBadRequest)
+			return
+		}
+		wait = min(wait, maxGoalWait)
+	}
+
+	next := func() (*domain.Goal, error) {
+		if worker == "" {
+			return s.goalRepo.GetActiveGoal(r.Context())
+		}
+		return s.goalRepo.ClaimGoal(r.Context(), worker, lease)
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	var goal *domain.Goal
+	for {
+		// Taken before looking, so a goal injected in between still wakes us
+		ready := s.goalWakeup()
+		if goal, err = next(); err != nil || goal != nil || wait == 0 {
+			break
+		}
+		select {
+		case <-ready:
+		case <-time.After(goalRecheckInterval):
+		case <-deadline.C:
+			wait = 0
+		case <-r.Context().Done():
+			return
+		}
+	}
 	if err != nil {
 		log.Printf("[PLANNER] Failed to fetch active goal: %v", err)
 		http.Error(w, "Failed to fetch goal", http.StatusInternalServerError)
 		return
 	}
+	if goal != nil && worker != "" {
+		log.Printf("[PLANNER] 🔒 Goal %s claimed by %s until %s", goal.ID[:8], worker, goal.LeaseExpiresAt.Format(time.RFC3339))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -842,6 +1716,559 @@ func (s *Server) handlePollGoal(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// goalWakeup returns a channel closed the next time a goal is injected
+func (s *Server) goalWakeup() <-chan struct{} {
+	s.goalMu.Lock()
+	defer s.goalMu.Unlock()
+	if s.goalReady == nil {
+		s.goalReady = make(chan struct{})
+	}
+	return s.goalReady
+}
+
+// notifyGoal wakes every planner long-polling GET /api/goal
+func (s *Server) notifyGoal() {
+	s.goalMu.Lock()
+	defer s.goalMu.Unlock()
+	if s.goalReady != nil {
+		close(s.goalReady)
+		s.goalReady = nil
+	}
+}
+
+// GoalQueued wakes planners long-polling GET /api/goal when a goal is queued outside a request,
+// such as by the goal scheduler
+func (s *Server) GoalQueued(goal domain.Goal) {
+	s.notifyGoal()
+}
+
+// goalLease parses a requested lease, defaulting and clamping it to the allowed range
+func goalLease(raw string) (time.Duration, error) {
+	if raw == "" {
+		return defaultGoalLease, nil
+	}
+	lease, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lease duration %q: %w", raw, err)
+	}
+	return min(max(lease, minGoalLease), maxGoalLease), nil
+}
+
+// PlanRequest is the ordered plan the planner generated for a goal
+type PlanRequest struct {
+	Steps []PlanStepRequest `json:"steps"`
+}
+
+// PlanStepRequest is a single step of a submitted plan
+type PlanStepRequest struct {
+	Intent string `json:"intent"`
+}
+
+// handleGoalByID handles GET /api/goal/{id} (plan tree, progress and failure point),
+// PUT /api/goal/{id}/plan (planner submits its steps), POST /api/goal/{id}/fail (planning failed)
+// POST /api/goal/{id}/heartbeat (planner extends its lease), POST /api/goal/{id}/pause or /resume
+// (the user holds or releases the goal) and DELETE /api/goal/{id} or POST /api/goal/{id}/cancel
+// (withdraw the goal and its work). Planner writes name the planner with ?worker=ID.
+func (s *Server) handleGoalByID(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/goal/"), "/")
+	goalID := parts[0]
+	if goalID == "" || len(parts) > 2 {
+		http.Error(w, "Invalid path format. Use /api/goal/{id}, /api/goal/{id}/plan, /fail, /heartbeat, /pause, /resume or /cancel", http.StatusBadRequest)
+		return
+	}
+
+	var sub string
+	if len(parts) == 2 {
+		sub = parts[1]
+	}
+
+	switch {
+	case sub == "" && r.Method == http.MethodGet:
+		s.handleGoalPlan(w, r, goalID)
+	case sub == "plan" && r.Method == http.MethodPut:
+		s.handleSubmitPlan(w, r, goalID)
+	case sub == "fail" && r.Method == http.MethodPost:
+		s.handleFailGoal(w, r, goalID)
+	case sub == "heartbeat" && r.Method == http.MethodPost:
+		s.handleGoalHeartbeat(w, r, goalID)
+	case sub == "pause" && r.Method == http.MethodPost:
+		goal, err := s.goalRepo.PauseGoal(r.Context(), goalID)
+		s.changeGoal(w, goalID, "paused", goal, err)
+	case sub == "resume" && r.Method == http.MethodPost:
+		goal, err := s.goalRepo.ResumeGoal(r.Context(), goalID)
+		if err == nil && goal.Status == domain.GoalStatusActive {
+			s.notifyGoal()
+		}
+		s.changeGoal(w, goalID, "resumed", goal, err)
+	case (sub == "" && r.Method == http.MethodDelete) || (sub == "cancel" && r.Method == http.MethodPost):
+		s.handleCancelGoal(w, r, goalID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleGoalPlan returns the goal with its steps, each step's proposal status, progress and failed step
+func (s *Server) handleGoalPlan(w http.ResponseWriter, r *http.Request, goalID string) {
+	plan, err := s.goalRepo.GetPlan(r.Context(), goalID)
+	if err != nil {
+		writeGoalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// handleSubmitPlan stores the planner's ordered steps and moves the goal out of the poll queue
+func (s *Server) handleSubmitPlan(w http.ResponseWriter, r *http.Request, goalID string) {
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PLANNER] Failed to decode plan request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Steps) == 0 {
+		http.Error(w, "A plan needs at least one step", http.StatusBadRequest)
+		return
+	}
+	intents := make([]string, len(req.Steps))
+	for i, step := range req.Steps {
+		if step.Intent == "" {
+			http.Error(w, fmt.Sprintf("Step %d has no intent", i), http.StatusBadRequest)
+			return
+		}
+		intents[i] = step.Intent
+	}
+
+	plan, err := s.goalRepo.SavePlan(r.Context(), goalID, r.URL.Query().Get("worker"), intents)
+	if err != nil {
+		writeGoalError(w, err)
+		return
+	}
+
+	log.Printf("[PLANNER] 📋 Plan saved for goal %s: %d steps", goalID, plan.Total)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// handleFailGoal marks a goal FAILED when the planner cannot produce a plan for it
+func (s *Server) handleFailGoal(w http.ResponseWriter, r *http.Request, goalID string) {
+	goal, err := s.goalRepo.FailGoal(r.Context(), goalID, r.URL.Query().Get("worker"))
+	if err != nil {
+		writeGoalError(w, err)
+		return
+	}
+
+	log.Printf("[PLANNER] ✗ Goal %s marked %s", goalID, goal.Status)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(goal)
+}
+
+// handleGoalHeartbeat extends the calling planner's lease (?worker=ID&lease=60s)
+// 409 means the lease lapsed and the planner must stop working on the goal.
+func (s *Server) handleGoalHeartbeat(w http.ResponseWriter, r *http.Request, goalID string) {
+	worker := r.URL.Query().Get("worker")
+	if worker == "" {
+		http.Error(w, "worker is required", http.StatusBadRequest)
+		return
+	}
+	lease, err := goalLease(r.URL.Query().Get("lease"))
+	if err != nil {
+		http.Error(w, "Invalid lease duration", http.StatusBadRequest)
+		return
+	}
+
+	goal, err := s.goalRepo.Heartbeat(r.Context(), goalID, worker, lease)
+	if err != nil {
+		writeGoalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(goal)
+}
+
+// handleCancelGoal cancels a goal with its proposals and commands and tells the Body to abort it
+// An optional ?reason= is recorded in the audit trail.
+func (s *Server) handleCancelGoal(w http.ResponseWriter, r *http.Request, goalID string) {
+	if s.goalCanceller == nil {
+		http.Error(w, "Goal cancellation is not configured", http.StatusNotFound)
+		return
+	}
+
+	cancellation, err := s.goalCanceller.Cancel(r.Context(), callerActor(r), goalID, r.URL.Query().Get("reason"))
+	if err != nil {
+		writeGoalError(w, err)
+		return
+	}
+
+	log.Printf("[PLANNER] ✗ Goal %s cancelled by %s: %d rejected, %d cancelled, %d commands",
+		goalID, callerActor(r), cancellation.RejectedActions, cancellation.CancelledActions, cancellation.CancelledCommands)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(cancellation)
+}
+
+// changeGoal answers a pause or resume with the goal's new state
+func (s *Server) changeGoal(w http.ResponseWriter, goalID, verb string, goal *domain.Goal, err error) {
+	if err != nil {
+		writeGoalError(w, err)
+		return
+	}
+
+	log.Printf("[PLANNER] Goal %s %s, now %s", goalID, verb, goal.Status)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(goal)
+}
+
+// writeGoalError maps goal repository errors onto HTTP statuses
+func writeGoalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, adapter.ErrGoalNotFound):
+		http.Error(w, "Goal not found", http.StatusNotFound)
+	case errors.Is(err, adapter.ErrPlanStarted), errors.Is(err, adapter.ErrStepNotAvailable),
+		errors.Is(err, adapter.ErrLeaseHeld), errors.Is(err, adapter.ErrLeaseLost), errors.Is(err, adapter.ErrGoalState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("[PLANNER] Goal request failed: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// ========================================
+// ACTION BATCHES
+// ========================================
+
+// maxBatchSteps caps the steps of one batch
+const maxBatchSteps = 50
+
+// BatchActionRequest is one proposed action of a batch; its domain defaults to the batch's
+// A step in a MANUAL domain holds the whole batch for the user.
+type BatchActionRequest struct {
+	Intent    string          `json:"intent"`
+	RiskScore int             `json:"risk_score"`
+	Payload   json.RawMessage `json:"payload"`
+	Domain    string          `json:"domain"`
+}
+
+// BatchStepRequest is a step with the steps it waits for and an optional undo action
+type BatchStepRequest struct {
+	BatchActionRequest
+	DependsOn    []int               `json:"depends_on"`   // Zero-based positions of earlier steps; omitted means the previous step
+	Compensation *BatchActionRequest `json:"compensation"` // Runs only if a later step fails
+}
+
+// BatchRequest represents a multi-step plan proposed for one approval
+type BatchRequest struct {
+	Intent  string             `json:"intent"`
+	Domain  string             `json:"domain"`
+	TraceID string             `json:"trace_id"` // Optional; defaults to the request's OpenTelemetry trace
+	Steps   []BatchStepRequest `json:"steps"`
+}
+
+// proposal validates one action of a batch and creates its proposal
+func (a *BatchActionRequest) proposal(batchDomain string) (*domain.ActionProposal, error) {
+	if a.Intent == "" {
+		return nil, fmt.Errorf("intent is required")
+	}
+	if a.RiskScore < 0 || a.RiskScore > 100 {
+		return nil, fmt.Errorf("risk score must be between 0 and 100")
+	}
+	if a.Domain == "" {
+		a.Domain = batchDomain
+	}
+	return domain.NewActionProposal(a.Intent, a.RiskScore, a.Payload, a.Domain), nil
+}
+
+// batchMode returns the mode a batch is held to: the first MANUAL mode among the domains of the batch,
+// its steps and their compensations, otherwise the batch domain's own mode
+func (s *Server) batchMode(ctx context.Context, batch *domain.ActionBatch) (*domain.UserMode, error) {
+	names := []string{batch.Domain}
+	for _, step := range batch.Steps {
+		names = append(names, step.Action.Domain)
+		if step.Compensation != nil {
+			names = append(names, step.Compensation.Domain)
+		}
+	}
+
+	var batchMode *domain.UserMode
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		mode, err := s.actionRepo.GetUserMode(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if mode.Mode == domain.ModeTypeManual {
+			return mode, nil
+		}
+		if batchMode == nil {
+			batchMode = mode
+		}
+	}
+	return batchMode, nil
+}
+
+// handleProposeBatch handles POST /api/batch - Cortex submits a multi-step plan
+// The whole batch is approved once, on its aggregate risk; steps run in dependency order,
+// the batch stops at the first failure and completed steps are compensated latest first.
+func (s *Server) handleProposeBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.batchRepo == nil {
+		http.Error(w, "Action batches are not configured", http.StatusNotFound)
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[KERNEL] Failed to decode batch request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Intent == "" {
+		http.Error(w, "Intent is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Steps) == 0 || len(req.Steps) > maxBatchSteps {
+		http.Error(w, fmt.Sprintf("A batch needs between 1 and %d steps", maxBatchSteps), http.StatusBadRequest)
+		return
+	}
+
+	steps := make([]domain.BatchStep, len(req.Steps))
+	for i := range req.Steps {
+		step := &req.Steps[i]
+		action, err := step.proposal(req.Domain)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Step %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		steps[i] = domain.BatchStep{Action: action, DependsOn: step.DependsOn}
+		if step.Compensation != nil {
+			if steps[i].Compensation, err = step.Compensation.proposal(action.Domain); err != nil {
+				http.Error(w, fmt.Sprintf("Step %d compensation: %v", i, err), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	batch, err := domain.NewActionBatch(req.Intent, req.Domain, steps)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := tracing.WithTraceID(r.Context(), req.TraceID)
+	batch.TraceID = tracing.TraceID(ctx)
+
+	client := r.RemoteAddr
+	if role, ok := auth.RoleFromContext(r.Context()); ok {
+		client = string(role)
+	}
+	userMode, err := s.batchMode(r.Context(), batch)
+	if err != nil {
+		log.Printf("[KERNEL] Failed to get user mode: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// The batch is approved as a whole on its aggregate risk, or if grants cover every step and compensation
+	var payloads []json.RawMessage
+	for _, step := range batch.Steps {
+		payloads = append(payloads, step.Action.Payload)
+		if step.Compensation != nil {
+			payloads = append(payloads, step.Compensation.Payload)
+		}
+	}
+	trace, throttled := s.evaluateProposal(r.Context(), client, req.Domain, req.Intent, batch.RiskScore, userMode, payloads, false)
+	metrics.ProposalRisk(batch.RiskScore)
+	switch trace.Decision {
+	case metrics.DecisionHalted:
+		recordDecision(ctx, metrics.DecisionHalted, metrics.RuleEmergencyStop)
+		s.writeHalted(w)
+		return
+	case metrics.DecisionThrottled:
+		log.Printf("[KERNEL] Batch throttled: %v", throttled)
+		recordDecision(ctx, metrics.DecisionThrottled, metrics.RuleThrottle)
+		writeThrottled(w, throttled)
+		return
+	case metrics.DecisionApproved:
+		batch.Decide(true)
+		log.Printf("[KERNEL] ✓ AUTO-APPROVED BATCH: %s | %d steps | Risk: %d | Domain: %s", batch.Intent, len(batch.Steps), batch.RiskScore, batch.Domain)
+	default:
+		log.Printf("[KERNEL] ⏸ BATCH WAITING FOR USER: %s | %d steps | Risk: %d (max step %d)", batch.Intent, len(batch.Steps), batch.RiskScore, batch.MaxStepRisk)
+	}
+
+	if err := s.batchRepo.SaveBatch(context.Background(), batch); err != nil {
+		log.Printf("[KERNEL] Failed to save action batch: %v", err)
+		if s.throttle != nil {
+			s.throttle.RecordDenial(r.Context(), client, req.Domain)
+		}
+		http.Error(w, "Failed to save batch", http.StatusInternalServerError)
+		return
+	}
+	recordDecision(ctx, trace.Decision, trace.Rule, attribute.String("batch_id", batch.ID), attribute.Int("risk_score", batch.RiskScore))
+	s.logDecision(ctx, trace, req.Intent, req.Domain, batch.RiskScore)
+	s.auditAutoApproval(ctx, trace, "batch_id", batch.ID, batch.RiskScore)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(batch)
+}
+
+// handleListBatches handles GET /api/batches?status=&limit=
+func (s *Server) handleListBatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.batchRepo == nil {
+		http.Error(w, "Action batches are not configured", http.StatusNotFound)
+		return
+	}
+
+	status := domain.ActionBatchStatus(strings.ToUpper(r.URL.Query().Get("status")))
+	limit := defaultGoalList
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxGoalList)
+	}
+
+	batches, err := s.batchRepo.ListBatches(r.Context(), status, limit)
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(batches)
+}
+
+// handleBatchByID handles GET /api/batch/{id} and POST /api/batch/{id}/approve
+func (s *Server) handleBatchByID(w http.ResponseWriter, r *http.Request) {
+	if s.batchRepo == nil {
+		http.Error(w, "Action batches are not configured", http.StatusNotFound)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/batch/"), "/")
+	batchID := parts[0]
+	if batchID == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "approve") {
+		http.Error(w, "Invalid path format. Use /api/batch/{id} or /api/batch/{id}/approve", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		batch, err := s.batchRepo.GetBatch(r.Context(), batchID)
+		if err != nil {
+			writeBatchError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(batch)
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		s.handleApproveBatch(w, r, batchID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleApproveBatch records the user's one decision for every step of a batch
+func (s *Server) handleApproveBatch(w http.ResponseWriter, r *http.Request, batchID string) {
+	var req ApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[KERNEL] Failed to decode batch approval: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Approved && s.halted() {
+		s.writeHalted(w)
+		return
+	}
+
+	// A high-risk batch needs a second factor for every step and compensation it would run
+	var approval *protocol.SignedApproval
+	if req.Approved {
+		batch, err := s.batchRepo.GetBatch(r.Context(), batchID)
+		if err != nil {
+			writeBatchError(w, err)
+			return
+		}
+		if approval, err = s.secondFactor.ApproveBatch(batch, req.Code); err != nil {
+			writeSecondFactorError(w, err)
+			return
+		}
+	}
+
+	batch, err := s.batchRepo.DecideBatch(r.Context(), batchID, req.Approved)
+	if err != nil {
+		s.secondFactor.Release(approval)
+		writeBatchError(w, err)
+		return
+	}
+	if req.Approved {
+		log.Printf("[KERNEL] ✓ USER APPROVED BATCH: %s | %d steps | Risk: %d", batchID, len(batch.Steps), batch.RiskScore)
+	} else {
+		log.Printf("[KERNEL] ✗ USER REJECTED BATCH: %s", batchID)
+	}
+
+	r = r.WithContext(tracing.WithTraceID(r.Context(), batch.TraceID))
+	metrics.ApprovalLatency(metrics.PathREST, req.Approved, time.Since(batch.CreatedAt))
+	s.auditDecision(r, adapter.AuditEventBatchApproval, service.ApprovalDetail(map[string]interface{}{"batch_id": batchID, "risk_score": batch.RiskScore}, req.Approved, approval))
+	tracing.Event(r.Context(), tracing.StageApproval, attribute.String("batch_id", batchID), attribute.Bool("approved", req.Approved))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(batch)
+}
+
+// writeSecondFactorError refuses an approval with 403, naming the request and digest an approval client confirms
+func writeSecondFactorError(w http.ResponseWriter, err error) {
+	body := map[string]interface{}{"status": "approval_required", "message": err.Error()}
+	var refused *service.SecondFactorError
+	if errors.As(err, &refused) {
+		body["message"], body["request_id"], body["digest"] = refused.Err.Error(), refused.RequestID, refused.Digest
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeBatchError maps batch repository errors onto HTTP statuses
+func writeBatchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, adapter.ErrBatchNotFound):
+		http.Error(w, "Batch not found", http.StatusNotFound)
+	case errors.Is(err, adapter.ErrBatchState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("[KERNEL] Batch request failed: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
 // ========================================
 // RAG ENDPOINTS (OMNISCIENT OPERATOR)
 // ========================================
@@ -955,10 +2382,13 @@ func (s *Server) handleGetState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	estop := s.stateRepo.EmergencyStop()
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"state": string(state),
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"state":          string(state),
+		"emergency_stop": estop,
 	})
 }
 
@@ -979,7 +2409,10 @@ func (s *Server) handleSetState(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update state
-	if err := s.stateRepo.SetState(context.Background(), newState); err != nil {
+	if err := s.stateRepo.SetState(context.Background(), newState); errors.Is(err, adapter.ErrEmergencyStop) {
+		http.Error(w, "Emergency stop engaged: re-arm via /api/estop/rearm first", http.StatusConflict)
+		return
+	} else if err != nil {
 		log.Printf("[STATE] Failed to set state: %v", err)
 		http.Error(w, "Failed to update state", http.StatusInternalServerError)
 		return
@@ -1006,3 +2439,344 @@ func (s *Server) handleSetState(w http.ResponseWriter, r *http.Request) {
 		"state":   string(newState),
 	})
 }
+
+// ========================================
+// EMERGENCY STOP ENDPOINTS
+// ========================================
+
+// StopRequest carries why the emergency stop was pulled or released
+type StopRequest struct {
+	Reason string `json:"reason"`
+}
+
+// handleEmergencyStop handles GET /api/estop (latch) and POST /api/estop (stop everything now)
+// Any authenticated caller may stop; re-arming is reserved for people
+func (s *Server) handleEmergencyStop(w http.ResponseWriter, r *http.Request) {
+	if s.killSwitch == nil {
+		http.Error(w, "Emergency stop is not configured", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(s.killSwitch.Status())
+		return
+	case http.MethodPost:
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// A stop is never refused for a malformed body
+	var req StopRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	result, err := s.killSwitch.Halt(r.Context(), callerActor(r), req.Reason)
+	if err != nil {
+		log.Printf("[STATE] 🛑 Emergency stop engaged but not persisted: %v", err)
+		http.Error(w, "Emergency stop engaged in memory but not persisted", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[STATE] 🛑 EMERGENCY STOP by %s: %s", callerActor(r), result.Reason)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleRearm handles POST /api/estop/rearm - release the stop with a reason and resume in SHADOW
+func (s *Server) handleRearm(w http.ResponseWriter, r *http.Request) {
+	if s.killSwitch == nil {
+		http.Error(w, "Emergency stop is not configured", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req StopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.killSwitch.Rearm(r.Context(), callerActor(r), req.Reason)
+	switch {
+	case errors.Is(err, service.ErrRearmReason):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, adapter.ErrNotStopped):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("[STATE] Failed to re-arm: %v", err)
+		http.Error(w, "Failed to re-arm", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("[STATE] 🟡 Re-armed by %s: %s", callerActor(r), req.Reason)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// halted reports whether the emergency stop is engaged
+func (s *Server) halted() bool {
+	return s.killSwitch != nil && s.killSwitch.Engaged()
+}
+
+// writeHalted answers 409 with the latch so callers can tell a stop from a failure
+func (s *Server) writeHalted(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":          "emergency stop engaged",
+		"emergency_stop": s.killSwitch.Status(),
+	})
+}
+
+// ========================================
+// TRACE ENDPOINTS
+// ========================================
+
+// TraceResponse is the lifecycle of one intent, from proposal to the Body's outcome
+type TraceResponse struct {
+	TraceID   string                   `json:"trace_id"`
+	Proposals []*domain.ActionProposal `json:"proposals"`
+	Commands  []domain.Command         `json:"commands"`
+	Audit     []adapter.AuditRecord    `json:"audit"`
+	Events    []adapter.TraceEvent     `json:"events"`
+}
+
+// handleTrace handles GET /api/trace/{id} - Reconstruct an intent's lifecycle
+func (s *Server) handleTrace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.traceRepo == nil {
+		http.Error(w, "Tracing is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	traceID := strings.TrimPrefix(r.URL.Path, "/api/trace/")
+	if traceID == "" || strings.Contains(traceID, "/") {
+		http.Error(w, "Trace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	resp := TraceResponse{
+		TraceID:   traceID,
+		Proposals: []*domain.ActionProposal{},
+		Commands:  []domain.Command{},
+		Audit:     []adapter.AuditRecord{},
+		Events:    []adapter.TraceEvent{},
+	}
+
+	var err error
+	if proposals, e := s.actionRepo.GetActionsByTraceID(ctx, traceID); e != nil {
+		err = e
+	} else if len(proposals) > 0 {
+		resp.Proposals = proposals
+	}
+	if commands, e := s.cmdRepo.GetCommandsByTraceID(ctx, traceID); e != nil {
+		err = e
+	} else if len(commands) > 0 {
+		resp.Commands = commands
+	}
+	if s.auditRepo != nil {
+		if audit, e := s.auditRepo.GetByTraceID(ctx, traceID); e != nil {
+			err = e
+		} else if len(audit) > 0 {
+			resp.Audit = audit
+		}
+	}
+	if events, e := s.traceRepo.Events(ctx, traceID); e != nil {
+		err = e
+	} else if len(events) > 0 {
+		resp.Events = events
+	}
+	if err != nil {
+		log.Printf("[TRACE] Failed to load trace %s: %v", traceID, err)
+		http.Error(w, "Failed to load trace", http.StatusInternalServerError)
+		return
+	}
+
+	if len(resp.Proposals)+len(resp.Commands)+len(resp.Audit)+len(resp.Events) == 0 {
+		http.Error(w, "Trace not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ========================================
+// DATA GOVERNANCE ENDPOINTS
+// ========================================
+
+// handleForget handles POST /api/forget - Right-to-forget deletion
+// Deletes all captured data matching the scope; Forget checkpoints and VACUUMs so it is physically gone
+func (s *Server) handleForget(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.retentionRepo == nil || s.auditRepo == nil {
+		http.Error(w, "Forget is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var scope adapter.ForgetScope
+	if err := json.NewDecoder(r.Body).Decode(&scope); err != nil {
+		log.Printf("[PRIVACY] Failed to decode forget request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := scope.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.retentionRepo.Forget(r.Context(), scope)
+	if err != nil {
+		log.Printf("[PRIVACY] Forget failed: %v", err)
+		http.Error(w, "Failed to forget data", http.StatusInternalServerError)
+		return
+	}
+
+	// Audit the scope and counts only - never the deleted content
+	if _, err := s.auditRepo.Record(r.Context(), adapter.AuditEventForget, "http", result); err != nil {
+		log.Printf("[PRIVACY] Failed to audit forget request: %v", err)
+	}
+
+	var total int64
+	for _, n := range result.Deleted {
+		total += n
+	}
+	log.Printf("[PRIVACY] 🧹 Forgot %d rows (app=%q window=%q)", total, scope.Application, scope.WindowPattern)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ========================================
+// BACKUP & PORTABILITY ENDPOINTS
+// ========================================
+
+// authorizeAdmin checks the bearer token guarding backup, export and import
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.backupRepo == nil || s.auditRepo == nil || s.adminToken == "" {
+		http.Error(w, "Backup is not configured", http.StatusServiceUnavailable)
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		log.Printf("[BACKUP] Rejected unauthenticated %s %s", r.Method, r.URL.Path)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// handleBackup handles POST /api/backup - Online snapshot via VACUUM INTO
+// The file is always written inside the configured backup directory
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	if err := os.MkdirAll(s.backupDir, 0700); err != nil {
+		log.Printf("[BACKUP] Failed to create backup directory: %v", err)
+		http.Error(w, "Failed to create backup", http.StatusInternalServerError)
+		return
+	}
+
+	// Sub-second time and a random suffix, so backups taken together never collide
+	name := fmt.Sprintf("kernel-%s-%s.db", time.Now().Format("20060102-150405.000000"), uuid.New().String()[:8])
+	dest := filepath.Join(s.backupDir, name)
+	if err := s.backupRepo.Backup(r.Context(), dest); err != nil {
+		log.Printf("[BACKUP] Backup failed: %v", err)
+		http.Error(w, "Failed to create backup", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := s.auditRepo.Record(r.Context(), adapter.AuditEventBackup, "http", map[string]string{"destination": dest}); err != nil {
+		log.Printf("[BACKUP] Failed to audit backup: %v", err)
+	}
+
+	log.Printf("[BACKUP] 💾 Snapshot written to %s", dest)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"path": dest})
+}
+
+// handleExport handles GET /api/export - Streams the portable JSON-lines export
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	opts := adapter.ExportOptions{Redact: r.URL.Query().Get("redact") == "true"}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="ghost-export.jsonl"`)
+
+	counts, err := s.backupRepo.Export(r.Context(), w, opts)
+	if err != nil {
+		// Headers are already sent; the truncated body is the only signal left
+		log.Printf("[BACKUP] Export failed: %v", err)
+		return
+	}
+
+	if _, err := s.auditRepo.Record(r.Context(), adapter.AuditEventExport, "http", map[string]interface{}{"counts": counts, "redacted": opts.Redact}); err != nil {
+		log.Printf("[BACKUP] Failed to audit export: %v", err)
+	}
+}
+
+// handleImport handles POST /api/import - Merges a JSON-lines export in one transaction
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	counts, err := s.backupRepo.Import(r.Context(), r.Body)
+	if err != nil {
+		log.Printf("[BACKUP] Import failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.auditRepo.Record(r.Context(), adapter.AuditEventImport, "http", map[string]interface{}{"counts": counts}); err != nil {
+		log.Printf("[BACKUP] Failed to audit import: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"imported": counts})
+}
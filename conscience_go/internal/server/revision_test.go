@@ -0,0 +1,72 @@
+// Author: Enkae (enkae.dev@pm.me)
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+)
+
+func TestEditedApprovalAuditsTheRevision(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestServer(t)
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := adapter.KeySource{KeyFile: filepath.Join(t.TempDir(), "db.key")}
+	if err := adapter.GenerateKeyFile(key.KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := adapter.OpenFieldCipher(ctx, store, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo.SetCipher(cipher)
+	s.SetDataGovernance(adapter.NewRetentionRepository(store), auditRepo)
+
+	action := domain.NewActionProposal("write note", 20, json.RawMessage(`{"actions":[{"type":"TYPE","payload":{"text":"dear landlord"}}]}`), "editor")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := s.actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+	body := `{"approved": true, "payload": {"actions":[{"type":"TYPE","payload":{"text":"dear tenant"}}]}}`
+	if rec := serve(s, http.MethodPost, "/api/approve/"+action.ID, body); rec.Code != http.StatusOK {
+		t.Fatalf("approve edited: status %d: %s", rec.Code, rec.Body)
+	}
+
+	// The entry alone shows what was proposed, what was approved and what changed
+	records, err := auditRepo.GetRecent(ctx, 1)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("GetRecent() = %v, %v", records, err)
+	}
+	var detail struct {
+		RevisionID string                 `json:"revision_id"`
+		Original   json.RawMessage        `json:"original"`
+		Edited     json.RawMessage        `json:"edited"`
+		Changes    []domain.PayloadChange `json:"changes"`
+	}
+	if err := json.Unmarshal(records[0].Detail, &detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.RevisionID == "" || !strings.Contains(string(detail.Original), "dear landlord") || !strings.Contains(string(detail.Edited), "dear tenant") {
+		t.Errorf("audit detail = %s, want the original and edited payloads", records[0].Detail)
+	}
+	if len(detail.Changes) != 1 || detail.Changes[0].Path != "/actions/0/payload/text" || string(detail.Changes[0].Before) != `"dear landlord"` {
+		t.Errorf("audit changes = %+v, want the text change with its old value", detail.Changes)
+	}
+
+	var stored string
+	if err := store.QueryRowContext(ctx, "SELECT detail FROM audit_log WHERE id = ?", records[0].ID).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored, "dear") {
+		t.Errorf("stored audit detail %q holds the payload in plaintext", stored)
+	}
+}
@@ -0,0 +1,84 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"ghost/kernel/internal/adapter"
+)
+
+// ActionTypeAbort is the command sent down StreamActions when a goal is cancelled.
+// Its payload lists the goal's traces; the Body drops their buffered commands and stops
+// the one it is running.
+const ActionTypeAbort = "ABORT"
+
+// GoalCanceller cancels a goal together with the work it spawned, on every path at once.
+type GoalCanceller struct {
+	goals     *adapter.GoalRepository
+	auditRepo *adapter.AuditRepository
+
+	mu        sync.Mutex
+	listeners []func(cancellation *adapter.GoalCancellation, reason string)
+}
+
+// NewGoalCanceller creates a goal canceller; auditRepo may be nil.
+func NewGoalCanceller(goals *adapter.GoalRepository, auditRepo *adapter.AuditRepository) *GoalCanceller {
+	return &GoalCanceller{goals: goals, auditRepo: auditRepo}
+}
+
+// OnCancel registers fn to run after each goal is cancelled.
+// Listeners withdraw work the database does not hold, such as commands queued for the Body.
+func (c *GoalCanceller) OnCancel(fn func(cancellation *adapter.GoalCancellation, reason string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Cancel marks the goal CANCELLED, rejects its waiting proposals, cancels its approved and
+// executing ones and their commands, tells listeners to abort, and audits the cancellation.
+func (c *GoalCanceller) Cancel(ctx context.Context, actor, goalID, reason string) (*adapter.GoalCancellation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "cancelled by " + actor
+	}
+
+	cancellation, err := c.goals.CancelGoal(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	listeners := append([]func(*adapter.GoalCancellation, string){}, c.listeners...)
+	c.mu.Unlock()
+	for _, fn := range listeners {
+		fn(cancellation, reason)
+	}
+
+	slog.Warn("Goal cancelled", "goal_id", goalID, "actor", actor, "reason", reason,
+		"rejected_actions", cancellation.RejectedActions, "cancelled_actions", cancellation.CancelledActions,
+		"cancelled_commands", cancellation.CancelledCommands, "executing", len(cancellation.ExecutingActions))
+
+	if c.auditRepo != nil {
+		detail := map[string]interface{}{
+			"goal_id":            goalID,
+			"reason":             reason,
+			"rejected_actions":   cancellation.RejectedActions,
+			"cancelled_actions":  cancellation.CancelledActions,
+			"cancelled_commands": cancellation.CancelledCommands,
+			"executing_actions":  cancellation.ExecutingActions,
+		}
+		if _, err := c.auditRepo.Record(ctx, adapter.AuditEventGoalCancel, actor, detail); err != nil {
+			slog.Error("Failed to audit goal cancellation", "goal_id", goalID, "actor", actor, "error", err)
+		}
+	}
+
+	return cancellation, nil
+}
+
+// aborts reports whether the Body is told to abort for this cancellation.
+func aborts(cancellation *adapter.GoalCancellation) bool {
+	return len(cancellation.TraceIDs) > 0
+}
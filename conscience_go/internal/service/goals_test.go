@@ -0,0 +1,154 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/auth"
+	"ghost/kernel/internal/domain"
+	pb "ghost/kernel/internal/protocol"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCancelGoalWithdrawsItsWork(t *testing.T) {
+	ctx := context.Background()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	actionRepo, err := adapter.NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goalRepo, err := adapter.NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmdRepo, err := adapter.NewCommandRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateRepo, err := adapter.NewStateRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewGhostService(actionRepo, nil, nil, stateRepo)
+	canceller := NewGoalCanceller(goalRepo, auditRepo)
+	canceller.OnCancel(svc.RevokeGoal)
+	svc.GoalCanceller = canceller
+
+	goal := domain.NewGoal("order groceries")
+	if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := goalRepo.SavePlan(ctx, goal.ID, "", []string{"open shop", "fill basket", "pay"}); err != nil {
+		t.Fatal(err)
+	}
+	propose := func(position int, status domain.ActionProposalStatus, traceID string) string {
+		t.Helper()
+		action := domain.NewActionProposal("step", 20, json.RawMessage(`{}`), "PLANNER")
+		action.Status = status
+		action.TraceID = traceID
+		if err := actionRepo.SaveActionProposal(ctx, action); err != nil {
+			t.Fatal(err)
+		}
+		if position >= 0 {
+			if _, err := goalRepo.AttachAction(ctx, goal.ID, position, action); err != nil {
+				t.Fatalf("AttachAction(%d) error = %v", position, err)
+			}
+		}
+		return action.ID
+	}
+	executing := propose(0, domain.ActionProposalStatusExecuting, "trace-shop")
+	waiting := propose(1, domain.ActionProposalStatusWaitingForUser, "trace-basket")
+	unrelated := propose(-1, domain.ActionProposalStatusWaitingForUser, "trace-other")
+
+	cmd := domain.NewCommand(domain.CommandActionType, "browser", "milk")
+	cmd.TraceID = "trace-shop"
+	if err := cmdRepo.SaveCommand(ctx, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	streamCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	stream := &fakeActionStream{ctx: streamCtx, sent: make(chan *pb.ActionCommand, 4)}
+	go svc.StreamActions(nil, stream)
+	svc.actionChan <- &pb.ActionCommand{CommandId: "trace-warmup-0", TraceId: "trace-warmup", Action: &pb.Action{Type: "TYPE"}}
+	if sent := <-stream.sent; sent.CommandId != "trace-warmup-0" {
+		t.Fatalf("sent %q, want trace-warmup-0", sent.CommandId)
+	}
+
+	result, err := svc.CancelGoal(auth.WithRole(ctx, auth.RoleHuman), &pb.CancelGoalRequest{GoalId: goal.ID, Reason: "bought them myself"})
+	if err != nil {
+		t.Fatalf("CancelGoal() error = %v", err)
+	}
+	if result.Status != "CANCELLED" || result.RejectedActions != 1 || result.CancelledActions != 1 || result.CancelledCommands != 1 || !result.Aborted {
+		t.Errorf("CancelGoal() = %+v, want CANCELLED with 1 rejected, 1 cancelled, 1 command and an abort", result)
+	}
+
+	for id, want := range map[string]domain.ActionProposalStatus{
+		executing: domain.ActionProposalStatusCancelled,
+		waiting:   domain.ActionProposalStatusRejected,
+		unrelated: domain.ActionProposalStatusWaitingForUser,
+	} {
+		action, err := actionRepo.GetActionByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if action.Status != want {
+			t.Errorf("action %s status = %s, want %s", id[:8], action.Status, want)
+		}
+	}
+
+	// The Body is told to abort the goal's traces, and its commands still queued never reach it
+	select {
+	case sent := <-stream.sent:
+		if sent.Action.GetType() != ActionTypeAbort || sent.Action.Payload["goal_id"] != goal.ID || sent.Action.Payload["trace_ids"] == "" {
+			t.Errorf("sent %+v, want an ABORT for the goal", sent)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Body was not sent an ABORT command")
+	}
+	svc.actionChan <- &pb.ActionCommand{CommandId: "trace-shop-1", TraceId: "trace-shop", Action: &pb.Action{Type: "TYPE"}}
+	svc.actionChan <- &pb.ActionCommand{CommandId: "trace-other-0", TraceId: "trace-other", Action: &pb.Action{Type: "TYPE"}}
+	if sent := <-stream.sent; sent.CommandId != "trace-other-0" {
+		t.Errorf("sent %q, want the cancelled goal's command dropped", sent.CommandId)
+	}
+
+	// Cancelling is final: its withdrawn proposals cannot be approved back into the effector queue
+	for _, id := range []string{waiting, executing} {
+		if _, err := svc.ApproveAction(auth.WithRole(ctx, auth.RoleHuman), &pb.ApprovalDecision{ActionId: id, Approved: true}); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("ApproveAction() after cancelling error = %v, want FailedPrecondition", err)
+		}
+	}
+	if queue, _ := actionRepo.GetApprovedActions(ctx); len(queue) != 0 {
+		t.Errorf("effector queue has %d actions after cancelling", len(queue))
+	}
+	if _, err := svc.CancelGoal(ctx, &pb.CancelGoalRequest{GoalId: goal.ID}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("CancelGoal() twice error = %v, want FailedPrecondition", err)
+	}
+	if _, err := svc.CancelGoal(ctx, &pb.CancelGoalRequest{GoalId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("CancelGoal() on a missing goal error = %v, want NotFound", err)
+	}
+
+	records, err := auditRepo.GetRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Event != adapter.AuditEventGoalCancel || records[0].Actor != "grpc:human" {
+		t.Errorf("audit log = %+v, want one %s by grpc:human", records, adapter.AuditEventGoalCancel)
+	}
+}
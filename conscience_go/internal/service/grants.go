@@ -0,0 +1,82 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
+	pb "ghost/kernel/internal/protocol"
+)
+
+// EvaluateGrants lets a proposal the approval rules held for the user run if standing grants cover it.
+// Every action in payloads must pass the action rules and fall within an active grant. A grant's
+// process is matched against the process Sentinel reports in focus, where the Body will run the action;
+// a "process" field in the payload is the Brain's claim and is ignored. Nothing is recorded when there
+// are no grants to check.
+func (s *SafetyChecker) EvaluateGrants(trace *domain.DecisionTrace, grants []domain.Grant, payloads []json.RawMessage, focusedProcess string, now time.Time) {
+	if trace.Decision != metrics.DecisionPending || len(grants) == 0 {
+		return
+	}
+
+	ids, why := s.coveringGrants(grants, payloads, focusedProcess, now)
+	if ids == nil {
+		trace.Check(metrics.RuleGrant, false, metrics.DecisionPending, why)
+		return
+	}
+	approveByGrants(trace, ids)
+}
+
+// coveringGrants returns the grants that cover every action in payloads, or why they do not.
+func (s *SafetyChecker) coveringGrants(grants []domain.Grant, payloads []json.RawMessage, focusedProcess string, now time.Time) ([]string, string) {
+	var actions []*pb.Action
+	for _, payload := range payloads {
+		decoded, err := PayloadActions(payload)
+		if err != nil {
+			return nil, "payload actions are unreadable: " + err.Error()
+		}
+		actions = append(actions, decoded...)
+	}
+	if len(actions) == 0 {
+		return nil, "proposal has no actions for a grant to cover"
+	}
+
+	var ids []string
+	seen := map[string]bool{}
+	for i, action := range actions {
+		if ok, why := s.ValidateAction(action); !ok {
+			return nil, fmt.Sprintf("action %d: %s", i, why)
+		}
+		target := action.Payload["path"]
+		if target == "" {
+			target = action.Payload["directory"]
+		}
+
+		covered := false
+		for _, grant := range grants {
+			if grant.Covers(action.Type, focusedProcess, target, now) {
+				covered = true
+				if !seen[grant.ID] {
+					seen[grant.ID] = true
+					ids = append(ids, grant.ID)
+				}
+				break
+			}
+		}
+		if !covered {
+			return nil, fmt.Sprintf("no grant covers action %d (%s)", i, strings.ToUpper(action.Type))
+		}
+	}
+	return ids, ""
+}
+
+// approveByGrants approves a proposal held for the user because the grants ids cover it.
+func approveByGrants(trace *domain.DecisionTrace, ids []string) {
+	reason := "covered by grant " + strings.Join(ids, ", ")
+	trace.Check(metrics.RuleGrant, true, metrics.DecisionPending, reason)
+	trace.Decision, trace.Rule, trace.Reason = metrics.DecisionApproved, metrics.RuleGrant, reason
+	trace.Grants = ids
+}
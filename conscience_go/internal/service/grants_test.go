@@ -0,0 +1,74 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
+)
+
+func TestEvaluateGrants(t *testing.T) {
+	checker := NewSafetyChecker(DefaultSafetyConfig())
+	now := time.Now()
+	expiry := now.Add(30 * time.Minute)
+
+	typing, err := domain.NewGrant("TYPE", "code.exe", "", &expiry, "grpc:human")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reading, err := domain.NewGrant("READ", "", "docs", nil, "grpc:human")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants := []domain.Grant{*typing, *reading}
+
+	tests := []struct {
+		name     string
+		payloads []string
+		focused  string
+		at       time.Time
+		want     string
+		grants   int
+	}{
+		{"typing in the focused process", []string{`{"type": "TYPE", "text": "hi"}`}, "Code.exe", now, metrics.DecisionApproved, 1},
+		{"typing elsewhere", []string{`{"type": "TYPE", "text": "hi"}`}, "slack.exe", now, metrics.DecisionPending, 0},
+		{"action claiming the granted process", []string{`{"type": "TYPE", "payload": {"text": "hi", "process": "code.exe"}}`}, "slack.exe", now, metrics.DecisionPending, 0},
+		{"action claiming another process", []string{`{"type": "TYPE", "process": "slack.exe"}`}, "code.exe", now, metrics.DecisionApproved, 1},
+		{"after expiry", []string{`{"type": "TYPE"}`}, "code.exe", now.Add(time.Hour), metrics.DecisionPending, 0},
+		{"plan under the prefix", []string{`{"actions": [{"type": "READ", "path": "docs/a.md"}, {"type": "TYPE"}]}`}, "code.exe", now, metrics.DecisionApproved, 2},
+		{"sibling of the prefix", []string{`{"type": "READ", "path": "docs-old/a.md"}`}, "", now, metrics.DecisionPending, 0},
+		{"traversal out of the prefix", []string{`{"type": "READ", "path": "docs/../secrets.txt"}`}, "", now, metrics.DecisionPending, 0},
+		{"one step not covered", []string{`{"type": "READ", "path": "docs/a.md"}`, `{"type": "WRITE", "path": "docs/a.md"}`}, "", now, metrics.DecisionPending, 0},
+		{"no actions", []string{`{"url": "https://example.com"}`}, "code.exe", now, metrics.DecisionPending, 0},
+	}
+	for _, tt := range tests {
+		trace := &domain.DecisionTrace{}
+		trace.Check(metrics.RuleManualMode, false, metrics.DecisionPending, "domain is in MANUAL mode")
+		var payloads []json.RawMessage
+		for _, payload := range tt.payloads {
+			payloads = append(payloads, json.RawMessage(payload))
+		}
+
+		checker.EvaluateGrants(trace, grants, payloads, tt.focused, tt.at)
+		if trace.Decision != tt.want || len(trace.Grants) != tt.grants {
+			t.Errorf("%s: decision %s with grants %v, want %s with %d", tt.name, trace.Decision, trace.Grants, tt.want, tt.grants)
+		}
+		if last := trace.Checks[len(trace.Checks)-1]; last.Rule != metrics.RuleGrant {
+			t.Errorf("%s: last check is %s, want %s", tt.name, last.Rule, metrics.RuleGrant)
+		}
+		if tt.want == metrics.DecisionApproved && trace.Rule != metrics.RuleGrant {
+			t.Errorf("%s: decided by %s, want %s", tt.name, trace.Rule, metrics.RuleGrant)
+		}
+	}
+
+	// A denied proposal stays denied
+	trace := &domain.DecisionTrace{}
+	trace.Check(metrics.RuleBlockedKeyword, false, metrics.DecisionDenied, "blocked")
+	checker.EvaluateGrants(trace, grants, []json.RawMessage{json.RawMessage(`{"type": "TYPE"}`)}, "code.exe", now)
+	if trace.Decision != metrics.DecisionDenied || len(trace.Checks) != 1 {
+		t.Errorf("denied proposal = %s after %d checks, want denied without a grant check", trace.Decision, len(trace.Checks))
+	}
+}
@@ -0,0 +1,131 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+	pb "ghost/kernel/internal/protocol"
+)
+
+// ActionTypeStop is the command sent down StreamActions when an emergency stop is engaged.
+// The Body aborts whatever input it is performing and drops anything it has buffered.
+const ActionTypeStop = "STOP"
+
+// ErrRearmReason is returned when re-arming without saying why it is safe to continue.
+var ErrRearmReason = errors.New("a reason is required to re-arm the emergency stop")
+
+// KillSwitch halts agency on every path at once and keeps it halted until a person re-arms it.
+// The latch lives in the state repository, so a restart does not release it.
+type KillSwitch struct {
+	stateRepo *adapter.StateRepository
+	auditRepo *adapter.AuditRepository
+
+	mu        sync.Mutex
+	listeners []func(reason string)
+}
+
+// NewKillSwitch creates a kill switch; auditRepo may be nil.
+func NewKillSwitch(stateRepo *adapter.StateRepository, auditRepo *adapter.AuditRepository) *KillSwitch {
+	return &KillSwitch{stateRepo: stateRepo, auditRepo: auditRepo}
+}
+
+// OnHalt registers fn to run on every emergency stop, after the latch is set.
+// Listeners abort work the database does not hold, such as queued stream commands and open connections.
+func (k *KillSwitch) OnHalt(fn func(reason string)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.listeners = append(k.listeners, fn)
+}
+
+// Engaged reports whether new work must be refused.
+func (k *KillSwitch) Engaged() bool {
+	return k.stateRepo.EmergencyStop().Engaged
+}
+
+// Status returns the latch, including who engaged it and why.
+func (k *KillSwitch) Status() adapter.EmergencyStop {
+	return k.stateRepo.EmergencyStop()
+}
+
+// Halt switches to PAUSED, cancels every queued and approved-but-unexecuted action and tells
+// listeners to abort. It is never refused: stopping again while stopped cancels anything that
+// slipped in since.
+func (k *KillSwitch) Halt(ctx context.Context, actor, reason string) (*pb.StopResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "no reason given"
+	}
+
+	cancelled, err := k.stateRepo.Halt(ctx, actor, reason)
+
+	// Listeners run even if the write failed; the latch is already set in memory
+	k.mu.Lock()
+	listeners := append([]func(string){}, k.listeners...)
+	k.mu.Unlock()
+	for _, fn := range listeners {
+		fn(reason)
+	}
+
+	if err != nil {
+		slog.Error("EMERGENCY STOP engaged but not persisted", "actor", actor, "reason", reason, "error", err)
+		return nil, err
+	}
+	slog.Warn("EMERGENCY STOP engaged", "actor", actor, "reason", reason,
+		"cancelled_actions", cancelled.CancelledActions, "cancelled_commands", cancelled.CancelledCommands)
+
+	k.audit(ctx, adapter.AuditEventEmergencyStop, actor, map[string]interface{}{
+		"reason":             reason,
+		"cancelled_actions":  cancelled.CancelledActions,
+		"cancelled_commands": cancelled.CancelledCommands,
+	})
+
+	return &pb.StopResult{
+		Engaged:           true,
+		State:             string(domain.AppStatePaused),
+		Reason:            reason,
+		CancelledActions:  int32(cancelled.CancelledActions),
+		CancelledCommands: int32(cancelled.CancelledCommands),
+	}, nil
+}
+
+// Rearm releases the stop and resumes in SHADOW; reason is required and audited.
+func (k *KillSwitch) Rearm(ctx context.Context, actor, reason string) (*pb.StopResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRearmReason
+	}
+
+	previous := k.stateRepo.EmergencyStop()
+	if err := k.stateRepo.Rearm(ctx); err != nil {
+		return nil, err
+	}
+	slog.Warn("Emergency stop re-armed", "actor", actor, "reason", reason)
+
+	k.audit(ctx, adapter.AuditEventRearm, actor, map[string]interface{}{
+		"reason":      reason,
+		"stopped_by":  previous.Actor,
+		"stop_reason": previous.Reason,
+	})
+
+	return &pb.StopResult{
+		Engaged: false,
+		State:   string(domain.AppStateShadow),
+		Reason:  reason,
+	}, nil
+}
+
+// audit records a kill switch change; a failed write is logged rather than undoing it.
+func (k *KillSwitch) audit(ctx context.Context, event, actor string, detail interface{}) {
+	if k.auditRepo == nil {
+		return
+	}
+	if _, err := k.auditRepo.Record(ctx, event, actor, detail); err != nil {
+		slog.Error("Failed to audit emergency stop", "event", event, "actor", actor, "error", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/auth"
+	"ghost/kernel/internal/domain"
+	pb "ghost/kernel/internal/protocol"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// killSwitchFixture is a service wired to a kill switch on a real database
+type killSwitchFixture struct {
+	store      *adapter.Store
+	actionRepo *adapter.ActionRepository
+	cmdRepo    *adapter.CommandRepository
+	stateRepo  *adapter.StateRepository
+	auditRepo  *adapter.AuditRepository
+	service    *GhostService
+	killSwitch *KillSwitch
+}
+
+func newKillSwitchFixture(t *testing.T) *killSwitchFixture {
+	t.Helper()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	f := &killSwitchFixture{store: store}
+	if f.actionRepo, err = adapter.NewActionRepository(store); err != nil {
+		t.Fatal(err)
+	}
+	if f.cmdRepo, err = adapter.NewCommandRepository(store); err != nil {
+		t.Fatal(err)
+	}
+	if f.stateRepo, err = adapter.NewStateRepository(store); err != nil {
+		t.Fatal(err)
+	}
+	if f.auditRepo, err = adapter.NewAuditRepository(store); err != nil {
+		t.Fatal(err)
+	}
+
+	f.service = NewGhostService(f.actionRepo, nil, nil, f.stateRepo)
+	f.killSwitch = NewKillSwitch(f.stateRepo, f.auditRepo)
+	f.killSwitch.OnHalt(f.service.AbortActions)
+	f.service.KillSwitch = f.killSwitch
+	return f
+}
+
+// propose saves a proposal with the given status and returns its ID
+func (f *killSwitchFixture) propose(t *testing.T, status domain.ActionProposalStatus) string {
+	t.Helper()
+	action := domain.NewActionProposal("open editor", 10, json.RawMessage(`{}`), "editor")
+	action.Status = status
+	if err := f.actionRepo.SaveActionProposal(context.Background(), action); err != nil {
+		t.Fatalf("SaveActionProposal() error = %v", err)
+	}
+	return action.ID
+}
+
+func TestEmergencyStopCancelsQueuedWork(t *testing.T) {
+	ctx := context.Background()
+	f := newKillSwitchFixture(t)
+	if err := f.stateRepo.SetState(ctx, domain.AppStateActive); err != nil {
+		t.Fatal(err)
+	}
+
+	waiting := f.propose(t, domain.ActionProposalStatusWaitingForUser)
+	approved := f.propose(t, domain.ActionProposalStatusApproved)
+	executing := f.propose(t, domain.ActionProposalStatusExecuting)
+	completed := f.propose(t, domain.ActionProposalStatusCompleted)
+	cmd := domain.NewCommand(domain.CommandActionType, "editor", "hello")
+	if err := f.cmdRepo.SaveCommand(ctx, cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := f.service.EmergencyStop(auth.WithRole(ctx, auth.RoleBody), &pb.StopRequest{Reason: "runaway typing"})
+	if err != nil {
+		t.Fatalf("EmergencyStop() error = %v", err)
+	}
+	if !result.Engaged || result.State != "PAUSED" || result.CancelledActions != 3 || result.CancelledCommands != 1 {
+		t.Errorf("EmergencyStop() = %+v, want PAUSED with 3 actions and 1 command cancelled", result)
+	}
+
+	for id, want := range map[string]domain.ActionProposalStatus{
+		waiting:   domain.ActionProposalStatusCancelled,
+		approved:  domain.ActionProposalStatusCancelled,
+		executing: domain.ActionProposalStatusCancelled,
+		completed: domain.ActionProposalStatusCompleted,
+	} {
+		action, err := f.actionRepo.GetActionByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if action.Status != want {
+			t.Errorf("action %s status = %s, want %s", id[:8], action.Status, want)
+		}
+	}
+	if queue, _ := f.actionRepo.GetApprovedActions(ctx); len(queue) != 0 {
+		t.Errorf("effector queue has %d actions after stop", len(queue))
+	}
+	if pending, _ := f.cmdRepo.GetPendingCommands(ctx); len(pending) != 0 {
+		t.Errorf("%d commands still pending after stop", len(pending))
+	}
+
+	// New work is refused until re-armed
+	resp, err := f.service.RequestPermission(ctx, &pb.PermissionRequest{Intent: "open editor", Actions: []*pb.Action{{Type: "CLICK"}}})
+	if err != nil || resp.Approved {
+		t.Errorf("RequestPermission() while stopped = %+v, %v; want refused", resp, err)
+	}
+	if len(f.service.actionChan) != 0 {
+		t.Errorf("%d actions enqueued while stopped", len(f.service.actionChan))
+	}
+	if _, err := f.service.ApproveAction(ctx, &pb.ApprovalDecision{ActionId: waiting, Approved: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ApproveAction() while stopped error = %v, want FailedPrecondition", err)
+	}
+	if err := f.stateRepo.SetState(ctx, domain.AppStateActive); !errors.Is(err, adapter.ErrEmergencyStop) {
+		t.Errorf("SetState(ACTIVE) while stopped error = %v, want ErrEmergencyStop", err)
+	}
+
+	records, err := f.auditRepo.GetRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Event != adapter.AuditEventEmergencyStop || records[0].Actor != "grpc:body" {
+		t.Errorf("audit log = %+v, want one %s by grpc:body", records, adapter.AuditEventEmergencyStop)
+	}
+}
+
+func TestEmergencyStopAbortsActionStreams(t *testing.T) {
+	f := newKillSwitchFixture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &fakeActionStream{ctx: ctx, sent: make(chan *pb.ActionCommand, 4)}
+	go f.service.StreamActions(nil, stream)
+
+	// Queued but not yet sent: the stop must drop it
+	f.service.actionChan <- &pb.ActionCommand{CommandId: "trace-0", Action: &pb.Action{Type: "TYPE"}}
+	if cmd := <-stream.sent; cmd.CommandId != "trace-0" {
+		t.Fatalf("sent %q before stop, want trace-0", cmd.CommandId)
+	}
+	f.service.actionChan <- &pb.ActionCommand{CommandId: "trace-1", Action: &pb.Action{Type: "TYPE"}}
+
+	if _, err := f.killSwitch.Halt(context.Background(), "test", "user pressed stop"); err != nil {
+		t.Fatalf("Halt() error = %v", err)
+	}
+
+	select {
+	case cmd := <-stream.sent:
+		if cmd.Action.GetType() != ActionTypeStop || cmd.Action.Payload["reason"] != "user pressed stop" {
+			t.Errorf("sent %+v after stop, want a STOP command with the reason", cmd)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Body was not sent a STOP command")
+	}
+	select {
+	case cmd := <-stream.sent:
+		t.Errorf("sent %q after the STOP command", cmd.CommandId)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRearmRequiresReason(t *testing.T) {
+	ctx := context.Background()
+	f := newKillSwitchFixture(t)
+	human := auth.WithRole(ctx, auth.RoleHuman)
+
+	if _, err := f.service.Rearm(human, &pb.RearmRequest{Reason: "checked"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Rearm() while not stopped error = %v, want FailedPrecondition", err)
+	}
+	waiting := f.propose(t, domain.ActionProposalStatusWaitingForUser)
+	if _, err := f.service.EmergencyStop(ctx, &pb.StopRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.Rearm(human, &pb.RearmRequest{Reason: "  "}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Rearm() without reason error = %v, want InvalidArgument", err)
+	}
+
+	// The latch survives a restart
+	reopened, err := adapter.NewStateRepository(f.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stop := reopened.EmergencyStop(); !stop.Engaged || stop.Reason != "no reason given" {
+		t.Errorf("reloaded latch = %+v, want engaged", stop)
+	}
+
+	result, err := f.service.Rearm(human, &pb.RearmRequest{Reason: "Body restarted, editor closed"})
+	if err != nil {
+		t.Fatalf("Rearm() error = %v", err)
+	}
+	if result.Engaged || result.State != "SHADOW" {
+		t.Errorf("Rearm() = %+v, want released into SHADOW", result)
+	}
+	if state, _ := f.stateRepo.GetState(ctx); state != domain.AppStateShadow {
+		t.Errorf("state after re-arm = %s, want SHADOW", state)
+	}
+	if err := f.stateRepo.SetState(ctx, domain.AppStateActive); err != nil {
+		t.Errorf("SetState(ACTIVE) after re-arm error = %v", err)
+	}
+
+	// Work the stop cancelled stays cancelled once re-armed
+	if _, err := f.service.ApproveAction(human, &pb.ApprovalDecision{ActionId: waiting, Approved: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ApproveAction() of cancelled work after re-arm error = %v, want FailedPrecondition", err)
+	}
+
+	records, _ := f.auditRepo.GetRecent(ctx, 10)
+	if len(records) != 2 || records[0].Event != adapter.AuditEventRearm || records[0].Actor != "grpc:human" {
+		t.Errorf("audit log = %+v, want the re-arm by grpc:human last", records)
+	}
+}
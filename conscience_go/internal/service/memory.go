@@ -0,0 +1,94 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+	pb "ghost/kernel/internal/protocol"
+)
+
+// defaultMemorySearchLimit is used when memory.search omits a limit.
+const defaultMemorySearchLimit = 5
+
+// MemoryService implements the gateway's memory.store and memory.search methods.
+type MemoryService struct {
+	// Repo persists memories (values are encrypted at rest when a key is configured).
+	Repo *adapter.MemoryRepository
+}
+
+// NewMemoryService creates the memory handler for the gateway.
+func NewMemoryService(repo *adapter.MemoryRepository) *MemoryService {
+	return &MemoryService{Repo: repo}
+}
+
+// Store saves a fact, replacing any previous value for the same key.
+func (m *MemoryService) Store(ctx context.Context, req *pb.MemoryStoreParams) (*pb.MemoryStoreResult, error) {
+	if strings.TrimSpace(req.Key) == "" {
+		return nil, fmt.Errorf("memory key is required")
+	}
+
+	memory := domain.NewMemory(req.Key, req.Value, req.Context, time.Duration(req.TTLDays)*24*time.Hour)
+	memory.Embedding = req.Vector
+
+	if err := m.Repo.SaveMemory(ctx, memory); err != nil {
+		return nil, err
+	}
+
+	return &pb.MemoryStoreResult{Success: true, ArtifactID: memory.ID}, nil
+}
+
+// Search returns the closest memories to a vector, or text matches when only a query is given.
+func (m *MemoryService) Search(ctx context.Context, req *pb.MemorySearchParams) (*pb.MemorySearchResult, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultMemorySearchLimit
+	}
+
+	result := &pb.MemorySearchResult{Artifacts: []pb.MemoryArtifact{}}
+
+	if len(req.Vector) > 0 {
+		memories, scores, err := m.Repo.SearchMemories(ctx, req.Vector, limit)
+		if err != nil {
+			return nil, err
+		}
+		for i, memory := range memories {
+			result.Artifacts = append(result.Artifacts, toMemoryArtifact(memory, float64(scores[i])))
+		}
+		return result, nil
+	}
+
+	// The kernel has no embedding model, so a text query falls back to substring matching
+	memories, err := m.Repo.GetAllMemories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := strings.ToLower(strings.TrimSpace(req.Query))
+	for _, memory := range memories {
+		if len(result.Artifacts) >= limit {
+			break
+		}
+		haystack := strings.ToLower(memory.Key + " " + memory.Value + " " + memory.Context)
+		if query == "" || strings.Contains(haystack, query) {
+			result.Artifacts = append(result.Artifacts, toMemoryArtifact(memory, 0))
+		}
+	}
+
+	return result, nil
+}
+
+// toMemoryArtifact converts a stored memory into its gateway representation.
+func toMemoryArtifact(memory domain.Memory, score float64) pb.MemoryArtifact {
+	return pb.MemoryArtifact{
+		ID:              memory.ID,
+		Key:             memory.Key,
+		Value:           memory.Value,
+		Context:         memory.Context,
+		SimilarityScore: score,
+		CreatedAt:       memory.CreatedAt,
+	}
+}
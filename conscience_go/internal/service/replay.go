@@ -0,0 +1,106 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
+)
+
+// ReplayReport summarises how a candidate policy would have decided past requests.
+type ReplayReport struct {
+	Evaluated int              `json:"evaluated"`
+	Changed   int              `json:"changed"`
+	Skipped   int              `json:"skipped"` // Records that could not be decoded
+	Changes   []DecisionChange `json:"changes"`
+}
+
+// DecisionChange is a recorded decision the candidate policy would make differently.
+type DecisionChange struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	Intent    string    `json:"intent"`
+	CreatedAt time.Time `json:"created_at"`
+	Before    string    `json:"before"` // decision/rule as recorded
+	After     string    `json:"after"`  // decision/rule under the candidate
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// ProposalInput is what the policy rules look at in a REST proposal besides its intent.
+type ProposalInput struct {
+	RiskScore int             `json:"risk_score"`
+	Mode      domain.ModeType `json:"mode"`
+	Grants    []string        `json:"grants,omitempty"` // Standing approvals that covered it
+}
+
+// ReplayDecisions re-evaluates recorded decisions under a candidate safety policy.
+// Only the policy rules are replayed; the emergency stop and throttling are not, as they were never recorded.
+func ReplayDecisions(records []domain.DecisionRecord, policy SafetyConfig) *ReplayReport {
+	safety := NewSafetyChecker(policy)
+	validator := conscience.NewValidator()
+	validator.SetAllowedActions(policy.AllowedActions)
+
+	report := &ReplayReport{Changes: []DecisionChange{}}
+	for _, record := range records {
+		trace, err := replayOne(safety, validator, record)
+		if err != nil {
+			report.Skipped++
+			continue
+		}
+		report.Evaluated++
+
+		before := record.Decision + "/" + record.Rule
+		after := trace.Decision + "/" + trace.Rule
+		if trace.Decision == record.Decision {
+			continue
+		}
+		report.Changed++
+		report.Changes = append(report.Changes, DecisionChange{
+			ID:        record.ID,
+			Path:      record.Path,
+			Intent:    record.Intent,
+			CreatedAt: record.CreatedAt,
+			Before:    before,
+			After:     after,
+			Reason:    trace.Reason,
+		})
+	}
+	return report
+}
+
+// replayOne evaluates a single record with the evaluator of the path it came through.
+func replayOne(safety *SafetyChecker, validator *conscience.Validator, record domain.DecisionRecord) (*domain.DecisionTrace, error) {
+	trace := &domain.DecisionTrace{Path: record.Path, DryRun: true}
+	switch record.Path {
+	case metrics.PathGRPC:
+		var input PermissionInput
+		if err := json.Unmarshal(record.Request, &input); err != nil {
+			return nil, fmt.Errorf("failed to decode permission request: %w", err)
+		}
+		safety.EvaluateRequest(trace, record.Intent, input.Actions)
+		trace.Settle(metrics.DecisionApproved, metrics.RulePassed)
+	case metrics.PathREST:
+		var input ProposalInput
+		if err := json.Unmarshal(record.Request, &input); err != nil {
+			return nil, fmt.Errorf("failed to decode proposal: %w", err)
+		}
+		safety.EvaluateProposal(trace, input.RiskScore, &domain.UserMode{Mode: input.Mode})
+		// Grants are not part of the policy, so the ones that applied still apply
+		if len(input.Grants) > 0 && trace.Decision == metrics.DecisionPending {
+			approveByGrants(trace, input.Grants)
+		}
+	case metrics.PathGateway:
+		var input conscience.DecisionInput
+		if err := json.Unmarshal(record.Request, &input); err != nil || input.Request == nil {
+			return nil, fmt.Errorf("failed to decode exec request: %v", err)
+		}
+		_, trace = validator.Evaluate(input.Request, input.FocusedWindow, input.TrustScore)
+	default:
+		return nil, fmt.Errorf("unknown decision path %q", record.Path)
+	}
+	return trace, nil
+}
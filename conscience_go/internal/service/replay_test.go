@@ -0,0 +1,143 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/auth"
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
+	pb "ghost/kernel/internal/protocol"
+)
+
+// newDecisionService returns a service that records its decisions, throttled to one request at a time
+func newDecisionService(t *testing.T) (*GhostService, *adapter.DecisionLogRepository) {
+	t.Helper()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	actionRepo, err := adapter.NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intentRepo, err := adapter.NewIntentHistoryRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decisionRepo, err := adapter.NewDecisionLogRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewGhostService(actionRepo, intentRepo, nil, nil)
+	s.Decisions = decisionRepo
+	s.Throttle = NewThrottle(ThrottleConfig{ClientPerMinute: 1, ClientBurst: 1})
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+	return s, decisionRepo
+}
+
+func TestDryRunPermissionHasNoSideEffects(t *testing.T) {
+	ctx := auth.WithRole(context.Background(), auth.RoleBrain)
+	s, decisionRepo := newDecisionService(t)
+
+	req := &pb.PermissionRequest{
+		Intent:  "type greeting",
+		Actions: []*pb.Action{{Type: "TYPE", Payload: map[string]string{"text": "hello"}}, {Type: "EXEC"}},
+		DryRun:  true,
+	}
+	for i := 0; i < 3; i++ {
+		resp, err := s.RequestPermission(ctx, req)
+		if err != nil {
+			t.Fatalf("dry run %d: RequestPermission() error = %v", i, err)
+		}
+		trace := resp.Trace
+		if resp.Approved || trace == nil || trace.Decision != metrics.DecisionDenied || trace.Rule != metrics.RuleActionValidation || !trace.DryRun {
+			t.Fatalf("dry run %d = %v, want denied by %s", i, resp, metrics.RuleActionValidation)
+		}
+		var rules []string
+		for _, check := range trace.Checks {
+			rules = append(rules, check.Rule)
+		}
+		want := []string{metrics.RuleThrottle, metrics.RuleBlockedKeyword, metrics.RuleActionValidation}
+		if len(rules) != len(want) || rules[0] != want[0] || rules[1] != want[1] || rules[2] != want[2] {
+			t.Errorf("dry run %d checked %v, want %v", i, rules, want)
+		}
+		if len(trace.Actions) != 2 || trace.Actions[0].Risk != int32(conscience.ActionTypeRisk("TYPE")) {
+			t.Errorf("dry run %d action risks = %v, want one per action", i, trace.Actions)
+		}
+	}
+	if len(s.actionChan) != 0 {
+		t.Errorf("dry runs enqueued %d commands", len(s.actionChan))
+	}
+
+	// The throttle's single token is still there for a real request
+	req.Actions, req.DryRun = req.Actions[:1], false
+	if resp, err := s.RequestPermission(ctx, req); err != nil || !resp.Approved || resp.Trace != nil {
+		t.Fatalf("RequestPermission() = %v, %v; want approved without a trace", resp, err)
+	}
+	records, err := decisionRepo.List(context.Background(), time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Decision != metrics.DecisionApproved {
+		t.Fatalf("decision log = %v, want only the real request", records)
+	}
+}
+
+func TestReplayReportsChangedDecisions(t *testing.T) {
+	ctx := auth.WithRole(context.Background(), auth.RoleBrain)
+	s, decisionRepo := newDecisionService(t)
+	s.Throttle = nil
+
+	for _, req := range []*pb.PermissionRequest{
+		{Intent: "type greeting", Actions: []*pb.Action{{Type: "TYPE"}}},
+		{Intent: "sudo make me a sandwich", Actions: []*pb.Action{{Type: "TYPE"}}},
+		{Intent: "read notes", Actions: []*pb.Action{{Type: "READ", Payload: map[string]string{"path": "notes.txt"}}}},
+	} {
+		if _, err := s.RequestPermission(ctx, req); err != nil {
+			t.Fatalf("RequestPermission(%q) error = %v", req.Intent, err)
+		}
+	}
+	records, err := decisionRepo.List(context.Background(), time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A proposal the default threshold auto-approved, and one whose record is unreadable
+	trace := &domain.DecisionTrace{Path: metrics.PathREST}
+	s.Safety.EvaluateProposal(trace, 20, nil)
+	proposal, err := domain.NewDecisionRecord(trace, "open editor", "EDITOR", "", &ProposalInput{RiskScore: 20, Mode: domain.ModeTypeAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records = append(records, *proposal, domain.DecisionRecord{Path: metrics.PathGateway, Request: json.RawMessage(`"garbage"`)})
+
+	// The candidate stops blocking "sudo", drops READ and only auto-approves below 10
+	candidate := DefaultSafetyConfig()
+	candidate.BlockedKeywords = []string{"delete"}
+	delete(candidate.AllowedActions, "READ")
+	candidate.AutoApproveBelow = 10
+
+	report := ReplayDecisions(records, candidate)
+	if report.Evaluated != 4 || report.Skipped != 1 || report.Changed != 3 {
+		t.Fatalf("report = %d evaluated, %d changed, %d skipped; want 4, 3, 1", report.Evaluated, report.Changed, report.Skipped)
+	}
+	want := map[string]string{
+		"sudo make me a sandwich": "approved/passed",
+		"read notes":              "denied/action_validation",
+		"open editor":             "pending/risk_threshold",
+	}
+	for _, change := range report.Changes {
+		if want[change.Intent] != change.After {
+			t.Errorf("%q replayed to %s, want %s", change.Intent, change.After, want[change.Intent])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+)
+
+// RetentionConfig defines the background compaction schedule.
+type RetentionConfig struct {
+	// Policy is the per-table retention window.
+	Policy adapter.RetentionPolicy
+	// CompactInterval is how often expired rows are deleted.
+	CompactInterval time.Duration
+	// VacuumInterval is the minimum time between VACUUM runs; zero disables VACUUM.
+	VacuumInterval time.Duration
+}
+
+// DefaultRetentionConfig returns the default compaction schedule.
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		Policy:          adapter.DefaultRetentionPolicy(),
+		CompactInterval: time.Hour,
+		VacuumInterval:  24 * time.Hour,
+	}
+}
+
+// RetentionJob periodically deletes expired rows and reclaims disk space.
+type RetentionJob struct {
+	repo   *adapter.RetentionRepository
+	config RetentionConfig
+
+	// lastVacuum is when VACUUM last ran.
+	lastVacuum time.Time
+	// dirty is true when rows were deleted since the last VACUUM.
+	dirty bool
+}
+
+// NewRetentionJob creates a compaction job for the given repository.
+func NewRetentionJob(repo *adapter.RetentionRepository, config RetentionConfig) *RetentionJob {
+	return &RetentionJob{
+		repo:       repo,
+		config:     config,
+		lastVacuum: time.Now(),
+	}
+}
+
+// Run compacts on every tick until ctx is cancelled.
+func (j *RetentionJob) Run(ctx context.Context) {
+	if j.config.CompactInterval <= 0 {
+		slog.Info("Retention compaction disabled")
+		return
+	}
+
+	ticker := time.NewTicker(j.config.CompactInterval)
+	defer ticker.Stop()
+
+	// Sweep once at startup so a long-stopped kernel does not serve stale data
+	j.RunOnce(ctx, time.Now())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			j.RunOnce(ctx, now)
+		}
+	}
+}
+
+// RunOnce performs a single compaction pass and a VACUUM if one is due.
+func (j *RetentionJob) RunOnce(ctx context.Context, now time.Time) {
+	deleted, err := j.repo.Compact(ctx, j.config.Policy, now)
+	if err != nil {
+		slog.Error("Retention compaction failed", "error", err)
+		return
+	}
+
+	var total int64
+	for _, n := range deleted {
+		total += n
+	}
+	if total > 0 {
+		j.dirty = true
+		slog.Info("Retention compaction removed expired rows", "deleted", deleted)
+	}
+
+	if j.config.VacuumInterval <= 0 || !j.dirty || now.Sub(j.lastVacuum) < j.config.VacuumInterval {
+		return
+	}
+
+	if err := j.repo.Vacuum(ctx); err != nil {
+		slog.Error("Scheduled VACUUM failed", "error", err)
+		return
+	}
+
+	j.lastVacuum = now
+	j.dirty = false
+	slog.Info("Scheduled VACUUM completed")
+}
@@ -0,0 +1,205 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
+	pb "ghost/kernel/internal/protocol"
+)
+
+// payloadActionList returns the action objects in a proposal payload.
+// A payload is a plan ({"actions": [...]}), a bare list of actions, or a single action ({"type": ...});
+// any other payload carries no actions.
+func payloadActionList(payload json.RawMessage) ([]map[string]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+
+	var list []map[string]json.RawMessage
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, fmt.Errorf("failed to decode actions: %w", err)
+		}
+		return list, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return nil, fmt.Errorf("payload must be a JSON object or a list of actions: %w", err)
+	}
+	if actions, ok := object["actions"]; ok {
+		if err := json.Unmarshal(actions, &list); err != nil {
+			return nil, fmt.Errorf("failed to decode actions: %w", err)
+		}
+		return list, nil
+	}
+	if _, ok := object["type"]; ok {
+		return []map[string]json.RawMessage{object}, nil
+	}
+	return nil, nil
+}
+
+// PayloadActions decodes the actions of a proposal payload for policy checks.
+// An action's fields are either nested under "payload" or sit beside "type", as the planner writes them;
+// values that are not strings are kept as their JSON text.
+func PayloadActions(payload json.RawMessage) ([]*pb.Action, error) {
+	list, err := payloadActionList(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]*pb.Action, 0, len(list))
+	for i, object := range list {
+		action := &pb.Action{Payload: map[string]string{}}
+		if err := json.Unmarshal(object["type"], &action.Type); err != nil {
+			return nil, fmt.Errorf("action %d has no type", i)
+		}
+
+		fields := object
+		nested, isNested := object["payload"]
+		if isNested {
+			fields = nil
+			if err := json.Unmarshal(nested, &fields); err != nil {
+				return nil, fmt.Errorf("action %d payload must be an object: %w", i, err)
+			}
+		}
+		for key, value := range fields {
+			if key == "type" && !isNested {
+				continue
+			}
+			var text string
+			if err := json.Unmarshal(value, &text); err != nil {
+				text = string(value)
+			}
+			action.Payload[key] = text
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// CorrectedPlan returns an edited payload as a plan the reflex cache can replay, or false if it holds no actions.
+func CorrectedPlan(payload json.RawMessage) (string, bool) {
+	list, err := payloadActionList(payload)
+	if err != nil || len(list) == 0 {
+		return "", false
+	}
+	plan, err := json.Marshal(map[string]interface{}{"actions": list})
+	if err != nil {
+		return "", false
+	}
+	return string(plan), true
+}
+
+// EvaluateEdit records the policy rules a payload edited by an approver is held to in trace.
+// Its actions get the same checks as a gRPC permission request, and every "path" or "directory"
+// field anywhere in it must be a safe relative path. The edit is then rescored and held to the
+// mode and risk-threshold rules a fresh proposal gets: the approver is the user a held proposal
+// waits for, but an edit that raises the risk to the threshold or above stays pending until the
+// user confirms the higher risk with riskConfirmed.
+func (s *SafetyChecker) EvaluateEdit(trace *domain.DecisionTrace, action *domain.ActionProposal, edited json.RawMessage, userMode *domain.UserMode, riskConfirmed bool) error {
+	actions, err := PayloadActions(edited)
+	if err != nil {
+		return err
+	}
+	s.EvaluateRequest(trace, action.Intent, actions)
+
+	unsafe := ""
+	if s.config.SafeMode {
+		var decoded interface{}
+		if err := json.Unmarshal(edited, &decoded); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		unsafe = s.unsafePath(decoded)
+	}
+	reason := ""
+	if unsafe != "" {
+		reason = "Unsafe path in edited payload: " + unsafe
+	}
+	trace.Check(metrics.RulePath, unsafe == "", metrics.DecisionDenied, reason)
+
+	riskScore := RevisedRiskScore(action, edited)
+	s.EvaluateProposal(trace, riskScore, userMode)
+	if trace.Decision != metrics.DecisionPending {
+		return nil
+	}
+
+	if riskScore > action.RiskScore && riskScore >= s.config.AutoApproveBelow {
+		if !riskConfirmed {
+			reason = fmt.Sprintf("edit raises risk score from %d to %d, not below %d; confirm the higher risk to approve it", action.RiskScore, riskScore, s.config.AutoApproveBelow)
+			trace.Check(metrics.RuleRiskThreshold, false, metrics.DecisionPending, reason)
+			trace.Decision, trace.Rule, trace.Reason = metrics.DecisionPending, metrics.RuleRiskThreshold, reason
+			return nil
+		}
+		reason = fmt.Sprintf("user confirmed the edit raising risk score from %d to %d", action.RiskScore, riskScore)
+		trace.Check(metrics.RuleRiskThreshold, true, metrics.DecisionApproved, reason)
+		trace.Decision, trace.Rule, trace.Reason = metrics.DecisionApproved, metrics.RulePassed, reason
+		return nil
+	}
+	trace.Decision, trace.Rule, trace.Reason = metrics.DecisionApproved, metrics.RulePassed, "approved by the user it was held for"
+	return nil
+}
+
+// RevisedRiskScore is the risk score of action with its payload replaced by edited.
+func RevisedRiskScore(action *domain.ActionProposal, edited json.RawMessage) int {
+	// A payload the brain wrote in its own shape has no actions to rescore against
+	original, _ := PayloadActions(action.Payload)
+	actions, _ := PayloadActions(edited)
+	return EditedRiskScore(action.RiskScore, original, actions)
+}
+
+// EditedRiskScore rescores a proposal whose actions were edited.
+// The brain's score rises by ten points for each level the riskiest edited action type sits
+// above the riskiest original one; an edit never lowers it.
+func EditedRiskScore(riskScore int, original, edited []*pb.Action) int {
+	raised := 10 * (maxActionRisk(edited) - maxActionRisk(original))
+	if raised <= 0 {
+		return riskScore
+	}
+	if riskScore+raised > 100 {
+		return 100
+	}
+	return riskScore + raised
+}
+
+// maxActionRisk returns the highest action-type risk level among actions.
+func maxActionRisk(actions []*pb.Action) int {
+	highest := 0
+	for _, action := range actions {
+		if action == nil {
+			continue
+		}
+		if risk := int(conscience.ActionTypeRisk(action.Type)); risk > highest {
+			highest = risk
+		}
+	}
+	return highest
+}
+
+// unsafePath returns the first "path" or "directory" string in v that isSafePath rejects.
+func (s *SafetyChecker) unsafePath(v interface{}) string {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if path, ok := field.(string); ok && (key == "path" || key == "directory") && !s.isSafePath(path) {
+				return path
+			}
+			if unsafe := s.unsafePath(field); unsafe != "" {
+				return unsafe
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if unsafe := s.unsafePath(item); unsafe != "" {
+				return unsafe
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
+)
+
+func TestEvaluateEdit(t *testing.T) {
+	safety := NewSafetyChecker(DefaultSafetyConfig())
+	write := &domain.ActionProposal{Intent: "write note", RiskScore: 10, Payload: json.RawMessage(`{"actions":[{"type":"WRITE","path":"notes/draft.md"}]}`)}
+
+	tests := []struct {
+		name     string
+		intent   string
+		payload  string
+		decision string
+		rule     string
+	}{
+		{"planner plan", "write note", `{"actions":[{"type":"WRITE","path":"notes/today.md","text":"hi"}]}`, metrics.DecisionApproved, metrics.RuleAutoApprove},
+		{"nested single action", "read notes", `{"type":"READ","payload":{"path":"notes.txt"}}`, metrics.DecisionApproved, metrics.RuleAutoApprove},
+		{"action outside the allowlist", "run script", `[{"type":"EXEC","cmd":"ls"}]`, metrics.DecisionDenied, metrics.RuleActionValidation},
+		{"traversal in a typed action", "write note", `{"actions":[{"type":"WRITE","path":"../../etc/passwd"}]}`, metrics.DecisionDenied, metrics.RuleActionValidation},
+		{"absolute path in an untyped payload", "save report", `{"target":{"path":"/etc/hosts"}}`, metrics.DecisionDenied, metrics.RulePath},
+		{"blocked keyword in the intent", "delete logs", `{"key":"ENTER"}`, metrics.DecisionDenied, metrics.RuleBlockedKeyword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := &domain.DecisionTrace{Path: metrics.PathREST}
+			action := *write
+			action.Intent = tt.intent
+			if err := safety.EvaluateEdit(trace, &action, json.RawMessage(tt.payload), nil, false); err != nil {
+				t.Fatalf("EvaluateEdit() error = %v", err)
+			}
+			if trace.Decision != tt.decision || trace.Rule != tt.rule {
+				t.Errorf("EvaluateEdit() = %s/%s (%s), want %s/%s", trace.Decision, trace.Rule, trace.Reason, tt.decision, tt.rule)
+			}
+		})
+	}
+
+	if err := safety.EvaluateEdit(&domain.DecisionTrace{}, write, json.RawMessage(`{"actions":[{"text":"no type"}]}`), nil, false); err == nil {
+		t.Error("EvaluateEdit() accepted an action without a type")
+	}
+}
+
+func TestEditRaisingRiskIsRescored(t *testing.T) {
+	safety := NewSafetyChecker(DefaultSafetyConfig())
+	manual := &domain.UserMode{Mode: domain.ModeTypeManual}
+	typing := &domain.ActionProposal{Intent: "reply to Sam", RiskScore: 10, Payload: json.RawMessage(`{"actions":[{"type":"TYPE","text":"Dear Sam"}]}`)}
+
+	tests := []struct {
+		name      string
+		payload   string
+		mode      *domain.UserMode
+		confirmed bool
+		decision  string
+		rule      string
+	}{
+		{"same action type", `{"actions":[{"type":"TYPE","text":"Hi Sam"}]}`, nil, false, metrics.DecisionApproved, metrics.RuleAutoApprove},
+		{"raised past the threshold", `{"actions":[{"type":"TYPE","text":"Hi"},{"type":"WRITE","path":"notes/sam.md"}]}`, nil, false, metrics.DecisionPending, metrics.RuleRiskThreshold},
+		{"raised past the threshold in MANUAL mode", `{"actions":[{"type":"WRITE","path":"notes/sam.md"}]}`, manual, false, metrics.DecisionPending, metrics.RuleRiskThreshold},
+		{"raised risk confirmed by the user", `{"actions":[{"type":"TYPE","text":"Hi"},{"type":"WRITE","path":"notes/sam.md"}]}`, nil, true, metrics.DecisionApproved, metrics.RulePassed},
+		{"raised risk confirmed in MANUAL mode", `{"actions":[{"type":"WRITE","path":"notes/sam.md"}]}`, manual, true, metrics.DecisionApproved, metrics.RulePassed},
+		{"held only by MANUAL mode", `{"actions":[{"type":"CLICK","x":"10","y":"20"}]}`, manual, false, metrics.DecisionApproved, metrics.RulePassed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := &domain.DecisionTrace{Path: metrics.PathREST}
+			if err := safety.EvaluateEdit(trace, typing, json.RawMessage(tt.payload), tt.mode, tt.confirmed); err != nil {
+				t.Fatalf("EvaluateEdit() error = %v", err)
+			}
+			if trace.Decision != tt.decision || trace.Rule != tt.rule {
+				t.Errorf("EvaluateEdit() = %s/%s (%s), want %s/%s", trace.Decision, trace.Rule, trace.Reason, tt.decision, tt.rule)
+			}
+		})
+	}
+
+	// A proposal the user already saw at a high risk keeps its score when edited
+	held := &domain.ActionProposal{Intent: "write note", RiskScore: 80, Payload: json.RawMessage(`{"actions":[{"type":"WRITE","path":"notes/a.md"}]}`)}
+	trace := &domain.DecisionTrace{Path: metrics.PathREST}
+	if err := safety.EvaluateEdit(trace, held, json.RawMessage(`{"actions":[{"type":"WRITE","path":"notes/b.md"}]}`), nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if trace.Decision != metrics.DecisionApproved {
+		t.Errorf("EvaluateEdit() of a held proposal = %s/%s (%s), want approved", trace.Decision, trace.Rule, trace.Reason)
+	}
+}
+
+func TestCorrectedPlan(t *testing.T) {
+	plan, ok := CorrectedPlan(json.RawMessage(`{"type":"TYPE","text":"Dear Sam"}`))
+	if !ok || plan != `{"actions":[{"text":"Dear Sam","type":"TYPE"}]}` {
+		t.Errorf("CorrectedPlan(single action) = %s, %v", plan, ok)
+	}
+	if _, ok := CorrectedPlan(json.RawMessage(`{"key":"ENTER"}`)); ok {
+		t.Error("CorrectedPlan() learned a payload without actions")
+	}
+}
@@ -2,8 +2,12 @@
 package service
 
 import (
+	"fmt"
 	"strings"
 
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
 	pb "ghost/kernel/internal/protocol"
 )
 
@@ -15,6 +19,8 @@ type SafetyConfig struct {
 	BlockedKeywords []string
 	// AllowedActions is a set of action types that are permitted.
 	AllowedActions map[string]bool
+	// AutoApproveBelow is the risk score under which AUTO mode approves a proposal without asking.
+	AutoApproveBelow int
 }
 
 // DefaultSafetyConfig returns strict defaults for safety validation.
@@ -36,6 +42,7 @@ func DefaultSafetyConfig() SafetyConfig {
 			"WRITE":    true,
 			"EDIT":     true,
 		},
+		AutoApproveBelow: domain.DefaultAutoApproveBelow,
 	}
 }
 
@@ -126,6 +133,59 @@ func (s *SafetyChecker) ValidateAction(action *pb.Action) (bool, string) {
 	return true, ""
 }
 
+// EvaluateRequest records the policy rules a gRPC permission request is held to in trace.
+func (s *SafetyChecker) EvaluateRequest(trace *domain.DecisionTrace, intent string, actions []*pb.Action) {
+	detail := ""
+	if !s.config.SafeMode {
+		detail = "safe mode is off"
+	}
+
+	dangerous, keyword := s.IsDangerous(intent)
+	reason := detail
+	if dangerous {
+		reason = "Violates Safety Policy: Blocked Keyword '" + keyword + "'"
+	}
+	trace.Check(metrics.RuleBlockedKeyword, !dangerous, metrics.DecisionDenied, reason)
+
+	ok, why := s.ValidateActions(actions)
+	reason = detail
+	if !ok {
+		reason = "Safety Violation: " + why
+	}
+	trace.Check(metrics.RuleActionValidation, ok, metrics.DecisionDenied, reason)
+
+	for i, action := range actions {
+		if action != nil {
+			trace.Actions = append(trace.Actions, domain.ActionRisk{Index: i, Type: action.Type, Risk: int(conscience.ActionTypeRisk(action.Type))})
+		}
+	}
+}
+
+// EvaluateProposal records whether a proposal with this risk runs at once or waits for the user.
+func (s *SafetyChecker) EvaluateProposal(trace *domain.DecisionTrace, riskScore int, userMode *domain.UserMode) {
+	trace.Mode = string(domain.ModeTypeAuto)
+	if userMode != nil {
+		trace.Mode = string(userMode.Mode)
+	}
+	trace.Actions = append(trace.Actions, domain.ActionRisk{Risk: riskScore})
+
+	manual := trace.Mode == string(domain.ModeTypeManual)
+	reason := ""
+	if manual {
+		reason = "domain is in MANUAL mode"
+	}
+	trace.Check(metrics.RuleManualMode, !manual, metrics.DecisionPending, reason)
+
+	below := riskScore < s.config.AutoApproveBelow
+	reason = fmt.Sprintf("risk score %d is below %d", riskScore, s.config.AutoApproveBelow)
+	if !below {
+		reason = fmt.Sprintf("risk score %d is not below %d", riskScore, s.config.AutoApproveBelow)
+	}
+	trace.Check(metrics.RuleRiskThreshold, below, metrics.DecisionPending, reason)
+
+	trace.Settle(metrics.DecisionApproved, metrics.RuleAutoApprove)
+}
+
 // isSafePath returns true if the path is relative and does not contain directory traversal.
 func (s *SafetyChecker) isSafePath(path string) bool {
 	if path == "" {
@@ -0,0 +1,100 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+)
+
+// GoalScheduler queues scheduled and recurring goals when they come due
+// and fails queued goals whose deadline has passed.
+type GoalScheduler struct {
+	goals    *adapter.GoalRepository
+	state    *adapter.StateRepository
+	interval time.Duration
+
+	// listeners are told about every goal the scheduler queues.
+	listeners []func(goal domain.Goal)
+}
+
+// NewGoalScheduler creates a scheduler that checks for due goals every interval.
+func NewGoalScheduler(goals *adapter.GoalRepository, state *adapter.StateRepository, interval time.Duration) *GoalScheduler {
+	return &GoalScheduler{
+		goals:    goals,
+		state:    state,
+		interval: interval,
+	}
+}
+
+// OnFire registers a listener called for each goal the scheduler queues.
+// Listeners must be registered before Run is started.
+func (s *GoalScheduler) OnFire(fn func(goal domain.Goal)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// Run checks for due goals on every tick until ctx is cancelled.
+func (s *GoalScheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		slog.Info("Goal scheduler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	// Catch up at startup on goals that came due while the kernel was stopped
+	s.RunOnce(ctx, time.Now())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.RunOnce(ctx, now)
+		}
+	}
+}
+
+// RunOnce performs a single scheduling pass. Nothing fires while the kernel is PAUSED;
+// due goals wait and fire on the first pass after it resumes. Otherwise each pass first
+// requeues goals whose planner let its lease lapse.
+func (s *GoalScheduler) RunOnce(ctx context.Context, now time.Time) {
+	state, err := s.state.GetState(ctx)
+	if err != nil {
+		slog.Error("Goal scheduler could not read app state", "error", err)
+		return
+	}
+	if state == domain.AppStatePaused {
+		return
+	}
+
+	requeued, err := s.goals.ExpireLeases(ctx)
+	if err != nil {
+		slog.Error("Failed to expire goal leases", "error", err)
+	} else if requeued > 0 {
+		slog.Info("Requeued goals abandoned by their planner", "count", requeued)
+	}
+
+	failed, err := s.goals.FailOverdueGoals(ctx, now)
+	if err != nil {
+		slog.Error("Failed to expire overdue goals", "error", err)
+	} else if failed > 0 {
+		slog.Info("Failed goals past their deadline", "count", failed)
+	}
+
+	fired, err := s.goals.FireDueGoals(ctx, now)
+	if err != nil {
+		slog.Error("Failed to fire scheduled goals", "error", err)
+		return
+	}
+	for _, goal := range fired {
+		slog.Info("Scheduled goal queued", "goal_id", goal.ID, "parent_id", goal.ParentID, "priority", goal.Priority)
+		for _, fn := range s.listeners {
+			fn(goal)
+		}
+	}
+}
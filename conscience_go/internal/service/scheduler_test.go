@@ -0,0 +1,75 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+)
+
+func TestSchedulerHoldsGoalsWhilePaused(t *testing.T) {
+	ctx := context.Background()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	goalRepo, err := adapter.NewGoalRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateRepo, err := adapter.NewStateRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goal := domain.NewGoal("water the plants")
+	runAt := time.Now().Add(time.Minute)
+	goal.RunAt = &runAt
+	goal.Status = domain.GoalStatusScheduled
+	if err := goalRepo.SaveGoal(ctx, goal); err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler := NewGoalScheduler(goalRepo, stateRepo, time.Second)
+	var fired []domain.Goal
+	scheduler.OnFire(func(goal domain.Goal) { fired = append(fired, goal) })
+
+	// Due, but the kernel is paused: the goal waits
+	if err := stateRepo.SetState(ctx, domain.AppStatePaused); err != nil {
+		t.Fatal(err)
+	}
+	scheduler.RunOnce(ctx, runAt.Add(time.Second))
+	if stored, _ := goalRepo.GetGoalByID(ctx, goal.ID); stored.Status != domain.GoalStatusScheduled || len(fired) != 0 {
+		t.Fatalf("goal = %s with %d fired while paused, want SCHEDULED with none", stored.Status, len(fired))
+	}
+
+	// Resumed: the first pass fires it
+	if err := stateRepo.SetState(ctx, domain.AppStateActive); err != nil {
+		t.Fatal(err)
+	}
+	scheduler.RunOnce(ctx, runAt.Add(2*time.Second))
+	if len(fired) != 1 || fired[0].ID != goal.ID || fired[0].Status != domain.GoalStatusActive {
+		t.Fatalf("fired = %+v, want the goal queued ACTIVE", fired)
+	}
+	if active, _ := goalRepo.GetActiveGoal(ctx); active == nil || active.ID != goal.ID {
+		t.Errorf("GetActiveGoal() = %v, want the fired goal", active)
+	}
+
+	// A planner claims it and dies: the next pass puts it back in the queue
+	if claimed, err := goalRepo.ClaimGoal(ctx, "planner", time.Millisecond); err != nil || claimed == nil {
+		t.Fatalf("ClaimGoal() = %v, %v; want the goal", claimed, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if active, _ := goalRepo.GetActiveGoal(ctx); active != nil {
+		t.Fatalf("GetActiveGoal() = %v before the pass, want nothing", active)
+	}
+	scheduler.RunOnce(ctx, time.Now())
+	if active, _ := goalRepo.GetActiveGoal(ctx); active == nil || active.ID != goal.ID || active.LeaseOwner != "" {
+		t.Errorf("GetActiveGoal() after the pass = %+v, want the abandoned goal unleased", active)
+	}
+}
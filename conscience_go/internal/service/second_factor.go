@@ -0,0 +1,133 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	pb "ghost/kernel/internal/protocol"
+)
+
+// HighRiskScore is the proposal and batch risk score from which approving needs a second factor.
+// It is protocol.RiskLevelHigh on the 0-100 scale proposals are scored on.
+const HighRiskScore = int(pb.RiskLevelHigh) * 10
+
+// SecondFactorError refuses a high-risk approval that carried no valid second factor.
+// RequestID and Digest are what an approval client confirms to let the approval through.
+type SecondFactorError struct {
+	RequestID string
+	Digest    string
+	Err       error
+}
+
+func (e *SecondFactorError) Error() string {
+	return fmt.Sprintf("%v (request %s, digest %s)", e.Err, e.RequestID, e.Digest)
+}
+
+func (e *SecondFactorError) Unwrap() error { return e.Err }
+
+// SecondFactor holds a person's approval of a high-risk proposal or batch, on every path, to a
+// one-time code or a registered approval client, as the Conscience holds high-risk exec requests.
+// A nil SecondFactor refuses every high-risk approval.
+type SecondFactor struct {
+	approvals *conscience.Approvals
+
+	mu        sync.Mutex
+	listeners []func(pb.ApprovalPendingEvent)
+}
+
+// NewSecondFactor creates a second factor that signs and redeems approvals through approvals.
+func NewSecondFactor(approvals *conscience.Approvals) *SecondFactor {
+	return &SecondFactor{approvals: approvals}
+}
+
+// OnPending registers fn to run when an approval waits on a second factor, so approval clients can prompt for it.
+func (f *SecondFactor) OnPending(fn func(pb.ApprovalPendingEvent)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, fn)
+}
+
+// ApproveProposal redeems the second factor approving action with payload needs at riskScore.
+// It returns no approval and no error below HighRiskScore.
+func (f *SecondFactor) ApproveProposal(action *domain.ActionProposal, payload json.RawMessage, riskScore int, code string) (*pb.SignedApproval, error) {
+	if riskScore < HighRiskScore {
+		return nil, nil
+	}
+	subject := struct {
+		ActionID string          `json:"action_id"`
+		Intent   string          `json:"intent"`
+		Domain   string          `json:"domain"`
+		Payload  json.RawMessage `json:"payload"`
+	}{action.ID, action.Intent, action.Domain, payload}
+	return f.redeem("proposal:"+action.ID, action.Intent, riskScore, payload, subject, code)
+}
+
+// ApproveBatch redeems the second factor approving batch needs at its aggregate risk score.
+// It returns no approval and no error below HighRiskScore.
+func (f *SecondFactor) ApproveBatch(batch *domain.ActionBatch, code string) (*pb.SignedApproval, error) {
+	if batch.RiskScore < HighRiskScore {
+		return nil, nil
+	}
+	steps := make([]map[string]json.RawMessage, len(batch.Steps))
+	for i, step := range batch.Steps {
+		steps[i] = map[string]json.RawMessage{"payload": step.Action.Payload}
+		if step.Compensation != nil {
+			steps[i]["compensation"] = step.Compensation.Payload
+		}
+	}
+	payload, err := json.Marshal(steps)
+	if err != nil {
+		return nil, err
+	}
+	subject := struct {
+		BatchID string          `json:"batch_id"`
+		Intent  string          `json:"intent"`
+		Domain  string          `json:"domain"`
+		Steps   json.RawMessage `json:"steps"`
+	}{batch.ID, batch.Intent, batch.Domain, payload}
+	return f.redeem("batch:"+batch.ID, batch.Intent, batch.RiskScore, payload, subject, code)
+}
+
+// Release returns a redeemed approval whose decision could not be recorded, so it can be used again until it expires.
+func (f *SecondFactor) Release(approval *pb.SignedApproval) {
+	if f == nil || approval == nil {
+		return
+	}
+	f.approvals.Release(approval)
+}
+
+// redeem redeems the second factor for subject under requestID, prompting approval clients when there is none.
+func (f *SecondFactor) redeem(requestID, intent string, riskScore int, actions json.RawMessage, subject interface{}, code string) (*pb.SignedApproval, error) {
+	digest := conscience.Digest(subject)
+	if f == nil {
+		return nil, &SecondFactorError{RequestID: requestID, Digest: digest, Err: errors.New("second-factor approvals are not configured")}
+	}
+
+	approval, err := f.approvals.Redeem(requestID, digest, code)
+	if err == nil {
+		return approval, nil
+	}
+	if errors.Is(err, conscience.ErrNeedsApproval) {
+		event := pb.ApprovalPendingEvent{
+			RequestID: requestID,
+			Intent:    intent,
+			RiskLevel: riskScore / 10,
+			Actions:   actions,
+			Digest:    digest,
+			Timestamp: time.Now(),
+		}
+		f.mu.Lock()
+		listeners := append([]func(pb.ApprovalPendingEvent){}, f.listeners...)
+		f.mu.Unlock()
+		for _, fn := range listeners {
+			fn(event)
+		}
+	}
+	return nil, &SecondFactorError{RequestID: requestID, Digest: digest, Err: err}
+}
@@ -0,0 +1,59 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/auth"
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	pb "ghost/kernel/internal/protocol"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestApproveActionNeedsSecondFactor(t *testing.T) {
+	ctx := auth.WithRole(context.Background(), auth.RoleHuman)
+	f := newKillSwitchFixture(t)
+	f.service.AuditRepo = f.auditRepo
+
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	approvals, err := conscience.NewApprovals([]byte("signing-key"), secret, nil, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.service.SecondFactor = NewSecondFactor(approvals)
+
+	action := domain.NewActionProposal("send payment", HighRiskScore, json.RawMessage(`{}`), "banking")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	if err := f.actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.service.ApproveAction(ctx, &pb.ApprovalDecision{ActionId: action.ID, Approved: true})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("ApproveAction() without a code error = %v, want PermissionDenied", err)
+	}
+	if !strings.Contains(status.Convert(err).Message(), "proposal:"+action.ID) {
+		t.Errorf("refusal %v does not name the request an approval client confirms", err)
+	}
+
+	decoded, _ := conscience.DecodeTOTPSecret(secret)
+	code := conscience.TOTPCode(decoded, uint64(time.Now().Unix())/30)
+	if _, err := f.service.ApproveAction(ctx, &pb.ApprovalDecision{ActionId: action.ID, Approved: true, Code: code}); err != nil {
+		t.Fatalf("ApproveAction() with the code error = %v", err)
+	}
+	records, err := f.auditRepo.GetRecent(ctx, 1)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("GetRecent() = %v, %v", records, err)
+	}
+	var detail map[string]interface{}
+	if err := json.Unmarshal(records[0].Detail, &detail); err != nil || detail["approval_id"] == nil || detail["factor"] != conscience.FactorTOTP {
+		t.Errorf("audit detail = %s, want the signed approval", records[0].Detail)
+	}
+}
@@ -3,15 +3,25 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/auth"
 	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/metrics"
 	pb "ghost/kernel/internal/protocol"
+	"ghost/kernel/internal/tracing"
 
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -30,6 +40,20 @@ type GhostService struct {
 	StateRepo *adapter.StateRepository
 	// Safety is the safety checker for intents and actions.
 	Safety *SafetyChecker
+	// AuditRepo records approvals and mode changes with the caller's role; nil disables auditing.
+	AuditRepo *adapter.AuditRepository
+	// Throttle rate-limits permission requests; nil admits everything.
+	Throttle *Throttle
+	// KillSwitch refuses new work while an emergency stop is engaged; nil leaves the stop RPCs unavailable.
+	KillSwitch *KillSwitch
+	// SecondFactor must confirm approving a high-risk proposal; nil refuses such approvals.
+	SecondFactor *SecondFactor
+	// GoalCanceller cancels goals with the work they spawned; nil leaves CancelGoal unavailable.
+	GoalCanceller *GoalCanceller
+	// Decisions records what the policy decided so it can be replayed; nil disables recording.
+	Decisions *adapter.DecisionLogRepository
+	// Grants stores standing approvals; nil leaves the grant RPCs unavailable.
+	Grants *adapter.GrantRepository
 
 	// focusMu protects focusState.
 	focusMu sync.RWMutex
@@ -38,6 +62,22 @@ type GhostService struct {
 
 	// actionChan is a buffered channel for sending action commands to the Body.
 	actionChan chan *pb.ActionCommand
+
+	// broadcastMu protects the commands every open action stream sends the Body exactly once,
+	// such as emergency stops and goal aborts: each is appended to broadcasts and closes broadcastSignal.
+	broadcastMu     sync.Mutex
+	broadcasts      []*pb.ActionCommand
+	broadcastBase   uint64 // Sequence number of broadcasts[0]; older ones have been trimmed
+	broadcastSignal chan struct{}
+	// revoked holds the traces of cancelled goals and when they were revoked; queued commands
+	// under them are dropped instead of sent. Guarded by broadcastMu.
+	revoked map[string]time.Time
+
+	// shutdown is closed by Shutdown to end open action streams.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	// background tracks fire-and-forget writes so shutdown can wait for them.
+	background sync.WaitGroup
 }
 
 // NewGhostService creates the service with dependencies.
@@ -48,13 +88,35 @@ func NewGhostService(
 	stateRepo *adapter.StateRepository,
 ) *GhostService {
 	return &GhostService{
-		ActionRepo: actionRepo,
-		IntentRepo: intentRepo,
-		MemoryRepo: memoryRepo,
-		StateRepo:  stateRepo,
-		Safety:     NewSafetyChecker(DefaultSafetyConfig()), // Use strict defaults by default
-		focusState: &pb.FocusState{WindowTitle: "Unknown"},
-		actionChan: make(chan *pb.ActionCommand, 100), // Buffer for safety
+		ActionRepo:      actionRepo,
+		IntentRepo:      intentRepo,
+		MemoryRepo:      memoryRepo,
+		StateRepo:       stateRepo,
+		Safety:          NewSafetyChecker(DefaultSafetyConfig()), // Use strict defaults by default
+		focusState:      &pb.FocusState{WindowTitle: "Unknown"},
+		actionChan:      make(chan *pb.ActionCommand, 100), // Buffer for safety
+		broadcastSignal: make(chan struct{}),
+		revoked:         make(map[string]time.Time),
+		shutdown:        make(chan struct{}),
+	}
+}
+
+// Shutdown ends open action streams and waits for pending background writes.
+// Call it before grpc.Server.GracefulStop, which otherwise waits on StreamActions forever.
+func (s *GhostService) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -78,71 +140,361 @@ func (s *GhostService) ReportFocus(stream pb.NervousSystem_ReportFocusServer) er
 
 // --- COGNITION ---
 
+// PermissionInput is what the policy rules look at in a permission request besides its intent.
+type PermissionInput struct {
+	Actions []*pb.Action `json:"actions"`
+}
+
+// permissionTrustScore is reported to the Brain for approved requests.
+const permissionTrustScore = 85
+
 // RequestPermission evaluates a request from the Brain to perform actions.
+// A dry run returns the decision trace without throttling, enqueueing or recording anything.
 func (s *GhostService) RequestPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
-	slog.Info("Permission Request", "intent", req.Intent, "trace_id", req.TraceId)
+	// The Brain's trace ID names the intent; without one the request's own trace stands in
+	ctx = tracing.WithTraceID(ctx, req.TraceId)
+	traceID := tracing.TraceID(ctx)
+	slog.Info("Permission Request", "intent", req.Intent, "trace_id", traceID, "dry_run", req.DryRun)
+
+	client := "unknown"
+	if role, ok := auth.RoleFromContext(ctx); ok {
+		client = string(role)
+	}
 
 	// 1. Check Current Focus (Context Awareness)
 	s.focusMu.RLock()
 	currentWindow := s.focusState.WindowTitle
+	currentProcess := s.focusState.ProcessName
 	s.focusMu.RUnlock()
 
-	// 2. Safety Check (Policy Engine)
-	isDangerous, kw := s.Safety.IsDangerous(req.Intent)
-	if isDangerous {
-		slog.Warn("Safety Violation", "intent", req.Intent, "keyword", kw)
-		return &pb.PermissionResponse{
-			Approved: false,
-			Reason:   "Violates Safety Policy: Blocked Keyword '" + kw + "'",
-		}, nil
+	trace := &domain.DecisionTrace{
+		Path:   metrics.PathGRPC,
+		Focus:  &domain.FocusCheck{Actual: currentWindow, Matched: true},
+		DryRun: req.DryRun,
+	}
+
+	// Nothing is enqueued while the emergency stop is engaged
+	if s.KillSwitch != nil {
+		engaged := s.KillSwitch.Engaged()
+		reason := ""
+		if engaged {
+			reason = "Emergency stop engaged: " + s.KillSwitch.Status().Reason
+		}
+		trace.Check(metrics.RuleEmergencyStop, !engaged, metrics.DecisionHalted, reason)
 	}
 
-	// 3. Action Validation
-	if ok, reason := s.Safety.ValidateActions(req.Actions); !ok {
-		slog.Warn("Action Validation Failed", "reason", reason, "trace_id", req.TraceId)
+	// 0. Back-pressure: a looping planner is refused before it reaches the queue
+	var throttled error
+	if s.Throttle != nil {
+		switch {
+		case req.DryRun:
+			throttled = s.Throttle.Peek(client, "", req.Intent)
+		case !trace.Decided():
+			throttled = s.Throttle.Allow(ctx, client, "", req.Intent)
+		}
+		reason := ""
+		if throttled != nil {
+			reason = throttled.Error()
+		}
+		trace.Check(metrics.RuleThrottle, throttled == nil, metrics.DecisionThrottled, reason)
+	}
+
+	// 2. Safety Check (Policy Engine) and 3. Action Validation
+	s.Safety.EvaluateRequest(trace, req.Intent, req.Actions)
+	trace.Settle(metrics.DecisionApproved, metrics.RulePassed)
+	if trace.Decision == metrics.DecisionApproved {
+		trace.TrustScore = permissionTrustScore
+	}
+
+	if req.DryRun {
 		return &pb.PermissionResponse{
-			Approved: false,
-			Reason:   "Safety Violation: " + reason,
+			Approved:   trace.Decision == metrics.DecisionApproved,
+			Reason:     trace.Reason,
+			TrustScore: int32(trace.TrustScore),
+			Trace:      traceToProto(trace),
 		}, nil
 	}
 
+	recordDecision(ctx, trace.Decision, trace.Rule)
+	switch trace.Decision {
+	case metrics.DecisionHalted:
+		return &pb.PermissionResponse{Approved: false, Reason: trace.Reason}, nil
+	case metrics.DecisionThrottled:
+		return nil, resourceExhausted(ctx, throttled)
+	case metrics.DecisionDenied:
+		slog.Warn("Safety Violation", "intent", req.Intent, "rule", trace.Rule, "reason", trace.Reason, "trace_id", traceID)
+		s.recordDenial(ctx, client)
+		s.logDecision(ctx, trace, req)
+		return &pb.PermissionResponse{Approved: false, Reason: trace.Reason}, nil
+	}
+	s.logDecision(ctx, trace, req)
+
 	// 4. Log Intent
 	// Note: We perform this async or ignore error to not block latency
+	s.background.Add(1)
 	go func() {
-		_ = s.IntentRepo.RecordSuccess(context.Background(), req.Intent, currentWindow, "")
+		defer s.background.Done()
+		_ = s.IntentRepo.RecordExecution(context.Background(), req.Intent, currentWindow, currentProcess, "")
 	}()
 
 	// 5. Enqueue approved actions to Body stream
 	for i, action := range req.Actions {
 		cmd := &pb.ActionCommand{
-			CommandId: fmt.Sprintf("%s-%d", req.TraceId, i),
+			CommandId: fmt.Sprintf("%s-%d", traceID, i),
 			Action:    action,
+			TraceId:   traceID,
 		}
 		select {
 		case s.actionChan <- cmd:
 			slog.Info("Action enqueued for Body", "id", cmd.CommandId, "type", action.Type)
+			tracing.Event(ctx, tracing.StageCommandEnqueued, attribute.String("command_id", cmd.CommandId), attribute.String("type", action.Type))
 		default:
 			slog.Warn("Action channel full, dropping", "id", cmd.CommandId)
+			metrics.ActionDropped(metrics.DropQueueFull, 1)
+			tracing.Event(ctx, tracing.StageCommandDropped, attribute.String("command_id", cmd.CommandId), attribute.String("reason", metrics.DropQueueFull))
 		}
 	}
+	metrics.ActionQueueDepth(len(s.actionChan))
 
 	return &pb.PermissionResponse{
 		Approved:   true,
-		TrustScore: 85,
+		TrustScore: permissionTrustScore,
 	}, nil
 }
 
+// logDecision records a request the policy rules decided, for replay against candidate policies.
+func (s *GhostService) logDecision(ctx context.Context, trace *domain.DecisionTrace, req *pb.PermissionRequest) {
+	if s.Decisions == nil {
+		return
+	}
+	record, err := domain.NewDecisionRecord(trace, req.Intent, "", tracing.TraceID(ctx), &PermissionInput{Actions: req.Actions})
+	if err == nil {
+		err = s.Decisions.Record(ctx, record)
+	}
+	if err != nil {
+		slog.Error("Failed to record permission decision", "intent", req.Intent, "error", err)
+	}
+}
+
+// traceToProto converts a decision trace for the gRPC response.
+func traceToProto(trace *domain.DecisionTrace) *pb.DecisionTrace {
+	out := &pb.DecisionTrace{
+		Path:       trace.Path,
+		Decision:   trace.Decision,
+		Rule:       trace.Rule,
+		Reason:     trace.Reason,
+		Mode:       trace.Mode,
+		TrustScore: int32(trace.TrustScore),
+		DryRun:     trace.DryRun,
+	}
+	for _, check := range trace.Checks {
+		out.Checks = append(out.Checks, &pb.RuleCheck{Rule: check.Rule, Passed: check.Passed, Detail: check.Detail})
+	}
+	for _, action := range trace.Actions {
+		out.Actions = append(out.Actions, &pb.ActionRisk{Index: int32(action.Index), Type: action.Type, Risk: int32(action.Risk)})
+	}
+	if trace.Focus != nil {
+		out.Focus = &pb.FocusCheck{Expected: trace.Focus.Expected, Actual: trace.Focus.Actual, Matched: trace.Focus.Matched}
+	}
+	return out
+}
+
+// recordDecision counts a permission decision and records it on the intent's trace.
+func recordDecision(ctx context.Context, decision, rule string) {
+	metrics.Permission(metrics.PathGRPC, decision, rule)
+	tracing.Decision(ctx, metrics.PathGRPC, decision, rule)
+}
+
+// recordDenial counts a refused request toward the throttle's circuit breaker.
+func (s *GhostService) recordDenial(ctx context.Context, client string) {
+	if s.Throttle != nil {
+		s.Throttle.RecordDenial(ctx, client, "")
+	}
+}
+
+// resourceExhausted tells the Brain to back off, with the wait in a retry-after header.
+func resourceExhausted(ctx context.Context, err error) error {
+	var throttled *ThrottleError
+	if errors.As(err, &throttled) {
+		seconds := int(throttled.RetryAfter.Round(time.Second) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+	}
+	return status.Error(codes.ResourceExhausted, err.Error())
+}
+
 // --- MOTOR CONTROL ---
 
 func (s *GhostService) StreamActions(_ *emptypb.Empty, stream pb.NervousSystem_StreamActionsServer) error {
 	slog.Info("Sentinel connected to Action Stream")
-	for cmd := range s.actionChan {
-		if err := stream.Send(cmd); err != nil {
-			slog.Error("Failed to send action", "error", err)
-			return err
+
+	s.broadcastMu.Lock()
+	seen := s.broadcastBase + uint64(len(s.broadcasts))
+	s.broadcastMu.Unlock()
+
+	for {
+		// Deliver any stop or abort raised since the last command, even one raised mid-send
+		pending, signal := s.broadcastsSince(&seen)
+		for _, cmd := range pending {
+			if err := stream.Send(cmd); err != nil {
+				slog.Error("Failed to send broadcast", "type", cmd.Action.Type, "error", err)
+				return err
+			}
+			slog.Warn("Broadcast sent to Body", "id", cmd.CommandId, "type", cmd.Action.Type)
+		}
+
+		select {
+		case <-stream.Context().Done():
+			slog.Info("Sentinel disconnected from Action Stream")
+			return stream.Context().Err()
+		case <-s.shutdown:
+			return status.Error(codes.Unavailable, "kernel is shutting down")
+		case <-signal:
+		case cmd := <-s.actionChan:
+			metrics.ActionQueueDepth(len(s.actionChan))
+			// Closes the gap between a permission check and an emergency stop draining the queue
+			if s.KillSwitch != nil && s.KillSwitch.Engaged() {
+				slog.Warn("Emergency stop engaged, dropping action", "id", cmd.CommandId)
+				metrics.ActionDropped(metrics.DropEmergencyStop, 1)
+				commandDropped(cmd, metrics.DropEmergencyStop)
+				continue
+			}
+			if s.isRevoked(cmd.TraceId) {
+				slog.Warn("Goal cancelled, dropping action", "id", cmd.CommandId)
+				metrics.ActionDropped(metrics.DropGoalCancelled, 1)
+				commandDropped(cmd, metrics.DropGoalCancelled)
+				continue
+			}
+			if err := stream.Send(cmd); err != nil {
+				slog.Error("Failed to send action", "error", err)
+				return err
+			}
+			tracing.Event(tracing.WithTraceID(stream.Context(), cmd.TraceId), tracing.StageCommandSent, attribute.String("command_id", cmd.CommandId))
+		}
+	}
+}
+
+// AbortActions drops every command queued for the Body and sends each open action stream a
+// STOP command. The kill switch calls it on every emergency stop.
+func (s *GhostService) AbortActions(reason string) {
+	dropped := 0
+drain:
+	for {
+		select {
+		case cmd := <-s.actionChan:
+			commandDropped(cmd, metrics.DropEmergencyStop)
+			dropped++
+		default:
+			break drain
 		}
 	}
-	return nil
+	metrics.ActionDropped(metrics.DropEmergencyStop, dropped)
+	metrics.ActionQueueDepth(len(s.actionChan))
+
+	s.broadcast("estop", &pb.Action{Type: ActionTypeStop, Payload: map[string]string{"reason": reason}})
+
+	slog.Warn("Aborted queued actions", "dropped", dropped)
+}
+
+// RevokeGoal withdraws a cancelled goal's commands: those still queued are dropped when a stream
+// reaches them, and each open action stream sends the Body an ABORT naming the goal's traces so it
+// drops what it has buffered and stops the step it is running. The goal canceller calls it.
+func (s *GhostService) RevokeGoal(cancellation *adapter.GoalCancellation, reason string) {
+	if !aborts(cancellation) {
+		return
+	}
+
+	now := time.Now()
+	s.broadcastMu.Lock()
+	for traceID, at := range s.revoked {
+		if now.Sub(at) > revokedTraceTTL {
+			delete(s.revoked, traceID)
+		}
+	}
+	for _, traceID := range cancellation.TraceIDs {
+		s.revoked[traceID] = now
+	}
+	s.broadcastMu.Unlock()
+
+	s.broadcast("abort", &pb.Action{Type: ActionTypeAbort, Payload: map[string]string{
+		"goal_id":   cancellation.Goal.ID,
+		"trace_ids": strings.Join(cancellation.TraceIDs, ","),
+		"reason":    reason,
+	}})
+}
+
+// revokedTraceTTL is how long a cancelled goal's queued commands keep being dropped
+const revokedTraceTTL = time.Hour
+
+// maxBroadcasts bounds the broadcast log; a stream that falls further behind misses the oldest
+const maxBroadcasts = 64
+
+// broadcast queues action for every open action stream and wakes them
+func (s *GhostService) broadcast(prefix string, action *pb.Action) {
+	s.broadcastMu.Lock()
+	defer s.broadcastMu.Unlock()
+
+	seq := s.broadcastBase + uint64(len(s.broadcasts)) + 1
+	s.broadcasts = append(s.broadcasts, &pb.ActionCommand{CommandId: fmt.Sprintf("%s-%d", prefix, seq), Action: action})
+	if over := len(s.broadcasts) - maxBroadcasts; over > 0 {
+		s.broadcasts = s.broadcasts[over:]
+		s.broadcastBase += uint64(over)
+	}
+	close(s.broadcastSignal)
+	s.broadcastSignal = make(chan struct{})
+}
+
+// broadcastsSince returns the broadcasts after seen, advances seen past them, and returns the
+// channel closed by the next broadcast
+func (s *GhostService) broadcastsSince(seen *uint64) ([]*pb.ActionCommand, <-chan struct{}) {
+	s.broadcastMu.Lock()
+	defer s.broadcastMu.Unlock()
+
+	if *seen < s.broadcastBase {
+		*seen = s.broadcastBase
+	}
+	pending := append([]*pb.ActionCommand(nil), s.broadcasts[*seen-s.broadcastBase:]...)
+	*seen = s.broadcastBase + uint64(len(s.broadcasts))
+	return pending, s.broadcastSignal
+}
+
+// isRevoked reports whether traceID belongs to a cancelled goal
+func (s *GhostService) isRevoked(traceID string) bool {
+	if traceID == "" {
+		return false
+	}
+	s.broadcastMu.Lock()
+	defer s.broadcastMu.Unlock()
+	_, ok := s.revoked[traceID]
+	return ok
+}
+
+// ReportOutcome records how the Body's execution of a streamed command turned out.
+func (s *GhostService) ReportOutcome(ctx context.Context, req *pb.ActionOutcome) (*pb.Ack, error) {
+	if req.CommandId == "" {
+		return nil, status.Error(codes.InvalidArgument, "command_id is required")
+	}
+	ctx = tracing.WithTraceID(ctx, req.TraceId)
+
+	outcome := "completed"
+	attrs := []attribute.KeyValue{attribute.String("command_id", req.CommandId), attribute.Bool("success", req.Success)}
+	if !req.Success {
+		outcome = "failed"
+		attrs = append(attrs, attribute.String("error", req.Error))
+		slog.Warn("Body reported failure", "id", req.CommandId, "trace_id", req.TraceId, "error", req.Error)
+	}
+	metrics.BodyExecution("stream", outcome)
+	tracing.Event(ctx, tracing.StageCommandOutcome, attrs...)
+
+	return &pb.Ack{Success: true}, nil
+}
+
+// commandDropped records a queued command that never reached the Body on its intent's trace.
+func commandDropped(cmd *pb.ActionCommand, reason string) {
+	ctx := tracing.WithTraceID(context.Background(), cmd.TraceId)
+	tracing.Event(ctx, tracing.StageCommandDropped, attribute.String("command_id", cmd.CommandId), attribute.String("reason", reason))
 }
 
 // --- HUMAN CONTROL PLANE (Gateway) ---
@@ -158,9 +510,13 @@ func (s *GhostService) GetSystemState(ctx context.Context, _ *emptypb.Empty) (*p
 	activeFocus := s.focusState.WindowTitle
 	s.focusMu.RUnlock()
 
+	estop := s.StateRepo.EmergencyStop()
+
 	return &pb.SystemState{
-		State:       string(stateStr),
-		ActiveFocus: activeFocus,
+		State:         string(stateStr),
+		ActiveFocus:   activeFocus,
+		EmergencyStop: estop.Engaged,
+		StopReason:    estop.Reason,
 	}, nil
 }
 
@@ -184,14 +540,55 @@ func (s *GhostService) GetPendingApprovals(ctx context.Context, _ *emptypb.Empty
 }
 
 func (s *GhostService) ApproveAction(ctx context.Context, req *pb.ApprovalDecision) (*pb.Ack, error) {
+	if req.Approved && s.KillSwitch != nil && s.KillSwitch.Engaged() {
+		return &pb.Ack{Success: false}, status.Error(codes.FailedPrecondition, "emergency stop engaged: re-arm before approving actions")
+	}
+
 	actionStatus := domain.ActionProposalStatusRejected
 	if req.Approved {
 		actionStatus = domain.ActionProposalStatusApproved
 	}
 
-	if err := s.ActionRepo.UpdateActionStatus(ctx, req.ActionId, actionStatus); err != nil {
+	// Looked up first so the approval latency covers only proposals that were waiting on a person
+	held, lookupErr := s.ActionRepo.GetActionByID(ctx, req.ActionId)
+	if held != nil {
+		ctx = tracing.WithTraceID(ctx, held.TraceID)
+	}
+	if held != nil && held.Status == domain.ActionProposalStatusBlocked {
+		return &pb.Ack{Success: false}, status.Error(codes.FailedPrecondition, "action is a step of a batch: decide the batch instead")
+	}
+
+	// A high-risk approval needs a second factor, which is used up even if the approval then fails
+	var approval *pb.SignedApproval
+	var err error
+	if req.Approved {
+		if held == nil {
+			err = lookupErr
+		} else if approval, err = s.SecondFactor.ApproveProposal(held, held.Payload, held.RiskScore, req.Code); err != nil {
+			slog.Warn("High-risk approval refused", "action_id", req.ActionId, "error", err)
+			return &pb.Ack{Success: false}, status.Error(codes.PermissionDenied, err.Error())
+		} else {
+			err = s.ActionRepo.ApproveAction(ctx, req.ActionId)
+			if err != nil {
+				s.SecondFactor.Release(approval)
+			}
+		}
+	} else {
+		err = s.ActionRepo.UpdateActionStatus(ctx, req.ActionId, actionStatus)
+	}
+	switch {
+	case errors.Is(err, adapter.ErrActionNotFound):
+		return &pb.Ack{Success: false}, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, adapter.ErrActionState):
+		return &pb.Ack{Success: false}, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
 		return &pb.Ack{Success: false}, status.Error(codes.Internal, err.Error())
 	}
+	if held != nil && held.Status == domain.ActionProposalStatusWaitingForUser {
+		metrics.ApprovalLatency(metrics.PathGRPC, req.Approved, time.Since(held.CreatedAt))
+	}
+	s.audit(ctx, adapter.AuditEventApproval, ApprovalDetail(map[string]interface{}{"action_id": req.ActionId}, req.Approved, approval))
+	tracing.Event(ctx, tracing.StageApproval, attribute.String("action_id", req.ActionId), attribute.Bool("approved", req.Approved))
 
 	// If approved, we might want to enqueue it to s.actionChan here immediately
 	// For now, we assume the Brain polls or streams "Approved" actions separately.
@@ -208,5 +605,212 @@ func (s *GhostService) SetSystemMode(ctx context.Context, req *pb.ModeRequest) (
 	if err := s.ActionRepo.SetUserMode(ctx, req.Domain, mode); err != nil {
 		return &pb.Ack{Success: false}, err
 	}
+	s.audit(ctx, adapter.AuditEventModeChange, map[string]string{"domain": req.Domain, "mode": string(mode)})
 	return &pb.Ack{Success: true}, nil
 }
+
+// --- KILL SWITCH ---
+
+// EmergencyStop halts agency on every path; any authenticated organ or person may call it.
+func (s *GhostService) EmergencyStop(ctx context.Context, req *pb.StopRequest) (*pb.StopResult, error) {
+	if s.KillSwitch == nil {
+		return nil, status.Error(codes.Unimplemented, "emergency stop is not configured")
+	}
+	result, err := s.KillSwitch.Halt(ctx, callerActor(ctx), req.Reason)
+	if err != nil {
+		// The stop is still in effect in memory; report that it was not persisted
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return result, nil
+}
+
+// Rearm releases the emergency stop once a person gives a reason.
+func (s *GhostService) Rearm(ctx context.Context, req *pb.RearmRequest) (*pb.StopResult, error) {
+	if s.KillSwitch == nil {
+		return nil, status.Error(codes.Unimplemented, "emergency stop is not configured")
+	}
+	result, err := s.KillSwitch.Rearm(ctx, callerActor(ctx), req.Reason)
+	switch {
+	case errors.Is(err, ErrRearmReason):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, adapter.ErrNotStopped):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return result, nil
+}
+
+// --- GOALS ---
+
+// CancelGoal cancels a goal and everything it spawned; the Brain may abandon a goal, a person may drop one.
+func (s *GhostService) CancelGoal(ctx context.Context, req *pb.CancelGoalRequest) (*pb.CancelGoalResult, error) {
+	if s.GoalCanceller == nil {
+		return nil, status.Error(codes.Unimplemented, "goal cancellation is not configured")
+	}
+	if req.GoalId == "" {
+		return nil, status.Error(codes.InvalidArgument, "goal_id is required")
+	}
+
+	cancellation, err := s.GoalCanceller.Cancel(ctx, callerActor(ctx), req.GoalId, req.Reason)
+	switch {
+	case errors.Is(err, adapter.ErrGoalNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, adapter.ErrGoalState):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.CancelGoalResult{
+		GoalId:            cancellation.Goal.ID,
+		Status:            string(cancellation.Goal.Status),
+		RejectedActions:   int32(cancellation.RejectedActions),
+		CancelledActions:  int32(cancellation.CancelledActions),
+		CancelledCommands: int32(cancellation.CancelledCommands),
+		Aborted:           aborts(cancellation),
+	}, nil
+}
+
+// --- STANDING APPROVALS ---
+
+// CreateGrant lets matching proposals run without asking, until the grant expires or is revoked.
+func (s *GhostService) CreateGrant(ctx context.Context, req *pb.CreateGrantRequest) (*pb.Grant, error) {
+	if s.Grants == nil {
+		return nil, status.Error(codes.Unimplemented, "grants are not configured")
+	}
+	if req.DurationSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "duration_seconds must not be negative")
+	}
+	var expiresAt *time.Time
+	if req.DurationSeconds > 0 {
+		expiry := time.Now().Add(time.Duration(req.DurationSeconds) * time.Second)
+		expiresAt = &expiry
+	}
+
+	grant, err := domain.NewGrant(req.ActionType, req.Process, req.PathPrefix, expiresAt, callerActor(ctx))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.Grants.SaveGrant(ctx, grant); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.audit(ctx, adapter.AuditEventGrantCreate, grantDetail(grant))
+	return grantProto(grant), nil
+}
+
+// ListGrants returns the grants in force, or every grant when asked to include inactive ones.
+func (s *GhostService) ListGrants(ctx context.Context, req *pb.ListGrantsRequest) (*pb.GrantList, error) {
+	if s.Grants == nil {
+		return nil, status.Error(codes.Unimplemented, "grants are not configured")
+	}
+	grants, err := s.Grants.ListGrants(ctx, req.IncludeInactive, time.Now())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	list := &pb.GrantList{}
+	for i := range grants {
+		list.Grants = append(list.Grants, grantProto(&grants[i]))
+	}
+	return list, nil
+}
+
+// RevokeGrant ends a grant at once; proposals it would have covered wait for the user again.
+func (s *GhostService) RevokeGrant(ctx context.Context, req *pb.RevokeGrantRequest) (*pb.Grant, error) {
+	if s.Grants == nil {
+		return nil, status.Error(codes.Unimplemented, "grants are not configured")
+	}
+	if req.GrantId == "" {
+		return nil, status.Error(codes.InvalidArgument, "grant_id is required")
+	}
+
+	grant, err := s.Grants.RevokeGrant(ctx, req.GrantId)
+	switch {
+	case errors.Is(err, adapter.ErrGrantNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, adapter.ErrGrantRevoked):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.audit(ctx, adapter.AuditEventGrantRevoke, grantDetail(grant))
+	return grantProto(grant), nil
+}
+
+// FocusedProcess returns the process the Sentinel last reported in focus.
+func (s *GhostService) FocusedProcess() string {
+	process, _ := s.Focus()
+	return process
+}
+
+// Focus returns the process and window title Sentinel last reported in focus.
+func (s *GhostService) Focus() (process, window string) {
+	s.focusMu.RLock()
+	defer s.focusMu.RUnlock()
+	return s.focusState.ProcessName, s.focusState.WindowTitle
+}
+
+// grantDetail is the audit detail of a grant: its scope, never what it was used on.
+func grantDetail(grant *domain.Grant) map[string]interface{} {
+	return map[string]interface{}{
+		"grant_id":    grant.ID,
+		"action_type": grant.ActionType,
+		"process":     grant.Process,
+		"path_prefix": grant.PathPrefix,
+		"expires_at":  grant.ExpiresAt,
+	}
+}
+
+// grantProto converts a grant for the control plane; unset times are 0.
+func grantProto(grant *domain.Grant) *pb.Grant {
+	unix := func(t *time.Time) int64 {
+		if t == nil {
+			return 0
+		}
+		return t.Unix()
+	}
+	return &pb.Grant{
+		GrantId:    grant.ID,
+		ActionType: grant.ActionType,
+		Process:    grant.Process,
+		PathPrefix: grant.PathPrefix,
+		ExpiresAt:  unix(grant.ExpiresAt),
+		CreatedBy:  grant.CreatedBy,
+		CreatedAt:  grant.CreatedAt.Unix(),
+		RevokedAt:  unix(grant.RevokedAt),
+	}
+}
+
+// callerActor names the caller in the audit log, like "grpc:human".
+func callerActor(ctx context.Context) string {
+	role, ok := auth.RoleFromContext(ctx)
+	if !ok {
+		role = "unknown"
+	}
+	return "grpc:" + string(role)
+}
+
+// ApprovalDetail completes the audit detail of an approval decision.
+// A decision a second factor let through names the signed approval, its factor and approver in place of
+// the bare approved flag, so the audit trail shows who confirmed it.
+func ApprovalDetail(detail map[string]interface{}, approved bool, approval *pb.SignedApproval) map[string]interface{} {
+	if approval == nil {
+		detail["approved"] = approved
+		return detail
+	}
+	detail["approval_id"], detail["factor"], detail["approver"] = approval.ID, approval.Factor, approval.Approver
+	return detail
+}
+
+// audit records a control-plane decision under the caller's role.
+// A failed write is logged rather than undoing a decision that has already been applied.
+func (s *GhostService) audit(ctx context.Context, event string, detail interface{}) {
+	if s.AuditRepo == nil {
+		return
+	}
+	actor := callerActor(ctx)
+	if _, err := s.AuditRepo.Record(ctx, event, actor, detail); err != nil {
+		slog.Error("Failed to audit decision", "event", event, "actor", actor, "error", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/auth"
+	"ghost/kernel/internal/domain"
+	pb "ghost/kernel/internal/protocol"
+	"ghost/kernel/internal/tracing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeActionStream captures commands sent on StreamActions
+type fakeActionStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *pb.ActionCommand
+}
+
+func (f *fakeActionStream) Context() context.Context { return f.ctx }
+
+func (f *fakeActionStream) Send(cmd *pb.ActionCommand) error {
+	f.sent <- cmd
+	return nil
+}
+
+func TestStreamActionsEndsOnShutdown(t *testing.T) {
+	s := NewGhostService(nil, nil, nil, nil)
+	stream := &fakeActionStream{ctx: context.Background(), sent: make(chan *pb.ActionCommand, 1)}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- s.StreamActions(nil, stream)
+	}()
+
+	s.actionChan <- &pb.ActionCommand{CommandId: "trace-0"}
+	if cmd := <-stream.sent; cmd.CommandId != "trace-0" {
+		t.Errorf("sent %q, want trace-0", cmd.CommandId)
+	}
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	select {
+	case err := <-result:
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("StreamActions() error = %v, want Unavailable", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("StreamActions() still blocked after Shutdown")
+	}
+}
+
+func TestStreamActionsEndsWhenClientLeaves(t *testing.T) {
+	s := NewGhostService(nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeActionStream{ctx: ctx, sent: make(chan *pb.ActionCommand, 1)}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- s.StreamActions(nil, stream)
+	}()
+	cancel()
+
+	select {
+	case err := <-result:
+		if err != context.Canceled {
+			t.Errorf("StreamActions() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("StreamActions() still blocked after the client disconnected")
+	}
+}
+
+func TestDecisionsAuditCallerRole(t *testing.T) {
+	ctx := context.Background()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	if _, err := adapter.Migrate(ctx, store); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	actionRepo, err := adapter.NewActionRepository(store)
+	if err != nil {
+		t.Fatalf("NewActionRepository() error = %v", err)
+	}
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatalf("NewAuditRepository() error = %v", err)
+	}
+
+	s := NewGhostService(actionRepo, nil, nil, nil)
+	s.AuditRepo = auditRepo
+	humanCtx := auth.WithRole(ctx, auth.RoleHuman)
+
+	if _, err := s.SetSystemMode(humanCtx, &pb.ModeRequest{Domain: "*", Mode: "AUTO"}); err != nil {
+		t.Fatalf("SetSystemMode() error = %v", err)
+	}
+	if _, err := s.ApproveAction(auth.WithRole(ctx, auth.RoleAdmin), &pb.ApprovalDecision{ActionId: "missing"}); err == nil {
+		t.Fatal("ApproveAction() of an unknown action succeeded")
+	}
+
+	records, err := auditRepo.GetRecent(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetRecent() error = %v", err)
+	}
+	// A failed approval changes nothing and is not audited
+	if len(records) != 1 {
+		t.Fatalf("audit log has %d entries, want 1", len(records))
+	}
+	if records[0].Event != adapter.AuditEventModeChange || records[0].Actor != "grpc:human" {
+		t.Errorf("audit entry = %s by %s, want %s by grpc:human", records[0].Event, records[0].Actor, adapter.AuditEventModeChange)
+	}
+}
+
+func TestTraceFollowsIntentToBody(t *testing.T) {
+	ctx := context.Background()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	actionRepo, err := adapter.NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intentRepo, err := adapter.NewIntentHistoryRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traceRepo, err := adapter.NewTraceRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracing.SetSink(traceRepo)
+	defer tracing.SetSink(nil)
+
+	s := NewGhostService(actionRepo, intentRepo, nil, nil)
+	s.AuditRepo = auditRepo
+
+	// Brain asks, the command reaches the Body tagged with the Brain's trace ID
+	resp, err := s.RequestPermission(auth.WithRole(ctx, auth.RoleBrain), &pb.PermissionRequest{
+		Intent:  "type greeting",
+		Actions: []*pb.Action{{Type: "TYPE", Payload: map[string]string{"text": "hello"}}},
+		TraceId: "brain-1",
+	})
+	if err != nil || !resp.Approved {
+		t.Fatalf("RequestPermission() = %v, %v; want approved", resp, err)
+	}
+
+	stream := &fakeActionStream{ctx: ctx, sent: make(chan *pb.ActionCommand, 1)}
+	result := make(chan error, 1)
+	go func() {
+		result <- s.StreamActions(nil, stream)
+	}()
+	cmd := <-stream.sent
+	if cmd.TraceId != "brain-1" || cmd.CommandId != "brain-1-0" {
+		t.Errorf("command = %s with trace %q, want brain-1-0 with trace brain-1", cmd.CommandId, cmd.TraceId)
+	}
+
+	if _, err := s.ReportOutcome(auth.WithRole(ctx, auth.RoleBody), &pb.ActionOutcome{CommandId: cmd.CommandId, TraceId: cmd.TraceId, Success: true}); err != nil {
+		t.Fatalf("ReportOutcome() error = %v", err)
+	}
+
+	// A proposal from the same intent is approved by a person
+	action := domain.NewActionProposal("send greeting", 40, json.RawMessage(`{}`), "chat")
+	action.Status = domain.ActionProposalStatusWaitingForUser
+	action.TraceID = "brain-1"
+	if err := actionRepo.SaveActionProposal(ctx, action); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ApproveAction(auth.WithRole(ctx, auth.RoleHuman), &pb.ApprovalDecision{ActionId: action.ID, Approved: true}); err != nil {
+		t.Fatalf("ApproveAction() error = %v", err)
+	}
+
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-result
+
+	events, err := traceRepo.Events(ctx, "brain-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stages []string
+	for _, e := range events {
+		stages = append(stages, e.Stage)
+	}
+	want := []string{tracing.StageDecision, tracing.StageCommandEnqueued, tracing.StageCommandSent, tracing.StageCommandOutcome, tracing.StageApproval}
+	if len(stages) != len(want) {
+		t.Fatalf("lifecycle = %v, want %v", stages, want)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Errorf("stage %d = %s, want %s", i, stages[i], want[i])
+		}
+	}
+	if events[0].Detail["rule"] != "passed" || events[0].Detail["path"] != "grpc" {
+		t.Errorf("decision detail = %v, want rule passed on grpc", events[0].Detail)
+	}
+
+	records, err := auditRepo.GetByTraceID(ctx, "brain-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Event != adapter.AuditEventApproval {
+		t.Errorf("audit for brain-1 = %v, want the approval", records)
+	}
+}
@@ -0,0 +1,329 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+)
+
+// ErrRateLimited is matched by every error Throttle.Allow returns.
+var ErrRateLimited = errors.New("rate limited")
+
+// ThrottleConfig bounds how fast proposals are accepted and when the breaker trips.
+// A zero rate disables that limiter; a zero threshold disables the breaker.
+type ThrottleConfig struct {
+	// ClientPerMinute and ClientBurst limit each caller (a role or gateway client).
+	ClientPerMinute int
+	ClientBurst     int
+	// DomainPerMinute and DomainBurst limit each automation domain.
+	DomainPerMinute int
+	DomainBurst     int
+	// IntentPerMinute and IntentBurst limit repeats of the same intent.
+	IntentPerMinute int
+	IntentBurst     int
+
+	// BreakerThreshold denials, failures or throttled requests within BreakerWindow trip the breaker.
+	BreakerThreshold int
+	BreakerWindow    time.Duration
+	// BreakerCooldown is how long a tripped breaker refuses every request from its source.
+	BreakerCooldown time.Duration
+}
+
+// DefaultThrottleConfig returns limits a well-behaved planner never reaches.
+func DefaultThrottleConfig() ThrottleConfig {
+	return ThrottleConfig{
+		ClientPerMinute:  120,
+		ClientBurst:      20,
+		DomainPerMinute:  60,
+		DomainBurst:      10,
+		IntentPerMinute:  20,
+		IntentBurst:      5,
+		BreakerThreshold: 20,
+		BreakerWindow:    time.Minute,
+		BreakerCooldown:  5 * time.Minute,
+	}
+}
+
+// ThrottleError tells a caller which limit it hit and when to try again.
+type ThrottleError struct {
+	// Scope is "client", "domain", "intent" or "breaker".
+	Scope string
+	// Key is the client, domain or intent that was limited.
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	if e.Scope == "breaker" {
+		return fmt.Sprintf("circuit breaker open for %s: back off for %s", e.Key, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("%s rate limit exceeded for %q: retry after %s", e.Scope, e.Key, e.RetryAfter.Round(time.Millisecond))
+}
+
+// MarshalJSON describes the limit for JSON-RPC error data and REST responses.
+func (e *ThrottleError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Error        string `json:"error"`
+		Scope        string `json:"scope"`
+		Key          string `json:"key"`
+		RetryAfterMs int64  `json:"retry_after_ms"`
+	}{e.Error(), e.Scope, e.Key, e.RetryAfter.Milliseconds()})
+}
+
+// Is makes every ThrottleError match ErrRateLimited.
+func (e *ThrottleError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// tokenBucket refills continuously at rate tokens per second up to burst.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// breaker counts recent denials for one source.
+type breaker struct {
+	events    []time.Time
+	openUntil time.Time
+}
+
+// Throttle rate-limits proposals and trips a circuit breaker on a misbehaving planner.
+// When the breaker trips for a domain, the domain is switched to MANUAL; when the source has
+// no domain, the whole system is switched to SHADOW. A person has to switch either back.
+type Throttle struct {
+	config ThrottleConfig
+
+	mu       sync.Mutex
+	buckets  map[string]*tokenBucket
+	breakers map[string]*breaker
+
+	actionRepo *adapter.ActionRepository
+	stateRepo  *adapter.StateRepository
+	auditRepo  *adapter.AuditRepository
+
+	now func() time.Time
+}
+
+// NewThrottle creates a throttle; call SetControls so a tripped breaker can change modes.
+func NewThrottle(config ThrottleConfig) *Throttle {
+	return &Throttle{
+		config:   config,
+		buckets:  make(map[string]*tokenBucket),
+		breakers: make(map[string]*breaker),
+		now:      time.Now,
+	}
+}
+
+// SetControls gives the breaker the repositories it switches modes through and audits to.
+func (t *Throttle) SetControls(actionRepo *adapter.ActionRepository, stateRepo *adapter.StateRepository, auditRepo *adapter.AuditRepository) {
+	t.actionRepo = actionRepo
+	t.stateRepo = stateRepo
+	t.auditRepo = auditRepo
+}
+
+// Allow admits one proposal or returns a *ThrottleError; a refusal counts toward the breaker.
+// client identifies the caller, domain may be empty, and intent is compared case-insensitively.
+func (t *Throttle) Allow(ctx context.Context, client, domainName, intent string) error {
+	source := breakerSource(client, domainName)
+
+	t.mu.Lock()
+	now := t.now()
+	limits := t.limits(client, domainName, intent)
+	refused := t.refusalLocked(source, limits, now)
+	if refused == nil {
+		for _, l := range limits {
+			t.buckets[l.scope+":"+l.key].tokens--
+		}
+		t.mu.Unlock()
+		return nil
+	}
+	if refused.Scope == "breaker" {
+		t.mu.Unlock()
+		return refused
+	}
+	tripped := t.recordLocked(source, now)
+	t.mu.Unlock()
+
+	if tripped {
+		t.trip(ctx, client, domainName)
+		return &ThrottleError{Scope: "breaker", Key: source, RetryAfter: t.config.BreakerCooldown}
+	}
+	return refused
+}
+
+// Peek returns the refusal Allow would return, without taking tokens or counting toward the breaker.
+func (t *Throttle) Peek(client, domainName, intent string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if refused := t.refusalLocked(breakerSource(client, domainName), t.limits(client, domainName, intent), t.now()); refused != nil {
+		return refused
+	}
+	return nil
+}
+
+// throttleLimit is one token bucket a proposal must draw from.
+type throttleLimit struct {
+	scope, key string
+	perMinute  int
+	burst      int
+}
+
+// limits lists the enabled buckets a proposal draws from.
+func (t *Throttle) limits(client, domainName, intent string) []throttleLimit {
+	intent = strings.ToLower(strings.TrimSpace(intent))
+	var limits []throttleLimit
+	for _, l := range []throttleLimit{
+		{"client", client, t.config.ClientPerMinute, t.config.ClientBurst},
+		{"domain", domainName, t.config.DomainPerMinute, t.config.DomainBurst},
+		{"intent", intent, t.config.IntentPerMinute, t.config.IntentBurst},
+	} {
+		if l.perMinute > 0 && l.key != "" {
+			limits = append(limits, l)
+		}
+	}
+	return limits
+}
+
+// refusalLocked checks the breaker and every bucket before anything is taken, so a refusal costs no tokens.
+func (t *Throttle) refusalLocked(source string, limits []throttleLimit, now time.Time) *ThrottleError {
+	if b, ok := t.breakers[source]; ok && now.Before(b.openUntil) {
+		return &ThrottleError{Scope: "breaker", Key: source, RetryAfter: b.openUntil.Sub(now)}
+	}
+	for _, l := range limits {
+		if wait := t.bucket(l.scope+":"+l.key, l.perMinute, l.burst, now); wait > 0 {
+			return &ThrottleError{Scope: l.scope, Key: l.key, RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// RecordDenial counts a safety denial or failed proposal toward the breaker.
+func (t *Throttle) RecordDenial(ctx context.Context, client, domainName string) {
+	t.mu.Lock()
+	tripped := t.recordLocked(breakerSource(client, domainName), t.now())
+	t.mu.Unlock()
+
+	if tripped {
+		t.trip(ctx, client, domainName)
+	}
+}
+
+// bucket refills the named bucket and returns how long until a token is available.
+func (t *Throttle) bucket(key string, perMinute, burst int, now time.Time) time.Duration {
+	if burst < 1 {
+		burst = 1
+	}
+	rate := float64(perMinute) / 60
+
+	b, ok := t.buckets[key]
+	if !ok {
+		t.pruneLocked(now)
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		t.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// pruneLocked drops buckets idle long enough to have refilled, bounding memory under many intents.
+func (t *Throttle) pruneLocked(now time.Time) {
+	if len(t.buckets) < 4096 {
+		return
+	}
+	for key, b := range t.buckets {
+		if now.Sub(b.last) > 10*time.Minute {
+			delete(t.buckets, key)
+		}
+	}
+}
+
+// recordLocked adds a denial and reports whether it tripped the breaker.
+func (t *Throttle) recordLocked(source string, now time.Time) bool {
+	if t.config.BreakerThreshold <= 0 {
+		return false
+	}
+	b, ok := t.breakers[source]
+	if !ok {
+		b = &breaker{}
+		t.breakers[source] = b
+	}
+	if now.Before(b.openUntil) {
+		return false
+	}
+
+	cutoff := now.Add(-t.config.BreakerWindow)
+	kept := b.events[:0]
+	for _, at := range b.events {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	b.events = append(kept, now)
+
+	if len(b.events) < t.config.BreakerThreshold {
+		return false
+	}
+	b.events = nil
+	b.openUntil = now.Add(t.config.BreakerCooldown)
+	return true
+}
+
+// trip takes automation away from the offending domain, or the whole system.
+func (t *Throttle) trip(ctx context.Context, client, domainName string) {
+	detail := map[string]interface{}{
+		"client":    client,
+		"threshold": t.config.BreakerThreshold,
+		"window":    t.config.BreakerWindow.String(),
+		"cooldown":  t.config.BreakerCooldown.String(),
+	}
+
+	if domainName != "" {
+		slog.Warn("Circuit breaker tripped: switching domain to MANUAL", "domain", domainName, "client", client)
+		detail["domain"] = domainName
+		detail["mode"] = string(domain.ModeTypeManual)
+		if t.actionRepo != nil {
+			if err := t.actionRepo.SetUserMode(ctx, domainName, domain.ModeTypeManual); err != nil {
+				slog.Error("Failed to switch domain to MANUAL", "domain", domainName, "error", err)
+			}
+		}
+	} else {
+		slog.Warn("Circuit breaker tripped: switching system to SHADOW", "client", client)
+		detail["state"] = string(domain.AppStateShadow)
+		if t.stateRepo != nil {
+			if err := t.stateRepo.SetState(ctx, domain.AppStateShadow); err != nil {
+				slog.Error("Failed to switch system to SHADOW", "error", err)
+			}
+		}
+	}
+
+	if t.auditRepo != nil {
+		if _, err := t.auditRepo.Record(ctx, adapter.AuditEventBreakerTrip, "kernel", detail); err != nil {
+			slog.Error("Failed to audit circuit breaker trip", "error", err)
+		}
+	}
+}
+
+// breakerSource is what a breaker is keyed by: the domain when known, else the client.
+func breakerSource(client, domainName string) string {
+	if domainName != "" {
+		return "domain:" + domainName
+	}
+	return "client:" + client
+}
@@ -0,0 +1,176 @@
+// Author: Enkae (enkae.dev@pm.me)
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/domain"
+)
+
+// newTestThrottle returns a throttle on a fake clock the test advances.
+func newTestThrottle(config ThrottleConfig) (*Throttle, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	t := NewThrottle(config)
+	t.now = func() time.Time { return now }
+	return t, &now
+}
+
+func TestThrottleTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	throttle, now := newTestThrottle(ThrottleConfig{ClientPerMinute: 60, ClientBurst: 3})
+
+	for i := 0; i < 3; i++ {
+		if err := throttle.Allow(ctx, "brain", "", "open editor"); err != nil {
+			t.Fatalf("request %d within burst: %v", i, err)
+		}
+	}
+
+	err := throttle.Allow(ctx, "brain", "", "open editor")
+	var throttled *ThrottleError
+	if !errors.As(err, &throttled) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("request over burst error = %v, want *ThrottleError", err)
+	}
+	if throttled.Scope != "client" || throttled.RetryAfter != time.Second {
+		t.Errorf("refusal = %+v, want client scope retrying after 1s", throttled)
+	}
+
+	// Other clients have their own bucket
+	if err := throttle.Allow(ctx, "gateway:ears", "", "open editor"); err != nil {
+		t.Errorf("other client refused: %v", err)
+	}
+
+	// One token refills per second at 60/min
+	*now = now.Add(time.Second)
+	if err := throttle.Allow(ctx, "brain", "", "open editor"); err != nil {
+		t.Errorf("request after refill: %v", err)
+	}
+}
+
+func TestThrottleIntentAndDomain(t *testing.T) {
+	ctx := context.Background()
+	throttle, _ := newTestThrottle(ThrottleConfig{DomainPerMinute: 60, DomainBurst: 2, IntentPerMinute: 60, IntentBurst: 1})
+
+	if err := throttle.Allow(ctx, "brain", "browser", "Open Tab"); err != nil {
+		t.Fatal(err)
+	}
+	// Intents are compared case-insensitively
+	err := throttle.Allow(ctx, "brain", "files", " open tab ")
+	var throttled *ThrottleError
+	if !errors.As(err, &throttled) || throttled.Scope != "intent" {
+		t.Fatalf("repeated intent error = %v, want intent scope", err)
+	}
+
+	// The refused request took no domain token, so the domain still has one left
+	if err := throttle.Allow(ctx, "brain", "browser", "close tab"); err != nil {
+		t.Fatalf("second browser proposal: %v", err)
+	}
+	err = throttle.Allow(ctx, "brain", "browser", "reload tab")
+	if !errors.As(err, &throttled) || throttled.Scope != "domain" {
+		t.Errorf("third browser proposal error = %v, want domain scope", err)
+	}
+}
+
+func TestThrottleBreakerSwitchesModes(t *testing.T) {
+	ctx := context.Background()
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(filepath.Join(t.TempDir(), "kernel.db")))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	if _, err := adapter.Migrate(ctx, store); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	actionRepo, err := adapter.NewActionRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateRepo, err := adapter.NewStateRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := actionRepo.SetUserMode(ctx, "browser", domain.ModeTypeAuto); err != nil {
+		t.Fatal(err)
+	}
+	if err := stateRepo.SetState(ctx, domain.AppStateActive); err != nil {
+		t.Fatal(err)
+	}
+
+	throttle, now := newTestThrottle(ThrottleConfig{BreakerThreshold: 3, BreakerWindow: time.Minute, BreakerCooldown: 5 * time.Minute})
+	throttle.SetControls(actionRepo, stateRepo, auditRepo)
+
+	// Denials spread wider than the window never trip it
+	for i := 0; i < 3; i++ {
+		throttle.RecordDenial(ctx, "brain", "browser")
+		*now = now.Add(45 * time.Second)
+	}
+	if mode, _ := actionRepo.GetUserMode(ctx, "browser"); mode.Mode != domain.ModeTypeAuto {
+		t.Fatalf("breaker tripped on spread-out denials: mode %s", mode.Mode)
+	}
+
+	for i := 0; i < 3; i++ {
+		throttle.RecordDenial(ctx, "brain", "browser")
+	}
+	if mode, _ := actionRepo.GetUserMode(ctx, "browser"); mode.Mode != domain.ModeTypeManual {
+		t.Errorf("domain mode after trip = %s, want MANUAL", mode.Mode)
+	}
+
+	// While open, the tripped domain is refused outright
+	err = throttle.Allow(ctx, "brain", "browser", "open tab")
+	var throttled *ThrottleError
+	if !errors.As(err, &throttled) || throttled.Scope != "breaker" {
+		t.Errorf("request to tripped domain error = %v, want breaker scope", err)
+	}
+	*now = now.Add(5 * time.Minute)
+	if err := throttle.Allow(ctx, "brain", "browser", "open tab"); err != nil {
+		t.Errorf("request after cooldown: %v", err)
+	}
+
+	// A source without a domain takes the whole system to SHADOW
+	for i := 0; i < 3; i++ {
+		throttle.RecordDenial(ctx, "brain", "")
+	}
+	if state, _ := stateRepo.GetState(ctx); state != domain.AppStateShadow {
+		t.Errorf("state after trip = %s, want SHADOW", state)
+	}
+
+	records, err := auditRepo.GetRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trips := 0
+	for _, r := range records {
+		if r.Event == adapter.AuditEventBreakerTrip {
+			trips++
+		}
+	}
+	if trips != 2 {
+		t.Errorf("audited %d breaker trips, want 2", trips)
+	}
+}
+
+func TestThrottleRefusalsTripBreaker(t *testing.T) {
+	ctx := context.Background()
+	throttle, _ := newTestThrottle(ThrottleConfig{
+		IntentPerMinute: 1, IntentBurst: 1,
+		BreakerThreshold: 3, BreakerWindow: time.Minute, BreakerCooldown: time.Minute,
+	})
+
+	// A planner hammering one intent is refused, then cut off
+	var last error
+	for i := 0; i < 5; i++ {
+		last = throttle.Allow(ctx, "brain", "", "retry forever")
+	}
+	var throttled *ThrottleError
+	if !errors.As(last, &throttled) || throttled.Scope != "breaker" {
+		t.Errorf("flood error = %v, want breaker scope", last)
+	}
+}
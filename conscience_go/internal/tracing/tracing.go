@@ -0,0 +1,149 @@
+// Author: Enkae (enkae.dev@pm.me)
+// Package tracing wires OpenTelemetry into the kernel and records the lifecycle of each intent.
+//
+// An intent is identified by its trace ID: the one the Brain supplies (PermissionRequest.trace_id,
+// ExecApprovalRequestParams.TraceID, /api/propose's trace_id), else the OpenTelemetry trace the
+// request arrived on. The ID travels on the context, on proposals, commands and audit entries, and
+// down to the Body on ActionCommand.trace_id, so /api/trace/{id} can replay one intent end to end.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Lifecycle stages recorded for an intent
+const (
+	StageDecision        = "policy.decision"
+	StageCommandEnqueued = "command.enqueued"
+	StageCommandSent     = "command.sent"
+	StageCommandDropped  = "command.dropped"
+	StageCommandOutcome  = "command.outcome"
+	StageProposalStatus  = "proposal.status"
+	StageApproval        = "approval.decision"
+)
+
+// AttrTraceID carries the intent's trace ID on every kernel span event
+const AttrTraceID = attribute.Key("ghost.trace_id")
+
+// Config selects where spans are exported
+type Config struct {
+	ServiceName string
+	Endpoint    string // OTLP/HTTP collector URL, e.g. http://localhost:4318; empty keeps spans in-process
+}
+
+// Sink persists lifecycle events so an intent can be reconstructed without a collector
+type Sink interface {
+	RecordTraceEvent(ctx context.Context, traceID, stage string, attrs map[string]string) error
+}
+
+// instrumentation names the kernel's own spans
+const instrumentation = "ghost/kernel"
+
+var (
+	sinkMu sync.RWMutex
+	sink   Sink
+)
+
+// Setup installs the global tracer provider and W3C trace-context propagation
+// The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	name := cfg.ServiceName
+	if name == "" {
+		name = "ghost-kernel"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if cfg.Endpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// SetSink directs lifecycle events to s; nil stops recording them
+func SetSink(s Sink) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	sink = s
+}
+
+// Start opens a kernel span, for entry points the gRPC and HTTP instrumentation does not cover
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+type traceIDKey struct{}
+
+// WithTraceID makes id the intent trace ID for ctx; an empty id leaves ctx unchanged
+func WithTraceID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceID returns the intent trace ID for ctx, falling back to the OpenTelemetry trace ID
+func TraceID(ctx context.Context) string {
+	if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return id
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// Event adds a span event for stage and records it against the intent's trace ID
+// Attributes must be identifiers and outcomes, never captured user content.
+func Event(ctx context.Context, stage string, attrs ...attribute.KeyValue) {
+	id := TraceID(ctx)
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent(stage, trace.WithAttributes(append(attrs, AttrTraceID.String(id))...))
+
+	sinkMu.RLock()
+	s := sink
+	sinkMu.RUnlock()
+	if s == nil || id == "" {
+		return
+	}
+
+	detail := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		detail[string(a.Key)] = a.Value.Emit()
+	}
+	if err := s.RecordTraceEvent(ctx, id, stage, detail); err != nil {
+		slog.Warn("Failed to record trace event", "trace_id", id, "stage", stage, "error", err)
+	}
+}
+
+// Decision records which rule decided a permission request, on the span and in the lifecycle
+func Decision(ctx context.Context, path, decision, rule string, attrs ...attribute.KeyValue) {
+	Event(ctx, StageDecision, append([]attribute.KeyValue{
+		attribute.String("path", path),
+		attribute.String("decision", decision),
+		attribute.String("rule", rule),
+	}, attrs...)...)
+}
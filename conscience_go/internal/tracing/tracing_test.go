@@ -0,0 +1,155 @@
+// Author: Enkae (enkae.dev@pm.me)
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collectorStub accepts OTLP/HTTP trace exports and keeps the spans
+type collectorStub struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collectorStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req collectortrace.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	c.mu.Unlock()
+
+	resp, _ := proto.Marshal(&collectortrace.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(resp)
+}
+
+// recordingSink keeps lifecycle events in memory
+type recordingSink struct {
+	mu     sync.Mutex
+	events []string
+	detail []map[string]string
+}
+
+func (s *recordingSink) RecordTraceEvent(_ context.Context, traceID, stage string, attrs map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, traceID+" "+stage)
+	s.detail = append(s.detail, attrs)
+	return nil
+}
+
+func TestDecisionIsExportedToCollector(t *testing.T) {
+	stub := &collectorStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	ctx := context.Background()
+	shutdown, err := Setup(ctx, Config{Endpoint: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spanCtx, span := Start(ctx, "RequestPermission")
+	Decision(WithTraceID(spanCtx, "brain-42"), "grpc", "denied", "blocked_keyword", attribute.String("action_id", "a1"))
+	span.End()
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown did not flush: %v", err)
+	}
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if len(stub.spans) != 1 || stub.spans[0].Name != "RequestPermission" {
+		t.Fatalf("collector received %d spans, want the RequestPermission span", len(stub.spans))
+	}
+	events := stub.spans[0].Events
+	if len(events) != 1 || events[0].Name != StageDecision {
+		t.Fatalf("span events = %v, want one %s", events, StageDecision)
+	}
+	got := make(map[string]string)
+	for _, kv := range events[0].Attributes {
+		got[kv.Key] = kv.Value.GetStringValue()
+	}
+	for key, want := range map[string]string{"rule": "blocked_keyword", "decision": "denied", string(AttrTraceID): "brain-42", "action_id": "a1"} {
+		if got[key] != want {
+			t.Errorf("event attribute %s = %q, want %q", key, got[key], want)
+		}
+	}
+}
+
+func TestTraceIDPrefersIntentID(t *testing.T) {
+	ctx := context.Background()
+	if _, err := Setup(ctx, Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := TraceID(ctx); got != "" {
+		t.Errorf("TraceID without a span = %q, want empty", got)
+	}
+
+	spanCtx, span := Start(ctx, "test")
+	defer span.End()
+	otelID := span.SpanContext().TraceID().String()
+	if got := TraceID(spanCtx); got != otelID {
+		t.Errorf("TraceID = %q, want the span's %q", got, otelID)
+	}
+	if got := TraceID(WithTraceID(spanCtx, "brain-7")); got != "brain-7" {
+		t.Errorf("TraceID = %q, want the Brain's brain-7", got)
+	}
+	if got := TraceID(WithTraceID(spanCtx, "")); got != otelID {
+		t.Errorf("empty intent ID replaced the span's trace ID: %q", got)
+	}
+}
+
+func TestEventsReachSink(t *testing.T) {
+	sink := &recordingSink{}
+	SetSink(sink)
+	defer SetSink(nil)
+
+	ctx := WithTraceID(context.Background(), "brain-9")
+	Event(ctx, StageCommandEnqueued, attribute.String("command_id", "brain-9-0"))
+	Event(ctx, StageCommandOutcome, attribute.Bool("success", true))
+	Event(context.Background(), StageCommandSent) // No trace ID: nothing to attach it to
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	want := []string{"brain-9 " + StageCommandEnqueued, "brain-9 " + StageCommandOutcome}
+	if len(sink.events) != len(want) {
+		t.Fatalf("sink got %v, want %v", sink.events, want)
+	}
+	for i := range want {
+		if sink.events[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, sink.events[i], want[i])
+		}
+	}
+	if sink.detail[0]["command_id"] != "brain-9-0" || sink.detail[1]["success"] != "true" {
+		t.Errorf("event detail = %v", sink.detail)
+	}
+}
@@ -0,0 +1,35 @@
+// Author: Enkae (enkae.dev@pm.me)
+//go:build linux
+
+package transport
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// peerCredentials reads SO_PEERCRED from a Unix socket connection
+func peerCredentials(conn net.Conn) (PeerAddr, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return PeerAddr{}, fmt.Errorf("not a unix socket connection: %T", conn)
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return PeerAddr{}, fmt.Errorf("failed to access socket: %w", err)
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return PeerAddr{}, fmt.Errorf("failed to access socket: %w", err)
+	}
+	if credErr != nil {
+		return PeerAddr{}, fmt.Errorf("failed to read SO_PEERCRED: %w", credErr)
+	}
+
+	return PeerAddr{PID: cred.Pid, UID: cred.Uid, GID: cred.Gid}, nil
+}
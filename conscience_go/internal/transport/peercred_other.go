@@ -0,0 +1,11 @@
+// Author: Enkae (enkae.dev@pm.me)
+//go:build !linux
+
+package transport
+
+import "net"
+
+// peerCredentials is unavailable here; the socket's file permissions still apply
+func peerCredentials(conn net.Conn) (PeerAddr, error) {
+	return PeerAddr{}, errPeerCredentialsUnsupported
+}
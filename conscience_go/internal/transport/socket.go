@@ -0,0 +1,113 @@
+// Author: Enkae (enkae.dev@pm.me)
+// Package transport provides the local transports for the NervousSystem gRPC service:
+// a Unix domain socket that checks the connecting process's credentials, and mutual TLS
+// backed by a certificate authority generated on first start.
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// errPeerCredentialsUnsupported is returned where the OS cannot report a socket peer
+var errPeerCredentialsUnsupported = errors.New("peer credentials are not supported on this platform")
+
+// PeerAddr identifies the process on the other end of a Unix socket connection
+// It is the connection's RemoteAddr, so gRPC exposes it through peer.FromContext.
+type PeerAddr struct {
+	Socket string
+	PID    int32
+	UID    uint32
+	GID    uint32
+}
+
+// Network implements net.Addr
+func (a PeerAddr) Network() string { return "unix" }
+
+// String implements net.Addr
+func (a PeerAddr) String() string {
+	return a.Socket + " pid=" + strconv.Itoa(int(a.PID)) + " uid=" + strconv.FormatUint(uint64(a.UID), 10)
+}
+
+// ListenUnix serves on a socket only the kernel's own user can open
+// The socket's directory is created 0700 and the socket chmod'ed 0600; on platforms that
+// support SO_PEERCRED, connections from any other user are also refused at accept time.
+func ListenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	// A previous run that was killed leaves its socket behind; never remove anything else
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("refusing to replace %s: not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	return &peerListener{Listener: l, path: path, uid: uint32(os.Getuid())}, nil
+}
+
+// peerListener refuses connections from processes running as another user
+type peerListener struct {
+	net.Listener
+	path string
+	uid  uint32
+}
+
+// Accept returns the next connection from an allowed peer
+func (l *peerListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		peer, err := peerCredentials(conn)
+		if errors.Is(err, errPeerCredentialsUnsupported) {
+			// The socket's file permissions are the only check left
+			return conn, nil
+		}
+		if err != nil {
+			slog.Warn("Rejected socket connection without peer credentials", "socket", l.path, "error", err)
+			conn.Close()
+			continue
+		}
+		peer.Socket = l.path
+
+		// Root may connect regardless, as it could read the kernel's tokens anyway
+		if peer.UID != l.uid && peer.UID != 0 {
+			slog.Warn("Rejected socket connection from another user", "peer", peer.String())
+			conn.Close()
+			continue
+		}
+		return &peerConn{Conn: conn, peer: peer}, nil
+	}
+}
+
+// peerConn reports the peer's credentials as its remote address
+type peerConn struct {
+	net.Conn
+	peer PeerAddr
+}
+
+func (c *peerConn) RemoteAddr() net.Addr {
+	return c.peer
+}
@@ -0,0 +1,93 @@
+// Author: Enkae (enkae.dev@pm.me)
+package transport
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "kernel.sock")
+	l, err := ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix() error = %v", err)
+	}
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %o, want 600", perm)
+	}
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	conn, ok := <-accepted
+	if !ok {
+		t.Fatal("Accept() failed")
+	}
+	defer conn.Close()
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	peer, ok := conn.RemoteAddr().(PeerAddr)
+	if !ok {
+		t.Fatalf("RemoteAddr() = %T, want PeerAddr", conn.RemoteAddr())
+	}
+	if peer.PID != int32(os.Getpid()) || peer.UID != uint32(os.Getuid()) {
+		t.Errorf("peer = %s, want pid=%d uid=%d", peer, os.Getpid(), os.Getuid())
+	}
+}
+
+func TestListenUnixReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kernel.sock")
+
+	// A crashed kernel leaves its socket file behind
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := ListenUnix(path)
+	if err != nil {
+		t.Fatalf("ListenUnix() over a stale socket error = %v", err)
+	}
+	l.Close()
+}
+
+func TestListenUnixRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kernel.db")
+	if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if l, err := ListenUnix(path); err == nil {
+		l.Close()
+		t.Fatal("ListenUnix() replaced a regular file")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Errorf("file was modified: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,124 @@
+// Author: Enkae (enkae.dev@pm.me)
+package transport
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// handshake runs a TLS handshake between the two configs over loopback TCP
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) (serverErr, clientErr error) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		server := tls.Server(conn, serverConfig)
+		err = server.Handshake()
+		if err == nil {
+			// TLS 1.3 reports a rejected client certificate on the first read
+			_, err = server.Read(make([]byte, 1))
+		}
+		done <- err
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	client := tls.Client(conn, clientConfig)
+	clientErr = client.Handshake()
+	if clientErr == nil {
+		_, clientErr = client.Write([]byte{1})
+	}
+	return <-done, clientErr
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	if err := EnsureCertificates(dir); err != nil {
+		t.Fatalf("EnsureCertificates() error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "ca.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("ca.key permissions = %o, want 600", perm)
+	}
+
+	serverConfig, err := ServerTLSConfig(dir)
+	if err != nil {
+		t.Fatalf("ServerTLSConfig() error = %v", err)
+	}
+	clientConfig, err := ClientTLSConfig(dir, "gateway")
+	if err != nil {
+		t.Fatalf("ClientTLSConfig() error = %v", err)
+	}
+
+	if serverErr, clientErr := handshake(t, serverConfig, clientConfig); serverErr != nil || clientErr != nil {
+		t.Fatalf("handshake failed: server %v, client %v", serverErr, clientErr)
+	}
+
+	// A client without a certificate is refused
+	anonymous := clientConfig.Clone()
+	anonymous.Certificates = nil
+	if serverErr, _ := handshake(t, serverConfig, anonymous); serverErr == nil {
+		t.Error("server accepted a client without a certificate")
+	}
+
+	// So is a client whose certificate comes from another kernel's CA
+	otherDir := t.TempDir()
+	if err := EnsureCertificates(otherDir); err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := ClientTLSConfig(otherDir, "brain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign.RootCAs = clientConfig.RootCAs
+	if serverErr, _ := handshake(t, serverConfig, foreign); serverErr == nil {
+		t.Error("server accepted a certificate from another CA")
+	}
+}
+
+func TestEnsureCertificatesKeepsExisting(t *testing.T) {
+	dir := t.TempDir()
+	if err := EnsureCertificates(dir); err != nil {
+		t.Fatalf("EnsureCertificates() error = %v", err)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, "brain.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := EnsureCertificates(dir); err != nil {
+		t.Fatalf("second EnsureCertificates() error = %v", err)
+	}
+	after, err := os.ReadFile(filepath.Join(dir, "brain.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("EnsureCertificates() reissued a valid certificate")
+	}
+}
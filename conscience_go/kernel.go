@@ -0,0 +1,765 @@
+// Author: Enkae (enkae.dev@pm.me)
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/auth"
+	"ghost/kernel/internal/config"
+	"ghost/kernel/internal/conscience"
+	"ghost/kernel/internal/domain"
+	"ghost/kernel/internal/gateway"
+	"ghost/kernel/internal/lifecycle"
+	"ghost/kernel/internal/metrics"
+	pb "ghost/kernel/internal/protocol"
+	"ghost/kernel/internal/server"
+	"ghost/kernel/internal/service"
+	"ghost/kernel/internal/tracing"
+	"ghost/kernel/internal/transport"
+)
+
+// kernel is the composition root: it owns every subsystem and their shutdown order
+type kernel struct {
+	cfg *config.Config
+	// nextKey, when set, re-encrypts the database under a new key before serving
+	nextKey adapter.KeySource
+
+	store     *adapter.Store
+	validator *conscience.Validator
+
+	ghostService *service.GhostService
+	grpcServer   *grpc.Server
+	httpServer   *http.Server
+	gateway      *gateway.Server
+	retention    *service.RetentionJob
+	scheduler    *service.GoalScheduler
+
+	// shutdownTracing flushes spans still buffered for the OTLP collector
+	shutdownTracing func(context.Context) error
+
+	// proxyCtx keeps the gRPC-gateway's client connection open until the HTTP server stops
+	proxyCtx    context.Context
+	cancelProxy context.CancelFunc
+
+	// lifecycle starts the components in dependency order and stops them in reverse
+	lifecycle *lifecycle.Manager
+}
+
+// newKernel opens the database and constructs every subsystem without starting any listener
+func newKernel(cfg *config.Config, nextKey adapter.KeySource) (*kernel, error) {
+	k := &kernel{cfg: cfg, nextKey: nextKey, lifecycle: lifecycle.NewManager()}
+	k.proxyCtx, k.cancelProxy = context.WithCancel(context.Background())
+
+	if err := k.build(); err != nil {
+		k.cancelProxy()
+		if k.shutdownTracing != nil {
+			k.shutdownTracing(context.Background())
+		}
+		if k.store != nil {
+			k.store.Close()
+		}
+		return nil, err
+	}
+	k.register()
+	return k, nil
+}
+
+// build wires storage, repositories, the Conscience and all three servers together
+func (k *kernel) build() error {
+	ctx := context.Background()
+
+	// 0. Tracing: spans for every gRPC, HTTP and gateway call, exported when a collector is configured
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{Endpoint: k.cfg.Tracing.Endpoint})
+	if err != nil {
+		return err
+	}
+	k.shutdownTracing = shutdownTracing
+
+	// 1. Database: one store for every repository, schema brought up to date
+	if err := os.MkdirAll(filepath.Dir(k.cfg.Database.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	store, err := adapter.OpenStore(adapter.DefaultStoreConfig(k.cfg.Database.Path))
+	if err != nil {
+		return fmt.Errorf("failed to open DB: %w", err)
+	}
+	k.store = store
+	if err := errors.Join(
+		metrics.RegisterDBPool("writer", store.Writer()),
+		metrics.RegisterDBPool("reader", store.Reader()),
+	); err != nil {
+		return fmt.Errorf("failed to register database metrics: %w", err)
+	}
+
+	applied, err := adapter.Migrate(ctx, store)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	for _, m := range applied {
+		slog.Info("Applied schema migration", "version", m.Version, "name", m.Name)
+	}
+
+	// 2. Repositories
+	actionRepo, err := adapter.NewActionRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init ActionRepository: %w", err)
+	}
+	intentRepo, err := adapter.NewIntentHistoryRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init IntentHistoryRepository: %w", err)
+	}
+	memoryRepo, err := adapter.NewSQLiteRepositoryWithDB(store)
+	if err != nil {
+		return fmt.Errorf("failed to init MemoryRepository: %w", err)
+	}
+	stateRepo, err := adapter.NewStateRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init StateRepository: %w", err)
+	}
+	goalRepo, err := adapter.NewGoalRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init GoalRepository: %w", err)
+	}
+	batchRepo, err := adapter.NewBatchRepository(store, actionRepo)
+	if err != nil {
+		return fmt.Errorf("failed to init BatchRepository: %w", err)
+	}
+	messageRepo, err := adapter.NewMessageRepository(store, actionRepo)
+	if err != nil {
+		return fmt.Errorf("failed to init MessageRepository: %w", err)
+	}
+	grantRepo, err := adapter.NewGrantRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init GrantRepository: %w", err)
+	}
+	commandRepo, err := adapter.NewCommandRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init CommandRepository: %w", err)
+	}
+	decisionRepo, err := adapter.NewDecisionLogRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init DecisionLogRepository: %w", err)
+	}
+	memoriesRepo, err := adapter.NewMemoryRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init MemoriesRepository: %w", err)
+	}
+	auditRepo, err := adapter.NewAuditRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init AuditRepository: %w", err)
+	}
+	traceRepo, err := adapter.NewTraceRepository(store)
+	if err != nil {
+		return fmt.Errorf("failed to init TraceRepository: %w", err)
+	}
+	retentionRepo := adapter.NewRetentionRepository(store)
+	backupRepo := adapter.NewBackupRepository(store)
+	// Lifecycle events land next to the proposals and commands they describe
+	tracing.SetSink(traceRepo)
+
+	// 3. At-rest encryption: refuse to serve if the key does not match the database. Encrypting existing
+	// rows or rotating the key checkpoints the WAL and VACUUMs, so the old values are gone from the file;
+	// backups taken before that still hold them.
+	currentKey := adapter.KeySource{Passphrase: k.cfg.Database.Passphrase, KeyFile: k.cfg.Database.KeyFile}
+	var cipher *adapter.FieldCipher
+	if !k.nextKey.IsZero() {
+		slog.Info("Rotating database encryption key...")
+		cipher, err = adapter.RotateEncryptionKey(ctx, store, currentKey, k.nextKey, 500)
+	} else {
+		cipher, err = adapter.OpenFieldCipher(ctx, store, currentKey)
+	}
+	if err != nil {
+		return fmt.Errorf("refusing to start: %w", err)
+	}
+	if cipher != nil {
+		encrypted, err := adapter.EncryptExistingRows(ctx, store, cipher, 500)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt existing rows: %w", err)
+		}
+		slog.Info("Database encryption enabled", "rows_encrypted", encrypted)
+	}
+	actionRepo.SetCipher(cipher)
+	memoryRepo.SetCipher(cipher)
+	commandRepo.SetCipher(cipher)
+	memoriesRepo.SetCipher(cipher)
+	backupRepo.SetCipher(cipher)
+	decisionRepo.SetCipher(cipher)
+	intentRepo.SetCipher(cipher)
+	auditRepo.SetCipher(cipher)
+
+	// 4. Logic: the Brain-facing service and the Conscience
+	k.ghostService = service.NewGhostService(actionRepo, intentRepo, memoryRepo, stateRepo)
+	safety := safetyPolicy(k.cfg)
+	if !safety.SafeMode {
+		slog.Warn("Safe mode disabled: intents and actions are not filtered")
+	}
+	k.ghostService.Safety = service.NewSafetyChecker(safety)
+	k.ghostService.Decisions = decisionRepo
+	k.ghostService.AuditRepo = auditRepo
+	throttle := service.NewThrottle(throttleConfig(k.cfg))
+	throttle.SetControls(actionRepo, stateRepo, auditRepo)
+	k.ghostService.Throttle = throttle
+	killSwitch := service.NewKillSwitch(stateRepo, auditRepo)
+	killSwitch.OnHalt(k.ghostService.AbortActions)
+	// Goals follow their plan: every proposal outcome re-derives the step and goal it belongs to
+	actionRepo.OnStatusChange(func(ctx context.Context, id string, _ domain.ActionProposalStatus) {
+		if _, err := goalRepo.SyncAction(ctx, id); err != nil {
+			slog.Warn("Failed to update goal plan", "action_id", id, "error", err)
+		}
+	})
+	killSwitch.OnHalt(func(string) {
+		if err := goalRepo.SyncOpenGoals(context.Background()); err != nil {
+			slog.Warn("Failed to update goal plans after emergency stop", "error", err)
+		}
+	})
+	// Batches release their next step, or start compensating, as each step's proposal finishes
+	actionRepo.OnStatusChange(func(ctx context.Context, id string, _ domain.ActionProposalStatus) {
+		if _, err := batchRepo.SyncAction(ctx, id); err != nil {
+			slog.Warn("Failed to advance action batch", "action_id", id, "error", err)
+		}
+	})
+	killSwitch.OnHalt(func(string) {
+		if err := batchRepo.SyncOpenBatches(context.Background()); err != nil {
+			slog.Warn("Failed to update action batches after emergency stop", "error", err)
+		}
+	})
+	k.ghostService.KillSwitch = killSwitch
+
+	// Goal cancellation withdraws the goal's proposals and commands and tells the Body to abort it
+	goalCanceller := service.NewGoalCanceller(goalRepo, auditRepo)
+	goalCanceller.OnCancel(k.ghostService.RevokeGoal)
+	k.ghostService.GoalCanceller = goalCanceller
+	k.ghostService.Grants = grantRepo
+	// Every decision the policy rules make is kept so a candidate policy can be replayed against it
+	k.validator = conscience.NewValidator()
+	k.validator.SetAllowedActions(safety.AllowedActions)
+	k.validator.OnDecision(func(ctx context.Context, record *domain.DecisionRecord) {
+		if err := decisionRepo.Record(ctx, record); err != nil {
+			slog.Error("Failed to record exec decision", "id", record.ID, "error", err)
+		}
+	})
+
+	// 5. gRPC server (Body and Brain), every call authenticated with a role token and checked
+	// against the per-RPC role table
+	authn, err := k.authenticator()
+	if err != nil {
+		return err
+	}
+	grpcOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(authn.UnaryServerInterceptor(), auth.NervousSystemPolicy.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(authn.StreamServerInterceptor(), auth.NervousSystemPolicy.StreamServerInterceptor()),
+	}
+	if k.cfg.GRPC.TLS {
+		if err := transport.EnsureCertificates(k.cfg.GRPC.TLSDir); err != nil {
+			return fmt.Errorf("failed to prepare gRPC certificates: %w", err)
+		}
+		tlsConfig, err := transport.ServerTLSConfig(k.cfg.GRPC.TLSDir)
+		if err != nil {
+			return err
+		}
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	k.grpcServer = grpc.NewServer(grpcOpts...)
+	pb.RegisterNervousSystemServer(k.grpcServer, k.ghostService)
+
+	// 6. REST API (/api/*) for the planner, dashboard and Sentinel
+	restServer := server.NewServer(memoryRepo, commandRepo, actionRepo, goalRepo, stateRepo)
+	restServer.SetDataGovernance(retentionRepo, auditRepo)
+	restServer.SetPortability(backupRepo, k.cfg.Database.BackupDir, k.cfg.HTTP.AdminToken)
+	restServer.SetThrottle(throttle)
+	restServer.SetSafety(k.ghostService.Safety)
+	restServer.SetDecisionLog(decisionRepo)
+	restServer.SetKillSwitch(killSwitch)
+	restServer.SetGoalCanceller(goalCanceller)
+	restServer.SetBatchRepository(batchRepo)
+	restServer.SetMessageRepository(messageRepo)
+	restServer.SetIntentHistory(intentRepo)
+	restServer.SetGrants(grantRepo, k.ghostService.FocusedProcess)
+	restServer.SetFocus(k.ghostService.Focus)
+	restServer.SetTraceRepository(traceRepo)
+
+	handler, err := k.httpHandler(restServer.Handler(), authn)
+	if err != nil {
+		return err
+	}
+	k.httpServer = &http.Server{
+		Addr:              fmt.Sprintf("127.0.0.1:%d", k.cfg.HTTP.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	// 7. JSON-RPC gateway with the Conscience deciding exec.request
+	token, err := loadOrCreateToken(k.cfg.Gateway.Token, k.cfg.Gateway.TokenFile, "gateway")
+	if err != nil {
+		return err
+	}
+	k.gateway = gateway.NewServer("127.0.0.1", k.cfg.Gateway.Port, token)
+	k.gateway.SetApprovalHandler(k.validator)
+	if err := k.secondFactor(restServer); err != nil {
+		return err
+	}
+	k.gateway.SetLimiter(throttle)
+	k.gateway.SetEmergencyStopper(killSwitch)
+	killSwitch.OnHalt(k.gateway.BroadcastStop)
+	k.gateway.SetMemoryHandler(service.NewMemoryService(memoriesRepo))
+
+	// Background compaction (retention windows + scheduled VACUUM)
+	k.retention = service.NewRetentionJob(retentionRepo, retentionJobConfig(k.cfg))
+
+	// Scheduled and recurring goals wake planners long-polling for work when they fire
+	k.scheduler = service.NewGoalScheduler(goalRepo, stateRepo, time.Duration(k.cfg.Scheduler.Interval))
+	k.scheduler.OnFire(restServer.GoalQueued)
+
+	return nil
+}
+
+// httpHandler mounts the gRPC-gateway (/v1/*), the REST API (/api/*) and the static dashboard
+func (k *kernel) httpHandler(api http.Handler, authn *auth.Authenticator) (http.Handler, error) {
+	// API Mux (gRPC Gateway) talking to the local gRPC server as the authenticated HTTP caller
+	apiMux := runtime.NewServeMux(
+		runtime.WithMetadata(authn.ProxyMetadata),
+		runtime.WithIncomingHeaderMatcher(auth.ProxyHeaderMatcher),
+	)
+	creds := insecure.NewCredentials()
+	if k.cfg.GRPC.TLS {
+		tlsConfig, err := transport.ClientTLSConfig(k.cfg.GRPC.TLSDir, "gateway")
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	// The client handler carries the HTTP request's trace into the proxied gRPC call
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds), grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
+	if err := pb.RegisterNervousSystemHandlerFromEndpoint(k.proxyCtx, apiMux, k.grpcTarget(), opts); err != nil {
+		return nil, fmt.Errorf("failed to register gateway: %w", err)
+	}
+
+	rootMux := http.NewServeMux()
+
+	// 1. gRPC Gateway patterns defined in proto (e.g. /v1/...)
+	rootMux.Handle("/v1/", auth.StripCredentials(apiMux))
+
+	// 2. REST API used by the Python planner and the Sentinel
+	rootMux.Handle("/api/", api)
+	// Approvals, replies, mode and state changes, re-arming and forgetting are for people only,
+	// matching auth.NervousSystemPolicy
+	rootMux.Handle("/api/approve/", auth.RestrictWrites(api, auth.RoleHuman, auth.RoleAdmin))
+	rootMux.Handle("/api/reply/", auth.RestrictWrites(api, auth.RoleHuman, auth.RoleAdmin))
+	rootMux.Handle("/api/batch/", auth.RestrictWrites(api, auth.RoleHuman, auth.RoleAdmin))
+	rootMux.Handle("/api/batch", api) // Proposing stays open; without this the mux redirects to /api/batch/
+	rootMux.Handle("/api/modes", auth.RestrictWrites(api, auth.RoleHuman, auth.RoleAdmin))
+	rootMux.Handle("/api/state", auth.RestrictWrites(api, auth.RoleHuman, auth.RoleAdmin))
+	rootMux.Handle("/api/forget", auth.RestrictWrites(api, auth.RoleHuman, auth.RoleAdmin))
+	rootMux.Handle("/api/estop/rearm", auth.RestrictWrites(api, auth.RoleHuman, auth.RoleAdmin))
+	// Execution status is the Sentinel's to report; a proposal's clarification thread stays open to the brain and people
+	rootMux.Handle("/api/actions/", auth.RestrictWrites(api, auth.RoleBody))
+	rootMux.Handle("/api/actions/{id}/messages", api)
+	// Artifacts are the Sentinel's to capture; the kernel stamps each with the focus the Sentinel last reported
+	rootMux.Handle("/api/artifacts", auth.RestrictWrites(api, auth.RoleBody))
+	rootMux.Handle("/health", api)
+	// Prometheus scrape endpoint; like /health it is readable on loopback without a token
+	rootMux.Handle("/metrics", metrics.Handler())
+	rootMux.Handle("/api/session", authn.SessionHandler())
+
+	// 3. Static frontend (build output from apps/landing or apps/dashboard)
+	staticDir := k.cfg.HTTP.StaticDir
+	fs := http.FileServer(http.Dir(staticDir))
+	rootMux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Clean path to prevent directory traversal
+		path := filepath.Clean(r.URL.Path)
+		fullPath := filepath.Join(staticDir, path)
+
+		// If file doesn't exist, serve index.html for client-side routing
+		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+			http.ServeFile(w, r, filepath.Join(staticDir, "index.html"))
+			return
+		}
+
+		fs.ServeHTTP(w, r)
+	}))
+
+	// Authentication: everything under /v1/ (it proxies to gRPC) and every mutating /api/ call
+	authenticated := authn.HTTPMiddleware(k.cfg.AllowedOrigins(), requiresAuth, rootMux)
+
+	// CORS middleware for frontend dashboard
+	allowedOrigins := k.cfg.AllowedOrigins()
+	cors := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		for _, allowed := range allowedOrigins {
+			if origin == allowed {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				w.Header().Set("Vary", "Origin")
+				break
+			}
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+auth.CSRFHeader)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+
+	// Host check first so a DNS-rebinding page never reaches CORS or auth
+	hosts := append(auth.LocalHosts(k.cfg.HTTP.Port), k.cfg.HTTP.AllowedHosts...)
+	traced := otelhttp.NewHandler(auth.HostGuard(hosts, cors), "http",
+		otelhttp.WithSpanNameFormatter(httpSpanName),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/metrics" && r.URL.Path != "/health"
+		}),
+	)
+	return traced, nil
+}
+
+// httpSpanName names a request span by method and route prefix, never by IDs in the path
+func httpSpanName(_ string, r *http.Request) string {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return r.Method + " /" + strings.Join(parts, "/")
+}
+
+// requiresAuth is the kernel's HTTP protection policy
+func requiresAuth(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/v1/") {
+		return true
+	}
+	// Logging in is how a dashboard obtains credentials in the first place
+	if r.URL.Path == "/api/session" {
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, "/api/") && auth.MutatingRequest(r)
+}
+
+// loadOrCreateToken returns configured, or the token kept in path, generating it on first start
+// Reusing the file keeps local clients working across restarts.
+func loadOrCreateToken(configured, path, name string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	}
+
+	token, err := auth.NewToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s token: %w", name, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s token directory: %w", name, err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s token: %w", name, err)
+	}
+	slog.Info("Generated token", "name", name, "path", path)
+	return token, nil
+}
+
+// authenticator loads every role's credential and builds the control-plane authenticator
+func (k *kernel) authenticator() (*auth.Authenticator, error) {
+	configured := map[auth.Role]string{
+		auth.RoleBrain: k.cfg.Auth.BrainToken,
+		auth.RoleBody:  k.cfg.Auth.BodyToken,
+		auth.RoleHuman: k.cfg.Auth.HumanToken,
+	}
+
+	tokens := map[auth.Role]string{auth.RoleAdmin: k.cfg.HTTP.AdminToken}
+	for role, token := range configured {
+		path := filepath.Join(k.cfg.Auth.TokenDir, string(role)+".token")
+		resolved, err := loadOrCreateToken(token, path, string(role))
+		if err != nil {
+			return nil, err
+		}
+		tokens[role] = resolved
+	}
+
+	return auth.NewAuthenticator(tokens, time.Duration(k.cfg.Auth.SessionTTL))
+}
+
+// secondFactor lets a one-time code or an approval client confirm the high-risk exec requests the
+// Conscience refuses and a person's approvals of high-risk proposals and batches, and lets those
+// clients connect to the gateway with their own tokens
+func (k *kernel) secondFactor(restServer *server.Server) error {
+	key, err := loadOrCreateToken("", k.cfg.Approval.KeyFile, "approval signing key")
+	if err != nil {
+		return err
+	}
+	approvals, err := conscience.NewApprovals([]byte(key), k.cfg.Approval.TOTPSecret, k.cfg.Approval.Clients, time.Duration(k.cfg.Approval.TTL))
+	if err != nil {
+		return fmt.Errorf("failed to set up approvals: %w", err)
+	}
+
+	approvers := make(map[string]string, len(k.cfg.Approval.Clients))
+	for _, id := range k.cfg.Approval.Clients {
+		path := filepath.Join(k.cfg.Auth.TokenDir, "approver-"+id+".token")
+		token, err := loadOrCreateToken("", path, "approver "+id)
+		if err != nil {
+			return err
+		}
+		approvers[id] = token
+	}
+
+	k.validator.SetApprovals(approvals)
+	k.gateway.SetConfirmer(k.validator)
+	k.gateway.SetApprovers(approvers)
+
+	secondFactor := service.NewSecondFactor(approvals)
+	secondFactor.OnPending(k.gateway.PublishPending)
+	k.ghostService.SecondFactor = secondFactor
+	restServer.SetSecondFactor(secondFactor)
+	return nil
+}
+
+// grpcTarget is the address the REST proxy dials, in gRPC target syntax
+func (k *kernel) grpcTarget() string {
+	if k.cfg.GRPC.Socket != "" {
+		// "unix:" accepts relative and absolute paths alike
+		return "unix:" + k.cfg.GRPC.Socket
+	}
+	return fmt.Sprintf("127.0.0.1:%d", k.cfg.GRPC.Port)
+}
+
+// listenGRPC opens the owner-only Unix socket when one is configured, else loopback TCP
+func (k *kernel) listenGRPC() (net.Listener, error) {
+	if k.cfg.GRPC.Socket != "" {
+		return transport.ListenUnix(k.cfg.GRPC.Socket)
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", k.cfg.GRPC.Port)
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return l, nil
+}
+
+// register adds the components in start order: storage first, the outward-facing servers last
+func (k *kernel) register() {
+	k.lifecycle.Add(lifecycle.Component{
+		Name: "database",
+		Stop: func(ctx context.Context) error {
+			// Fold the WAL into kernel.db so the file is complete while the kernel is down
+			checkpointErr := k.store.Checkpoint(ctx)
+			return errors.Join(checkpointErr, k.store.Close())
+		},
+	})
+
+	// Stopped after the servers so their last spans are flushed
+	k.lifecycle.Add(lifecycle.Component{
+		Name: "tracing",
+		Stop: k.shutdownTracing,
+	})
+
+	jobCtx, cancelJob := context.WithCancel(context.Background())
+	jobDone := make(chan struct{})
+	k.lifecycle.Add(lifecycle.Component{
+		Name: "retention",
+		Run: func() error {
+			defer close(jobDone)
+			k.retention.Run(jobCtx)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			// Wait for a compaction in progress so it does not race the database close
+			cancelJob()
+			select {
+			case <-jobDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	schedulerCtx, cancelScheduler := context.WithCancel(context.Background())
+	schedulerDone := make(chan struct{})
+	k.lifecycle.Add(lifecycle.Component{
+		Name: "scheduler",
+		Run: func() error {
+			defer close(schedulerDone)
+			k.scheduler.Run(schedulerCtx)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancelScheduler()
+			select {
+			case <-schedulerDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	var grpcListener net.Listener
+	k.lifecycle.Add(lifecycle.Component{
+		Name: "grpc",
+		Start: func(ctx context.Context) error {
+			l, err := k.listenGRPC()
+			if err != nil {
+				return err
+			}
+			grpcListener = l
+			slog.Info("gRPC Server listening", "addr", k.grpcTarget(), "tls", k.cfg.GRPC.TLS)
+			return nil
+		},
+		Run: func() error {
+			return k.grpcServer.Serve(grpcListener)
+		},
+		Stop: func(ctx context.Context) error {
+			// End action streams first; GracefulStop waits for every open stream
+			serviceErr := k.ghostService.Shutdown(ctx)
+
+			stopped := make(chan struct{})
+			go func() {
+				k.grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return serviceErr
+			case <-ctx.Done():
+				// Client streams such as ReportFocus only end when the connection is cut
+				k.grpcServer.Stop()
+				<-stopped
+				return serviceErr
+			}
+		},
+	})
+
+	var httpListener net.Listener
+	k.lifecycle.Add(lifecycle.Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			l, err := net.Listen("tcp", k.httpServer.Addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", k.httpServer.Addr, err)
+			}
+			httpListener = l
+			slog.Info("HTTP Gateway listening", "addr", k.httpServer.Addr)
+			return nil
+		},
+		Run: func() error {
+			if err := k.httpServer.Serve(httpListener); err != nil && err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			defer k.cancelProxy()
+			if err := k.httpServer.Shutdown(ctx); err != nil {
+				k.httpServer.Close()
+				return err
+			}
+			return nil
+		},
+	})
+
+	var gatewayListener net.Listener
+	k.lifecycle.Add(lifecycle.Component{
+		Name: "gateway",
+		Start: func(ctx context.Context) error {
+			l, err := k.gateway.Listen()
+			if err != nil {
+				return err
+			}
+			gatewayListener = l
+			return nil
+		},
+		Run: func() error {
+			return k.gateway.Serve(context.Background(), gatewayListener)
+		},
+		Stop: k.gateway.Shutdown,
+	})
+}
+
+// start binds every listener in order; a bind failure stops what already started
+func (k *kernel) start(ctx context.Context) error {
+	return k.lifecycle.Start(ctx)
+}
+
+// failed reports a server that exited on its own
+func (k *kernel) failed() <-chan error {
+	return k.lifecycle.Failed()
+}
+
+// shutdown stops the servers front to back, then background jobs, then checkpoints and closes the database
+func (k *kernel) shutdown(ctx context.Context) error {
+	return k.lifecycle.Stop(ctx)
+}
+
+// retentionJobConfig converts the retention settings for service.NewRetentionJob
+func retentionJobConfig(c *config.Config) service.RetentionConfig {
+	return service.RetentionConfig{
+		Policy: adapter.RetentionPolicy{
+			Artifacts:       time.Duration(c.Retention.Artifacts),
+			IntentHistory:   time.Duration(c.Retention.IntentHistory),
+			ActionProposals: time.Duration(c.Retention.ActionProposals),
+			Goals:           time.Duration(c.Retention.Goals),
+			Commands:        time.Duration(c.Retention.Commands),
+			TraceEvents:     time.Duration(c.Retention.TraceEvents),
+		},
+		CompactInterval: time.Duration(c.Retention.CompactInterval),
+		VacuumInterval:  time.Duration(c.Retention.VacuumInterval),
+	}
+}
+
+// throttleConfig converts the limits for service.NewThrottle
+func throttleConfig(c *config.Config) service.ThrottleConfig {
+	return service.ThrottleConfig{
+		ClientPerMinute:  c.Limits.ClientPerMinute,
+		ClientBurst:      c.Limits.ClientBurst,
+		DomainPerMinute:  c.Limits.DomainPerMinute,
+		DomainBurst:      c.Limits.DomainBurst,
+		IntentPerMinute:  c.Limits.IntentPerMinute,
+		IntentBurst:      c.Limits.IntentBurst,
+		BreakerThreshold: c.Limits.BreakerThreshold,
+		BreakerWindow:    time.Duration(c.Limits.BreakerWindow),
+		BreakerCooldown:  time.Duration(c.Limits.BreakerCooldown),
+	}
+}
+
+// safetyPolicy converts the safety settings for service.NewSafetyChecker
+func safetyPolicy(c *config.Config) service.SafetyConfig {
+	allowed := make(map[string]bool, len(c.Safety.AllowedActions))
+	for _, action := range c.Safety.AllowedActions {
+		allowed[strings.ToUpper(action)] = true
+	}
+	keywords := make([]string, len(c.Safety.BlockedKeywords))
+	for i, keyword := range c.Safety.BlockedKeywords {
+		keywords[i] = strings.ToLower(keyword)
+	}
+	return service.SafetyConfig{
+		SafeMode:         c.Safety.SafeMode,
+		BlockedKeywords:  keywords,
+		AllowedActions:   allowed,
+		AutoApproveBelow: c.Safety.AutoApproveBelow,
+	}
+}
@@ -0,0 +1,77 @@
+// Author: Enkae (enkae.dev@pm.me)
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ghost/kernel/internal/auth"
+	"ghost/kernel/internal/config"
+	"ghost/kernel/internal/service"
+)
+
+func TestRESTWritesReservedForPeople(t *testing.T) {
+	cfg := config.Default()
+	k := &kernel{cfg: &cfg, proxyCtx: context.Background()}
+	authn, err := auth.NewAuthenticator(map[auth.Role]string{
+		auth.RoleBrain: "brain-token-0123456789",
+		auth.RoleHuman: "human-token-0123456789",
+		auth.RoleBody:  "body-token-01234567890",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler, err := k.httpHandler(api, authn)
+	if err != nil {
+		t.Fatalf("httpHandler() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		method, path, token string
+		want                int
+	}{
+		{http.MethodPost, "/api/state", "brain-token-0123456789", http.StatusForbidden},
+		{http.MethodPost, "/api/forget", "brain-token-0123456789", http.StatusForbidden},
+		{http.MethodPost, "/api/state", "human-token-0123456789", http.StatusOK},
+		{http.MethodPost, "/api/forget", "human-token-0123456789", http.StatusOK},
+		{http.MethodGet, "/api/state", "", http.StatusOK},
+		{http.MethodPost, "/api/actions/a1/executing", "brain-token-0123456789", http.StatusForbidden},
+		{http.MethodPost, "/api/actions/a1/complete", "human-token-0123456789", http.StatusForbidden},
+		{http.MethodPost, "/api/actions/a1/executing", "body-token-01234567890", http.StatusOK},
+		{http.MethodPost, "/api/actions/a1/messages", "brain-token-0123456789", http.StatusOK},
+		{http.MethodPost, "/api/actions/a1/messages", "human-token-0123456789", http.StatusOK},
+		{http.MethodPost, "/api/artifacts", "brain-token-0123456789", http.StatusForbidden},
+		{http.MethodPost, "/api/artifacts", "body-token-01234567890", http.StatusOK},
+		{http.MethodPost, "/api/artifacts/a1/enrich", "brain-token-0123456789", http.StatusOK},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`))
+		req.Host = fmt.Sprintf("127.0.0.1:%d", cfg.HTTP.Port)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s with %q: status %d, want %d", tt.method, tt.path, tt.token, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestConfigDefaultsMatchSubsystemDefaults(t *testing.T) {
+	cfg := config.Default()
+	if got, want := retentionJobConfig(&cfg), service.DefaultRetentionConfig(); !reflect.DeepEqual(got, want) {
+		t.Errorf("retention defaults = %+v, want %+v", got, want)
+	}
+	if got, want := throttleConfig(&cfg), service.DefaultThrottleConfig(); !reflect.DeepEqual(got, want) {
+		t.Errorf("limits defaults = %+v, want %+v", got, want)
+	}
+	if got, want := safetyPolicy(&cfg), service.DefaultSafetyConfig(); !reflect.DeepEqual(got, want) {
+		t.Errorf("safety defaults = %+v, want %+v", got, want)
+	}
+}
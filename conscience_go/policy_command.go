@@ -0,0 +1,99 @@
+// Author: Enkae (enkae.dev@pm.me)
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"ghost/kernel/internal/adapter"
+	"ghost/kernel/internal/config"
+	"ghost/kernel/internal/service"
+)
+
+// runPolicyCommand implements `ghost policy <command>` and returns the process exit code
+func runPolicyCommand(args []string) int {
+	fs := flag.NewFlagSet("policy", flag.ContinueOnError)
+	loader := config.NewLoader(fs)
+	since := fs.Duration("since", 7*24*time.Hour, "replay: how far back to replay decisions")
+	limit := fs.Int("limit", 0, "replay: replay at most this many decisions, oldest first (0 for all)")
+	asJSON := fs.Bool("json", false, "replay: print the full report as JSON")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "Usage: ghost policy [flags] <command>")
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "Commands:")
+		fmt.Fprintln(out, "  replay <file>    re-evaluate recorded permission decisions under the safety section of a candidate config file")
+		fmt.Fprintln(out, "                   and report which would change")
+		fmt.Fprintln(out, "")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 || fs.Arg(0) != "replay" {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+	candidate, err := config.LoadFile(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load candidate policy: %v\n", err)
+		return 2
+	}
+
+	db, err := adapter.OpenStore(adapter.DefaultStoreConfig(cfg.Database.Path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	decisionRepo, err := adapter.NewDecisionLogRepository(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to init DecisionLogRepository: %v\n", err)
+		return 1
+	}
+	// Recorded requests are encrypted with the rest of the sensitive columns
+	cipher, err := adapter.OpenFieldCipher(ctx, db, adapter.KeySource{Passphrase: cfg.Database.Passphrase, KeyFile: cfg.Database.KeyFile})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open database key: %v\n", err)
+		return 1
+	}
+	decisionRepo.SetCipher(cipher)
+
+	records, err := decisionRepo.List(ctx, time.Now().Add(-*since), *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read decisions: %v\n", err)
+		return 1
+	}
+	report := service.ReplayDecisions(records, safetyPolicy(candidate))
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Printf("replayed %d decisions from the last %s: %d would change, %d skipped\n", report.Evaluated, *since, report.Changed, report.Skipped)
+	for _, c := range report.Changes {
+		fmt.Printf("  %s  %-7s  %s -> %s  %q\n", c.CreatedAt.Format("2006-01-02 15:04:05"), c.Path, c.Before, c.After, c.Intent)
+		if c.Reason != "" {
+			fmt.Printf("      %s\n", c.Reason)
+		}
+	}
+	return 0
+}